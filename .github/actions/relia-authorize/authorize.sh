@@ -90,10 +90,11 @@ PY
   fi
 
   POLL_URL="$RELIA_URL/v1/approvals/$APPROVAL_ID"
-  echo "Approval required. Polling: $POLL_URL"
+  echo "Approval required. Waiting on: $POLL_URL"
 
-  for _ in {1..60}; do
-    S=$(curl -sS "$POLL_URL" -H "Authorization: Bearer $JWT")
+  # Each wait returns as soon as the approval is decided, or after 60s.
+  for _ in {1..5}; do
+    S=$(curl -sS --max-time 90 "$POLL_URL/wait?timeout=60s" -H "Authorization: Bearer $JWT" || echo '{}')
     STATUS=$(echo "$S" | python - <<'PY'
 import json
 import sys
@@ -108,8 +109,10 @@ PY
     elif [[ "$STATUS" == "denied" ]]; then
       echo "Denied."
       exit 2
+    elif [[ "$STATUS" == "expired" ]]; then
+      echo "Approval expired."
+      exit 2
     fi
-    sleep 5
   done
 
   RESP=$(curl -sS -X POST "$RELIA_URL/v1/authorize" \
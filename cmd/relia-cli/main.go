@@ -13,8 +13,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/davidahmann/relia/internal/crypto"
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/ledger/pgstore"
+	"github.com/davidahmann/relia/internal/ledger/sqlstore"
+	"github.com/davidahmann/relia/internal/pack"
 	"github.com/davidahmann/relia/internal/policy"
+	"github.com/davidahmann/relia/pkg/types"
 )
 
 const defaultAddr = "http://localhost:8080"
@@ -40,6 +47,8 @@ func run(args []string, stdout io.Writer, stderr io.Writer) int {
 		return handlePolicy(args[2:], stdout, stderr)
 	case "keys":
 		return handleKeys(args[2:], stdout, stderr)
+	case "outbox":
+		return handleOutbox(args[2:], stdout, stderr)
 	default:
 		usage(stderr)
 		return 2
@@ -52,11 +61,28 @@ func handleVerify(args []string, stdout io.Writer, stderr io.Writer) int {
 	addr := fs.String("addr", envOrDefault("RELIA_ADDR", defaultAddr), "Relia API address")
 	jsonOut := fs.Bool("json", false, "print raw JSON response")
 	token := fs.String("token", envOrDefault("RELIA_TOKEN", os.Getenv("RELIA_DEV_TOKEN")), "bearer token")
+	packPath := fs.String("pack", "", "verify a pack zip offline (requires --pubkey)")
+	receiptPath := fs.String("receipt", "", "verify a receipt.json offline (requires --pubkey)")
+	pubkeyPath := fs.String("pubkey", "", "path to the Ed25519 public key for offline verification")
 	if err := fs.Parse(args); err != nil {
 		fs.Usage()
 		return 2
 	}
 
+	if *packPath != "" || *receiptPath != "" {
+		if *packPath != "" && *receiptPath != "" {
+			fmt.Fprintln(stderr, "verify accepts only one of --pack or --receipt")
+			fs.Usage()
+			return 2
+		}
+		if *pubkeyPath == "" || fs.NArg() != 0 {
+			fmt.Fprintln(stderr, "offline verify requires --pubkey and no <receipt_id>")
+			fs.Usage()
+			return 2
+		}
+		return verifyOffline(*packPath, *receiptPath, *pubkeyPath, *jsonOut, stdout, stderr)
+	}
+
 	if fs.NArg() != 1 {
 		fmt.Fprintln(stderr, "verify requires <receipt_id>")
 		fs.Usage()
@@ -117,6 +143,70 @@ func handleVerify(args []string, stdout io.Writer, stderr io.Writer) int {
 	return 1
 }
 
+// verifyOffline checks a pack or receipt against a local public key without calling the gateway.
+func verifyOffline(packPath string, receiptPath string, pubkeyPath string, jsonOut bool, stdout io.Writer, stderr io.Writer) int {
+	pub, err := crypto.LoadEd25519PublicKey(pubkeyPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "load public key:", err)
+		return 1
+	}
+
+	var result pack.VerifyResult
+	if packPath != "" {
+		// #nosec G304 -- path is user-provided CLI input.
+		data, err := os.ReadFile(packPath)
+		if err != nil {
+			fmt.Fprintln(stderr, "read pack:", err)
+			return 1
+		}
+		files, err := pack.ReadZip(data)
+		if err != nil {
+			fmt.Fprintln(stderr, "read pack:", err)
+			return 1
+		}
+		result = pack.VerifyFiles(files, pub)
+	} else {
+		// #nosec G304 -- path is user-provided CLI input.
+		data, err := os.ReadFile(receiptPath)
+		if err != nil {
+			fmt.Fprintln(stderr, "read receipt:", err)
+			return 1
+		}
+		result = pack.VerifyReceiptJSON(data, pub)
+	}
+
+	if jsonOut {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		_, _ = stdout.Write(append(out, '\n'))
+		if result.Valid {
+			return 0
+		}
+		return 1
+	}
+
+	var receipt map[string]any
+	_ = json.Unmarshal(result.Receipt.BodyJSON, &receipt)
+
+	line := fmt.Sprintf("valid=%t receipt_id=%s", result.Valid, result.ReceiptID)
+	if !result.Valid {
+		line += " error=" + result.Error()
+	}
+	if result.Grade != "" {
+		line += " grade=" + result.Grade
+	}
+	if ir := formatInteractionRef(receipt); ir != "" {
+		line += " interaction=" + ir
+	}
+	if refs := formatRefs(receipt); refs != "" {
+		line += " refs=" + refs
+	}
+	fmt.Fprintln(stdout, line)
+	if result.Valid {
+		return 0
+	}
+	return 1
+}
+
 func formatInteractionRef(receipt map[string]any) string {
 	if receipt == nil {
 		return ""
@@ -228,25 +318,7 @@ func handlePolicy(args []string, stdout io.Writer, stderr io.Writer) int {
 	}
 	switch args[0] {
 	case "lint":
-		fs := flag.NewFlagSet("policy lint", flag.ContinueOnError)
-		fs.SetOutput(stderr)
-		if err := fs.Parse(args[1:]); err != nil {
-			fs.Usage()
-			return 2
-		}
-		if fs.NArg() != 1 {
-			fmt.Fprintln(stderr, "policy lint requires <policy_path>")
-			fs.Usage()
-			return 2
-		}
-		path := fs.Arg(0)
-		loaded, err := policy.LoadPolicy(path)
-		if err != nil {
-			fmt.Fprintln(stderr, err.Error())
-			return 1
-		}
-		fmt.Fprintf(stdout, "ok policy_id=%s policy_hash=%s\n", loaded.Policy.PolicyID, loaded.Hash)
-		return 0
+		return handlePolicyLint(args[1:], stdout, stderr)
 	case "test":
 		fs := flag.NewFlagSet("policy test", flag.ContinueOnError)
 		fs.SetOutput(stderr)
@@ -254,16 +326,48 @@ func handlePolicy(args []string, stdout io.Writer, stderr io.Writer) int {
 		action := fs.String("action", "", "action to test (required)")
 		resource := fs.String("resource", "", "resource to test (required)")
 		envName := fs.String("env", "", "environment to test (required)")
+		issuer := fs.String("issuer", "", "actor token issuer")
+		subject := fs.String("subject", "", "actor subject")
+		repo := fs.String("repo", "", "actor repository (owner/name)")
+		workflow := fs.String("workflow", "", "actor workflow ref")
+		sha := fs.String("sha", "", "actor commit sha")
+		ref := fs.String("ref", "", "actor git ref (e.g. refs/heads/main)")
+		environment := fs.String("environment", "", "actor deployment environment")
+		actor := fs.String("actor", "", "actor login")
+		intentJSON := fs.String("intent", "", "request intent as a JSON object")
+		planDigest := fs.String("plan-digest", "", "evidence plan digest")
+		diffURL := fs.String("diff-url", "", "evidence diff URL")
+		atFlag := fs.String("at", "", "evaluation time (RFC3339, default now)")
+		suite := fs.String("suite", "", "run the tests of every policy under this file or directory")
 		jsonOut := fs.Bool("json", false, "print raw JSON output")
 		if err := fs.Parse(args[1:]); err != nil {
 			fs.Usage()
 			return 2
 		}
+		if *suite != "" {
+			return runPolicySuite(*suite, *jsonOut, stdout, stderr)
+		}
 		if *policyPath == "" || *action == "" || *resource == "" || *envName == "" {
 			fmt.Fprintln(stderr, "policy test requires --policy --action --resource --env")
 			fs.Usage()
 			return 2
 		}
+		var intent map[string]any
+		if *intentJSON != "" {
+			if err := json.Unmarshal([]byte(*intentJSON), &intent); err != nil {
+				fmt.Fprintln(stderr, "invalid --intent:", err)
+				return 2
+			}
+		}
+		at := time.Now()
+		if *atFlag != "" {
+			parsed, err := time.Parse(time.RFC3339, *atFlag)
+			if err != nil {
+				fmt.Fprintln(stderr, "invalid --at:", err)
+				return 2
+			}
+			at = parsed
+		}
 		loaded, err := policy.LoadPolicy(*policyPath)
 		if err != nil {
 			fmt.Fprintln(stderr, err.Error())
@@ -271,9 +375,21 @@ func handlePolicy(args []string, stdout io.Writer, stderr io.Writer) int {
 		}
 
 		decision := policy.Evaluate(loaded.Policy, loaded.Hash, policy.Input{
-			Action:   *action,
-			Resource: *resource,
-			Env:      *envName,
+			Action:      *action,
+			Resource:    *resource,
+			Env:         *envName,
+			Issuer:      *issuer,
+			Subject:     *subject,
+			Repo:        *repo,
+			Workflow:    *workflow,
+			SHA:         *sha,
+			Ref:         *ref,
+			Environment: *environment,
+			Actor:       *actor,
+			Intent:      intent,
+			PlanDigest:  *planDigest,
+			DiffURL:     *diffURL,
+			At:          at,
 		})
 		if *jsonOut {
 			out, _ := json.MarshalIndent(decision, "", "  ")
@@ -287,6 +403,9 @@ func handlePolicy(args []string, stdout io.Writer, stderr io.Writer) int {
 		} else {
 			fmt.Fprintf(stdout, "matched_rule=<defaults>\n")
 		}
+		if len(decision.MatchedRuleIDs) > 1 {
+			fmt.Fprintf(stdout, "matched_rules=%s\n", strings.Join(decision.MatchedRuleIDs, ","))
+		}
 		fmt.Fprintf(stdout, "policy_id=%s policy_version=%s policy_hash=%s\n", decision.PolicyID, decision.PolicyVersion, decision.PolicyHash)
 		if decision.AWSRoleARN != "" {
 			fmt.Fprintf(stdout, "aws_role_arn=%s\n", decision.AWSRoleARN)
@@ -304,12 +423,333 @@ func handlePolicy(args []string, stdout io.Writer, stderr io.Writer) int {
 			fmt.Fprintf(stdout, "reason_codes=%s\n", strings.Join(decision.ReasonCodes, ","))
 		}
 		return 0
+	case "diff":
+		return handlePolicyDiff(args[1:], stdout, stderr)
 	default:
 		usage(stderr)
 		return 2
 	}
 }
 
+// runPolicySuite runs embedded and sibling policy tests and exits 1 on any mismatch.
+func runPolicySuite(path string, jsonOut bool, stdout io.Writer, stderr io.Writer) int {
+	targets, err := policy.LoadSuite(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+
+	type suiteReport struct {
+		Policy     string              `json:"policy"`
+		PolicyHash string              `json:"policy_hash"`
+		Results    []policy.TestResult `json:"results"`
+	}
+	reports := []suiteReport{}
+	passed, failed := 0, 0
+	for _, target := range targets {
+		if len(target.Tests) == 0 {
+			continue
+		}
+		results := policy.RunTests(target.Policy, target.Tests)
+		for _, r := range results {
+			if r.Passed {
+				passed++
+			} else {
+				failed++
+			}
+		}
+		reports = append(reports, suiteReport{Policy: target.Path, PolicyHash: target.Policy.Hash, Results: results})
+	}
+
+	if jsonOut {
+		out, _ := json.MarshalIndent(struct {
+			Passed   int           `json:"passed"`
+			Failed   int           `json:"failed"`
+			Policies []suiteReport `json:"policies"`
+		}{passed, failed, reports}, "", "  ")
+		_, _ = stdout.Write(append(out, '\n'))
+	} else {
+		for _, report := range reports {
+			for _, r := range report.Results {
+				status := "ok"
+				if !r.Passed {
+					status = "FAIL"
+				}
+				fmt.Fprintf(stdout, "%s %s:%d %s\n", status, r.File, r.Line, r.Name)
+				for _, m := range r.Mismatches {
+					fmt.Fprintf(stdout, "    %s: want %s, got %s\n", m.Field, m.Want, m.Got)
+				}
+			}
+		}
+		fmt.Fprintf(stdout, "%d passed, %d failed\n", passed, failed)
+	}
+
+	if passed+failed == 0 {
+		fmt.Fprintf(stderr, "no policy tests found under %s\n", path)
+		return 1
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func handlePolicyDiff(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("policy diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	oldPath := fs.String("old", "", "path to the current policy (required)")
+	newPath := fs.String("new", "", "path to the proposed policy (required)")
+	requestsPath := fs.String("requests", "", "JSONL corpus of policy inputs")
+	fromLedger := fs.Bool("from-ledger", false, "replay inputs from stored contexts")
+	dbDriver := fs.String("db-driver", envOrDefault("RELIA_DB_DRIVER", "sqlite"), "ledger driver for --from-ledger (sqlite|postgres)")
+	dbDSN := fs.String("db-dsn", envOrDefault("RELIA_DB_DSN", "file:relia.db?_journal_mode=WAL"), "ledger DSN for --from-ledger")
+	since := fs.String("since", "", "with --from-ledger, only contexts created at or after this RFC3339 time")
+	limit := fs.Int("limit", 10000, "with --from-ledger, maximum contexts to replay")
+	jsonOut := fs.Bool("json", false, "print the report as JSON")
+	failOnChange := fs.Bool("fail-on-change", false, "exit 1 when any outcome changes")
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		return 2
+	}
+	if *oldPath == "" || *newPath == "" || (*requestsPath == "") == !*fromLedger {
+		fmt.Fprintln(stderr, "policy diff requires --old --new and one of --requests or --from-ledger")
+		fs.Usage()
+		return 2
+	}
+
+	oldPolicy, err := policy.LoadPolicy(*oldPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "old policy:", err)
+		return 1
+	}
+	newPolicy, err := policy.LoadPolicy(*newPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "new policy:", err)
+		return 1
+	}
+
+	var cases []policy.DiffCase
+	if *fromLedger {
+		cases, err = ledgerDiffCases(*dbDriver, *dbDSN, *since, *limit)
+	} else {
+		cases, err = readDiffCases(*requestsPath)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+
+	report := policy.Diff(oldPolicy, newPolicy, cases)
+	if *jsonOut {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		_, _ = stdout.Write(append(out, '\n'))
+	} else {
+		fmt.Fprintf(stdout, "old_policy_hash=%s new_policy_hash=%s\n", report.OldHash, report.NewHash)
+		fmt.Fprintf(stdout, "requests=%d changed=%d\n", report.Total, report.Changed)
+		for _, group := range report.Groups {
+			fmt.Fprintf(stdout, "\nrule %s -> %s (%d)\n", ruleLabel(group.OldRule), ruleLabel(group.NewRule), len(group.Changes))
+			for _, change := range group.Changes {
+				fmt.Fprintf(stdout, "  %s %s %s %s:", change.ID, change.Input.Action, change.Input.Resource, change.Input.Env)
+				for _, field := range change.Fields {
+					switch field {
+					case "verdict":
+						fmt.Fprintf(stdout, " verdict %s->%s", change.Old.Verdict, change.New.Verdict)
+					case "require_approval":
+						fmt.Fprintf(stdout, " require_approval %t->%t", change.Old.RequireApproval, change.New.RequireApproval)
+					case "ttl_seconds":
+						fmt.Fprintf(stdout, " ttl_seconds %d->%d", change.Old.TTLSeconds, change.New.TTLSeconds)
+					case "aws_role_arn":
+						fmt.Fprintf(stdout, " aws_role_arn %q->%q", change.Old.AWSRoleARN, change.New.AWSRoleARN)
+					}
+				}
+				fmt.Fprintln(stdout)
+			}
+		}
+	}
+
+	if *failOnChange && report.Changed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func ruleLabel(id string) string {
+	if id == "" {
+		return "<defaults>"
+	}
+	return id
+}
+
+// readDiffCases reads a JSONL corpus of policy inputs; blank lines and lines starting
+// with # are skipped. Each case is labeled "line:N" unless it carries an "id".
+func readDiffCases(path string) ([]policy.DiffCase, error) {
+	// #nosec G304 -- path is operator-provided.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cases []policy.DiffCase
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var entry struct {
+			policy.Input
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+		id := entry.ID
+		if id == "" {
+			id = fmt.Sprintf("line:%d", i+1)
+		}
+		cases = append(cases, policy.DiffCase{ID: id, Input: entry.Input})
+	}
+	return cases, nil
+}
+
+// ledgerDiffCases rebuilds policy inputs from stored contexts. Contexts record the
+// request, evidence, and source (repo, workflow, subject, ref, sha); issuer,
+// environment, and actor claims are not stored and replay as empty.
+func ledgerDiffCases(driver, dsn, since string, limit int) ([]policy.DiffCase, error) {
+	store, closeFn, err := openLedger(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFn()
+
+	records, err := store.ListContexts(since, limit)
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]policy.DiffCase, 0, len(records))
+	for _, rec := range records {
+		var ctx types.ContextRecord
+		if err := json.Unmarshal(rec.BodyJSON, &ctx); err != nil {
+			return nil, fmt.Errorf("context %s: %w", rec.ContextID, err)
+		}
+		at, _ := time.Parse(time.RFC3339, ctx.CreatedAt)
+		cases = append(cases, policy.DiffCase{
+			ID: rec.ContextID,
+			Input: policy.Input{
+				Action:     ctx.Inputs.Action,
+				Resource:   ctx.Inputs.Resource,
+				Env:        ctx.Inputs.Env,
+				Subject:    ctx.Source.Actor,
+				Repo:       ctx.Source.Repo,
+				Workflow:   ctx.Source.Workflow,
+				SHA:        ctx.Source.SHA,
+				Ref:        ctx.Source.Ref,
+				Intent:     ctx.Inputs.Intent,
+				PlanDigest: ctx.Evidence.PlanDigest,
+				DiffURL:    ctx.Evidence.DiffURL,
+				At:         at,
+			},
+		})
+	}
+	return cases, nil
+}
+
+func openLedger(driver, dsn string) (ledger.Store, func(), error) {
+	switch driver {
+	case "sqlite":
+		store, err := sqlstore.OpenSQLite(dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { _ = store.DB().Close() }, nil
+	case "postgres":
+		store, err := pgstore.OpenPostgres(dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { _ = store.DB().Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported db driver: %s", driver)
+	}
+}
+
+func handlePolicyLint(args []string, stdout io.Writer, stderr io.Writer) int {
+	fs := flag.NewFlagSet("policy lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOut := fs.Bool("json", false, "print issues as JSON")
+	strict := fs.Bool("strict", false, "exit non-zero on warnings too")
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "policy lint requires <policy_path|policy_dir>")
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+
+	loaded, err := policy.LoadPolicy(path)
+	var issues []policy.LintIssue
+	if err != nil {
+		issues = []policy.LintIssue{policy.LoadIssue(err)}
+	} else {
+		issues = policy.Lint(loaded.Policy)
+	}
+
+	// Issue files are bundle-relative; report them as paths the caller can open.
+	root := path
+	if info, statErr := os.Stat(path); statErr == nil && !info.IsDir() {
+		root = filepath.Dir(path)
+	}
+	failed := false
+	for i := range issues {
+		if issues[i].File == "" {
+			issues[i].File = path
+		} else {
+			issues[i].File = filepath.Join(root, issues[i].File)
+		}
+		if issues[i].Severity == policy.LintError || *strict {
+			failed = true
+		}
+	}
+
+	if *jsonOut {
+		report := struct {
+			PolicyID   string             `json:"policy_id,omitempty"`
+			PolicyHash string             `json:"policy_hash,omitempty"`
+			Files      []string           `json:"files,omitempty"`
+			Issues     []policy.LintIssue `json:"issues"`
+		}{Issues: issues}
+		if err == nil {
+			report.PolicyID = loaded.Policy.PolicyID
+			report.PolicyHash = loaded.Hash
+			report.Files = loaded.Files
+		}
+		if report.Issues == nil {
+			report.Issues = []policy.LintIssue{}
+		}
+		out, _ := json.MarshalIndent(report, "", "  ")
+		_, _ = stdout.Write(append(out, '\n'))
+	} else {
+		for _, issue := range issues {
+			loc := issue.File
+			if issue.Line > 0 {
+				loc += fmt.Sprintf(":%d:%d", issue.Line, issue.Column)
+			}
+			fmt.Fprintf(stderr, "%s: %s: %s [%s]\n", loc, issue.Severity, issue.Message, issue.Code)
+		}
+		if !failed {
+			fmt.Fprintf(stdout, "ok policy_id=%s policy_hash=%s\n", loaded.Policy.PolicyID, loaded.Hash)
+			if len(loaded.Files) > 0 {
+				fmt.Fprintf(stdout, "bundle_files=%s\n", strings.Join(loaded.Files, ","))
+			}
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
 func handleKeys(args []string, stdout io.Writer, stderr io.Writer) int {
 	if len(args) == 0 {
 		usage(stderr)
@@ -372,6 +812,55 @@ func handleKeys(args []string, stdout io.Writer, stderr io.Writer) int {
 	}
 }
 
+func handleOutbox(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "retry" {
+		usage(stderr)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("outbox retry", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", envOrDefault("RELIA_ADDR", defaultAddr), "Relia API address")
+	jsonOut := fs.Bool("json", false, "print raw JSON response")
+	token := fs.String("token", os.Getenv("RELIA_ADMIN_TOKEN"), "admin token")
+	if err := fs.Parse(args[1:]); err != nil {
+		fs.Usage()
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "outbox retry requires <outbox_id>")
+		fs.Usage()
+		return 2
+	}
+	outboxID := fs.Arg(0)
+
+	respBody, status, err := httpPost(http.DefaultClient, *addr+"/v1/admin/outbox/"+outboxID+"/retry", *token)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	if status != http.StatusOK {
+		fmt.Fprintf(stderr, "outbox retry failed: %s\n", strings.TrimSpace(string(respBody)))
+		return 1
+	}
+	if *jsonOut {
+		_, _ = stdout.Write(respBody)
+		return 0
+	}
+
+	var payload struct {
+		OutboxID      string `json:"outbox_id"`
+		Status        string `json:"status"`
+		NextAttemptAt string `json:"next_attempt_at"`
+	}
+	if err := json.Unmarshal(respBody, &payload); err != nil {
+		fmt.Fprintln(stderr, "invalid response:", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "requeued outbox_id=%s status=%s next_attempt_at=%s\n", payload.OutboxID, payload.Status, payload.NextAttemptAt)
+	return 0
+}
+
 func encodeKey(key []byte, format string) ([]byte, error) {
 	switch strings.ToLower(strings.TrimSpace(format)) {
 	case "raw":
@@ -403,7 +892,15 @@ func writeFile(path string, contents []byte, mode os.FileMode, overwrite bool) e
 }
 
 func httpGet(client *http.Client, url string, token string) ([]byte, int, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	return httpDo(client, http.MethodGet, url, token)
+}
+
+func httpPost(client *http.Client, url string, token string) ([]byte, int, error) {
+	return httpDo(client, http.MethodPost, url, token)
+}
+
+func httpDo(client *http.Client, method string, url string, token string) ([]byte, int, error) {
+	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -437,9 +934,17 @@ func usage(w io.Writer) {
 
 Usage:
   relia verify <receipt_id> [--addr URL] [--json] [--token TOKEN]
+  relia verify --pack relia-pack.zip --pubkey PATH [--json]
+  relia verify --receipt receipt.json --pubkey PATH [--json]
   relia pack <receipt_id> --out relia-pack.zip [--addr URL] [--token TOKEN]
+  relia outbox retry <outbox_id> [--addr URL] [--json] [--token ADMIN_TOKEN]
   relia keys gen --private PATH [--public PATH] [--format hex|base64|raw] [--overwrite]
-  relia policy lint <policy_path>
+  relia policy lint [--json] [--strict] <policy_path|policy_dir>
   relia policy test --policy PATH --action ACTION --resource RESOURCE --env ENV [--json]
+                    [--repo R] [--workflow W] [--ref REF] [--environment E] [--actor A] [--subject S] [--issuer I] [--sha SHA]
+                    [--intent JSON] [--plan-digest DIGEST] [--diff-url URL] [--at RFC3339]
+  relia policy test --suite <policy_path|policy_dir> [--json]
+  relia policy diff --old PATH --new PATH (--requests requests.jsonl | --from-ledger [--db-driver D] [--db-dsn DSN] [--since T] [--limit N])
+                    [--json] [--fail-on-change]
 `)
 }
@@ -2,12 +2,24 @@ package main
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	reliactx "github.com/davidahmann/relia/internal/context"
+	"github.com/davidahmann/relia/internal/crypto"
+	"github.com/davidahmann/relia/internal/decision"
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/ledger/sqlstore"
+	"github.com/davidahmann/relia/internal/pack"
+	"github.com/davidahmann/relia/internal/policy"
+	"github.com/davidahmann/relia/pkg/types"
 )
 
 func TestRun_UsageAndUnknown(t *testing.T) {
@@ -123,6 +135,181 @@ func TestHandleVerify_Errors(t *testing.T) {
 	}
 }
 
+type cliTestSigner struct {
+	priv ed25519.PrivateKey
+}
+
+func (s cliTestSigner) KeyID() string { return "test" }
+func (s cliTestSigner) SignEd25519(message []byte) ([]byte, error) {
+	return ed25519.Sign(s.priv, message), nil
+}
+
+func writeOfflinePack(t *testing.T) (packPath string, pubPath string) {
+	t.Helper()
+
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+	createdAt := "2025-12-20T00:00:00Z"
+	policyBytes := []byte("policy_id: relia-default\n")
+	policyHash := crypto.DigestWithPrefix(policyBytes)
+
+	ctx, err := reliactx.BuildContext(
+		types.ContextSource{Kind: "github_actions", Repo: "org/repo", Workflow: "wf", RunID: "1", Actor: "dev", SHA: "abc"},
+		types.ContextInputs{Action: "terraform.apply", Resource: "stack/prod", Env: "prod"},
+		types.ContextEvidence{},
+		createdAt,
+	)
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+	dec, err := decision.BuildDecision(ctx.ContextID, types.DecisionPolicy{PolicyID: "relia-default", PolicyHash: policyHash}, "deny", nil, nil, false, "", createdAt)
+	if err != nil {
+		t.Fatalf("decision: %v", err)
+	}
+	receipt, err := ledger.MakeReceipt(ledger.MakeReceiptInput{
+		CreatedAt:      createdAt,
+		IdemKey:        "idem",
+		ContextID:      ctx.ContextID,
+		DecisionID:     dec.DecisionID,
+		Actor:          types.ReceiptActor{Kind: "workload", Subject: "dev"},
+		Request:        types.ReceiptRequest{Action: "terraform.apply", Resource: "stack/prod", Env: "prod"},
+		Policy:         types.ReceiptPolicy{PolicyID: "relia-default", PolicyHash: policyHash},
+		InteractionRef: &types.InteractionRef{Mode: "voice", CallID: "call-1"},
+		Outcome:        types.ReceiptOutcome{Status: types.OutcomeDenied},
+	}, cliTestSigner{priv: priv})
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+	zipBytes, err := pack.BuildZip(pack.Input{Receipt: receipt, Context: ctx, Decision: dec, Policy: policyBytes, CreatedAt: createdAt}, "")
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+
+	dir := t.TempDir()
+	packPath = filepath.Join(dir, "relia-pack.zip")
+	pubPath = filepath.Join(dir, "ed25519.pub")
+	if err := os.WriteFile(packPath, zipBytes, 0o600); err != nil {
+		t.Fatalf("write pack: %v", err)
+	}
+	if err := os.WriteFile(pubPath, []byte("hex:"+hex.EncodeToString(pub)+"\n"), 0o600); err != nil {
+		t.Fatalf("write pub: %v", err)
+	}
+	return packPath, pubPath
+}
+
+func TestHandleVerifyOfflinePack(t *testing.T) {
+	packPath, pubPath := writeOfflinePack(t)
+
+	var out, errOut bytes.Buffer
+	code := handleVerify([]string{"--pack", packPath, "--pubkey", pubPath}, &out, &errOut)
+	if code != 0 {
+		t.Fatalf("expected 0, got %d stdout=%s stderr=%s", code, out.String(), errOut.String())
+	}
+	if !strings.Contains(out.String(), "valid=true") || !strings.Contains(out.String(), "interaction=mode=voice") {
+		t.Fatalf("unexpected stdout: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	code = handleVerify([]string{"--pack", packPath, "--pubkey", pubPath, "--json"}, &out, &errOut)
+	if code != 0 || !strings.Contains(out.String(), `"valid": true`) {
+		t.Fatalf("unexpected json output: code=%d %s", code, out.String())
+	}
+
+	data, err := os.ReadFile(packPath)
+	if err != nil {
+		t.Fatalf("read pack: %v", err)
+	}
+	files, err := pack.ReadZip(data)
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	receiptPath := filepath.Join(t.TempDir(), "receipt.json")
+	if err := os.WriteFile(receiptPath, files["receipt.json"], 0o600); err != nil {
+		t.Fatalf("write receipt: %v", err)
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := handleVerify([]string{"--receipt", receiptPath, "--pubkey", pubPath}, &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stdout=%s", code, out.String())
+	}
+
+	tampered := []byte(strings.Replace(string(files["receipt.json"]), "stack/prod", "stack/dev", 1))
+	if err := os.WriteFile(receiptPath, tampered, 0o600); err != nil {
+		t.Fatalf("write receipt: %v", err)
+	}
+	out.Reset()
+	errOut.Reset()
+	if code := handleVerify([]string{"--receipt", receiptPath, "--pubkey", pubPath}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1, got %d", code)
+	}
+	if !strings.Contains(out.String(), "valid=false") || !strings.Contains(out.String(), "receipt_signature") {
+		t.Fatalf("unexpected stdout: %s", out.String())
+	}
+}
+
+func TestHandleVerifyOffline_Errors(t *testing.T) {
+	packPath, pubPath := writeOfflinePack(t)
+
+	var out, errOut bytes.Buffer
+	if code := handleVerify([]string{"--pack", packPath}, &out, &errOut); code != 2 {
+		t.Fatalf("expected 2 without pubkey, got %d", code)
+	}
+	if code := handleVerify([]string{"--pack", packPath, "--receipt", packPath, "--pubkey", pubPath}, &out, &errOut); code != 2 {
+		t.Fatalf("expected 2 for both sources, got %d", code)
+	}
+	if code := handleVerify([]string{"--pack", packPath, "--pubkey", pubPath, "r1"}, &out, &errOut); code != 2 {
+		t.Fatalf("expected 2 with receipt id, got %d", code)
+	}
+	if code := handleVerify([]string{"--pack", packPath, "--pubkey", filepath.Join(t.TempDir(), "missing")}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1 for missing pubkey, got %d", code)
+	}
+	if code := handleVerify([]string{"--pack", filepath.Join(t.TempDir(), "missing.zip"), "--pubkey", pubPath}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1 for missing pack, got %d", code)
+	}
+	if code := handleVerify([]string{"--pack", pubPath, "--pubkey", pubPath}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1 for non-zip pack, got %d", code)
+	}
+	if code := handleVerify([]string{"--receipt", filepath.Join(t.TempDir(), "missing.json"), "--pubkey", pubPath}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1 for missing receipt, got %d", code)
+	}
+}
+
+func TestHandleOutboxRetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v1/admin/outbox/webhook:a1/retry" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"outbox record is not dead"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"outbox_id":"webhook:a1","status":"pending","next_attempt_at":"2025-12-20T16:35:00Z"}`))
+	}))
+	defer srv.Close()
+
+	var out, errOut bytes.Buffer
+	code := handleOutbox([]string{"retry", "--addr", srv.URL, "--token", "tok", "webhook:a1"}, &out, &errOut)
+	if code != 0 || !strings.Contains(out.String(), "requeued outbox_id=webhook:a1 status=pending") {
+		t.Fatalf("expected requeue, got %d stdout=%s stderr=%s", code, out.String(), errOut.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := handleOutbox([]string{"retry", "--addr", srv.URL, "--token", "tok", "webhook:a2"}, &out, &errOut); code != 1 || !strings.Contains(errOut.String(), "not dead") {
+		t.Fatalf("expected 1 for a conflict, got %d stderr=%s", code, errOut.String())
+	}
+	if code := handleOutbox([]string{"retry"}, &out, &errOut); code != 2 {
+		t.Fatalf("expected 2 without an id, got %d", code)
+	}
+	if code := handleOutbox([]string{"list"}, &out, &errOut); code != 2 {
+		t.Fatalf("expected 2 for an unknown subcommand, got %d", code)
+	}
+}
+
 func TestHandlePack(t *testing.T) {
 	payload := []byte("zip-bytes")
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -252,6 +439,19 @@ func TestHandlePolicy_Errors(t *testing.T) {
 	if code := run([]string{"relia", "policy", "lint", "missing.yaml"}, &out, &errOut); code != 1 {
 		t.Fatalf("expected 1, got %d", code)
 	}
+
+	badPath := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(badPath, []byte("policy_id: bad\nrules:\n  - id: r\n    match:\n      env: \"prod-[\"\n"), 0o600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	out.Reset()
+	errOut.Reset()
+	if code := run([]string{"relia", "policy", "lint", badPath}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1, got %d", code)
+	}
+	if !strings.Contains(errOut.String(), "invalid pattern") {
+		t.Fatalf("unexpected stderr: %s", errOut.String())
+	}
 }
 
 func TestHandlePolicyTest(t *testing.T) {
@@ -298,6 +498,94 @@ rules:
 	}
 }
 
+func TestHandlePolicyTestActorFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	policyYAML := `
+policy_id: test
+policy_version: "1"
+defaults:
+  deny: true
+rules:
+  - id: infra_deploy
+    match:
+      action: "terraform.apply"
+      repo: "org/infra"
+      workflow: "org/infra/.github/workflows/deploy.yml@*"
+      ref: "refs/heads/main"
+    effect:
+      deny: false
+`
+	if err := os.WriteFile(path, []byte(policyYAML), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	base := []string{"relia", "policy", "test", "--policy", path, "--action", "terraform.apply", "--resource", "stack/prod", "--env", "prod", "--ref", "refs/heads/main"}
+	var out, errOut bytes.Buffer
+	code := run(append(base, "--repo", "org/infra", "--workflow", "org/infra/.github/workflows/deploy.yml@refs/heads/main"), &out, &errOut)
+	if code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "verdict=allow") || !strings.Contains(out.String(), "matched_rule=infra_deploy") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	code = run(append(base, "--repo", "org/other", "--workflow", "org/other/.github/workflows/deploy.yml@refs/heads/main"), &out, &errOut)
+	if code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "verdict=deny") || !strings.Contains(out.String(), "matched_rule=<defaults>") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestHandlePolicyTestIntent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	policyYAML := `
+policy_id: test
+policy_version: "1"
+rules:
+  - id: destructive
+    match:
+      action: "terraform.apply"
+      intent:
+        - field: destroy_count
+          op: gt
+          value: 0
+    effect:
+      require_approval: true
+      require_evidence: [plan_digest]
+`
+	if err := os.WriteFile(path, []byte(policyYAML), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	base := []string{"relia", "policy", "test", "--policy", path, "--action", "terraform.apply", "--resource", "stack/prod", "--env", "prod", "--intent", `{"destroy_count":3}`}
+	var out, errOut bytes.Buffer
+	if code := run(append(base, "--plan-digest", "sha256:p"), &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "verdict=require_approval") || !strings.Contains(out.String(), "INTENT_MATCH:destroy_count:gt:0") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run(base, &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "verdict=deny") || !strings.Contains(out.String(), "EVIDENCE_MISSING:plan_digest") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run(append(base[:len(base)-1], "not-json"), &out, &errOut); code != 2 {
+		t.Fatalf("expected 2 for invalid intent, got %d", code)
+	}
+}
+
 func TestHandlePolicyTest_Errors(t *testing.T) {
 	var out, errOut bytes.Buffer
 	code := run([]string{"relia", "policy", "test"}, &out, &errOut)
@@ -447,3 +735,363 @@ func TestKeysCommandUsage(t *testing.T) {
 		t.Fatalf("expected 2, got %d", code)
 	}
 }
+
+func TestHandlePolicyTestAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	policyYAML := `
+policy_id: test
+policy_version: "1"
+rules:
+  - id: prod
+    match:
+      env: prod
+freezes:
+  - id: holidays
+    when:
+      timezone: Europe/Berlin
+      dates:
+        - from: "2026-12-24"
+          to: "2026-12-26"
+    effect: deny
+    reason: holiday freeze
+`
+	if err := os.WriteFile(path, []byte(policyYAML), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	base := []string{"relia", "policy", "test", "--policy", path, "--action", "deploy", "--resource", "svc", "--env", "prod"}
+	var out, errOut bytes.Buffer
+	if code := run(append(base, "--at", "2026-12-24T10:00:00Z"), &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "verdict=deny") || !strings.Contains(out.String(), "FREEZE:holidays") || !strings.Contains(out.String(), "reason=holiday freeze") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run(append(base, "--at", "2026-12-28T10:00:00Z"), &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "verdict=allow") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run(append(base, "--at", "christmas"), &out, &errOut); code != 2 {
+		t.Fatalf("expected 2 for invalid --at, got %d", code)
+	}
+}
+
+func TestHandlePolicyLintBundle(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "00-base.yaml"), []byte("policy_id: bundle\npolicy_version: \"1\"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "10-team.yaml"), []byte("rules:\n  - id: prod\n    match: {env: prod}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	if code := run([]string{"relia", "policy", "lint", dir}, &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "ok policy_id=bundle") || !strings.Contains(out.String(), "bundle_files=00-base.yaml,10-team.yaml") {
+		t.Fatalf("unexpected stdout: %s", out.String())
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "20-dup.yaml"), []byte("rules:\n  - id: prod\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out.Reset()
+	errOut.Reset()
+	if code := run([]string{"relia", "policy", "lint", dir}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1, got %d", code)
+	}
+	if !strings.Contains(errOut.String(), `duplicate rule id "prod"`) {
+		t.Fatalf("unexpected stderr: %s", errOut.String())
+	}
+}
+
+func TestHandlePolicyLintIssues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	policyYAML := `policy_id: lint
+rules:
+  - id: prod
+    match: {env: prod}
+    effect: {deny: true}
+  - id: prod_apply
+    match: {action: terraform.apply, env: prod}
+    effect: {require_approval: true, aws_role_arn: "arn:aws:iam::123456789012:role/r"}
+`
+	if err := os.WriteFile(path, []byte(policyYAML), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	// Warnings alone pass unless --strict.
+	var out, errOut bytes.Buffer
+	if code := run([]string{"relia", "policy", "lint", path}, &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), path+":6:5: warning: ") || !strings.Contains(errOut.String(), "[shadowed_rule]") {
+		t.Fatalf("unexpected stderr: %s", errOut.String())
+	}
+	if !strings.Contains(out.String(), "ok policy_id=lint") {
+		t.Fatalf("unexpected stdout: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run([]string{"relia", "policy", "lint", "--strict", "--json", path}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1 with --strict, got %d", code)
+	}
+	var report struct {
+		PolicyID string             `json:"policy_id"`
+		Issues   []policy.LintIssue `json:"issues"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode: %v (%s)", err, out.String())
+	}
+	if report.PolicyID != "lint" || len(report.Issues) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if got := report.Issues[0]; got.File != path || got.Line != 6 || got.Column != 5 || got.Code != "shadowed_rule" || got.RuleID != "prod_apply" {
+		t.Fatalf("unexpected issue: %+v", got)
+	}
+
+	// Load errors are reported as positioned issues too.
+	if err := os.WriteFile(path, []byte("policy_id: typo\nrules:\n  - id: r\n    effect: {require_aproval: true}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out.Reset()
+	errOut.Reset()
+	if code := run([]string{"relia", "policy", "lint", "--json", path}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1, got %d", code)
+	}
+	report.Issues = nil
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Code != "load" || report.Issues[0].Line != 4 || report.Issues[0].Column != 14 {
+		t.Fatalf("unexpected issues: %+v", report.Issues)
+	}
+}
+
+func TestHandlePolicyTestSuite(t *testing.T) {
+	var out, errOut bytes.Buffer
+	if code := run([]string{"relia", "policy", "test", "--suite", "../../policies"}, &out, &errOut); code != 0 {
+		t.Fatalf("expected sample suite to pass, got %d stdout=%s stderr=%s", code, out.String(), errOut.String())
+	}
+	if !strings.Contains(out.String(), "3 passed, 0 failed") {
+		t.Fatalf("unexpected stdout: %s", out.String())
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "relia.yaml"), []byte("policy_id: p\ndefaults: {ttl_seconds: 900}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tests := "tests:\n  - name: passes\n    input: {action: a}\n    expect: {verdict: allow}\n  - name: fails\n    input: {action: a}\n    expect: {verdict: deny, ttl_seconds: 3600}\n"
+	if err := os.WriteFile(filepath.Join(dir, "relia_test.yaml"), []byte(tests), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run([]string{"relia", "policy", "test", "--suite", dir}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1, got %d", code)
+	}
+	for _, want := range []string{"FAIL " + filepath.Join(dir, "relia_test.yaml") + ":5 fails", "    verdict: want deny, got allow", "    ttl_seconds: want 3600, got 900", "1 passed, 1 failed"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in output:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run([]string{"relia", "policy", "test", "--suite", dir, "--json"}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1, got %d", code)
+	}
+	var report struct {
+		Passed   int `json:"passed"`
+		Failed   int `json:"failed"`
+		Policies []struct {
+			Results []policy.TestResult `json:"results"`
+		} `json:"policies"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if report.Passed != 1 || report.Failed != 1 || len(report.Policies[0].Results[1].Mismatches) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// A suite without tests is an error, as is a missing path.
+	empty := t.TempDir()
+	if err := os.WriteFile(filepath.Join(empty, "relia.yaml"), []byte("policy_id: p\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, path := range []string{empty, filepath.Join(empty, "missing")} {
+		errOut.Reset()
+		if code := run([]string{"relia", "policy", "test", "--suite", path}, &out, &errOut); code != 1 {
+			t.Fatalf("%s: expected 1, got %d", path, code)
+		}
+	}
+}
+
+func TestHandlePolicyLintCondition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("policy_id: p\nrules:\n  - id: r\n    condition: request.action\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var out, errOut bytes.Buffer
+	if code := run([]string{"relia", "policy", "lint", path}, &out, &errOut); code != 1 {
+		t.Fatalf("expected 1, got %d", code)
+	}
+	if !strings.Contains(errOut.String(), path+":4:5: error: rule \"r\": invalid condition: must evaluate to bool") {
+		t.Fatalf("unexpected stderr: %s", errOut.String())
+	}
+}
+
+const diffOldPolicy = `policy_id: p
+policy_version: "1"
+rules:
+  - id: prod
+    match: {env: prod}
+    effect: {deny: true}
+`
+
+const diffNewPolicy = `policy_id: p
+policy_version: "2"
+rules:
+  - id: prod_deploy
+    match: {action: deploy, env: prod}
+    effect: {require_approval: true}
+  - id: prod
+    match: {env: prod}
+    effect: {deny: true}
+`
+
+func writeDiffPolicies(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.yaml")
+	newPath := filepath.Join(dir, "new.yaml")
+	if err := os.WriteFile(oldPath, []byte(diffOldPolicy), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(newPath, []byte(diffNewPolicy), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return oldPath, newPath
+}
+
+func TestHandlePolicyDiffRequests(t *testing.T) {
+	oldPath, newPath := writeDiffPolicies(t)
+	requests := filepath.Join(t.TempDir(), "requests.jsonl")
+	corpus := `# replay corpus
+{"id":"deploy-prod","action":"deploy","resource":"svc","env":"prod"}
+{"action":"terraform.apply","resource":"stack","env":"prod"}
+
+{"action":"deploy","resource":"svc","env":"dev"}
+`
+	if err := os.WriteFile(requests, []byte(corpus), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	args := []string{"relia", "policy", "diff", "--old", oldPath, "--new", newPath, "--requests", requests}
+	if code := run(args, &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	for _, want := range []string{"requests=3 changed=1", "rule prod -> prod_deploy (1)", "deploy-prod deploy svc prod: verdict deny->require_approval require_approval false->true"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run(append(args, "--json", "--fail-on-change"), &out, &errOut); code != 1 {
+		t.Fatalf("expected 1 with --fail-on-change, got %d", code)
+	}
+	if !strings.Contains(out.String(), `"changed": 1`) || !strings.Contains(out.String(), `"new_rule": "prod_deploy"`) {
+		t.Fatalf("unexpected json: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run([]string{"relia", "policy", "diff", "--old", oldPath, "--new", oldPath, "--requests", requests, "--fail-on-change"}, &out, &errOut); code != 0 {
+		t.Fatalf("expected 0 for identical policies, got %d", code)
+	}
+}
+
+func TestHandlePolicyDiffFromLedger(t *testing.T) {
+	oldPath, newPath := writeDiffPolicies(t)
+	dsn := "file:" + filepath.Join(t.TempDir(), "relia.db")
+	store, err := sqlstore.OpenSQLite(dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := ledger.Migrate(store.DB(), ledger.DBSQLite); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	ctx, err := reliactx.BuildContext(
+		types.ContextSource{Kind: "github_actions", Repo: "org/app", Actor: "repo:org/app"},
+		types.ContextInputs{Action: "deploy", Resource: "svc", Env: "prod"},
+		types.ContextEvidence{},
+		"2025-12-20T16:34:14Z",
+	)
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+	body, _ := json.Marshal(ctx)
+	if err := store.PutContext(ledger.ContextRecord{ContextID: ctx.ContextID, BodyJSON: body, CreatedAt: ctx.CreatedAt}); err != nil {
+		t.Fatalf("put context: %v", err)
+	}
+	_ = store.DB().Close()
+
+	var out, errOut bytes.Buffer
+	args := []string{"relia", "policy", "diff", "--old", oldPath, "--new", newPath, "--from-ledger", "--db-driver", "sqlite", "--db-dsn", dsn}
+	if code := run(args, &out, &errOut); code != 0 {
+		t.Fatalf("expected 0, got %d stderr=%s", code, errOut.String())
+	}
+	if !strings.Contains(out.String(), "requests=1 changed=1") || !strings.Contains(out.String(), ctx.ContextID) {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if code := run(append(args, "--since", "2026-01-01T00:00:00Z"), &out, &errOut); code != 0 || !strings.Contains(out.String(), "requests=0 changed=0") {
+		t.Fatalf("expected empty replay, got %d %s", code, out.String())
+	}
+}
+
+func TestHandlePolicyDiffErrors(t *testing.T) {
+	oldPath, newPath := writeDiffPolicies(t)
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"--old", oldPath, "--new", newPath}, 2},
+		{[]string{"--old", oldPath, "--new", newPath, "--requests", "r.jsonl", "--from-ledger"}, 2},
+		{[]string{"--old", "missing.yaml", "--new", newPath, "--requests", "r.jsonl"}, 1},
+		{[]string{"--old", oldPath, "--new", newPath, "--requests", "missing.jsonl"}, 1},
+		{[]string{"--old", oldPath, "--new", newPath, "--from-ledger", "--db-driver", "oracle"}, 1},
+	}
+	for _, tc := range cases {
+		var out, errOut bytes.Buffer
+		if code := run(append([]string{"relia", "policy", "diff"}, tc.args...), &out, &errOut); code != tc.code {
+			t.Fatalf("%v: expected %d, got %d stderr=%s", tc.args, tc.code, code, errOut.String())
+		}
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.jsonl")
+	if err := os.WriteFile(bad, []byte("{\"action\":\"deploy\"}\nnot json\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var out, errOut bytes.Buffer
+	if code := run([]string{"relia", "policy", "diff", "--old", oldPath, "--new", newPath, "--requests", bad}, &out, &errOut); code != 1 || !strings.Contains(errOut.String(), "bad.jsonl:2") {
+		t.Fatalf("expected line error, got %d %s", code, errOut.String())
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/davidahmann/relia/internal/api"
@@ -19,7 +22,11 @@ import (
 	"github.com/davidahmann/relia/internal/ledger"
 	"github.com/davidahmann/relia/internal/ledger/pgstore"
 	"github.com/davidahmann/relia/internal/ledger/sqlstore"
+	"github.com/davidahmann/relia/internal/outbox"
+	"github.com/davidahmann/relia/internal/policy"
 	"github.com/davidahmann/relia/internal/slack"
+	"github.com/davidahmann/relia/internal/teams"
+	"github.com/davidahmann/relia/internal/webhook"
 )
 
 func main() {
@@ -75,6 +82,14 @@ func listenAndServe(server *http.Server) error {
 func newServer(cfg config.Config, getenv envFn) (*http.Server, error) {
 	addr := firstNonEmpty(getenv("RELIA_LISTEN_ADDR"), cfg.ListenAddr, ":8080")
 	policyPath := firstNonEmpty(getenv("RELIA_POLICY_PATH"), cfg.PolicyPath, "policies/relia.yaml")
+	var policyReload time.Duration
+	if raw := firstNonEmpty(getenv("RELIA_POLICY_RELOAD_INTERVAL"), cfg.PolicyReloadInterval, ""); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, logErrorf("invalid policy reload interval: %v", err)
+		}
+		policyReload = interval
+	}
 
 	slackEnabled := cfg.Slack.Enabled
 	if raw := getenv("RELIA_SLACK_ENABLED"); raw != "" {
@@ -88,6 +103,25 @@ func newServer(cfg config.Config, getenv envFn) (*http.Server, error) {
 		return nil, logErrorf("missing slack signing secret")
 	}
 
+	teamsEnabled := cfg.Teams.Enabled
+	if raw := getenv("RELIA_TEAMS_ENABLED"); raw != "" {
+		teamsEnabled = envBool(raw)
+	}
+	teamsWebhookURL := firstNonEmpty(getenv("RELIA_TEAMS_WEBHOOK_URL"), cfg.Teams.WebhookURL, "")
+	teamsToken := firstNonEmpty(getenv("RELIA_TEAMS_SECURITY_TOKEN"), cfg.Teams.SecurityToken, "")
+	if teamsEnabled && teamsToken == "" {
+		return nil, logErrorf("missing teams security token")
+	}
+
+	webhookCfg := cfg.Webhook
+	if raw := getenv("RELIA_WEBHOOK_ENABLED"); raw != "" {
+		webhookCfg.Enabled = envBool(raw)
+	}
+	webhookCfg.URL = firstNonEmpty(getenv("RELIA_WEBHOOK_URL"), webhookCfg.URL, "")
+	webhookCfg.Signing = firstNonEmpty(getenv("RELIA_WEBHOOK_SIGNING"), webhookCfg.Signing, "hmac")
+	webhookCfg.HMACSecret = firstNonEmpty(getenv("RELIA_WEBHOOK_HMAC_SECRET"), webhookCfg.HMACSecret, "")
+	webhookCfg.CallbackPublicKeyPath = firstNonEmpty(getenv("RELIA_WEBHOOK_CALLBACK_PUBLIC_KEY_PATH"), webhookCfg.CallbackPublicKeyPath, "")
+
 	dbDriver := firstNonEmpty(getenv("RELIA_DB_DRIVER"), cfg.DB.Driver, "sqlite")
 	dbDSN := firstNonEmpty(getenv("RELIA_DB_DSN"), cfg.DB.DSN, "file:relia.db?_journal_mode=WAL")
 
@@ -130,9 +164,21 @@ func newServer(cfg config.Config, getenv envFn) (*http.Server, error) {
 		signer = apiDevSigner{keyID: keyID, priv: priv}
 	}
 
-	var notifier api.SlackNotifier
+	var notifier api.ApprovalNotifier
+	var slackClient *slack.Client
 	if slackEnabled && slackToken != "" {
-		notifier = &slack.Client{Token: slackToken}
+		slackClient = &slack.Client{Token: slackToken}
+		notifier = slackClient
+	}
+
+	var teamsNotifier api.ApprovalNotifier
+	if teamsEnabled && (teamsWebhookURL != "" || len(cfg.Teams.Channels) > 0) {
+		teamsNotifier = &teams.Client{WebhookURL: teamsWebhookURL, Channels: cfg.Teams.Channels}
+	}
+
+	webhookNotifier, webhookVerifier, err := webhookFromConfig(webhookCfg, signer)
+	if err != nil {
+		return nil, err
 	}
 
 	authorizeService, err := api.NewAuthorizeService(api.NewAuthorizeServiceInput{
@@ -143,20 +189,43 @@ func newServer(cfg config.Config, getenv envFn) (*http.Server, error) {
 		Broker:     awsBrokerFromEnv(getenv, cfg),
 		Slack:      notifier,
 		SlackChan:  slackChannel,
+		Teams:      teamsNotifier,
+		Webhook:    webhookNotifier,
+		PublicURL:  firstNonEmpty(getenv("RELIA_PUBLIC_URL"), cfg.PublicURL, ""),
 	})
 	if err != nil {
 		return nil, err
 	}
+	if raw := getenv("RELIA_OUTBOX_MAX_ATTEMPTS"); raw != "" {
+		maxAttempts, err := strconv.Atoi(raw)
+		if err != nil || maxAttempts < 1 {
+			return nil, logErrorf("invalid outbox max attempts: %q", raw)
+		}
+		authorizeService.OutboxMaxAttempts = maxAttempts
+	}
 
 	slackHandler := &slack.InteractionHandler{
 		SigningSecret: signingSecret,
 		Approver:      authorizeService,
 	}
+	if slackClient != nil {
+		slackHandler.Groups = slackClient
+	}
+
+	// Typed nil interfaces are not nil, so only set ApproverAuth when configured.
+	var approverAuth auth.ApproverAuthenticator
+	if a := auth.NewApproverAuthenticatorFromEnv(); a != nil {
+		approverAuth = a
+	}
 
 	h := &api.Handler{
 		Auth:             auth.NewAuthenticatorFromEnv(),
+		ApproverAuth:     approverAuth,
+		AdminAuth:        auth.NewAdminAuthenticatorFromEnv(),
 		AuthorizeService: authorizeService,
 		SlackHandler:     slackHandler,
+		TeamsHandler:     &teams.InteractionHandler{SecurityToken: teamsToken, Approver: authorizeService},
+		WebhookVerifier:  webhookVerifier,
 		PublicVerify:     envBool(getenv("RELIA_PUBLIC_VERIFY")),
 	}
 
@@ -166,15 +235,100 @@ func newServer(cfg config.Config, getenv envFn) (*http.Server, error) {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	if notifier != nil && slackChannel != "" && getenv("RELIA_SLACK_OUTBOX_WORKER") != "0" {
+	policyCtx, stopPolicy := context.WithCancel(context.Background())
+	server.RegisterOnShutdown(stopPolicy)
+	go reloadPolicyOnSignal(policyCtx, authorizeService.Policies)
+	if policyReload > 0 {
+		go authorizeService.Policies.Watch(policyCtx, policyReload)
+	}
+
+	for _, worker := range authorizeService.OutboxWorkers() {
+		if getenv(outboxWorkerEnv[worker.Kind]) == "0" {
+			continue
+		}
 		ctx, cancel := context.WithCancel(context.Background())
 		server.RegisterOnShutdown(cancel)
-		go slack.RunOutboxWorker(ctx, store, notifier, 2*time.Second)
+		go worker.Run(ctx, 2*time.Second)
+	}
+
+	if getenv("RELIA_APPROVAL_EXPIRY_WORKER") != "0" {
+		ctx, cancel := context.WithCancel(context.Background())
+		server.RegisterOnShutdown(cancel)
+		go api.RunApprovalExpiryWorker(ctx, authorizeService, 15*time.Second)
 	}
 
 	return server, nil
 }
 
+// outboxWorkerEnv names the env var that disables the background worker for each
+// outbox kind.
+var outboxWorkerEnv = map[string]string{
+	outbox.KindSlackPost:   "RELIA_SLACK_OUTBOX_WORKER",
+	outbox.KindSlackUpdate: "RELIA_SLACK_OUTBOX_WORKER",
+	outbox.KindTeamsPost:   "RELIA_TEAMS_OUTBOX_WORKER",
+	outbox.KindWebhook:     "RELIA_WEBHOOK_OUTBOX_WORKER",
+}
+
+// reloadPolicyOnSignal reloads the policy on SIGHUP until ctx is done.
+func reloadPolicyOnSignal(ctx context.Context, policies *policy.Manager) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			result, err := policies.Reload()
+			if err != nil {
+				log.Printf("policy reload failed, keeping %s: %v", result.PolicyHash, err)
+				continue
+			}
+			log.Printf("policy reloaded on SIGHUP: %s (changed=%t)", result.PolicyHash, result.Changed)
+		}
+	}
+}
+
+// webhookFromConfig builds the webhook notifier and callback verifier, either of which
+// is nil when not configured. Ed25519 signing uses the gateway's signing key.
+func webhookFromConfig(cfg config.WebhookConfig, signer ledger.Signer) (api.ApprovalNotifier, webhook.Verifier, error) {
+	if !cfg.Enabled {
+		return nil, nil, nil
+	}
+	if cfg.URL == "" {
+		return nil, nil, logErrorf("missing webhook url")
+	}
+
+	var notifier api.ApprovalNotifier
+	switch cfg.Signing {
+	case "hmac":
+		if cfg.HMACSecret == "" {
+			return nil, nil, logErrorf("missing webhook hmac secret")
+		}
+		notifier = &webhook.Client{URL: cfg.URL, Signer: webhook.HMAC{Secret: []byte(cfg.HMACSecret)}}
+	case "ed25519":
+		if signer == nil {
+			return nil, nil, logErrorf("ed25519 webhook signing requires signing_key.private_key_path")
+		}
+		notifier = &webhook.Client{URL: cfg.URL, Signer: webhook.Ed25519Signer{Key: signer}}
+	default:
+		return nil, nil, logErrorf("unsupported webhook signing: %s", cfg.Signing)
+	}
+
+	// Typed nil interfaces are not nil, so only return a verifier when one is configured.
+	var verifier webhook.Verifier
+	if cfg.CallbackPublicKeyPath != "" {
+		pub, err := crypto.LoadEd25519PublicKey(cfg.CallbackPublicKeyPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		verifier = webhook.Ed25519Verifier{PublicKey: pub}
+	} else if cfg.HMACSecret != "" {
+		verifier = webhook.HMAC{Secret: []byte(cfg.HMACSecret)}
+	}
+	return notifier, verifier, nil
+}
+
 type apiDevSigner struct {
 	keyID string
 	priv  ed25519.PrivateKey
@@ -18,7 +18,7 @@ import (
 func TestNewServer(t *testing.T) {
 	cfg := config.Config{
 		ListenAddr: ":9999",
-		PolicyPath: "policies/relia.yaml",
+		PolicyPath: "../../policies/relia.yaml",
 		DB:         config.DBConfig{Driver: "sqlite", DSN: "file::memory:?cache=shared"},
 	}
 	srv, err := newServer(cfg, func(string) string { return "" })
@@ -36,7 +36,7 @@ func TestNewServer(t *testing.T) {
 func TestNewServerUnsupportedDBDriver(t *testing.T) {
 	cfg := config.Config{
 		ListenAddr: ":9999",
-		PolicyPath: "policies/relia.yaml",
+		PolicyPath: "../../policies/relia.yaml",
 		DB:         config.DBConfig{Driver: "postgres", DSN: "ignored"},
 	}
 	_, err := newServer(cfg, func(string) string { return "" })
@@ -55,7 +55,7 @@ func TestNewServerSigningKeyAndSlack(t *testing.T) {
 
 	cfg := config.Config{
 		ListenAddr: ":9999",
-		PolicyPath: "policies/relia.yaml",
+		PolicyPath: "../../policies/relia.yaml",
 		DB:         config.DBConfig{Driver: "sqlite", DSN: "file::memory:?cache=shared"},
 		SigningKey: config.SigningKeyConfig{PrivateKeyPath: keyPath},
 		Slack: config.SlackConfig{
@@ -82,7 +82,7 @@ func TestNewServerSigningKeyAndSlack(t *testing.T) {
 func TestNewServerStartsSlackOutboxWorker(t *testing.T) {
 	cfg := config.Config{
 		ListenAddr: ":9999",
-		PolicyPath: "policies/relia.yaml",
+		PolicyPath: "../../policies/relia.yaml",
 		DB:         config.DBConfig{Driver: "sqlite", DSN: "file::memory:?cache=shared"},
 		Slack: config.SlackConfig{
 			Enabled:         true,
@@ -293,3 +293,97 @@ func TestEnvBool(t *testing.T) {
 		t.Fatalf("expected false values")
 	}
 }
+
+func TestNewServerPolicyReloadInterval(t *testing.T) {
+	cfg := config.Config{
+		ListenAddr: ":9999",
+		PolicyPath: "../../policies/relia.yaml",
+		DB:         config.DBConfig{Driver: "sqlite", DSN: "file::memory:?cache=shared"},
+	}
+	if _, err := newServer(cfg, func(key string) string {
+		if key == "RELIA_POLICY_RELOAD_INTERVAL" {
+			return "often"
+		}
+		return ""
+	}); err == nil {
+		t.Fatalf("expected invalid interval error")
+	}
+
+	srv, err := newServer(cfg, func(key string) string {
+		if key == "RELIA_POLICY_RELOAD_INTERVAL" {
+			return "1s"
+		}
+		return ""
+	})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	_ = srv.Shutdown(context.Background())
+}
+
+func TestNewServerOutboxMaxAttempts(t *testing.T) {
+	cfg := config.Config{
+		ListenAddr: ":9999",
+		PolicyPath: "../../policies/relia.yaml",
+		DB:         config.DBConfig{Driver: "sqlite", DSN: "file::memory:?cache=shared"},
+	}
+	if _, err := newServer(cfg, func(key string) string {
+		if key == "RELIA_OUTBOX_MAX_ATTEMPTS" {
+			return "0"
+		}
+		return ""
+	}); err == nil {
+		t.Fatalf("expected invalid max attempts error")
+	}
+
+	srv, err := newServer(cfg, func(key string) string {
+		if key == "RELIA_OUTBOX_MAX_ATTEMPTS" {
+			return "3"
+		}
+		return ""
+	})
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+	_ = srv.Shutdown(context.Background())
+}
+
+func TestNewServerRejectsInvalidPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("rules: [\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg := config.Config{
+		ListenAddr: ":9999",
+		PolicyPath: path,
+		DB:         config.DBConfig{Driver: "sqlite", DSN: "file::memory:?cache=shared"},
+	}
+	if _, err := newServer(cfg, func(string) string { return "" }); err == nil {
+		t.Fatalf("expected startup error for invalid policy")
+	}
+}
+
+func TestWebhookFromConfig(t *testing.T) {
+	if notifier, verifier, err := webhookFromConfig(config.WebhookConfig{}, nil); err != nil || notifier != nil || verifier != nil {
+		t.Fatalf("expected nothing when disabled: %v %v %v", notifier, verifier, err)
+	}
+
+	cfg := config.WebhookConfig{Enabled: true, URL: "https://example.com/hook", Signing: "hmac", HMACSecret: "s"}
+	if notifier, verifier, err := webhookFromConfig(cfg, nil); err != nil || notifier == nil || verifier == nil {
+		t.Fatalf("expected hmac notifier and verifier: %v %v %v", notifier, verifier, err)
+	}
+
+	cfg = config.WebhookConfig{Enabled: true, URL: "https://example.com/hook", Signing: "ed25519"}
+	if _, _, err := webhookFromConfig(cfg, nil); err == nil {
+		t.Fatalf("expected an error for ed25519 without a signing key")
+	}
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	if notifier, verifier, err := webhookFromConfig(cfg, apiDevSigner{keyID: "k", priv: priv}); err != nil || notifier == nil || verifier != nil {
+		t.Fatalf("expected an ed25519 notifier without a callback verifier: %v %v %v", notifier, verifier, err)
+	}
+
+	cfg.Signing = "rsa"
+	if _, _, err := webhookFromConfig(cfg, nil); err == nil {
+		t.Fatalf("expected an error for unknown signing")
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/slack"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+// ErrApprovalExpired rejects a vote on an approval whose approval_timeout has passed.
+var ErrApprovalExpired = errors.New("approval expired")
+
+// approvalExpiresAt returns when an approval requested at createdAt (RFC3339) times out,
+// or nil when the policy sets no timeout.
+func approvalExpiresAt(createdAt string, timeout time.Duration) *string {
+	if timeout <= 0 {
+		return nil
+	}
+	at, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return nil
+	}
+	expiresAt := at.Add(timeout).UTC().Format(time.RFC3339)
+	return &expiresAt
+}
+
+// approvalExpired reports whether approval is still pending at now (RFC3339) although
+// its timeout has passed.
+func approvalExpired(approval ledger.ApprovalRecord, now string) bool {
+	if approval.Status != string(ApprovalPending) || approval.ExpiresAt == nil {
+		return false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, *approval.ExpiresAt)
+	if err != nil {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339, now)
+	if err != nil {
+		return false
+	}
+	return !at.Before(expiresAt)
+}
+
+// ExpireApprovals expires up to limit pending approvals whose timeout has passed at now
+// and returns how many it expired. Each gets a signed final approval_expired receipt
+// and its Slack message, if any, loses its buttons.
+func (s *AuthorizeService) ExpireApprovals(now time.Time, limit int) (int, error) {
+	createdAt := now.UTC().Format(time.RFC3339)
+	due, err := s.Ledger.ListExpiredApprovals(createdAt, limit)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, approval := range due {
+		ok, err := s.expireApprovalIfDue(approval.ApprovalID, createdAt)
+		if err != nil {
+			return expired, err
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// expireApprovalIfDue expires the approval if it is still pending past its timeout at
+// createdAt, and reports whether it did.
+func (s *AuthorizeService) expireApprovalIfDue(approvalID string, createdAt string) (bool, error) {
+	expired := false
+	err := s.Ledger.WithTx(func(tx ledger.Tx) error {
+		approval, ok, err := tx.LockApproval(approvalID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("approval not found")
+		}
+		// Another replica or a vote may have got there first.
+		if !approvalExpired(approval, createdAt) {
+			return nil
+		}
+		if err := s.putSigningKey(tx, createdAt); err != nil {
+			return err
+		}
+		if _, err := s.expireApproval(tx, approval, createdAt); err != nil {
+			return err
+		}
+		expired = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if expired {
+		s.hub.notify(approvalID)
+		s.updateSlackMessage(approvalID, slack.ApprovalResolution{Status: string(ApprovalExpired), At: createdAt})
+	}
+	return expired, nil
+}
+
+// expireApproval moves a pending approval to expired and signs the final receipt that
+// ends its request; the idempotency key becomes denied so retries get the expiry.
+func (s *AuthorizeService) expireApproval(tx ledger.Tx, approval ledger.ApprovalRecord, createdAt string) (ledger.ApprovalRecord, error) {
+	idem, ok := tx.GetIdempotencyKey(approval.IdemKey)
+	if !ok || idem.LatestReceiptID == nil {
+		return approval, fmt.Errorf("idempotency not found for approval")
+	}
+	latestReceipt, ok := tx.GetReceipt(*idem.LatestReceiptID)
+	if !ok {
+		return approval, fmt.Errorf("latest receipt not found")
+	}
+
+	receiptApproval := &types.ReceiptApproval{
+		Required:   true,
+		ApprovalID: approval.ApprovalID,
+		Status:     string(ApprovalExpired),
+		Approvers:  receiptApprovers(approval.Votes),
+	}
+	if required := requiredApprovals(approval); required > 1 {
+		receiptApproval.Quorum = required
+	}
+	if approval.ExpiresAt != nil {
+		receiptApproval.ExpiresAt = *approval.ExpiresAt
+	}
+
+	expiredReceipt, err := ledger.MakeReceipt(ledger.MakeReceiptInput{
+		CreatedAt:           createdAt,
+		IdemKey:             approval.IdemKey,
+		SupersedesReceiptID: idem.LatestReceiptID,
+		ContextID:           latestReceipt.ContextID,
+		DecisionID:          latestReceipt.DecisionID,
+		Actor:               types.ReceiptActor{Kind: "approval", Subject: "timeout"},
+		Request:             types.ReceiptRequest{RequestID: "approval", Action: "expire", Resource: approval.IdemKey, Env: ""},
+		Policy:              types.ReceiptPolicy{PolicyHash: latestReceipt.PolicyHash},
+		InteractionRef:      interactionRefFromBody(latestReceipt.BodyJSON),
+		Refs:                receiptRefsFromBody(latestReceipt.BodyJSON),
+		Approval:            receiptApproval,
+		Outcome:             types.ReceiptOutcome{Status: types.OutcomeApprovalExpired},
+	}, s.Signer)
+	if err != nil {
+		return approval, err
+	}
+	if err := tx.PutReceipt(receiptRecordFromStored(expiredReceipt)); err != nil {
+		return approval, err
+	}
+
+	approval.Status = string(ApprovalExpired)
+	approval.UpdatedAt = createdAt
+	if err := tx.PutApproval(approval); err != nil {
+		return approval, err
+	}
+
+	idem.Status = string(IdemDenied)
+	idem.LatestReceiptID = &expiredReceipt.ReceiptID
+	idem.FinalReceiptID = &expiredReceipt.ReceiptID
+	idem.UpdatedAt = createdAt
+	return approval, tx.PutIdempotencyKey(idem)
+}
+
+// RunApprovalExpiryWorker expires overdue approvals until ctx is cancelled.
+func RunApprovalExpiryWorker(ctx context.Context, s *AuthorizeService, pollInterval time.Duration) {
+	if pollInterval <= 0 {
+		pollInterval = 15 * time.Second
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			_, _ = s.ExpireApprovals(now, 25)
+		}
+	}
+}
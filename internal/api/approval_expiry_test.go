@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/slack"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+const timeoutPolicy = `policy_id: timeout
+defaults:
+  approval_timeout: 30m
+rules:
+  - id: prod
+    match: {env: prod}
+    effect:
+      require_approval: true
+      aws_role_arn: "arn:aws:iam::123456789012:role/prod"
+`
+
+type updatingSlack struct {
+	updates []slack.ApprovalResolution
+}
+
+func (s *updatingSlack) PostApproval(channel string, message slack.ApprovalMessageInput) (string, error) {
+	return "1700000000.1", nil
+}
+
+func (s *updatingSlack) UpdateMessage(channel, ts string, message slack.ApprovalMessageInput, resolution slack.ApprovalResolution) error {
+	s.updates = append(s.updates, resolution)
+	return nil
+}
+
+var timeoutRequest = AuthorizeRequest{Action: "terraform.apply", Resource: "stack/prod", Env: "prod"}
+
+func TestExpireApprovals(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, timeoutPolicy, timeoutRequest)
+	claims, req := approvalClaims, timeoutRequest
+
+	approval, _ := svc.GetApproval(approvalID)
+	if approval.ExpiresAt == nil || *approval.ExpiresAt != "2025-12-20T16:30:00Z" {
+		t.Fatalf("unexpected expires_at: %v", approval.ExpiresAt)
+	}
+	idem, _ := svc.Ledger.GetIdempotencyKey(approval.IdemKey)
+	if idem.TTLExpiresAt == nil || *idem.TTLExpiresAt != *approval.ExpiresAt {
+		t.Fatalf("expected idempotency ttl to match the approval: %v", idem.TTLExpiresAt)
+	}
+	if _, pending := signedApproval(t, svc, *idem.LatestReceiptID); pending.ExpiresAt != *approval.ExpiresAt {
+		t.Fatalf("expected expires_at in the pending receipt, got %+v", pending)
+	}
+
+	if n, err := svc.ExpireApprovals(time.Date(2025, 12, 20, 16, 29, 59, 0, time.UTC), 10); err != nil || n != 0 {
+		t.Fatalf("expected nothing to expire yet: n=%d err=%v", n, err)
+	}
+	if n, err := svc.ExpireApprovals(time.Date(2025, 12, 20, 16, 30, 0, 0, time.UTC), 10); err != nil || n != 1 {
+		t.Fatalf("expected one expiry: n=%d err=%v", n, err)
+	}
+	if n, err := svc.ExpireApprovals(time.Date(2025, 12, 20, 16, 31, 0, 0, time.UTC), 10); err != nil || n != 0 {
+		t.Fatalf("expected expiry to run once: n=%d err=%v", n, err)
+	}
+
+	approval, _ = svc.GetApproval(approvalID)
+	if approval.Status != string(ApprovalExpired) {
+		t.Fatalf("expected expired approval, got %s", approval.Status)
+	}
+	idem, _ = svc.Ledger.GetIdempotencyKey(approval.IdemKey)
+	if idem.Status != string(IdemDenied) || idem.FinalReceiptID == nil {
+		t.Fatalf("expected denied idempotency key with a final receipt: %+v", idem)
+	}
+	rec, signed := signedApproval(t, svc, *idem.FinalReceiptID)
+	if rec.OutcomeStatus != string(types.OutcomeApprovalExpired) || !rec.Final || signed.Status != "expired" {
+		t.Fatalf("unexpected expiry receipt: outcome=%s final=%v approval=%+v", rec.OutcomeStatus, rec.Final, signed)
+	}
+	stored := ledger.StoredReceipt{ReceiptID: rec.ReceiptID, BodyDigest: rec.BodyDigest, BodyJSON: rec.BodyJSON, KeyID: rec.KeyID, Sig: rec.Sig, IdemKey: rec.IdemKey, SupersedesReceiptID: rec.SupersedesReceiptID}
+	if err := ledger.VerifyReceipt(stored, svc.PublicKey); err != nil {
+		t.Fatalf("verify expiry receipt: %v", err)
+	}
+
+	resp, err := svc.Authorize(claims, req, "2025-12-20T16:45:00Z")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if resp.Verdict != string(VerdictDeny) || resp.Error != "approval expired" || resp.Approval == nil || resp.Approval.Status != "expired" || resp.ReceiptID != rec.ReceiptID {
+		t.Fatalf("expected an approval expired verdict, got %+v", resp)
+	}
+
+	_, err = svc.Approve(approvalID, types.Approver{Kind: "slack", ID: "U1"}, "approved", "2025-12-20T16:46:00Z")
+	var rejected *VoteRejectedError
+	if !errors.As(err, &rejected) || !errors.Is(err, ErrApprovalExpired) {
+		t.Fatalf("expected an expired vote rejection, got %v", err)
+	}
+	approval, _ = svc.GetApproval(approvalID)
+	if approval.Status != string(ApprovalExpired) || len(approval.Votes) != 1 || approval.Votes[0].Status != VoteRejected {
+		t.Fatalf("expected the late vote recorded as rejected: %+v", approval)
+	}
+}
+
+func TestAuthorizeRetryExpiresWithoutSweeper(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, timeoutPolicy, timeoutRequest)
+	claims, req := approvalClaims, timeoutRequest
+
+	resp, err := svc.Authorize(claims, req, "2025-12-20T16:29:00Z")
+	if err != nil || resp.Verdict != string(VerdictRequireApproval) {
+		t.Fatalf("expected pending before the timeout: %+v err=%v", resp, err)
+	}
+	resp, err = svc.Authorize(claims, req, "2025-12-20T16:30:00Z")
+	if err != nil || resp.Verdict != string(VerdictDeny) || resp.Error != "approval expired" {
+		t.Fatalf("expected expiry on retry: %+v err=%v", resp, err)
+	}
+	if approval, _ := svc.GetApproval(approvalID); approval.Status != string(ApprovalExpired) {
+		t.Fatalf("expected expired approval, got %s", approval.Status)
+	}
+}
+
+func TestApproveAfterTimeoutExpires(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, timeoutPolicy, timeoutRequest)
+	notifier := &updatingSlack{}
+	svc.Slack = notifier
+	channel, ts := "C1", "1700000000.1"
+	approval, _ := svc.GetApproval(approvalID)
+	approval.SlackChannel, approval.SlackMsgTS = &channel, &ts
+	if err := svc.Ledger.PutApproval(approval); err != nil {
+		t.Fatalf("put approval: %v", err)
+	}
+	if err := svc.Ledger.PutOutbox(ledger.OutboxRecord{OutboxID: "slack_post:" + approvalID, Kind: "slack_post", ApprovalID: approvalID, Target: channel, PayloadJSON: []byte(`{"ApprovalID":"` + approvalID + `"}`), Status: "sent"}); err != nil {
+		t.Fatalf("put outbox: %v", err)
+	}
+
+	_, err := svc.Approve(approvalID, types.Approver{Kind: "slack", ID: "U1"}, "approved", "2025-12-20T17:00:00Z")
+	svc.waitDeliveries()
+	if !errors.Is(err, ErrApprovalExpired) {
+		t.Fatalf("expected expired vote rejection, got %v", err)
+	}
+	approval, _ = svc.GetApproval(approvalID)
+	if approval.Status != string(ApprovalExpired) {
+		t.Fatalf("expected the vote to expire the approval, got %s", approval.Status)
+	}
+	if len(notifier.updates) != 1 || notifier.updates[0].Status != "expired" || notifier.updates[0].At != "2025-12-20T17:00:00Z" {
+		t.Fatalf("expected one expired Slack update, got %+v", notifier.updates)
+	}
+	if rec, ok := svc.Ledger.GetOutbox("slack_update:" + approvalID + ":resolved"); !ok || rec.Status != "sent" || rec.Target != channel {
+		t.Fatalf("expected a sent slack_update record, got %+v ok=%v", rec, ok)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	stdcontext "context"
+	"time"
+
+	"github.com/davidahmann/relia/internal/notify"
+	"github.com/davidahmann/relia/internal/outbox"
+	"github.com/davidahmann/relia/internal/policy"
+	"github.com/davidahmann/relia/internal/slack"
+)
+
+// notifyRoute is a configured transport and the channel its approvals are posted to.
+type notifyRoute struct {
+	transport string
+	channel   string
+}
+
+// channelAliases is implemented by notifiers that post to named channel aliases, such
+// as the Teams client.
+type channelAliases interface {
+	HasChannel(alias string) bool
+}
+
+// approvalRoutes lists the configured transports in fallback order. A rule's
+// approval_channel replaces the gateway channel of the transport it suits: a channel
+// alias the Teams notifier knows for Teams, and a Slack channel ID otherwise. Slack
+// without any channel is left out.
+func (s *AuthorizeService) approvalRoutes(ruleChannel string) []notifyRoute {
+	var routes []notifyRoute
+	aliases, ok := s.Teams.(channelAliases)
+	teamsChannel := ruleChannel != "" && ok && aliases.HasChannel(ruleChannel)
+	if s.Slack != nil {
+		channel := s.SlackChan
+		if ruleChannel != "" && !teamsChannel {
+			channel = ruleChannel
+		}
+		if channel != "" {
+			routes = append(routes, notifyRoute{notify.TransportSlack, channel})
+		}
+	}
+	if s.Teams != nil {
+		channel := s.TeamsChan
+		if teamsChannel {
+			channel = ruleChannel
+		}
+		routes = append(routes, notifyRoute{notify.TransportTeams, channel})
+	}
+	if s.Webhook != nil {
+		routes = append(routes, notifyRoute{notify.TransportWebhook, ""})
+	}
+	return routes
+}
+
+// approvalRoute picks where a pending approval is posted: the policy's
+// approval_transport when the gateway has it configured, otherwise Slack, Teams, then
+// the webhook. The transport is empty when no notifier is configured.
+func (s *AuthorizeService) approvalRoute(preferred policy.ApprovalTransport, ruleChannel string) (transport, channel string) {
+	routes := s.approvalRoutes(ruleChannel)
+	for _, route := range routes {
+		if route.transport == string(preferred) {
+			return route.transport, route.channel
+		}
+	}
+	if len(routes) == 0 {
+		return "", ""
+	}
+	return routes[0].transport, routes[0].channel
+}
+
+// outboxKind is the outbox kind that posts approval requests over transport.
+func outboxKind(transport string) string {
+	switch transport {
+	case notify.TransportTeams:
+		return outbox.KindTeamsPost
+	case notify.TransportWebhook:
+		return outbox.KindWebhook
+	default:
+		return outbox.KindSlackPost
+	}
+}
+
+func (s *AuthorizeService) outboxMaxAttempts() int {
+	if s.OutboxMaxAttempts > 0 {
+		return s.OutboxMaxAttempts
+	}
+	return outbox.DefaultMaxAttempts
+}
+
+// OutboxWorkers returns a worker for each outbox kind the configured transports
+// deliver. The gateway runs them in the background.
+func (s *AuthorizeService) OutboxWorkers() []*outbox.Worker {
+	var workers []*outbox.Worker
+	if s.Slack != nil {
+		workers = append(workers,
+			&outbox.Worker{Store: s.Ledger, Kind: outbox.KindSlackPost, Deliver: slack.DeliverPost(s.Ledger, s.Slack)},
+			&outbox.Worker{Store: s.Ledger, Kind: outbox.KindSlackUpdate, Deliver: s.deliverSlackUpdate},
+		)
+	}
+	if s.Teams != nil {
+		workers = append(workers, &outbox.Worker{Store: s.Ledger, Kind: outbox.KindTeamsPost, Deliver: notify.DeliverPost(s.Ledger, s.Teams)})
+	}
+	if s.Webhook != nil {
+		workers = append(workers, &outbox.Worker{Store: s.Ledger, Kind: outbox.KindWebhook, Deliver: notify.DeliverPost(s.Ledger, s.Webhook)})
+	}
+	return workers
+}
+
+// processOutbox starts delivering the just-queued record outboxID in the background,
+// rather than waiting for the outbox worker's next tick, so the request that queued it
+// does not wait on Slack or Teams. The attempt leases the record like any worker;
+// failures stay queued for the workers to retry.
+func (s *AuthorizeService) processOutbox(kind, outboxID string) {
+	for _, w := range s.OutboxWorkers() {
+		if w.Kind == kind {
+			s.deliveries.Add(1)
+			go func() {
+				defer s.deliveries.Done()
+				_, _ = w.ProcessOne(stdcontext.Background(), outboxID, time.Now().UTC())
+			}()
+			return
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davidahmann/relia/internal/notify"
+)
+
+const transportPolicy = `policy_id: transport
+policy_version: "1"
+defaults: {require_approval: true}
+rules:
+  - id: platform
+    match: {resource: "platform/*"}
+    effect: {approval_transport: teams}
+  - id: migrations
+    match: {action: "db.*"}
+    effect: {approval_channel: C_DATA, approval_mentions: [S_DBA]}
+`
+
+type fakeTeamsNotifier struct {
+	called  int
+	channel string
+	input   notify.ApprovalMessage
+}
+
+func (f *fakeTeamsNotifier) PostApproval(channel string, message notify.ApprovalMessage) (string, error) {
+	f.called++
+	f.channel = channel
+	f.input = message
+	return "", nil
+}
+
+func (f *fakeTeamsNotifier) HasChannel(alias string) bool { return alias == "platform" }
+
+func newTransportService(t *testing.T) (*AuthorizeService, *fakeSlackNotifier, *fakeTeamsNotifier) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "relia.yaml")
+	if err := os.WriteFile(path, []byte(transportPolicy), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	svc := newTestService(t, path)
+	slackNotifier, teamsNotifier := &fakeSlackNotifier{}, &fakeTeamsNotifier{}
+	svc.Slack, svc.SlackChan = slackNotifier, "C123"
+	svc.Teams = teamsNotifier
+	return svc, slackNotifier, teamsNotifier
+}
+
+func TestAuthorizeRoutesApprovalsByPolicyTransport(t *testing.T) {
+	svc, slackNotifier, teamsNotifier := newTransportService(t)
+	claims := ActorContext{Subject: "repo:org/repo:ref:refs/heads/main", Issuer: "relia-dev", Repo: "org/repo", RunID: "1"}
+
+	resp, err := svc.Authorize(claims, AuthorizeRequest{Action: "terraform.apply", Resource: "platform/vpc", Env: "prod"}, "2025-12-20T16:34:14Z")
+	svc.waitDeliveries()
+	if err != nil || resp.Approval == nil {
+		t.Fatalf("expected pending approval: %+v err=%v", resp, err)
+	}
+	if teamsNotifier.called != 1 || slackNotifier.called != 0 || teamsNotifier.input.ApprovalID != resp.Approval.ApprovalID {
+		t.Fatalf("expected only teams to be notified: teams=%d slack=%d", teamsNotifier.called, slackNotifier.called)
+	}
+	outbox, ok := svc.Ledger.GetOutbox("teams_post:" + resp.Approval.ApprovalID)
+	if !ok || outbox.Kind != "teams_post" || outbox.Status != "sent" {
+		t.Fatalf("expected a sent teams notification, got %+v ok=%v", outbox, ok)
+	}
+	if approval, _ := svc.Ledger.GetApproval(resp.Approval.ApprovalID); approval.SlackMsgTS != nil {
+		t.Fatalf("expected no slack message for a teams approval")
+	}
+
+	resp, err = svc.Authorize(claims, AuthorizeRequest{Action: "terraform.apply", Resource: "app", Env: "prod"}, "2025-12-20T16:34:15Z")
+	svc.waitDeliveries()
+	if err != nil || resp.Approval == nil {
+		t.Fatalf("expected pending approval: %+v err=%v", resp, err)
+	}
+	if slackNotifier.called != 1 || slackNotifier.channel != "C123" || teamsNotifier.called != 1 {
+		t.Fatalf("expected the default rule to go to slack: teams=%d slack=%d", teamsNotifier.called, slackNotifier.called)
+	}
+}
+
+func TestApprovalRouteFallsBackToConfiguredTransport(t *testing.T) {
+	svc, _, _ := newTransportService(t)
+	svc.Teams = nil
+	if transport, channel := svc.approvalRoute("teams", ""); transport != notify.TransportSlack || channel != "C123" {
+		t.Fatalf("expected slack when teams is not configured, got %s %s", transport, channel)
+	}
+
+	svc, _, _ = newTransportService(t)
+	svc.Slack = nil
+	svc.TeamsChan = "platform"
+	if transport, channel := svc.approvalRoute("", ""); transport != notify.TransportTeams || channel != "platform" {
+		t.Fatalf("expected teams when slack is not configured, got %s %s", transport, channel)
+	}
+
+	svc.Teams = nil
+	if transport, _ := svc.approvalRoute("teams", ""); transport != "" {
+		t.Fatalf("expected no transport, got %s", transport)
+	}
+}
+
+func TestAuthorizeRoutesApprovalsToRuleChannel(t *testing.T) {
+	svc, slackNotifier, _ := newTransportService(t)
+	claims := ActorContext{Subject: "repo:org/repo:ref:refs/heads/main", Issuer: "relia-dev", Repo: "org/repo", RunID: "1"}
+
+	resp, err := svc.Authorize(claims, AuthorizeRequest{Action: "db.migrate", Resource: "db/orders", Env: "prod"}, "2025-12-20T16:34:14Z")
+	svc.waitDeliveries()
+	if err != nil || resp.Approval == nil {
+		t.Fatalf("expected pending approval: %+v err=%v", resp, err)
+	}
+	if slackNotifier.channel != "C_DATA" || len(slackNotifier.input.Mentions) != 1 || slackNotifier.input.Mentions[0] != "S_DBA" {
+		t.Fatalf("expected the rule's channel and mentions, got %s %v", slackNotifier.channel, slackNotifier.input.Mentions)
+	}
+	outbox, ok := svc.Ledger.GetOutbox("slack_post:" + resp.Approval.ApprovalID)
+	if !ok || outbox.Target != "C_DATA" {
+		t.Fatalf("expected the outbox to record the rule's channel, got %+v ok=%v", outbox, ok)
+	}
+}
+
+func TestApprovalRouteRuleChannel(t *testing.T) {
+	svc, _, _ := newTransportService(t)
+	if transport, channel := svc.approvalRoute("", "C_DATA"); transport != notify.TransportSlack || channel != "C_DATA" {
+		t.Fatalf("expected the rule's slack channel, got %s %s", transport, channel)
+	}
+	// A Teams channel alias does not replace the Slack channel.
+	if transport, channel := svc.approvalRoute("", "platform"); transport != notify.TransportSlack || channel != "C123" {
+		t.Fatalf("expected the gateway's slack channel, got %s %s", transport, channel)
+	}
+	if transport, channel := svc.approvalRoute("teams", "platform"); transport != notify.TransportTeams || channel != "platform" {
+		t.Fatalf("expected the rule's teams channel, got %s %s", transport, channel)
+	}
+	// A name the Teams notifier does not know is taken as a Slack channel ID.
+	if transport, channel := svc.approvalRoute("teams", "C_DATA"); transport != notify.TransportTeams || channel != "" {
+		t.Fatalf("expected the gateway's teams channel, got %s %s", transport, channel)
+	}
+
+	// Slack without a gateway channel still serves rules that name one.
+	svc.SlackChan = ""
+	if transport, channel := svc.approvalRoute("slack", "C_DATA"); transport != notify.TransportSlack || channel != "C_DATA" {
+		t.Fatalf("expected slack with the rule's channel, got %s %s", transport, channel)
+	}
+	if transport, _ := svc.approvalRoute("slack", ""); transport != notify.TransportTeams {
+		t.Fatalf("expected teams when slack has no channel, got %s", transport)
+	}
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/policy"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+var (
+	// ErrApproverRequired rejects an anonymous vote on an approval that needs more
+	// than one approver or separation of duties.
+	ErrApproverRequired = errors.New("approval needs an identified approver; vote has no approver identity")
+	// ErrSelfApproval rejects a vote from an approver identity the policy maps to the
+	// requester.
+	ErrSelfApproval = errors.New("approver triggered the request and may not vote on it")
+	// ErrRequesterUnmapped rejects every vote under separation of duties when the
+	// requester has no approver identities in the policy, so the check cannot be made.
+	ErrRequesterUnmapped = errors.New("separation of duties: requester has no approver identities in the policy")
+	// ErrApproverNotInGroup rejects a vote from outside the approval's groups.
+	ErrApproverNotInGroup = errors.New("approver is not in an allowed approver group")
+	// ErrApproverNotAllowed rejects a vote from an approver missing from the approver
+	// list of a rule that matched the request.
+	ErrApproverNotAllowed = errors.New("approver is not allowed to approve this request")
+	// ErrSSOApproverUnrestricted rejects an SSO vote on an approval whose rules name
+	// neither approvers nor groups: the approver audience alone admits anyone in the
+	// identity provider.
+	ErrSSOApproverUnrestricted = errors.New("SSO approvals need an approvers list or approval groups on the matching rule")
+)
+
+// VoteRejectedError is returned by Approve when the approver may not vote. The
+// attempt is still recorded on the approval; Err is the reason.
+type VoteRejectedError struct {
+	Err error
+}
+
+func (e *VoteRejectedError) Error() string { return e.Err.Error() }
+
+func (e *VoteRejectedError) Unwrap() error { return e.Err }
+
+// VoteRejected marks the error for transports that tell the approver their vote did
+// not count.
+func (e *VoteRejectedError) VoteRejected() bool { return true }
+
+// VoteRejected is the status of a recorded vote attempt that did not count.
+const VoteRejected = "rejected"
+
+// checkVoter enforces the approval's quorum identity requirement and approver groups.
+func checkVoter(approval ledger.ApprovalRecord, approver types.Approver) error {
+	if approver.ID == "" && requiredApprovals(approval) > 1 {
+		return ErrApproverRequired
+	}
+	if len(approval.ApproverGroups) > 0 {
+		for _, group := range approver.Groups {
+			if slices.Contains(approval.ApproverGroups, group) {
+				return nil
+			}
+		}
+		return ErrApproverNotInGroup
+	}
+	return nil
+}
+
+// checkPolicyVoter checks approver against the rules that matched the request: their
+// approver lists, the restriction SSO approvers need, and separation of duties. It
+// returns the rejection reason, or nil.
+// The rules come from the decision and policy snapshot recorded when the request was
+// authorized, so later policy changes do not change who may vote.
+func checkPolicyVoter(tx ledger.Tx, latest ledger.ReceiptRecord, approval ledger.ApprovalRecord, approver types.Approver) (rejection error, err error) {
+	decisionRec, ok := tx.GetDecision(latest.DecisionID)
+	if !ok {
+		return nil, fmt.Errorf("decision not found")
+	}
+	var decision types.DecisionRecord
+	if err := json.Unmarshal(decisionRec.BodyJSON, &decision); err != nil {
+		return nil, err
+	}
+	var snapshot policy.Policy
+	matched := decisionMatchedRules(decision)
+	if len(matched) > 0 {
+		policyVersion, ok := tx.GetPolicyVersion(latest.PolicyHash)
+		if !ok {
+			return nil, fmt.Errorf("policy version not found")
+		}
+		loaded, err := policy.LoadPolicyFromBytes([]byte(policyVersion.PolicyYAML))
+		if err != nil {
+			return nil, err
+		}
+		snapshot = loaded.Policy
+	}
+
+	allowlists := snapshot.RuleApprovers(matched)
+	for _, allowlist := range allowlists {
+		if !allowlist.Allows(approver.Kind, approver.ID, approver.Groups) {
+			return ErrApproverNotAllowed, nil
+		}
+	}
+	if approver.Kind == "oidc" && len(allowlists) == 0 && len(approval.ApproverGroups) == 0 {
+		return ErrSSOApproverUnrestricted, nil
+	}
+	if snapshot.RequiresSeparationOfDuties(matched) {
+		return checkSeparationOfDuties(snapshot.Identities, approval, approver), nil
+	}
+	return nil, nil
+}
+
+// checkSeparationOfDuties rejects a vote from any approver identity mapped to the
+// requester, the verified GitHub actor recorded on the approval. It fails closed: an
+// anonymous vote, or a requester without mapped identities, is rejected.
+func checkSeparationOfDuties(identities policy.Identities, approval ledger.ApprovalRecord, approver types.Approver) error {
+	if approver.ID == "" {
+		return ErrApproverRequired
+	}
+	var requester string
+	if approval.RequestedBy != nil {
+		requester = *approval.RequestedBy
+	}
+	mapped := identities.Of(requester)
+	if len(mapped) == 0 {
+		return ErrRequesterUnmapped
+	}
+	if slices.Contains(mapped, approver.Subject()) {
+		return ErrSelfApproval
+	}
+	return nil
+}
+
+// decisionMatchedRules lists the rules that matched a decision. First-match decisions
+// do not record matched_rules; their one rule is named by the POLICY_MATCH reason code.
+func decisionMatchedRules(decision types.DecisionRecord) []string {
+	if len(decision.MatchedRules) > 0 {
+		return decision.MatchedRules
+	}
+	for _, code := range decision.ReasonCodes {
+		if id, ok := strings.CutPrefix(code, "POLICY_MATCH:"); ok {
+			return []string{id}
+		}
+	}
+	return nil
+}
+
+// priorVote returns the vote approver already cast, if any. Anonymous votes and
+// rejected attempts are never matched.
+func priorVote(approval ledger.ApprovalRecord, approver types.Approver) (ledger.ApprovalVote, bool) {
+	if approver.ID == "" {
+		return ledger.ApprovalVote{}, false
+	}
+	for _, vote := range approval.Votes {
+		if vote.Status == VoteRejected {
+			continue
+		}
+		if vote.ApproverKind == approver.Kind && vote.ApproverID == approver.ID {
+			return vote, true
+		}
+	}
+	return ledger.ApprovalVote{}, false
+}
+
+// tallyVotes returns the approval status the votes add up to: any deny denies, and
+// enough approvals approve.
+func tallyVotes(approval ledger.ApprovalRecord) ApprovalStatus {
+	approved := 0
+	for _, vote := range approval.Votes {
+		switch ApprovalStatus(vote.Status) {
+		case ApprovalDenied:
+			return ApprovalDenied
+		case ApprovalApproved:
+			approved++
+		}
+	}
+	if approved >= requiredApprovals(approval) {
+		return ApprovalApproved
+	}
+	return ApprovalPending
+}
+
+func requiredApprovals(approval ledger.ApprovalRecord) int {
+	if approval.RequiredApprovals < 1 {
+		return 1
+	}
+	return approval.RequiredApprovals
+}
+
+// rejectVote records approver's attempt on the approval without counting it.
+func rejectVote(approval ledger.ApprovalRecord, approver types.Approver, reason error, createdAt string) ledger.ApprovalRecord {
+	approval.Votes = append(approval.Votes, ledger.ApprovalVote{
+		ApproverKind:    approver.Kind,
+		ApproverID:      approver.ID,
+		ApproverDisplay: approver.Display,
+		ApproverTeamID:  approver.TeamID,
+		Status:          VoteRejected,
+		Reason:          reason.Error(),
+		CreatedAt:       createdAt,
+	})
+	approval.UpdatedAt = createdAt
+	return approval
+}
+
+// receiptApprovers lists the identified, counted votes for a signed receipt.
+func receiptApprovers(votes []ledger.ApprovalVote) []types.ReceiptApprover {
+	var approvers []types.ReceiptApprover
+	for _, vote := range votes {
+		if vote.ApproverID == "" || vote.Status == VoteRejected {
+			continue
+		}
+		approvers = append(approvers, types.ReceiptApprover{
+			Kind:    vote.ApproverKind,
+			ID:      vote.ApproverID,
+			Display: vote.ApproverDisplay,
+			Vote:    vote.Status,
+			At:      vote.CreatedAt,
+			Comment: vote.Comment,
+		})
+	}
+	return approvers
+}
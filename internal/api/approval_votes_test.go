@@ -0,0 +1,267 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/ledger/sqlstore"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+const quorumPolicy = `policy_id: quorum
+identities:
+  OctoCat: [slack:U9, "oidc:https://sso.example.com|octo"]
+rules:
+  - id: prod_destroy
+    match: {action: terraform.destroy, env: prod}
+    effect:
+      require_approval: true
+      aws_role_arn: "arn:aws:iam::123456789012:role/prod-destroy"
+      approvals: {required: 2, groups: [sre, security], separation_of_duties: true}
+`
+
+var quorumRequest = AuthorizeRequest{Action: "terraform.destroy", Resource: "stack/prod", Env: "prod"}
+
+func signedApproval(t *testing.T, svc *AuthorizeService, receiptID string) (ledger.ReceiptRecord, types.ReceiptApproval) {
+	t.Helper()
+	rec, ok := svc.Ledger.GetReceipt(receiptID)
+	if !ok {
+		t.Fatalf("receipt %s not found", receiptID)
+	}
+	var body struct {
+		Approval types.ReceiptApproval `json:"approval"`
+	}
+	if err := json.Unmarshal(rec.BodyJSON, &body); err != nil {
+		t.Fatalf("decode receipt: %v", err)
+	}
+	return rec, body.Approval
+}
+
+func TestVoteReachesQuorum(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, quorumPolicy, quorumRequest)
+	claims, req := approvalClaims, quorumRequest
+
+	alice := types.Approver{Kind: "slack", ID: "U1", Display: "alice", Groups: []string{"sre"}}
+	bob := types.Approver{Kind: "slack", ID: "U2", Display: "bob", Groups: []string{"security"}}
+
+	first, err := svc.Approve(approvalID, alice, "approved", "2025-12-20T16:35:00Z")
+	if err != nil {
+		t.Fatalf("first vote: %v", err)
+	}
+	rec, approval := signedApproval(t, svc, first)
+	if rec.OutcomeStatus != string(types.OutcomeApprovalPending) || approval.Status != "pending" || approval.Quorum != 2 || len(approval.Approvers) != 1 {
+		t.Fatalf("unexpected first vote receipt: outcome=%s approval=%+v", rec.OutcomeStatus, approval)
+	}
+	resp, err := svc.Authorize(claims, req, "2025-12-20T16:35:30Z")
+	if err != nil || resp.Verdict != string(VerdictRequireApproval) {
+		t.Fatalf("expected still pending after one vote: %+v err=%v", resp, err)
+	}
+
+	again, err := svc.Approve(approvalID, alice, "approved", "2025-12-20T16:35:40Z")
+	if err != nil || again != first {
+		t.Fatalf("repeat vote should return the first receipt: %s err=%v", again, err)
+	}
+
+	second, err := svc.Approve(approvalID, bob, "approved", "2025-12-20T16:36:00Z")
+	if err != nil {
+		t.Fatalf("second vote: %v", err)
+	}
+	rec, approval = signedApproval(t, svc, second)
+	if rec.OutcomeStatus != string(types.OutcomeApprovalApproved) || approval.Status != "approved" {
+		t.Fatalf("unexpected quorum receipt: outcome=%s approval=%+v", rec.OutcomeStatus, approval)
+	}
+	if len(approval.Approvers) != 2 || approval.Approvers[0].ID != "U1" || approval.Approvers[1].ID != "U2" || approval.Approvers[1].Vote != "approved" {
+		t.Fatalf("expected both approvers in the receipt: %+v", approval.Approvers)
+	}
+	if rec.SupersedesReceiptID == nil || *rec.SupersedesReceiptID != first {
+		t.Fatalf("quorum receipt should supersede the first vote")
+	}
+
+	stored, _ := svc.GetApproval(approvalID)
+	if len(stored.Votes) != 2 || stored.Votes[1].ReceiptID != second {
+		t.Fatalf("unexpected stored votes: %+v", stored.Votes)
+	}
+
+	resp, err = svc.Authorize(claims, req, "2025-12-20T16:34:14Z")
+	if err != nil || resp.Verdict != string(VerdictAllow) || resp.AWSCredentials == nil {
+		t.Fatalf("expected credentials after quorum: %+v err=%v", resp, err)
+	}
+}
+
+func TestVoteDenyEndsApproval(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, quorumPolicy, quorumRequest)
+
+	if _, err := svc.Approve(approvalID, types.Approver{Kind: "slack", ID: "U1", Groups: []string{"sre"}}, "approved", "2025-12-20T16:35:00Z"); err != nil {
+		t.Fatalf("first vote: %v", err)
+	}
+	denied, err := svc.Approve(approvalID, types.Approver{Kind: "slack", ID: "U2", Groups: []string{"security"}}, "denied", "2025-12-20T16:36:00Z")
+	if err != nil {
+		t.Fatalf("deny vote: %v", err)
+	}
+	rec, approval := signedApproval(t, svc, denied)
+	if rec.OutcomeStatus != string(types.OutcomeApprovalDenied) || approval.Status != "denied" || len(approval.Approvers) != 2 {
+		t.Fatalf("unexpected deny receipt: outcome=%s approval=%+v", rec.OutcomeStatus, approval)
+	}
+	stored, _ := svc.GetApproval(approvalID)
+	idem, _ := svc.Ledger.GetIdempotencyKey(stored.IdemKey)
+	if idem.Status != string(IdemDenied) || idem.FinalReceiptID == nil || *idem.FinalReceiptID != denied {
+		t.Fatalf("expected denied idempotency state: %+v", idem)
+	}
+
+	// Later votes do not reopen it.
+	after, err := svc.Approve(approvalID, types.Approver{Kind: "slack", ID: "U3", Groups: []string{"sre"}}, "approved", "2025-12-20T16:37:00Z")
+	if err != nil || after != denied {
+		t.Fatalf("expected the final receipt, got %s err=%v", after, err)
+	}
+}
+
+func TestVoteRejectsIneligibleApprovers(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, quorumPolicy, quorumRequest)
+
+	cases := []struct {
+		name     string
+		approver types.Approver
+		want     error
+	}{
+		{"anonymous", types.Approver{}, ErrApproverRequired},
+		{"requester", types.Approver{Kind: "slack", ID: "U9", Display: "someone-else", Groups: []string{"sre"}}, ErrSelfApproval},
+		{"outside groups", types.Approver{Kind: "slack", ID: "U1", Groups: []string{"dev"}}, ErrApproverNotInGroup},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Approve(approvalID, tc.approver, "approved", "2025-12-20T16:35:00Z"); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	stored, _ := svc.GetApproval(approvalID)
+	if stored.Status != string(ApprovalPending) || len(stored.Votes) != len(cases) {
+		t.Fatalf("expected every rejected attempt recorded: %+v", stored)
+	}
+	for i, vote := range stored.Votes {
+		if vote.Status != VoteRejected || vote.ReceiptID != "" || vote.Reason != cases[i].want.Error() {
+			t.Fatalf("unexpected rejected vote: %+v", vote)
+		}
+	}
+}
+
+func TestVoteSeparationOfDutiesFailsClosed(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, strings.Replace(quorumPolicy, "OctoCat:", "hubot:", 1), quorumRequest)
+
+	// The requester has no mapped identities, so no vote can be checked against them.
+	alice := types.Approver{Kind: "slack", ID: "U1", Display: "alice", Groups: []string{"sre"}}
+	if _, err := svc.Approve(approvalID, alice, "approved", "2025-12-20T16:35:00Z"); !errors.Is(err, ErrRequesterUnmapped) {
+		t.Fatalf("expected ErrRequesterUnmapped, got %v", err)
+	}
+}
+
+const allowlistPolicy = `policy_id: allowlist
+rules:
+  - id: prod_deploy
+    match: {action: deploy, env: prod}
+    effect:
+      require_approval: true
+      aws_role_arn: "arn:aws:iam::123456789012:role/prod-deploy"
+      approvers:
+        slack_users: [U1]
+        slack_groups: [S_SRE]
+`
+
+func TestVoteEnforcesPolicyApprovers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relia.yaml")
+	if err := os.WriteFile(path, []byte(allowlistPolicy), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	svc := newTestService(t, path)
+	claims := ActorContext{Subject: "sub", Issuer: "iss", Repo: "org/repo", RunID: "1", Actor: "octocat"}
+	resp, err := svc.Authorize(claims, AuthorizeRequest{Action: "deploy", Resource: "svc", Env: "prod"}, "2025-12-20T16:34:14Z")
+	if err != nil || resp.Approval == nil {
+		t.Fatalf("expected pending approval: %+v err=%v", resp, err)
+	}
+	approvalID := resp.Approval.ApprovalID
+
+	// The allowlist is read from the stored policy snapshot, not the file on disk.
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(allowlistPolicy, "[U1]", "[U2]")), 0o600); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+
+	outsider := types.Approver{Kind: "slack", ID: "U2", Groups: []string{"S_DEV"}}
+	_, err = svc.Approve(approvalID, outsider, "approved", "2025-12-20T16:35:00Z")
+	var rejected *VoteRejectedError
+	if !errors.As(err, &rejected) || !errors.Is(err, ErrApproverNotAllowed) {
+		t.Fatalf("expected a rejected vote, got %v", err)
+	}
+	if _, err := svc.Approve(approvalID, types.Approver{}, "approved", "2025-12-20T16:35:10Z"); !errors.Is(err, ErrApproverNotAllowed) {
+		t.Fatalf("anonymous votes cannot satisfy an approver list, got %v", err)
+	}
+	stored, _ := svc.GetApproval(approvalID)
+	if stored.Status != string(ApprovalPending) || len(stored.Votes) != 2 || stored.Votes[0].ApproverID != "U2" || stored.Votes[0].Status != VoteRejected {
+		t.Fatalf("expected recorded rejections: %+v", stored)
+	}
+
+	byGroup := types.Approver{Kind: "slack", ID: "U3", Groups: []string{"S_SRE"}}
+	receiptID, err := svc.Approve(approvalID, byGroup, "approved", "2025-12-20T16:36:00Z")
+	if err != nil {
+		t.Fatalf("group member vote: %v", err)
+	}
+	_, approval := signedApproval(t, svc, receiptID)
+	if approval.Status != "approved" || len(approval.Approvers) != 1 || approval.Approvers[0].ID != "U3" {
+		t.Fatalf("rejected attempts must not reach the receipt: %+v", approval)
+	}
+}
+
+// TestConcurrentVotesOnSQLite races two quorum votes through a real database, where
+// each vote is its own transaction; the in-memory store serializes them regardless.
+func TestConcurrentVotesOnSQLite(t *testing.T) {
+	store, err := sqlstore.OpenSQLite("file:" + filepath.Join(t.TempDir(), "relia.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+	if err := ledger.Migrate(store.DB(), ledger.DBSQLite); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "relia.yaml")
+	if err := os.WriteFile(path, []byte(quorumPolicy), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	svc := newStoreService(t, path, store)
+	approvalID := requestApproval(t, svc, quorumRequest)
+
+	approvers := []types.Approver{
+		{Kind: "slack", ID: "U1", Display: "alice", Groups: []string{"sre"}},
+		{Kind: "slack", ID: "U2", Display: "bob", Groups: []string{"security"}},
+	}
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, len(approvers))
+	for i, approver := range approvers {
+		wg.Add(1)
+		go func(i int, approver types.Approver) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = svc.Approve(approvalID, approver, "approved", "2025-12-20T16:35:00Z")
+		}(i, approver)
+	}
+	close(start)
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("vote %d: %v", i, err)
+		}
+	}
+
+	approval, _ := svc.Ledger.GetApproval(approvalID)
+	if approval.Status != "approved" || len(approval.Votes) != 2 {
+		t.Fatalf("expected both votes to count, got status=%s votes=%+v", approval.Status, approval.Votes)
+	}
+	first, _ := svc.Ledger.GetReceipt(approval.Votes[0].ReceiptID)
+	second, _ := svc.Ledger.GetReceipt(approval.Votes[1].ReceiptID)
+	if second.SupersedesReceiptID == nil || *second.SupersedesReceiptID != first.ReceiptID {
+		t.Fatalf("expected the second vote's receipt to supersede the first, got %v", second.SupersedesReceiptID)
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	stdcontext "context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+)
+
+// ErrApprovalNotFound is returned by WaitForApproval for an unknown approval ID.
+var ErrApprovalNotFound = errors.New("approval not found")
+
+// MaxApprovalWait caps how long one request may block waiting for an approval.
+const MaxApprovalWait = 15 * time.Minute
+
+// defaultWaitPollInterval is how often waiters re-read the approval, to see votes
+// handled by other replicas.
+const defaultWaitPollInterval = 2 * time.Second
+
+// approvalHub wakes requests waiting on an approval when this process changes it. The
+// zero value is ready to use.
+type approvalHub struct {
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{}
+}
+
+// subscribe returns a channel that receives after the next notify for approvalID, and
+// a func that must be called to stop waiting.
+func (h *approvalHub) subscribe(approvalID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.waiters == nil {
+		h.waiters = make(map[string]map[chan struct{}]struct{})
+	}
+	if h.waiters[approvalID] == nil {
+		h.waiters[approvalID] = make(map[chan struct{}]struct{})
+	}
+	h.waiters[approvalID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.waiters[approvalID], ch)
+		if len(h.waiters[approvalID]) == 0 {
+			delete(h.waiters, approvalID)
+		}
+	}
+}
+
+func (h *approvalHub) notify(approvalID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.waiters[approvalID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WaitForApproval blocks until the approval leaves pending, timeout passes, or ctx is
+// done, and returns the approval as it then stands. Changes made by this process wake
+// it at once; changes made by other replicas are seen on the next poll of the ledger.
+// An approval whose timeout passes while waiting is expired here.
+func (s *AuthorizeService) WaitForApproval(ctx stdcontext.Context, approvalID string, timeout time.Duration) (ledger.ApprovalRecord, error) {
+	if timeout > MaxApprovalWait {
+		timeout = MaxApprovalWait
+	}
+	pollInterval := s.WaitPollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultWaitPollInterval
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+
+	for {
+		// Subscribe before reading so a change between the read and the wait is not lost.
+		changed, stop := s.hub.subscribe(approvalID)
+		approval, ok := s.Ledger.GetApproval(approvalID)
+		if !ok {
+			stop()
+			return ledger.ApprovalRecord{}, ErrApprovalNotFound
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		if approvalExpired(approval, now) {
+			stop()
+			if _, err := s.expireApprovalIfDue(approvalID, now); err != nil {
+				return approval, err
+			}
+			continue
+		}
+		if approval.Status != string(ApprovalPending) {
+			stop()
+			return approval, nil
+		}
+
+		select {
+		case <-changed:
+		case <-poll.C:
+		case <-deadline.C:
+			stop()
+			return approval, nil
+		case <-ctx.Done():
+			stop()
+			return approval, ctx.Err()
+		}
+		stop()
+	}
+}
+
+// AuthorizeAndWait is Authorize, except that a request needing approval is held for up
+// to wait and, once approved, continues straight to issuance. A denial or expiry
+// returns the denied response; if nothing happens in time the pending response is
+// returned and the caller retries as usual.
+func (s *AuthorizeService) AuthorizeAndWait(ctx stdcontext.Context, claims ActorContext, req AuthorizeRequest, createdAt string, wait time.Duration) (AuthorizeResponse, error) {
+	resp, err := s.Authorize(claims, req, createdAt)
+	if err != nil || wait <= 0 || resp.Verdict != string(VerdictRequireApproval) || resp.Approval == nil {
+		return resp, err
+	}
+
+	approval, err := s.WaitForApproval(ctx, resp.Approval.ApprovalID, wait)
+	if err != nil || approval.Status == string(ApprovalPending) {
+		// A client that went away or a timeout leaves the approval pending; the retry
+		// picks it up.
+		return resp, nil
+	}
+	return s.Authorize(claims, req, time.Now().UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/internal/auth"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+var waitRequest = AuthorizeRequest{Action: "terraform.apply", Resource: "res", Env: "prod"}
+
+func approveLater(t *testing.T, svc *AuthorizeService, approvalID string) {
+	t.Helper()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		if _, err := svc.Approve(approvalID, types.Approver{Kind: "slack", ID: "U1"}, "approved", "2025-12-20T16:35:00Z"); err != nil {
+			t.Errorf("approve: %v", err)
+		}
+	}()
+}
+
+func TestWaitForApprovalWakesOnApprove(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, approvalPolicy, waitRequest)
+	// Only the hub can wake the waiter in time.
+	svc.WaitPollInterval = time.Hour
+
+	approveLater(t, svc, approvalID)
+	start := time.Now()
+	approval, err := svc.WaitForApproval(context.Background(), approvalID, 5*time.Second)
+	if err != nil || approval.Status != string(ApprovalApproved) {
+		t.Fatalf("expected approved: status=%s err=%v", approval.Status, err)
+	}
+	if waited := time.Since(start); waited > 2*time.Second {
+		t.Fatalf("expected the hub to wake the waiter, waited %s", waited)
+	}
+}
+
+func TestWaitForApprovalPollsOtherReplicas(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, approvalPolicy, waitRequest)
+	svc.WaitPollInterval = 10 * time.Millisecond
+
+	// A second service on the same ledger stands in for another replica.
+	replica, err := NewAuthorizeService(NewAuthorizeServiceInput{
+		PolicyPath: "../../policies/relia.yaml",
+		Ledger:     svc.Ledger,
+		Signer:     svc.Signer,
+		PublicKey:  svc.PublicKey,
+	})
+	if err != nil {
+		t.Fatalf("replica: %v", err)
+	}
+	approveLater(t, replica, approvalID)
+
+	approval, err := svc.WaitForApproval(context.Background(), approvalID, 5*time.Second)
+	if err != nil || approval.Status != string(ApprovalApproved) {
+		t.Fatalf("expected approved via polling: status=%s err=%v", approval.Status, err)
+	}
+}
+
+func TestWaitForApprovalTimeout(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, approvalPolicy, waitRequest)
+
+	approval, err := svc.WaitForApproval(context.Background(), approvalID, 30*time.Millisecond)
+	if err != nil || approval.Status != string(ApprovalPending) {
+		t.Fatalf("expected still pending: status=%s err=%v", approval.Status, err)
+	}
+	if _, err := svc.WaitForApproval(context.Background(), "missing", time.Second); err != ErrApprovalNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestAuthorizeAndWaitIssuesOnApproval(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, approvalPolicy, waitRequest)
+	claims, req := approvalClaims, waitRequest
+
+	approveLater(t, svc, approvalID)
+	resp, err := svc.AuthorizeAndWait(context.Background(), claims, req, "2025-12-20T16:34:30Z", 5*time.Second)
+	if err != nil {
+		t.Fatalf("authorize and wait: %v", err)
+	}
+	if resp.Verdict != string(VerdictAllow) || resp.AWSCredentials == nil {
+		t.Fatalf("expected credentials after approval, got %+v", resp)
+	}
+
+	// Without a wait the pending response comes straight back.
+	other := req
+	other.Resource = "res-2"
+	resp, err = svc.AuthorizeAndWait(context.Background(), claims, other, "2025-12-20T16:36:00Z", 0)
+	if err != nil || resp.Verdict != string(VerdictRequireApproval) {
+		t.Fatalf("expected require_approval without waiting: %+v err=%v", resp, err)
+	}
+}
+
+func TestApprovalWaitEndpoint(t *testing.T) {
+	os.Setenv("RELIA_DEV_TOKEN", "test-token")
+	defer os.Unsetenv("RELIA_DEV_TOKEN")
+
+	svc, approvalID := newPendingApproval(t, approvalPolicy, waitRequest)
+	router := NewRouter(&Handler{Auth: auth.NewAuthenticatorFromEnv(), AuthorizeService: svc})
+
+	approveLater(t, svc, approvalID)
+	code, out := serveApprovals(t, router, http.MethodGet, "/v1/approvals/"+approvalID+"/wait?timeout=5s", "test-token", "")
+	if code != http.StatusOK || out["status"] != "approved" {
+		t.Fatalf("expected approved from wait, got %d %v", code, out)
+	}
+
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/approvals/"+approvalID+"/wait?timeout=1h", "test-token", ""); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a timeout over the cap, got %d", code)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/approvals/missing/wait?timeout=1", "test-token", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/approvals/"+approvalID+"/wait", "", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", code)
+	}
+}
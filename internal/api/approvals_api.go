@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/relia/internal/auth"
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+// ApprovalVoteRequest is the body of POST /v1/approvals/{id}/approve and /deny.
+type ApprovalVoteRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// ApprovalSummary describes an approval for the REST API.
+type ApprovalSummary struct {
+	ApprovalID        string `json:"approval_id"`
+	Status            string `json:"status"`
+	Action            string `json:"action,omitempty"`
+	Resource          string `json:"resource,omitempty"`
+	Env               string `json:"env,omitempty"`
+	Risk              string `json:"risk,omitempty"`
+	RequiredApprovals int    `json:"required_approvals,omitempty"`
+	Approvals         int    `json:"approvals"`
+	CreatedAt         string `json:"created_at"`
+	ExpiresAt         string `json:"expires_at,omitempty"`
+}
+
+// ListApprovals returns up to limit approvals with the given status (all when empty),
+// oldest first.
+func (s *AuthorizeService) ListApprovals(status string, limit int) ([]ApprovalSummary, error) {
+	approvals, err := s.Ledger.ListApprovals(status, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ApprovalSummary, 0, len(approvals))
+	for _, approval := range approvals {
+		out = append(out, s.approvalSummary(approval))
+	}
+	return out, nil
+}
+
+// approvalSummary fills in what was requested from the approval's decision and context.
+func (s *AuthorizeService) approvalSummary(approval ledger.ApprovalRecord) ApprovalSummary {
+	summary := ApprovalSummary{
+		ApprovalID:        approval.ApprovalID,
+		Status:            approval.Status,
+		RequiredApprovals: requiredApprovals(approval),
+		CreatedAt:         approval.CreatedAt,
+	}
+	if approval.ExpiresAt != nil {
+		summary.ExpiresAt = *approval.ExpiresAt
+	}
+	for _, vote := range approval.Votes {
+		if vote.Status == string(ApprovalApproved) {
+			summary.Approvals++
+		}
+	}
+
+	idem, ok := s.Ledger.GetIdempotencyKey(approval.IdemKey)
+	if !ok || idem.LatestReceiptID == nil {
+		return summary
+	}
+	receipt, ok := s.Ledger.GetReceipt(*idem.LatestReceiptID)
+	if !ok {
+		return summary
+	}
+	if ctxRec, ok := s.Ledger.GetContext(receipt.ContextID); ok {
+		var ctx types.ContextRecord
+		if err := json.Unmarshal(ctxRec.BodyJSON, &ctx); err == nil {
+			summary.Action = ctx.Inputs.Action
+			summary.Resource = ctx.Inputs.Resource
+			summary.Env = ctx.Inputs.Env
+		}
+	}
+	if decRec, ok := s.Ledger.GetDecision(receipt.DecisionID); ok {
+		var dec types.DecisionRecord
+		if err := json.Unmarshal(decRec.BodyJSON, &dec); err == nil {
+			summary.Risk = dec.Risk
+		}
+	}
+	return summary
+}
+
+// approverFromClaims identifies an SSO approver as kind "oidc" with ID
+// "<issuer>|<subject>", the form policy approver subjects match.
+func approverFromClaims(claims auth.ApproverClaims) types.Approver {
+	display := claims.Email
+	if display == "" {
+		display = claims.Name
+	}
+	return types.Approver{
+		Kind:    "oidc",
+		ID:      claims.ID(),
+		Display: display,
+		Groups:  claims.Groups,
+	}
+}
+
+// ListApprovals serves GET /v1/approvals?status=pending&limit=50 to workloads and
+// approvers alike.
+func (h *Handler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	if !h.ensureAuthOrApprover(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if h.AuthorizeService == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "authorize service not configured"})
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch ApprovalStatus(status) {
+	case "", ApprovalPending, ApprovalApproved, ApprovalDenied, ApprovalExpired:
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid status"})
+		return
+	}
+	limit := 50
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 500 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	approvals, err := h.AuthorizeService.ListApprovals(status, limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"approvals": approvals})
+}
+
+// voteApproval serves POST /v1/approvals/{id}/approve and /deny. Only an approver
+// token is accepted: workload tokens can never vote.
+func (h *Handler) voteApproval(w http.ResponseWriter, r *http.Request, approvalID string, status ApprovalStatus) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if h.ApproverAuth == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "approver auth not configured"})
+		return
+	}
+	claims, err := h.ApproverAuth.AuthenticateApprover(r)
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req ApprovalVoteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+			return
+		}
+	}
+
+	if _, ok := h.AuthorizeService.GetApproval(approvalID); !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "approval not found"})
+		return
+	}
+
+	receiptID, err := h.AuthorizeService.ApproveWithComment(approvalID, approverFromClaims(claims), string(status), strings.TrimSpace(req.Comment), time.Now().UTC().Format(time.RFC3339))
+	var rejected *VoteRejectedError
+	if errors.As(err, &rejected) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	approval, _ := h.AuthorizeService.GetApproval(approvalID)
+	writeJSON(w, http.StatusOK, map[string]string{
+		"approval_id": approvalID,
+		"status":      approval.Status,
+		"receipt_id":  receiptID,
+	})
+}
+
+// ensureAuthOrApprover accepts an approver token when approver auth is configured,
+// and a workload token otherwise.
+func (h *Handler) ensureAuthOrApprover(w http.ResponseWriter, r *http.Request) bool {
+	if h.ApproverAuth != nil {
+		if _, err := h.ApproverAuth.AuthenticateApprover(r); err == nil {
+			return true
+		}
+	}
+	return h.ensureAuth(w, r)
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/davidahmann/relia/internal/auth"
+	"github.com/davidahmann/relia/internal/ledger"
+)
+
+// fakeApproverAuth accepts "sso-alice" as an approver token and nothing else.
+type fakeApproverAuth struct{}
+
+func (fakeApproverAuth) AuthenticateApprover(r *http.Request) (auth.ApproverClaims, error) {
+	if r.Header.Get("Authorization") != "Bearer sso-alice" {
+		return auth.ApproverClaims{}, auth.ErrInvalidToken
+	}
+	return auth.ApproverClaims{Issuer: "https://sso.example.com", Subject: "00u1", Email: "alice@example.com"}, nil
+}
+
+func serveApprovals(t *testing.T, router http.Handler, method, path, token, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	var out map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &out)
+	return res.Code, out
+}
+
+// ssoApprovalPolicy lets the fake SSO provider's users approve.
+const ssoApprovalPolicy = approvalPolicy + `      approvers:
+        subjects: ["https://sso.example.com|*"]
+`
+
+func TestApprovalsAPIListAndApprove(t *testing.T) {
+	os.Setenv("RELIA_DEV_TOKEN", "test-token")
+	defer os.Unsetenv("RELIA_DEV_TOKEN")
+
+	service, approvalID := newPendingApproval(t, ssoApprovalPolicy, AuthorizeRequest{Action: "terraform.apply", Resource: "res", Env: "prod"})
+
+	router := NewRouter(&Handler{Auth: auth.NewAuthenticatorFromEnv(), ApproverAuth: fakeApproverAuth{}, AuthorizeService: service})
+
+	code, out := serveApprovals(t, router, http.MethodGet, "/v1/approvals?status=pending", "sso-alice", "")
+	if code != http.StatusOK {
+		t.Fatalf("list: expected 200, got %d %v", code, out)
+	}
+	list, _ := out["approvals"].([]any)
+	if len(list) != 1 {
+		t.Fatalf("expected one pending approval, got %v", out)
+	}
+	first := list[0].(map[string]any)
+	if first["approval_id"] != approvalID || first["action"] != "terraform.apply" || first["env"] != "prod" {
+		t.Fatalf("unexpected approval summary: %v", first)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/approvals?status=bogus", "test-token", ""); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid status, got %d", code)
+	}
+
+	// Workload tokens may read approvals but never vote.
+	if code, _ := serveApprovals(t, router, http.MethodPost, "/v1/approvals/"+approvalID+"/approve", "test-token", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a workload token, got %d", code)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodPost, "/v1/approvals/missing/approve", "sso-alice", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown approval, got %d", code)
+	}
+
+	code, out = serveApprovals(t, router, http.MethodPost, "/v1/approvals/"+approvalID+"/approve", "sso-alice", `{"comment":"LGTM, change CHG-42"}`)
+	if code != http.StatusOK || out["status"] != "approved" {
+		t.Fatalf("approve: expected approved, got %d %v", code, out)
+	}
+
+	rec, signed := signedApproval(t, service, out["receipt_id"].(string))
+	if signed.Approver == nil || signed.Approver.Kind != "oidc" || signed.Approver.ID != "https://sso.example.com|00u1" || signed.Approver.Display != "alice@example.com" || signed.Approver.Comment != "LGTM, change CHG-42" {
+		t.Fatalf("unexpected signed approver: %+v", signed.Approver)
+	}
+	if len(signed.Approvers) != 1 || signed.Approvers[0].Comment != "LGTM, change CHG-42" {
+		t.Fatalf("expected the comment on the vote: %+v", signed.Approvers)
+	}
+	stored := ledger.StoredReceipt{ReceiptID: rec.ReceiptID, BodyDigest: rec.BodyDigest, BodyJSON: rec.BodyJSON, KeyID: rec.KeyID, Sig: rec.Sig, IdemKey: rec.IdemKey, SupersedesReceiptID: rec.SupersedesReceiptID}
+	if err := ledger.VerifyReceipt(stored, service.PublicKey); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	code, out = serveApprovals(t, router, http.MethodGet, "/v1/approvals?status=pending", "test-token", "")
+	if list, _ := out["approvals"].([]any); code != http.StatusOK || len(list) != 0 {
+		t.Fatalf("expected no pending approvals left, got %d %v", code, out)
+	}
+}
+
+func TestApprovalsAPIWithoutApproverAuth(t *testing.T) {
+	service := newTestService(t, "../../policies/relia.yaml")
+	router := NewRouter(&Handler{AuthorizeService: service})
+
+	if code, _ := serveApprovals(t, router, http.MethodPost, "/v1/approvals/a1/deny", "sso-alice", ""); code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 without approver auth, got %d", code)
+	}
+
+	router = NewRouter(&Handler{ApproverAuth: fakeApproverAuth{}, AuthorizeService: service})
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/approvals/a1/deny", "sso-alice", ""); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET, got %d", code)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodPost, "/v1/approvals/a1/escalate", "sso-alice", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown action, got %d", code)
+	}
+}
+
+func TestApprovalsAPIRequiresApproverRestriction(t *testing.T) {
+	service, approvalID := newPendingApproval(t, approvalPolicy, AuthorizeRequest{Action: "terraform.apply", Resource: "res", Env: "prod"})
+	router := NewRouter(&Handler{ApproverAuth: fakeApproverAuth{}, AuthorizeService: service})
+
+	code, out := serveApprovals(t, router, http.MethodPost, "/v1/approvals/"+approvalID+"/approve", "sso-alice", "")
+	if code != http.StatusForbidden || out["error"] != ErrSSOApproverUnrestricted.Error() {
+		t.Fatalf("expected an unrestricted SSO vote to be rejected, got %d %v", code, out)
+	}
+}
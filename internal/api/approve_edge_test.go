@@ -3,6 +3,8 @@ package api
 import (
 	"testing"
 	"time"
+
+	"github.com/davidahmann/relia/pkg/types"
 )
 
 func TestApproveIdempotentWhenAlreadyFinalized(t *testing.T) {
@@ -26,7 +28,7 @@ func TestApproveIdempotentWhenAlreadyFinalized(t *testing.T) {
 		t.Fatalf("expected approval id")
 	}
 
-	receipt1, err := service.Approve(resp.Approval.ApprovalID, "approved", now)
+	receipt1, err := service.Approve(resp.Approval.ApprovalID, types.Approver{}, "approved", now)
 	if err != nil {
 		t.Fatalf("approve: %v", err)
 	}
@@ -34,7 +36,7 @@ func TestApproveIdempotentWhenAlreadyFinalized(t *testing.T) {
 		t.Fatalf("expected receipt id")
 	}
 
-	receipt2, err := service.Approve(resp.Approval.ApprovalID, "approved", now)
+	receipt2, err := service.Approve(resp.Approval.ApprovalID, types.Approver{}, "approved", now)
 	if err != nil {
 		t.Fatalf("approve (idempotent): %v", err)
 	}
@@ -18,6 +18,11 @@ type AuthorizeRequest struct {
 	InteractionRef *types.InteractionRef `json:"interaction_ref,omitempty"`
 	ContextRef     *types.ContextRef     `json:"context_ref,omitempty"`
 	DecisionRef    *types.DecisionRef    `json:"decision_ref,omitempty"`
+
+	// WaitForApprovalSeconds holds a request that needs approval open for up to this
+	// long (at most MaxApprovalWait) and continues to issuance once approved. It is not
+	// part of the idempotency key.
+	WaitForApprovalSeconds int `json:"wait_for_approval_seconds,omitempty"`
 }
 
 type AuthorizeEvidence struct {
@@ -37,6 +42,10 @@ type ActorContext struct {
 	RunID    string
 	SHA      string
 	Token    string
+
+	Ref         string
+	Environment string
+	Actor       string
 }
 
 // ComputeIdemKey derives a deterministic idempotency key from actor + request.
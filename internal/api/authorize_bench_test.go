@@ -9,6 +9,15 @@ import (
 )
 
 func BenchmarkAuthorize(b *testing.B) {
+	benchmarkAuthorize(b, true)
+}
+
+// BenchmarkAuthorizeUncachedPolicy is the baseline that re-reads the policy per request.
+func BenchmarkAuthorizeUncachedPolicy(b *testing.B) {
+	benchmarkAuthorize(b, false)
+}
+
+func benchmarkAuthorize(b *testing.B, cached bool) {
 	seed := make([]byte, ed25519.SeedSize)
 	priv := ed25519.NewKeyFromSeed(seed)
 	pub := priv.Public().(ed25519.PublicKey)
@@ -22,6 +31,9 @@ func BenchmarkAuthorize(b *testing.B) {
 	if err != nil {
 		b.Fatalf("service: %v", err)
 	}
+	if !cached {
+		service.Policies = nil
+	}
 
 	claims := ActorContext{
 		Subject:  "repo:org/repo:ref:refs/heads/main",
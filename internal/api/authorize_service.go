@@ -1,17 +1,19 @@
 package api
 
 import (
-	stdcontext "context"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/davidahmann/relia/internal/aws"
 	reliactx "github.com/davidahmann/relia/internal/context"
 	"github.com/davidahmann/relia/internal/decision"
 	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/notify"
+	"github.com/davidahmann/relia/internal/outbox"
 	"github.com/davidahmann/relia/internal/policy"
 	"github.com/davidahmann/relia/internal/slack"
 	"github.com/davidahmann/relia/pkg/types"
@@ -19,12 +21,32 @@ import (
 
 type AuthorizeService struct {
 	PolicyPath string
-	Ledger     ledger.Store
-	Signer     ledger.Signer
-	Broker     aws.CredentialBroker
-	PublicKey  ed25519.PublicKey
-	Slack      SlackNotifier
-	SlackChan  string
+	// Policies caches the active policy for PolicyPath; nil loads it from disk per request.
+	Policies  *policy.Manager
+	Ledger    ledger.Store
+	Signer    ledger.Signer
+	Broker    aws.CredentialBroker
+	PublicKey ed25519.PublicKey
+	Slack     ApprovalNotifier
+	SlackChan string
+	// Teams posts approvals that policy routes to Teams (or all of them when Slack is not
+	// configured); TeamsChan optionally names the channel alias used when a rule names
+	// none.
+	Teams     ApprovalNotifier
+	TeamsChan string
+	// Webhook posts signed approval requests to an external approval system.
+	Webhook ApprovalNotifier
+	// PublicURL is the gateway's external base URL, used to link receipts from Slack.
+	PublicURL string
+	// WaitPollInterval is how often approval waiters re-read the ledger; zero means 2s.
+	WaitPollInterval time.Duration
+	// OutboxMaxAttempts is how many times a notification is tried before it is
+	// dead-lettered; zero means outbox.DefaultMaxAttempts.
+	OutboxMaxAttempts int
+
+	hub approvalHub
+	// deliveries tracks the immediate delivery attempts started by processOutbox.
+	deliveries sync.WaitGroup
 }
 
 type AuthorizeResponse struct {
@@ -45,8 +67,11 @@ type AuthorizeResponse struct {
 	Error string `json:"error,omitempty"`
 }
 
-type SlackNotifier interface {
-	PostApproval(channel string, message slack.ApprovalMessageInput) (msgTS string, err error)
+// ApprovalNotifier posts approval requests to a chat transport and returns a reference
+// to the posted message (a Slack ts; empty otherwise). *slack.Client, *teams.Client and
+// *webhook.Client implement it.
+type ApprovalNotifier interface {
+	PostApproval(channel string, message notify.ApprovalMessage) (ref string, err error)
 }
 
 type NewAuthorizeServiceInput struct {
@@ -55,8 +80,12 @@ type NewAuthorizeServiceInput struct {
 	Signer     ledger.Signer
 	PublicKey  ed25519.PublicKey
 	Broker     aws.CredentialBroker
-	Slack      SlackNotifier
+	Slack      ApprovalNotifier
 	SlackChan  string
+	Teams      ApprovalNotifier
+	TeamsChan  string
+	Webhook    ApprovalNotifier
+	PublicURL  string
 }
 
 func NewAuthorizeService(in NewAuthorizeServiceInput) (*AuthorizeService, error) {
@@ -78,14 +107,23 @@ func NewAuthorizeService(in NewAuthorizeServiceInput) (*AuthorizeService, error)
 	if in.Broker == nil {
 		in.Broker = aws.DevBroker{}
 	}
+	policies, err := policy.NewManager(in.PolicyPath)
+	if err != nil {
+		return nil, err
+	}
 	return &AuthorizeService{
 		PolicyPath: in.PolicyPath,
+		Policies:   policies,
 		Ledger:     in.Ledger,
 		Signer:     in.Signer,
 		Broker:     in.Broker,
 		PublicKey:  in.PublicKey,
 		Slack:      in.Slack,
 		SlackChan:  in.SlackChan,
+		Teams:      in.Teams,
+		TeamsChan:  in.TeamsChan,
+		Webhook:    in.Webhook,
+		PublicURL:  in.PublicURL,
 	}, nil
 }
 
@@ -98,7 +136,20 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 	existing, ok := s.Ledger.GetIdempotencyKey(idemKey)
 	if ok {
 		switch IdemStatus(existing.Status) {
-		case IdemAllowed, IdemDenied, IdemPendingApproval:
+		case IdemPendingApproval:
+			// Expire here too, so a retry after the timeout need not wait for the sweeper.
+			if existing.ApprovalID != nil {
+				if approval, ok := s.Ledger.GetApproval(*existing.ApprovalID); ok && approvalExpired(approval, createdAt) {
+					if _, err := s.expireApprovalIfDue(approval.ApprovalID, createdAt); err != nil {
+						return AuthorizeResponse{}, err
+					}
+					if existing, ok = s.Ledger.GetIdempotencyKey(idemKey); !ok {
+						return AuthorizeResponse{}, fmt.Errorf("idempotency key missing")
+					}
+				}
+			}
+			return s.handleExisting(existing)
+		case IdemAllowed, IdemDenied:
 			return s.handleExisting(existing)
 		case IdemApprovedReady:
 			return s.issueApprovedReady(idemKey, existing, claims, req, createdAt)
@@ -111,13 +162,12 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 		}
 	}
 
-	loaded, err := policy.LoadPolicy(s.PolicyPath)
+	loaded, err := s.activePolicy()
 	if err != nil {
 		return AuthorizeResponse{}, err
 	}
 
-	input := policy.Input{Action: req.Action, Resource: req.Resource, Env: req.Env}
-	decisionResult := policy.Evaluate(loaded.Policy, loaded.Hash, input)
+	decisionResult := policy.Evaluate(loaded.Policy, loaded.Hash, policyInput(claims, req, createdAt))
 
 	source := types.ContextSource{
 		Kind:     "github_actions",
@@ -125,7 +175,6 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 		Workflow: claims.Workflow,
 		RunID:    claims.RunID,
 		Actor:    claims.Subject,
-		Ref:      "",
 		SHA:      claims.SHA,
 	}
 	inputs := types.ContextInputs{Action: req.Action, Resource: req.Resource, Env: req.Env, Intent: req.Intent}
@@ -137,7 +186,7 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 	}
 
 	policyMeta := types.DecisionPolicy{PolicyID: loaded.Policy.PolicyID, PolicyVersion: loaded.Policy.PolicyVersion, PolicyHash: loaded.Hash}
-	decRecord, err := decision.BuildDecision(ctxRecord.ContextID, policyMeta, decisionResult.Verdict, decisionResult.ReasonCodes, decisionResult.RequireApproval, decisionResult.Risk, createdAt)
+	decRecord, err := decision.BuildDecision(ctxRecord.ContextID, policyMeta, decisionResult.Verdict, decisionResult.ReasonCodes, recordedMatchedRules(loaded.Policy, decisionResult), decisionResult.RequireApproval, decisionResult.Risk, createdAt)
 	if err != nil {
 		return AuthorizeResponse{}, err
 	}
@@ -147,9 +196,16 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 
 	approvalID := ""
 	var approval *types.ReceiptApproval
+	var approvalExpiry *string
 	if action == ActionReturnPending {
 		approvalID = newApprovalID()
 		approval = &types.ReceiptApproval{Required: true, ApprovalID: approvalID, Status: string(ApprovalPending)}
+		if required := decisionResult.Approvals.RequiredApprovals(); required > 1 {
+			approval.Quorum = required
+		}
+		if approvalExpiry = approvalExpiresAt(createdAt, decisionResult.ApprovalTimeout); approvalExpiry != nil {
+			approval.ExpiresAt = *approvalExpiry
+		}
 	}
 
 	receiptPolicy := types.ReceiptPolicy(policyMeta)
@@ -209,9 +265,9 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 		CreatedAt:     createdAt,
 	}
 
-	var postSlack bool
+	var transport string
 	var approvalRec ledger.ApprovalRecord
-	var outboxRec *ledger.SlackOutboxRecord
+	var outboxRec *ledger.OutboxRecord
 
 	var initialStatus IdemStatus
 	var finalReceiptID *string
@@ -225,15 +281,22 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 		initialStatus = IdemPendingApproval
 		finalReceiptID = nil
 		approvalRec = ledger.ApprovalRecord{
-			ApprovalID: approvalID,
-			IdemKey:    idemKey,
-			Status:     string(ApprovalPending),
-			CreatedAt:  createdAt,
-			UpdatedAt:  createdAt,
-		}
-		postSlack = s.Slack != nil && s.SlackChan != ""
-		if postSlack {
-			input := slack.ApprovalMessageInput{
+			ApprovalID:        approvalID,
+			IdemKey:           idemKey,
+			Status:            string(ApprovalPending),
+			RequiredApprovals: decisionResult.Approvals.RequiredApprovals(),
+			RequestedBy:       ptrOrNil(claims.Actor),
+			ExpiresAt:         approvalExpiry,
+			CreatedAt:         createdAt,
+			UpdatedAt:         createdAt,
+		}
+		if decisionResult.Approvals != nil {
+			approvalRec.ApproverGroups = decisionResult.Approvals.Groups
+		}
+		var channel string
+		transport, channel = s.approvalRoute(decisionResult.ApprovalTransport, decisionResult.ApprovalChannel)
+		if transport != "" {
+			input := notify.ApprovalMessage{
 				ApprovalID: approvalRec.ApprovalID,
 				ReceiptID:  baseReceipt.ReceiptID,
 				PolicyHash: loaded.Hash,
@@ -244,23 +307,25 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 				Env:        req.Env,
 				Risk:       decisionResult.Risk,
 				DiffURL:    req.Evidence.DiffURL,
+				Mentions:   decisionResult.ApprovalMentions,
 			}
 			msgBytes, err := json.Marshal(input)
 			if err != nil {
 				return AuthorizeResponse{}, err
 			}
-			outbox := ledger.SlackOutboxRecord{
-				NotificationID: "slack:" + approvalRec.ApprovalID,
-				ApprovalID:     approvalRec.ApprovalID,
-				Channel:        s.SlackChan,
-				MessageJSON:    msgBytes,
-				Status:         slack.OutboxStatusPending,
-				AttemptCount:   0,
-				NextAttemptAt:  createdAt,
-				CreatedAt:      createdAt,
-				UpdatedAt:      createdAt,
+			kind := outboxKind(transport)
+			outboxRec = &ledger.OutboxRecord{
+				OutboxID:      kind + ":" + approvalRec.ApprovalID,
+				Kind:          kind,
+				ApprovalID:    approvalRec.ApprovalID,
+				Target:        channel,
+				PayloadJSON:   msgBytes,
+				Status:        outbox.StatusPending,
+				MaxAttempts:   s.outboxMaxAttempts(),
+				NextAttemptAt: createdAt,
+				CreatedAt:     createdAt,
+				UpdatedAt:     createdAt,
 			}
-			outboxRec = &outbox
 		}
 	case ActionIssueCredentials:
 		initialStatus = IdemIssuing
@@ -285,10 +350,11 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 		}
 
 		idem := ledger.IdempotencyKey{
-			IdemKey:   idemKey,
-			Status:    string(initialStatus),
-			CreatedAt: createdAt,
-			UpdatedAt: createdAt,
+			IdemKey:      idemKey,
+			Status:       string(initialStatus),
+			CreatedAt:    createdAt,
+			UpdatedAt:    createdAt,
+			TTLExpiresAt: approvalExpiry,
 		}
 		if err := tx.PutIdempotencyKey(idem); err != nil {
 			return err
@@ -299,7 +365,7 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 				return err
 			}
 			if outboxRec != nil {
-				if err := tx.PutSlackOutbox(*outboxRec); err != nil {
+				if err := tx.PutOutbox(*outboxRec); err != nil {
 					return err
 				}
 			}
@@ -327,8 +393,8 @@ func (s *AuthorizeService) Authorize(claims ActorContext, req AuthorizeRequest,
 		return AuthorizeResponse{}, err
 	}
 
-	if action == ActionReturnPending && postSlack {
-		_, _ = slack.ProcessOutboxDue(stdcontext.Background(), s.Ledger, s.Slack, time.Now().UTC(), 1)
+	if action == ActionReturnPending && outboxRec != nil {
+		s.processOutbox(outboxRec.Kind, outboxRec.OutboxID)
 	}
 
 	if action == ActionIssueCredentials {
@@ -375,8 +441,8 @@ func (s *AuthorizeService) retryIssuing(idemKey string, idem ledger.IdempotencyK
 		return AuthorizeResponse{}, err
 	}
 
-	input := policy.Input{Action: req.Action, Resource: req.Resource, Env: req.Env}
-	decisionResult := policy.Evaluate(loaded.Policy, loaded.Hash, input)
+	// Evaluate at the original request time so freezes that start mid-retry do not change the outcome.
+	decisionResult := policy.Evaluate(loaded.Policy, loaded.Hash, policyInput(claims, req, idem.CreatedAt))
 	if decisionResult.AWSRoleARN == "" {
 		return AuthorizeResponse{}, fmt.Errorf("missing aws_role_arn in policy")
 	}
@@ -402,21 +468,50 @@ func (s *AuthorizeService) retryIssuing(idemKey string, idem ledger.IdempotencyK
 	return s.finalizeIssuance(idemKey, stored, claims, req, createdAt, decisionResult.AWSRoleARN, decisionResult.TTLSeconds)
 }
 
-func (s *AuthorizeService) Approve(approvalID string, status string, createdAt string) (string, error) {
+// Approve records approver's vote and returns the signed receipt for it. A deny ends
+// the approval; approvals complete it once the required number of distinct approvers
+// have voted, and until then each vote leaves it pending. Repeat votes return the
+// approver's earlier receipt. An anonymous approver (empty ID) can only decide
+// single-approver approvals. Votes the approver may not cast, including votes after the
+// approval expired, are recorded as rejected and return a *VoteRejectedError.
+func (s *AuthorizeService) Approve(approvalID string, approver types.Approver, status string, createdAt string) (string, error) {
+	return s.ApproveWithComment(approvalID, approver, status, "", createdAt)
+}
+
+// ApproveWithComment is Approve with the approver's comment, which is stored on the
+// vote and signed into its receipt.
+func (s *AuthorizeService) ApproveWithComment(approvalID string, approver types.Approver, status string, comment string, createdAt string) (string, error) {
 	approvalStatus := ApprovalStatus(status)
 	if approvalStatus != ApprovalApproved && approvalStatus != ApprovalDenied {
 		return "", fmt.Errorf("invalid approval status")
 	}
 
 	var receiptID string
+	var rejected error
+	var expiredNow bool
+	var resolved *slack.ApprovalResolution
 	err := s.Ledger.WithTx(func(tx ledger.Tx) error {
-		if err := s.putSigningKey(tx, createdAt); err != nil {
+		approval, ok, err := tx.LockApproval(approvalID)
+		if err != nil {
 			return err
 		}
-		approval, ok := tx.GetApproval(approvalID)
 		if !ok {
 			return fmt.Errorf("approval not found")
 		}
+		if err := s.putSigningKey(tx, createdAt); err != nil {
+			return err
+		}
+		if approvalExpired(approval, createdAt) {
+			var err error
+			if approval, err = s.expireApproval(tx, approval, createdAt); err != nil {
+				return err
+			}
+			expiredNow = true
+		}
+		if approval.Status == string(ApprovalExpired) {
+			rejected = &VoteRejectedError{Err: ErrApprovalExpired}
+			return tx.PutApproval(rejectVote(approval, approver, ErrApprovalExpired, createdAt))
+		}
 		if approval.Status == string(ApprovalApproved) || approval.Status == string(ApprovalDenied) {
 			// already finalized
 			idem, ok := tx.GetIdempotencyKey(approval.IdemKey)
@@ -425,6 +520,10 @@ func (s *AuthorizeService) Approve(approvalID string, status string, createdAt s
 			}
 			return nil
 		}
+		if prior, ok := priorVote(approval, approver); ok {
+			receiptID = prior.ReceiptID
+			return nil
+		}
 
 		idem, ok := tx.GetIdempotencyKey(approval.IdemKey)
 		if !ok || idem.LatestReceiptID == nil {
@@ -435,31 +534,78 @@ func (s *AuthorizeService) Approve(approvalID string, status string, createdAt s
 			return fmt.Errorf("latest receipt not found")
 		}
 
+		rejection := checkVoter(approval, approver)
+		if rejection == nil {
+			var err error
+			if rejection, err = checkPolicyVoter(tx, latestReceipt, approval, approver); err != nil {
+				return err
+			}
+		}
+		if rejection != nil {
+			// Keep the attempt on the approval for audit; it does not count toward the tally.
+			rejected = &VoteRejectedError{Err: rejection}
+			return tx.PutApproval(rejectVote(approval, approver, rejection, createdAt))
+		}
+
 		interactionRef := interactionRefFromBody(latestReceipt.BodyJSON)
 		refs := receiptRefsFromBody(latestReceipt.BodyJSON)
 
-		outcome := types.ReceiptOutcome{Status: types.OutcomeApprovalDenied}
-		if approvalStatus == ApprovalApproved {
+		approval.Votes = append(approval.Votes, ledger.ApprovalVote{
+			ApproverKind:    approver.Kind,
+			ApproverID:      approver.ID,
+			ApproverDisplay: approver.Display,
+			ApproverTeamID:  approver.TeamID,
+			Status:          string(approvalStatus),
+			Comment:         comment,
+			CreatedAt:       createdAt,
+		})
+		newStatus := tallyVotes(approval)
+
+		outcome := types.ReceiptOutcome{Status: types.OutcomeApprovalPending}
+		switch newStatus {
+		case ApprovalApproved:
 			outcome.Status = types.OutcomeApprovalApproved
+		case ApprovalDenied:
+			outcome.Status = types.OutcomeApprovalDenied
 		}
 
+		receiptApproval := &types.ReceiptApproval{
+			Required:   true,
+			ApprovalID: approvalID,
+			Status:     string(newStatus),
+			Approvers:  receiptApprovers(approval.Votes),
+		}
+		if required := requiredApprovals(approval); required > 1 {
+			receiptApproval.Quorum = required
+		}
+		if approver.ID != "" {
+			receiptApproval.Approver = &types.ReceiptApprover{
+				Kind:    approver.Kind,
+				ID:      approver.ID,
+				Display: approver.Display,
+				TeamID:  approver.TeamID,
+				Comment: comment,
+			}
+		}
+		if newStatus == ApprovalApproved {
+			receiptApproval.ApprovedAt = createdAt
+		}
+
+		actor := types.ReceiptActor{Kind: "approval", Subject: approvalActorSubject(approver)}
+
 		approvalReceipt, err := ledger.MakeReceipt(ledger.MakeReceiptInput{
 			CreatedAt:           createdAt,
 			IdemKey:             approval.IdemKey,
 			SupersedesReceiptID: idem.LatestReceiptID,
 			ContextID:           latestReceipt.ContextID,
 			DecisionID:          latestReceipt.DecisionID,
-			Actor:               types.ReceiptActor{Kind: "approval", Subject: "slack"},
+			Actor:               actor,
 			Request:             types.ReceiptRequest{RequestID: "approval", Action: "approve", Resource: approval.IdemKey, Env: ""},
 			Policy:              types.ReceiptPolicy{PolicyHash: latestReceipt.PolicyHash},
 			InteractionRef:      interactionRef,
 			Refs:                refs,
-			Approval: &types.ReceiptApproval{
-				Required:   true,
-				ApprovalID: approvalID,
-				Status:     string(approvalStatus),
-			},
-			Outcome: outcome,
+			Approval:            receiptApproval,
+			Outcome:             outcome,
 		}, s.Signer)
 		if err != nil {
 			return err
@@ -469,16 +615,23 @@ func (s *AuthorizeService) Approve(approvalID string, status string, createdAt s
 			return err
 		}
 
-		approval.Status = string(approvalStatus)
+		approval.Votes[len(approval.Votes)-1].ReceiptID = approvalReceipt.ReceiptID
+		approval.Status = string(newStatus)
 		approval.UpdatedAt = createdAt
+		if newStatus != ApprovalPending {
+			approval.ApprovedBy = ptrOrNil(approver.Subject())
+			approval.ApprovedAt = &createdAt
+		}
 		if err := tx.PutApproval(approval); err != nil {
 			return err
 		}
 
-		idem.Status = string(IdemApprovedReady)
 		idem.LatestReceiptID = &approvalReceipt.ReceiptID
 		idem.FinalReceiptID = nil
-		if approvalStatus == ApprovalDenied {
+		switch newStatus {
+		case ApprovalApproved:
+			idem.Status = string(IdemApprovedReady)
+		case ApprovalDenied:
 			idem.Status = string(IdemDenied)
 			idem.FinalReceiptID = &approvalReceipt.ReceiptID
 		}
@@ -488,8 +641,24 @@ func (s *AuthorizeService) Approve(approvalID string, status string, createdAt s
 		}
 
 		receiptID = approvalReceipt.ReceiptID
+		if newStatus != ApprovalPending {
+			resolved = &slack.ApprovalResolution{Status: string(newStatus), By: slackApproverName(approver), At: createdAt}
+		}
 		return nil
 	})
+	if err == nil {
+		s.hub.notify(approvalID)
+	}
+	if err == nil && expiredNow {
+		s.updateSlackMessage(approvalID, slack.ApprovalResolution{Status: string(ApprovalExpired), At: createdAt})
+	}
+	if err == nil && resolved != nil {
+		resolved.ReceiptURL = s.verifyURL(receiptID)
+		s.updateSlackMessage(approvalID, *resolved)
+	}
+	if err == nil && rejected != nil {
+		return "", rejected
+	}
 	return receiptID, err
 }
 
@@ -515,7 +684,18 @@ func (s *AuthorizeService) handleExisting(idem ledger.IdempotencyKey) (Authorize
 		if IdemStatus(idem.Status) == IdemDenied {
 			verdict = VerdictDeny
 		}
-		return AuthorizeResponse{Verdict: string(verdict), ContextID: receipt.ContextID, DecisionID: receipt.DecisionID, ReceiptID: receipt.ReceiptID}, nil
+		resp := AuthorizeResponse{Verdict: string(verdict), ContextID: receipt.ContextID, DecisionID: receipt.DecisionID, ReceiptID: receipt.ReceiptID}
+		if types.OutcomeStatus(receipt.OutcomeStatus) == types.OutcomeApprovalExpired {
+			resp.Error = ErrApprovalExpired.Error()
+			resp.Approval = &struct {
+				ApprovalID string `json:"approval_id"`
+				Status     string `json:"status"`
+			}{Status: string(ApprovalExpired)}
+			if idem.ApprovalID != nil {
+				resp.Approval.ApprovalID = *idem.ApprovalID
+			}
+		}
+		return resp, nil
 	case IdemPendingApproval:
 		var approvalID string
 		if idem.ApprovalID != nil {
@@ -585,6 +765,59 @@ func receiptRecordFromStored(stored ledger.StoredReceipt) ledger.ReceiptRecord {
 	}
 }
 
+// activePolicy returns the policy new requests are evaluated against.
+func (s *AuthorizeService) activePolicy() (policy.LoadedPolicy, error) {
+	if s.Policies != nil {
+		return s.Policies.Current(), nil
+	}
+	return policy.LoadPolicy(s.PolicyPath)
+}
+
+// policyInput builds the policy input for a request evaluated at createdAt (RFC3339).
+func policyInput(claims ActorContext, req AuthorizeRequest, createdAt string) policy.Input {
+	at, _ := time.Parse(time.RFC3339, createdAt)
+	return policy.Input{
+		Action:      req.Action,
+		Resource:    req.Resource,
+		Env:         req.Env,
+		Issuer:      claims.Issuer,
+		Subject:     claims.Subject,
+		Repo:        claims.Repo,
+		Workflow:    claims.Workflow,
+		SHA:         claims.SHA,
+		Ref:         claims.Ref,
+		Environment: claims.Environment,
+		Actor:       claims.Actor,
+		Intent:      req.Intent,
+		PlanDigest:  req.Evidence.PlanDigest,
+		DiffURL:     req.Evidence.DiffURL,
+		At:          at,
+	}
+}
+
+// approvalActorSubject names who cast a vote in its receipt: "kind:id" for an identified
+// approver, the transport kind alone for an anonymous one, and "anonymous" when the
+// vote carries neither.
+func approvalActorSubject(approver types.Approver) string {
+	if subject := approver.Subject(); subject != "" {
+		return subject
+	}
+	if approver.Kind != "" {
+		return approver.Kind
+	}
+	return "anonymous"
+}
+
+// recordedMatchedRules is the matched_rules list for the decision record. Only
+// all_matching decisions carry it, so first-match decision IDs stay as they were; their
+// one rule is in the POLICY_MATCH reason code.
+func recordedMatchedRules(p policy.Policy, d policy.Decision) []string {
+	if p.Evaluation != policy.EvaluationAllMatching {
+		return nil
+	}
+	return d.MatchedRuleIDs
+}
+
 func ptrOrNil(s string) *string {
 	if s == "" {
 		return nil
@@ -672,6 +905,7 @@ func (s *AuthorizeService) finalizeIssuance(idemKey string, issuingReceipt ledge
 		WebIdentityToken: claims.Token,
 	})
 	if err != nil {
+		s.postSlackThreadReply(idemKey, "Credential issuance failed: "+err.Error())
 		return AuthorizeResponse{}, err
 	}
 
@@ -731,6 +965,12 @@ func (s *AuthorizeService) finalizeIssuance(idemKey string, issuingReceipt ledge
 		return AuthorizeResponse{}, err
 	}
 
+	issued := "Credentials issued, expiring at " + creds.ExpiresAt.UTC().Format(time.RFC3339)
+	if link := s.verifyURL(finalReceipt.ReceiptID); link != "" {
+		issued += " (<" + link + "|receipt>)"
+	}
+	s.postSlackThreadReply(idemKey, issued+".")
+
 	return AuthorizeResponse{
 		Verdict:    string(VerdictAllow),
 		ContextID:  issuingReceipt.ContextID,
@@ -768,8 +1008,8 @@ func (s *AuthorizeService) issueApprovedReady(idemKey string, idem ledger.Idempo
 		return AuthorizeResponse{}, err
 	}
 
-	input := policy.Input{Action: req.Action, Resource: req.Resource, Env: req.Env}
-	decisionResult := policy.Evaluate(loaded.Policy, loaded.Hash, input)
+	// Evaluate at the original request time: the approval already covered any freeze active then.
+	decisionResult := policy.Evaluate(loaded.Policy, loaded.Hash, policyInput(claims, req, idem.CreatedAt))
 	if decisionResult.Verdict != string(VerdictAllow) && decisionResult.Verdict != string(VerdictRequireApproval) {
 		return AuthorizeResponse{}, fmt.Errorf("unexpected verdict for approved_ready: %s", decisionResult.Verdict)
 	}
@@ -2,11 +2,14 @@ package api
 
 import (
 	"crypto/ed25519"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/davidahmann/relia/internal/aws"
 	"github.com/davidahmann/relia/internal/ledger"
 	"github.com/davidahmann/relia/internal/slack"
+	"github.com/davidahmann/relia/pkg/types"
 )
 
 type fakeSlackNotifier struct {
@@ -61,6 +64,7 @@ func TestAuthorizePostsSlackForPendingApproval(t *testing.T) {
 	if err != nil {
 		t.Fatalf("authorize: %v", err)
 	}
+	service.waitDeliveries()
 	if resp.Verdict != string(VerdictRequireApproval) || resp.Approval == nil {
 		t.Fatalf("expected require_approval, got %+v", resp)
 	}
@@ -83,3 +87,70 @@ func TestAuthorizePostsSlackForPendingApproval(t *testing.T) {
 		t.Fatalf("expected slack msg ts to be stored")
 	}
 }
+
+type threadingSlack struct {
+	fakeSlackNotifier
+	updates []slack.ApprovalResolution
+	replies []string
+}
+
+func (f *threadingSlack) UpdateMessage(channel, ts string, message slack.ApprovalMessageInput, resolution slack.ApprovalResolution) error {
+	f.updates = append(f.updates, resolution)
+	return nil
+}
+
+func (f *threadingSlack) PostThreadReply(channel, threadTS, text string) error {
+	if channel != "C123" || threadTS != "1700000000.1234" {
+		return fmt.Errorf("unexpected thread %s/%s", channel, threadTS)
+	}
+	f.replies = append(f.replies, text)
+	return nil
+}
+
+func TestApproveUpdatesSlackAndRepliesOnIssuance(t *testing.T) {
+	notifier := &threadingSlack{}
+	service, err := NewAuthorizeService(NewAuthorizeServiceInput{
+		PolicyPath: "../../policies/relia.yaml",
+		Ledger:     ledger.NewInMemoryStore(),
+		Broker:     &flipBroker{},
+		Slack:      notifier,
+		SlackChan:  "C123",
+		PublicURL:  "https://relia.example/",
+	})
+	if err != nil {
+		t.Fatalf("service: %v", err)
+	}
+
+	claims := ActorContext{Subject: "repo:org/repo:ref:refs/heads/main", Issuer: "relia-dev", Repo: "org/repo", RunID: "1", Token: "jwt"}
+	req := AuthorizeRequest{Action: "terraform.apply", Resource: "res", Env: "prod"}
+	resp, err := service.Authorize(claims, req, "2025-12-20T16:34:14Z")
+	if err != nil || resp.Approval == nil {
+		t.Fatalf("expected pending approval: %+v err=%v", resp, err)
+	}
+	service.waitDeliveries()
+
+	receiptID, err := service.Approve(resp.Approval.ApprovalID, types.Approver{Kind: "slack", ID: "U1", Display: "alice"}, "approved", "2025-12-20T16:35:00Z")
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	service.waitDeliveries()
+	want := slack.ApprovalResolution{Status: "approved", By: "<@U1>", At: "2025-12-20T16:35:00Z", ReceiptURL: "https://relia.example/verify/" + receiptID}
+	if len(notifier.updates) != 1 || notifier.updates[0] != want {
+		t.Fatalf("expected one approved update %+v, got %+v", want, notifier.updates)
+	}
+
+	// flipBroker fails the first issuance and succeeds on retry.
+	if _, err := service.Authorize(claims, req, "2025-12-20T16:36:00Z"); err == nil {
+		t.Fatalf("expected first issuance error")
+	}
+	service.waitDeliveries()
+	final, err := service.Authorize(claims, req, "2025-12-20T16:37:00Z")
+	if err != nil || final.Verdict != string(VerdictAllow) {
+		t.Fatalf("expected allow on retry: %+v err=%v", final, err)
+	}
+	service.waitDeliveries()
+	if len(notifier.replies) != 2 || !strings.HasPrefix(notifier.replies[0], "Credential issuance failed: temporary sts failure") ||
+		!strings.HasPrefix(notifier.replies[1], "Credentials issued") || !strings.Contains(notifier.replies[1], "https://relia.example/verify/"+final.ReceiptID) {
+		t.Fatalf("unexpected thread replies: %q", notifier.replies)
+	}
+}
@@ -1,6 +1,17 @@
 package api
 
-import "testing"
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/internal/policy"
+	"github.com/davidahmann/relia/pkg/types"
+)
 
 func TestAuthorizeRequireApproval(t *testing.T) {
 	svc := newTestService(t, "../../policies/relia.yaml")
@@ -160,7 +171,7 @@ func TestAuthorizeApproveFlow(t *testing.T) {
 		t.Fatalf("expected approval")
 	}
 
-	receiptID, err := svc.Approve(pending.Approval.ApprovalID, string(ApprovalApproved), "2025-12-20T16:35:00Z")
+	receiptID, err := svc.Approve(pending.Approval.ApprovalID, types.Approver{}, string(ApprovalApproved), "2025-12-20T16:35:00Z")
 	if err != nil {
 		t.Fatalf("approve: %v", err)
 	}
@@ -206,7 +217,7 @@ func TestAuthorizeApproveDenied(t *testing.T) {
 		t.Fatalf("expected approval")
 	}
 
-	_, err = svc.Approve(pending.Approval.ApprovalID, string(ApprovalDenied), "2025-12-20T16:35:00Z")
+	_, err = svc.Approve(pending.Approval.ApprovalID, types.Approver{}, string(ApprovalDenied), "2025-12-20T16:35:00Z")
 	if err != nil {
 		t.Fatalf("approve: %v", err)
 	}
@@ -223,7 +234,7 @@ func TestAuthorizeApproveDenied(t *testing.T) {
 func TestApproveNotFound(t *testing.T) {
 	svc := newTestService(t, "../../policies/relia.yaml")
 
-	_, err := svc.Approve("missing", string(ApprovalApproved), "2025-12-20T16:35:00Z")
+	_, err := svc.Approve("missing", types.Approver{}, string(ApprovalApproved), "2025-12-20T16:35:00Z")
 	if err == nil {
 		t.Fatalf("expected error for missing approval")
 	}
@@ -249,7 +260,7 @@ func TestApproveInvalidStatus(t *testing.T) {
 		t.Fatalf("expected approval")
 	}
 
-	_, err = svc.Approve(pending.Approval.ApprovalID, "bad", "2025-12-20T16:35:00Z")
+	_, err = svc.Approve(pending.Approval.ApprovalID, types.Approver{}, "bad", "2025-12-20T16:35:00Z")
 	if err == nil {
 		t.Fatalf("expected error for invalid status")
 	}
@@ -292,7 +303,13 @@ func TestAuthorizeMissingActorFields(t *testing.T) {
 }
 
 func TestAuthorizeMissingPolicy(t *testing.T) {
+	if _, err := NewAuthorizeService(NewAuthorizeServiceInput{PolicyPath: "missing.yaml"}); err == nil {
+		t.Fatalf("expected error for missing policy at startup")
+	}
+
+	// Without a policy manager the path is loaded per request.
 	svc := newTestService(t, "../../policies/relia.yaml")
+	svc.Policies = nil
 	svc.PolicyPath = "missing.yaml"
 
 	_, err := svc.Authorize(ActorContext{Subject: "sub", Issuer: "iss", Repo: "repo", RunID: "run"}, AuthorizeRequest{Action: "a", Resource: "r", Env: "e"}, "2025-12-20T16:34:14Z")
@@ -300,3 +317,169 @@ func TestAuthorizeMissingPolicy(t *testing.T) {
 		t.Fatalf("expected error for missing policy")
 	}
 }
+
+func TestPolicyInputCarriesActorIntentAndEvidence(t *testing.T) {
+	claims := ActorContext{Subject: "sub", Issuer: "iss", Repo: "org/repo", Workflow: "wf", SHA: "sha", Ref: "refs/heads/main", Environment: "production", Actor: "octocat"}
+	req := AuthorizeRequest{
+		Action:   "terraform.apply",
+		Resource: "stack/prod",
+		Env:      "prod",
+		Intent:   map[string]any{"destroy_count": 1},
+		Evidence: AuthorizeEvidence{PlanDigest: "sha256:p", DiffURL: "https://example.com/diff"},
+	}
+
+	in := policyInput(claims, req, "2026-12-24T10:00:00Z")
+	if in.Action != "terraform.apply" || in.Repo != "org/repo" || in.Ref != "refs/heads/main" || in.Environment != "production" || in.Actor != "octocat" {
+		t.Fatalf("unexpected input: %+v", in)
+	}
+	if in.Intent["destroy_count"] != 1 || in.PlanDigest != "sha256:p" || in.DiffURL != "https://example.com/diff" {
+		t.Fatalf("expected intent and evidence, got %+v", in)
+	}
+	if !in.At.Equal(time.Date(2026, 12, 24, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected evaluation time from createdAt, got %v", in.At)
+	}
+}
+
+func TestAuthorizeStoresPolicyBundle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"relia.yaml": "policy_id: bundle\npolicy_version: \"1\"\nincludes: [prod.yaml]\ndefaults:\n  ttl_seconds: 900\n",
+		"prod.yaml":  "rules:\n  - id: prod\n    match: {env: prod}\n    effect: {deny: true}\n",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	svc := newTestService(t, filepath.Join(dir, "relia.yaml"))
+
+	claims := ActorContext{Subject: "sub", Issuer: "iss", Repo: "org/repo", RunID: "1"}
+	resp, err := svc.Authorize(claims, AuthorizeRequest{Action: "deploy", Resource: "svc", Env: "prod"}, "2025-12-20T16:34:14Z")
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if resp.Verdict != string(VerdictDeny) {
+		t.Fatalf("expected deny from included rule, got %s", resp.Verdict)
+	}
+
+	rec, ok := svc.Ledger.GetReceipt(resp.ReceiptID)
+	if !ok {
+		t.Fatalf("receipt not found")
+	}
+	version, ok := svc.Ledger.GetPolicyVersion(rec.PolicyHash)
+	if !ok {
+		t.Fatalf("policy version not found")
+	}
+	reloaded, err := policy.LoadPolicyFromBytes([]byte(version.PolicyYAML))
+	if err != nil {
+		t.Fatalf("reload bundle: %v", err)
+	}
+	if reloaded.Hash != rec.PolicyHash || len(reloaded.Policy.Rules) != 1 || reloaded.Policy.Rules[0].ID != "prod" {
+		t.Fatalf("stored bundle does not reproduce the snapshot: %+v", reloaded)
+	}
+}
+
+func TestAuthorizeRecordsAllMatchedRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relia.yaml")
+	body := `policy_id: combined
+evaluation: all_matching
+combining: deny_overrides
+defaults:
+  ttl_seconds: 3600
+rules:
+  - id: team_prod
+    match: {env: prod}
+    effect: {ttl_seconds: 1800}
+  - id: baseline_prod
+    match: {env: prod}
+    effect: {ttl_seconds: 900}
+`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	svc := newTestService(t, path)
+
+	claims := ActorContext{Subject: "sub", Issuer: "iss", Repo: "org/repo", RunID: "1"}
+	resp, err := svc.Authorize(claims, AuthorizeRequest{Action: "deploy", Resource: "svc", Env: "prod"}, "2025-12-20T16:34:14Z")
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	rec, ok := svc.Ledger.GetReceipt(resp.ReceiptID)
+	if !ok {
+		t.Fatalf("receipt not found")
+	}
+	stored, ok := svc.Ledger.GetDecision(rec.DecisionID)
+	if !ok {
+		t.Fatalf("decision not found")
+	}
+	var dec types.DecisionRecord
+	if err := json.Unmarshal(stored.BodyJSON, &dec); err != nil {
+		t.Fatalf("decode decision: %v", err)
+	}
+	if strings.Join(dec.MatchedRules, ",") != "team_prod,baseline_prod" {
+		t.Fatalf("unexpected matched rules: %v", dec.MatchedRules)
+	}
+	for _, code := range []string{"POLICY_MATCH:team_prod", "POLICY_MATCH:baseline_prod"} {
+		if !slices.Contains(dec.ReasonCodes, code) {
+			t.Fatalf("missing %s in %v", code, dec.ReasonCodes)
+		}
+	}
+	if resp.AWSCredentials == nil || resp.AWSCredentials.ExpiresAt == "" {
+		t.Fatalf("expected credentials: %+v", resp)
+	}
+}
+
+func TestAuthorizeFirstMatchOmitsMatchedRules(t *testing.T) {
+	svc := newTestService(t, "../../policies/relia.yaml")
+
+	claims := ActorContext{Subject: "sub", Issuer: "iss", Repo: "org/repo", RunID: "1"}
+	resp, err := svc.Authorize(claims, AuthorizeRequest{Action: "terraform.destroy", Resource: "stack/prod", Env: "prod"}, "2025-12-20T16:34:14Z")
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	rec, _ := svc.Ledger.GetReceipt(resp.ReceiptID)
+	stored, ok := svc.Ledger.GetDecision(rec.DecisionID)
+	if !ok {
+		t.Fatalf("decision not found")
+	}
+	var dec types.DecisionRecord
+	if err := json.Unmarshal(stored.BodyJSON, &dec); err != nil {
+		t.Fatalf("decode decision: %v", err)
+	}
+	if !slices.Contains(dec.ReasonCodes, "POLICY_MATCH:deny_unknown_prod_actions") {
+		t.Fatalf("missing POLICY_MATCH reason: %v", dec.ReasonCodes)
+	}
+	if dec.MatchedRules != nil || strings.Contains(string(stored.BodyJSON), "matched_rules") {
+		t.Fatalf("first-match decisions must not record matched_rules: %s", stored.BodyJSON)
+	}
+}
+
+func TestApprovalReceiptActorSubject(t *testing.T) {
+	svc := newTestService(t, "../../policies/relia.yaml")
+	claims := ActorContext{Subject: "sub", Issuer: "iss", Repo: "org/repo", RunID: "1"}
+	resp, err := svc.Authorize(claims, AuthorizeRequest{Action: "terraform.apply", Resource: "stack/prod", Env: "prod"}, "2025-12-20T16:34:14Z")
+	if err != nil || resp.Approval == nil {
+		t.Fatalf("expected pending approval: %+v err=%v", resp, err)
+	}
+	receiptID, err := svc.Approve(resp.Approval.ApprovalID, types.Approver{}, "approved", "2025-12-20T16:35:00Z")
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	rec, _ := svc.Ledger.GetReceipt(receiptID)
+	var body struct {
+		Actor types.ReceiptActor `json:"actor"`
+	}
+	if err := json.Unmarshal(rec.BodyJSON, &body); err != nil {
+		t.Fatalf("decode receipt: %v", err)
+	}
+	if body.Actor.Kind != "approval" || body.Actor.Subject != "anonymous" {
+		t.Fatalf("an anonymous vote must not be attributed to a transport: %+v", body.Actor)
+	}
+
+	if got := approvalActorSubject(types.Approver{Kind: "teams", ID: "aad-1"}); got != "teams:aad-1" {
+		t.Fatalf("unexpected identified subject: %q", got)
+	}
+	if got := approvalActorSubject(types.Approver{Kind: "webhook"}); got != "webhook" {
+		t.Fatalf("unexpected anonymous webhook subject: %q", got)
+	}
+}
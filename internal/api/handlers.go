@@ -4,7 +4,9 @@ import (
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,18 +15,60 @@ import (
 	"github.com/davidahmann/relia/internal/ledger"
 	"github.com/davidahmann/relia/internal/pack"
 	"github.com/davidahmann/relia/internal/slack"
+	"github.com/davidahmann/relia/internal/teams"
+	"github.com/davidahmann/relia/internal/webhook"
 	"github.com/davidahmann/relia/pkg/types"
 )
 
 type Handler struct {
-	Auth             auth.Authenticator
+	Auth auth.Authenticator
+	// ApproverAuth authenticates people voting through the REST API; nil disables it.
+	ApproverAuth auth.ApproverAuthenticator
+	// AdminAuth guards the /v1/admin endpoints; nil disables them.
+	AdminAuth        *auth.AdminAuthenticator
 	AuthorizeService *AuthorizeService
 	SlackHandler     *slack.InteractionHandler
-	PublicVerify     bool
+	TeamsHandler     *teams.InteractionHandler
+	// WebhookVerifier checks signed callbacks to /v1/webhooks/approvals; nil disables them.
+	WebhookVerifier webhook.Verifier
+	PublicVerify    bool
 }
 
 func (h *Handler) Healthz(w http.ResponseWriter, _ *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	payload := map[string]string{"status": "ok"}
+	if h.AuthorizeService != nil && h.AuthorizeService.Policies != nil {
+		payload["policy_hash"] = h.AuthorizeService.Policies.Hash()
+		if err := h.AuthorizeService.Policies.LastError(); err != nil {
+			payload["policy_reload_error"] = err.Error()
+		}
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
+
+// PolicyReload re-reads the policy; an invalid policy is rejected and the active one kept.
+func (h *Handler) PolicyReload(w http.ResponseWriter, r *http.Request) {
+	if !h.ensureAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if h.AuthorizeService == nil || h.AuthorizeService.Policies == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "policy manager not configured"})
+		return
+	}
+
+	result, err := h.AuthorizeService.Policies.Reload()
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"error":       err.Error(),
+			"policy_hash": result.PolicyHash,
+			"changed":     false,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
 }
 
 func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
@@ -57,9 +101,19 @@ func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
 		RunID:    claims.RunID,
 		SHA:      claims.SHA,
 		Token:    claims.Token,
+
+		Ref:         claims.Ref,
+		Environment: claims.Environment,
+		Actor:       claims.Actor,
 	}
 
-	resp, err := h.AuthorizeService.Authorize(actor, req, time.Now().UTC().Format(time.RFC3339))
+	if req.WaitForApprovalSeconds < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "wait_for_approval_seconds must not be negative"})
+		return
+	}
+	wait := time.Duration(req.WaitForApprovalSeconds) * time.Second
+
+	resp, err := h.AuthorizeService.AuthorizeAndWait(r.Context(), actor, req, time.Now().UTC().Format(time.RFC3339), wait)
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		return
@@ -69,6 +123,21 @@ func (h *Handler) Authorize(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Approvals(w http.ResponseWriter, r *http.Request) {
+	approvalID := strings.TrimPrefix(r.URL.Path, "/v1/approvals/")
+	if id, action, ok := strings.Cut(approvalID, "/"); ok && h.AuthorizeService != nil {
+		switch action {
+		case "approve":
+			h.voteApproval(w, r, id, ApprovalApproved)
+		case "deny":
+			h.voteApproval(w, r, id, ApprovalDenied)
+		case "wait":
+			h.waitApproval(w, r, id)
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+		return
+	}
+
 	if !h.ensureAuth(w, r) {
 		return
 	}
@@ -77,7 +146,6 @@ func (h *Handler) Approvals(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	approvalID := strings.TrimPrefix(r.URL.Path, "/v1/approvals/")
 	if approvalID == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing approval_id"})
 		return
@@ -89,10 +157,65 @@ func (h *Handler) Approvals(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{
+	writeJSON(w, http.StatusOK, approvalStatusResponse(approval))
+}
+
+// waitApproval serves GET /v1/approvals/{id}/wait?timeout=300s, a long-poll that
+// answers as soon as the approval leaves pending, or with the pending approval once
+// the timeout (default 60s) passes.
+func (h *Handler) waitApproval(w http.ResponseWriter, r *http.Request, approvalID string) {
+	if !h.ensureAuthOrApprover(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	timeout := 60 * time.Second
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			// Plain numbers are seconds.
+			seconds, convErr := strconv.Atoi(raw)
+			if convErr != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid timeout"})
+				return
+			}
+			d = time.Duration(seconds) * time.Second
+		}
+		if d <= 0 || d > MaxApprovalWait {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "timeout must be positive and at most " + MaxApprovalWait.String()})
+			return
+		}
+		timeout = d
+	}
+
+	approval, err := h.AuthorizeService.WaitForApproval(r.Context(), approvalID, timeout)
+	if errors.Is(err, ErrApprovalNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if r.Context().Err() != nil {
+		// The client went away; nobody reads the response.
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, approvalStatusResponse(approval))
+}
+
+func approvalStatusResponse(approval ledger.ApprovalRecord) map[string]string {
+	resp := map[string]string{
 		"approval_id": approval.ApprovalID,
 		"status":      approval.Status,
-	})
+	}
+	if approval.ExpiresAt != nil {
+		resp["expires_at"] = *approval.ExpiresAt
+	}
+	return resp
 }
 
 func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
@@ -122,6 +245,9 @@ func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
 		BodyJSON:   receiptRec.BodyJSON,
 		KeyID:      receiptRec.KeyID,
 		Sig:        receiptRec.Sig,
+
+		IdemKey:             receiptRec.IdemKey,
+		SupersedesReceiptID: receiptRec.SupersedesReceiptID,
 	}
 
 	pub, ok := h.AuthorizeService.Ledger.GetKey(receiptRec.KeyID)
@@ -170,26 +296,75 @@ func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
 		Decision: dec,
 	})
 
-	var body map[string]any
-	if err := json.Unmarshal(receiptRec.BodyJSON, &body); err == nil {
-		body["integrity"] = map[string]any{
-			"body_digest": receiptRec.BodyDigest,
-			"signatures": []map[string]any{
-				{
-					"alg":    "Ed25519",
-					"key_id": receiptRec.KeyID,
-					"sig":    "base64:" + base64.StdEncoding.EncodeToString(receiptRec.Sig),
-				},
-			},
-		}
-	}
-
 	writeJSON(w, http.StatusOK, map[string]any{
 		"receipt_id": receiptID,
 		"valid":      true,
 		"grade":      quality.Grade,
 		"grade_info": map[string]any{"reasons": quality.Reasons},
-		"receipt":    body,
+		"receipt":    receiptBodyWithIntegrity(receiptRec),
+	})
+}
+
+// Receipts serves GET /v1/receipts/{id}/chain: every receipt for the same intent,
+// linked through supersedes_receipt_id and ordered oldest first.
+func (h *Handler) Receipts(w http.ResponseWriter, r *http.Request) {
+	if !h.ensureAuth(w, r) {
+		return
+	}
+	if h.AuthorizeService == nil || h.AuthorizeService.Ledger == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "receipts not implemented"})
+		return
+	}
+
+	receiptID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/receipts/"), "/chain")
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if receiptID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing receipt_id"})
+		return
+	}
+
+	chain, err := ledger.ReceiptChain(h.AuthorizeService.Ledger, receiptID)
+	if err == ledger.ErrReceiptNotFound {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "receipt not found"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	allValid := true
+	receipts := make([]map[string]any, 0, len(chain))
+	for _, rec := range chain {
+		entry := map[string]any{
+			"receipt_id":            rec.ReceiptID,
+			"supersedes_receipt_id": rec.SupersedesReceiptID,
+			"created_at":            rec.CreatedAt,
+			"outcome_status":        rec.OutcomeStatus,
+			"approval_id":           rec.ApprovalID,
+			"final":                 rec.Final,
+			"receipt":               receiptBodyWithIntegrity(rec),
+		}
+		err := errors.New("public key not configured")
+		if key := h.verifyKey(rec.KeyID); key != nil {
+			err = ledger.VerifyReceipt(storedReceiptFromRecord(rec), key)
+		}
+		entry["valid"] = err == nil
+		if err != nil {
+			allValid = false
+			entry["error"] = err.Error()
+		}
+		receipts = append(receipts, entry)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"receipt_id": receiptID,
+		"idem_key":   chain[0].IdemKey,
+		"valid":      allValid,
+		"receipts":   receipts,
 	})
 }
 
@@ -263,28 +438,19 @@ func (h *Handler) Pack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	chain, err := h.storedReceiptChain(receiptRec.ReceiptID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
 	zipBytes, err := pack.BuildZip(pack.Input{
-		Receipt: ledger.StoredReceipt{
-			ReceiptID:           receiptRec.ReceiptID,
-			BodyDigest:          receiptRec.BodyDigest,
-			BodyJSON:            receiptRec.BodyJSON,
-			KeyID:               receiptRec.KeyID,
-			Sig:                 receiptRec.Sig,
-			IdemKey:             receiptRec.IdemKey,
-			CreatedAt:           receiptRec.CreatedAt,
-			SupersedesReceiptID: receiptRec.SupersedesReceiptID,
-			ContextID:           receiptRec.ContextID,
-			DecisionID:          receiptRec.DecisionID,
-			OutcomeStatus:       types.OutcomeStatus(receiptRec.OutcomeStatus),
-			ApprovalID:          receiptRec.ApprovalID,
-			PolicyHash:          receiptRec.PolicyHash,
-			Final:               receiptRec.Final,
-			ExpiresAt:           receiptRec.ExpiresAt,
-		},
+		Receipt:   storedReceiptFromRecord(receiptRec),
 		Context:   ctx,
 		Decision:  dec,
 		Policy:    []byte(policyVersion.PolicyYAML),
 		Approvals: approvals,
+		Chain:     chain,
 	}, baseURL)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
@@ -305,6 +471,72 @@ func (h *Handler) SlackInteractions(w http.ResponseWriter, r *http.Request) {
 	h.SlackHandler.HandleInteractions(w, r)
 }
 
+func (h *Handler) TeamsInteractions(w http.ResponseWriter, r *http.Request) {
+	if h.TeamsHandler == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "teams handler not configured"})
+		return
+	}
+	h.TeamsHandler.HandleInteractions(w, r)
+}
+
+// verifyKey returns the public key for keyID, falling back to the service key.
+func (h *Handler) verifyKey(keyID string) ed25519.PublicKey {
+	if pub, ok := h.AuthorizeService.Ledger.GetKey(keyID); ok {
+		return ed25519.PublicKey(pub.PublicKey)
+	}
+	return h.AuthorizeService.PublicKey
+}
+
+func (h *Handler) storedReceiptChain(receiptID string) ([]ledger.StoredReceipt, error) {
+	chain, err := ledger.ReceiptChain(h.AuthorizeService.Ledger, receiptID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ledger.StoredReceipt, 0, len(chain))
+	for _, rec := range chain {
+		out = append(out, storedReceiptFromRecord(rec))
+	}
+	return out, nil
+}
+
+func storedReceiptFromRecord(rec ledger.ReceiptRecord) ledger.StoredReceipt {
+	return ledger.StoredReceipt{
+		ReceiptID:           rec.ReceiptID,
+		BodyDigest:          rec.BodyDigest,
+		BodyJSON:            rec.BodyJSON,
+		KeyID:               rec.KeyID,
+		Sig:                 rec.Sig,
+		IdemKey:             rec.IdemKey,
+		CreatedAt:           rec.CreatedAt,
+		SupersedesReceiptID: rec.SupersedesReceiptID,
+		ContextID:           rec.ContextID,
+		DecisionID:          rec.DecisionID,
+		OutcomeStatus:       types.OutcomeStatus(rec.OutcomeStatus),
+		ApprovalID:          rec.ApprovalID,
+		PolicyHash:          rec.PolicyHash,
+		Final:               rec.Final,
+		ExpiresAt:           rec.ExpiresAt,
+	}
+}
+
+func receiptBodyWithIntegrity(rec ledger.ReceiptRecord) map[string]any {
+	var body map[string]any
+	if err := json.Unmarshal(rec.BodyJSON, &body); err != nil {
+		return nil
+	}
+	body["integrity"] = map[string]any{
+		"body_digest": rec.BodyDigest,
+		"signatures": []map[string]any{
+			{
+				"alg":    "Ed25519",
+				"key_id": rec.KeyID,
+				"sig":    "base64:" + base64.StdEncoding.EncodeToString(rec.Sig),
+			},
+		},
+	}
+	return body
+}
+
 func (h *Handler) ensureAuth(w http.ResponseWriter, r *http.Request) bool {
 	_, err := h.Authenticate(r)
 	if err != nil {
@@ -314,6 +546,21 @@ func (h *Handler) ensureAuth(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
+// ensureAdmin requires the admin token. Workload tokens are rejected, and the admin
+// endpoints answer 403 when no admin token is configured.
+func (h *Handler) ensureAdmin(w http.ResponseWriter, r *http.Request) bool {
+	err := h.AdminAuth.AuthenticateAdmin(r)
+	switch {
+	case errors.Is(err, auth.ErrAdminDisabled):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return false
+	case err != nil:
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
 func (h *Handler) Authenticate(r *http.Request) (auth.Claims, error) {
 	return h.Auth.Authenticate(r)
 }
@@ -2,13 +2,18 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/davidahmann/relia/internal/auth"
+	"github.com/davidahmann/relia/internal/pack"
 	"github.com/davidahmann/relia/internal/slack"
+	"github.com/davidahmann/relia/pkg/types"
 )
 
 func TestAuthorizeRequiresAuth(t *testing.T) {
@@ -454,3 +459,207 @@ func TestPackMissingContext(t *testing.T) {
 		t.Fatalf("expected 404, got %d", res.Code)
 	}
 }
+
+func authorizeApprovedChain(t *testing.T, service *AuthorizeService) (pendingID string, finalID string) {
+	t.Helper()
+
+	claims := ActorContext{
+		Subject:  "repo:org/repo:ref:refs/heads/main",
+		Issuer:   "relia-dev",
+		Repo:     "org/repo",
+		Workflow: "terraform-prod",
+		RunID:    "123456",
+		SHA:      "abcdef123",
+	}
+	req := AuthorizeRequest{Action: "terraform.apply", Resource: "res", Env: "prod"}
+
+	pending, err := service.Authorize(claims, req, "2025-12-20T16:34:14Z")
+	if err != nil || pending.Approval == nil {
+		t.Fatalf("authorize: err=%v resp=%+v", err, pending)
+	}
+	if _, err := service.Approve(pending.Approval.ApprovalID, types.Approver{}, string(ApprovalApproved), "2025-12-20T16:35:00Z"); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	allowed, err := service.Authorize(claims, req, "2025-12-20T16:35:02Z")
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	return pending.ReceiptID, allowed.ReceiptID
+}
+
+func TestReceiptChainEndpoint(t *testing.T) {
+	os.Setenv("RELIA_DEV_TOKEN", "test-token")
+	defer os.Unsetenv("RELIA_DEV_TOKEN")
+
+	service := newTestService(t, "../../policies/relia.yaml")
+	pendingID, finalID := authorizeApprovedChain(t, service)
+
+	router := NewRouter(&Handler{Auth: auth.NewAuthenticatorFromEnv(), AuthorizeService: service})
+	for _, id := range []string{pendingID, finalID} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/receipts/"+id+"/chain", nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d body=%s", res.Code, res.Body.String())
+		}
+		var payload struct {
+			Valid    bool `json:"valid"`
+			Receipts []struct {
+				ReceiptID           string  `json:"receipt_id"`
+				SupersedesReceiptID *string `json:"supersedes_receipt_id"`
+				OutcomeStatus       string  `json:"outcome_status"`
+				Valid               bool    `json:"valid"`
+			} `json:"receipts"`
+		}
+		if err := json.Unmarshal(res.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if !payload.Valid {
+			t.Fatalf("expected valid chain: %s", res.Body.String())
+		}
+		// pending -> approved -> issuing -> issued
+		if len(payload.Receipts) != 4 {
+			t.Fatalf("expected 4 receipts, got %d", len(payload.Receipts))
+		}
+		if payload.Receipts[0].ReceiptID != pendingID || payload.Receipts[3].ReceiptID != finalID {
+			t.Fatalf("unexpected chain order: %+v", payload.Receipts)
+		}
+		for i := 1; i < len(payload.Receipts); i++ {
+			prev := payload.Receipts[i].SupersedesReceiptID
+			if prev == nil || *prev != payload.Receipts[i-1].ReceiptID {
+				t.Fatalf("receipt %d does not supersede previous: %+v", i, payload.Receipts)
+			}
+		}
+	}
+}
+
+func TestReceiptChainEndpointErrors(t *testing.T) {
+	os.Setenv("RELIA_DEV_TOKEN", "test-token")
+	defer os.Unsetenv("RELIA_DEV_TOKEN")
+
+	service := newTestService(t, "../../policies/relia.yaml")
+	router := NewRouter(&Handler{Auth: auth.NewAuthenticatorFromEnv(), AuthorizeService: service})
+
+	cases := map[string]int{
+		"/v1/receipts/missing/chain": http.StatusNotFound,
+		"/v1/receipts/abc":           http.StatusNotFound,
+	}
+	for path, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		if res.Code != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, res.Code)
+		}
+	}
+}
+
+func TestPackEndpointIncludesChain(t *testing.T) {
+	os.Setenv("RELIA_DEV_TOKEN", "test-token")
+	defer os.Unsetenv("RELIA_DEV_TOKEN")
+
+	service := newTestService(t, "../../policies/relia.yaml")
+	_, finalID := authorizeApprovedChain(t, service)
+
+	router := NewRouter(&Handler{Auth: auth.NewAuthenticatorFromEnv(), AuthorizeService: service})
+	req := httptest.NewRequest(http.MethodGet, "/v1/pack/"+finalID, nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+
+	files, err := pack.ReadZip(res.Body.Bytes())
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	chainFiles := 0
+	for name := range files {
+		if strings.HasPrefix(name, "receipts/") {
+			chainFiles++
+		}
+	}
+	if chainFiles != 4 {
+		t.Fatalf("expected 4 chain receipts, got %d", chainFiles)
+	}
+	if !strings.Contains(string(files["summary.html"]), "Receipt Chain") {
+		t.Fatalf("expected chain summary in summary.html")
+	}
+
+	result := pack.VerifyFiles(files, service.PublicKey)
+	if !result.Valid {
+		t.Fatalf("expected valid pack, got %s", result.Error())
+	}
+}
+
+func TestPolicyReloadEndpoint(t *testing.T) {
+	os.Setenv("RELIA_DEV_TOKEN", "test-token")
+	defer os.Unsetenv("RELIA_DEV_TOKEN")
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte("policy_id: p\npolicy_version: \"1\"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	service := newTestService(t, path)
+	handler := &Handler{Auth: auth.NewAuthenticatorFromEnv(), AdminAuth: &auth.AdminAuthenticator{Token: "admin-token"}, AuthorizeService: service}
+	router := NewRouter(handler)
+	first := service.Policies.Hash()
+
+	do := func(method, path string, token bool) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest(method, path, nil)
+		if token {
+			req.Header.Set("Authorization", "Bearer admin-token")
+		}
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		var body map[string]any
+		_ = json.Unmarshal(res.Body.Bytes(), &body)
+		return res, body
+	}
+
+	if res, body := do(http.MethodGet, "/healthz", false); res.Code != http.StatusOK || body["policy_hash"] != first {
+		t.Fatalf("expected policy_hash on healthz, got %d %v", res.Code, body)
+	}
+	if res, _ := do(http.MethodPost, "/v1/admin/policy/reload", false); res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", res.Code)
+	}
+	workload := httptest.NewRequest(http.MethodPost, "/v1/admin/policy/reload", nil)
+	workload.Header.Set("Authorization", "Bearer test-token")
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, workload)
+	if res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a workload token, got %d", res.Code)
+	}
+	handler.AdminAuth = nil
+	if res, _ := do(http.MethodPost, "/v1/admin/policy/reload", true); res.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without an admin token configured, got %d", res.Code)
+	}
+	handler.AdminAuth = &auth.AdminAuthenticator{Token: "admin-token"}
+	if res, _ := do(http.MethodGet, "/v1/admin/policy/reload", true); res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", res.Code)
+	}
+
+	if err := os.WriteFile(path, []byte("policy_id: p\npolicy_version: \"2\"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	res, body := do(http.MethodPost, "/v1/admin/policy/reload", true)
+	if res.Code != http.StatusOK || body["changed"] != true || body["policy_hash"] == first {
+		t.Fatalf("expected reload, got %d %v", res.Code, body)
+	}
+	second := body["policy_hash"]
+
+	if err := os.WriteFile(path, []byte("rules: [\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	res, body = do(http.MethodPost, "/v1/admin/policy/reload", true)
+	if res.Code != http.StatusUnprocessableEntity || body["policy_hash"] != second || body["error"] == "" {
+		t.Fatalf("expected rejected reload, got %d %v", res.Code, body)
+	}
+	if res, body := do(http.MethodGet, "/healthz", false); body["policy_hash"] != second || body["policy_reload_error"] == nil {
+		t.Fatalf("expected last good hash and reload error, got %d %v", res.Code, body)
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/outbox"
+)
+
+// OutboxSummary describes an outbox record for the admin API. The target and payload
+// are left out: Teams records queued before channel aliases may hold a webhook URL,
+// which works as a credential, as their target.
+type OutboxSummary struct {
+	OutboxID      string                 `json:"outbox_id"`
+	Kind          string                 `json:"kind"`
+	ApprovalID    string                 `json:"approval_id,omitempty"`
+	Status        string                 `json:"status"`
+	AttemptCount  int                    `json:"attempt_count"`
+	MaxAttempts   int                    `json:"max_attempts"`
+	NextAttemptAt string                 `json:"next_attempt_at"`
+	LastError     string                 `json:"last_error,omitempty"`
+	Attempts      []ledger.OutboxAttempt `json:"attempts"`
+	SentAt        string                 `json:"sent_at,omitempty"`
+	CreatedAt     string                 `json:"created_at"`
+	UpdatedAt     string                 `json:"updated_at"`
+}
+
+func outboxSummary(rec ledger.OutboxRecord) OutboxSummary {
+	summary := OutboxSummary{
+		OutboxID:      rec.OutboxID,
+		Kind:          rec.Kind,
+		ApprovalID:    rec.ApprovalID,
+		Status:        rec.Status,
+		AttemptCount:  rec.AttemptCount,
+		MaxAttempts:   rec.MaxAttempts,
+		NextAttemptAt: rec.NextAttemptAt,
+		Attempts:      rec.Attempts,
+		CreatedAt:     rec.CreatedAt,
+		UpdatedAt:     rec.UpdatedAt,
+	}
+	if summary.Attempts == nil {
+		summary.Attempts = []ledger.OutboxAttempt{}
+	}
+	if rec.LastError != nil {
+		summary.LastError = *rec.LastError
+	}
+	if rec.SentAt != nil {
+		summary.SentAt = *rec.SentAt
+	}
+	return summary
+}
+
+// ListOutbox serves GET /v1/admin/outbox?status=dead&limit=50.
+func (h *Handler) ListOutbox(w http.ResponseWriter, r *http.Request) {
+	if !h.ensureAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if h.AuthorizeService == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "authorize service not configured"})
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", outbox.StatusPending, outbox.StatusSent, outbox.StatusDead:
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid status"})
+		return
+	}
+	limit := 50
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 500 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	records, err := h.AuthorizeService.Ledger.ListOutbox(status, limit)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	out := make([]OutboxSummary, 0, len(records))
+	for _, rec := range records {
+		out = append(out, outboxSummary(rec))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"outbox": out})
+}
+
+// RetryOutbox serves POST /v1/admin/outbox/{id}/retry, which requeues a dead record.
+func (h *Handler) RetryOutbox(w http.ResponseWriter, r *http.Request) {
+	if !h.ensureAdmin(w, r) {
+		return
+	}
+	outboxID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/admin/outbox/"), "/retry")
+	if !ok || outboxID == "" || strings.Contains(outboxID, "/") {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if h.AuthorizeService == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "authorize service not configured"})
+		return
+	}
+
+	rec, err := outbox.Retry(h.AuthorizeService.Ledger, outboxID, time.Now().UTC())
+	switch {
+	case errors.Is(err, outbox.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, outbox.ErrNotDead):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case err != nil:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusOK, outboxSummary(rec))
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/davidahmann/relia/internal/auth"
+	"github.com/davidahmann/relia/internal/ledger"
+)
+
+func TestOutboxAdminListAndRetry(t *testing.T) {
+	os.Setenv("RELIA_DEV_TOKEN", "test-token")
+	defer os.Unsetenv("RELIA_DEV_TOKEN")
+
+	svc := newTestService(t, "../../policies/relia.yaml")
+	lastError := "http 500"
+	dead := ledger.OutboxRecord{
+		OutboxID:      "teams_post:a1",
+		Kind:          "teams_post",
+		ApprovalID:    "a1",
+		Target:        "https://example.test/secret-hook",
+		PayloadJSON:   []byte(`{}`),
+		Status:        "dead",
+		AttemptCount:  3,
+		MaxAttempts:   3,
+		NextAttemptAt: "2025-12-20T16:35:00Z",
+		LastError:     &lastError,
+		Attempts:      []ledger.OutboxAttempt{{At: "2025-12-20T16:35:00Z", Error: lastError}},
+		CreatedAt:     "2025-12-20T16:34:14Z",
+		UpdatedAt:     "2025-12-20T16:35:00Z",
+	}
+	if err := svc.Ledger.PutOutbox(dead); err != nil {
+		t.Fatalf("put outbox: %v", err)
+	}
+	sent := dead
+	sent.OutboxID, sent.Status = "teams_post:a2", "sent"
+	if err := svc.Ledger.PutOutbox(sent); err != nil {
+		t.Fatalf("put outbox: %v", err)
+	}
+	router := NewRouter(&Handler{Auth: auth.NewAuthenticatorFromEnv(), AdminAuth: &auth.AdminAuthenticator{Token: "admin-token"}, AuthorizeService: svc})
+
+	code, out := serveApprovals(t, router, http.MethodGet, "/v1/admin/outbox?status=dead", "admin-token", "")
+	records, _ := out["outbox"].([]any)
+	if code != http.StatusOK || len(records) != 1 {
+		t.Fatalf("expected one dead record, got %d %v", code, out)
+	}
+	rec := records[0].(map[string]any)
+	if rec["outbox_id"] != "teams_post:a1" || rec["last_error"] != "http 500" || rec["target"] != nil || rec["payload"] != nil {
+		t.Fatalf("unexpected summary: %v", rec)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/admin/outbox?status=stuck", "admin-token", ""); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown status, got %d", code)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/admin/outbox", "", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", code)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/admin/outbox", "test-token", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a workload token, got %d", code)
+	}
+
+	code, out = serveApprovals(t, router, http.MethodPost, "/v1/admin/outbox/teams_post:a1/retry", "admin-token", "")
+	if code != http.StatusOK || out["status"] != "pending" || out["attempt_count"] != float64(0) {
+		t.Fatalf("expected the record requeued, got %d %v", code, out)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodPost, "/v1/admin/outbox/teams_post:a1/retry", "admin-token", ""); code != http.StatusConflict {
+		t.Fatalf("expected 409 for a record that is not dead, got %d", code)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodPost, "/v1/admin/outbox/missing/retry", "admin-token", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+	if code, _ := serveApprovals(t, router, http.MethodGet, "/v1/admin/outbox/teams_post:a1/retry", "admin-token", ""); code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", code)
+	}
+}
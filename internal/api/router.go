@@ -13,10 +13,17 @@ func NewRouter(handler *Handler) http.Handler {
 	mux.HandleFunc("/pack/", handler.PackPublic)
 
 	mux.HandleFunc("/v1/authorize", handler.Authorize)
+	mux.HandleFunc("/v1/approvals", handler.ListApprovals)
 	mux.HandleFunc("/v1/approvals/", handler.Approvals)
+	mux.HandleFunc("/v1/receipts/", handler.Receipts)
 	mux.HandleFunc("/v1/verify/", handler.Verify)
 	mux.HandleFunc("/v1/pack/", handler.Pack)
 	mux.HandleFunc("/v1/slack/interactions", handler.SlackInteractions)
+	mux.HandleFunc("/v1/teams/interactions", handler.TeamsInteractions)
+	mux.HandleFunc("/v1/webhooks/approvals", handler.WebhookApprovals)
+	mux.HandleFunc("/v1/admin/policy/reload", handler.PolicyReload)
+	mux.HandleFunc("/v1/admin/outbox", handler.ListOutbox)
+	mux.HandleFunc("/v1/admin/outbox/", handler.RetryOutbox)
 
 	return mux
 }
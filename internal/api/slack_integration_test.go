@@ -16,6 +16,7 @@ import (
 
 	"github.com/davidahmann/relia/internal/auth"
 	"github.com/davidahmann/relia/internal/slack"
+	"github.com/davidahmann/relia/pkg/types"
 )
 
 func TestSlackApprovalFlowEndToEnd(t *testing.T) {
@@ -43,6 +44,29 @@ func TestSlackApprovalFlowEndToEnd(t *testing.T) {
 	// Approve via Slack interaction callback.
 	postSlackApprove(t, srv.URL, signingSecret, approvalID)
 
+	// The clicking Slack user is recorded on the approval and signed into its receipt.
+	approval, ok := service.GetApproval(approvalID)
+	if !ok || approval.ApprovedBy == nil || *approval.ApprovedBy != "slack:U123" || approval.ApprovedAt == nil {
+		t.Fatalf("expected approver on approval: %+v", approval)
+	}
+	rec, ok := service.Ledger.GetReceipt(approval.Votes[0].ReceiptID)
+	if !ok {
+		t.Fatalf("approval receipt not found")
+	}
+	var body struct {
+		Actor    types.ReceiptActor     `json:"actor"`
+		Approval *types.ReceiptApproval `json:"approval"`
+	}
+	if err := json.Unmarshal(rec.BodyJSON, &body); err != nil {
+		t.Fatalf("decode receipt: %v", err)
+	}
+	if a := body.Approval; a == nil || a.Approver == nil || a.Approver.ID != "U123" || a.Approver.Display != "alice" || a.Approver.TeamID != "T1" || a.ApprovedAt == "" {
+		t.Fatalf("unexpected signed approval: %+v", body.Approval)
+	}
+	if body.Actor.Subject != "slack:U123" {
+		t.Fatalf("unexpected receipt actor: %+v", body.Actor)
+	}
+
 	// Re-call authorize; should mint creds (dev broker) and return allow.
 	resp := authorize(t, srv.URL, `{"action":"terraform.apply","resource":"res","env":"prod","request_id":"req-1"}`)
 	if resp.Verdict != string(VerdictAllow) {
@@ -111,7 +135,7 @@ func authorize(t *testing.T, baseURL, body string) authorizeResponse {
 func postSlackApprove(t *testing.T, baseURL, signingSecret, approvalID string) {
 	t.Helper()
 
-	payload := fmt.Sprintf(`{"actions":[{"action_id":"approve","value":"%s"}]}`, approvalID)
+	payload := fmt.Sprintf(`{"actions":[{"action_id":"approve","value":"%s"}],"user":{"id":"U123","username":"alice","team_id":"T1"}}`, approvalID)
 	form := url.Values{}
 	form.Set("payload", payload)
 	body := []byte(form.Encode())
@@ -0,0 +1,148 @@
+package api
+
+import (
+	stdcontext "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/outbox"
+	"github.com/davidahmann/relia/internal/slack"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+// SlackUpdater is implemented by Slack notifiers that can edit a posted approval
+// message; *slack.Client implements it.
+type SlackUpdater interface {
+	UpdateMessage(channel, ts string, message slack.ApprovalMessageInput, resolution slack.ApprovalResolution) error
+}
+
+// SlackThreader is implemented by Slack notifiers that can reply in an approval
+// message's thread; *slack.Client implements it.
+type SlackThreader interface {
+	PostThreadReply(channel, threadTS, text string) error
+}
+
+// slackUpdate is the payload of a slack_update outbox record: either a resolution that
+// replaces the approval message's buttons, or a reply in its thread.
+type slackUpdate struct {
+	TS         string                      `json:"ts"`
+	Message    *slack.ApprovalMessageInput `json:"message,omitempty"`
+	Resolution *slack.ApprovalResolution   `json:"resolution,omitempty"`
+	ThreadText string                      `json:"thread_text,omitempty"`
+}
+
+// updateSlackMessage queues replacing the buttons on an approval's Slack message with
+// how the approval ended. It is best-effort: the outcome is already signed and stored.
+func (s *AuthorizeService) updateSlackMessage(approvalID string, resolution slack.ApprovalResolution) {
+	if _, ok := s.Slack.(SlackUpdater); !ok {
+		return
+	}
+	approval, ok := s.Ledger.GetApproval(approvalID)
+	if !ok || approval.SlackChannel == nil || approval.SlackMsgTS == nil {
+		return
+	}
+	post, ok := s.Ledger.GetOutbox(outbox.KindSlackPost + ":" + approvalID)
+	if !ok {
+		return
+	}
+	var input slack.ApprovalMessageInput
+	if err := json.Unmarshal(post.PayloadJSON, &input); err != nil {
+		return
+	}
+	s.enqueueSlackUpdate(approvalID, "resolved", *approval.SlackChannel, slackUpdate{TS: *approval.SlackMsgTS, Message: &input, Resolution: &resolution})
+}
+
+// postSlackThreadReply queues a reply under the Slack message of the approval behind
+// idemKey, if the request needed one. Like updateSlackMessage it is best-effort.
+func (s *AuthorizeService) postSlackThreadReply(idemKey string, text string) {
+	if _, ok := s.Slack.(SlackThreader); !ok {
+		return
+	}
+	approval, ok := s.Ledger.GetApprovalByIdemKey(idemKey)
+	if !ok || approval.SlackChannel == nil || approval.SlackMsgTS == nil {
+		return
+	}
+	// Keyed by the text, so a retried request does not repeat the same reply.
+	sum := sha256.Sum256([]byte(text))
+	s.enqueueSlackUpdate(approval.ApprovalID, "reply:"+hex.EncodeToString(sum[:8]), *approval.SlackChannel, slackUpdate{TS: *approval.SlackMsgTS, ThreadText: text})
+}
+
+// enqueueSlackUpdate queues update for approvalID under key, unless it is already
+// queued, and tries it at once.
+func (s *AuthorizeService) enqueueSlackUpdate(approvalID, key, channel string, update slackUpdate) {
+	outboxID := outbox.KindSlackUpdate + ":" + approvalID + ":" + key
+	if _, ok := s.Ledger.GetOutbox(outboxID); ok {
+		return
+	}
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	rec := ledger.OutboxRecord{
+		OutboxID:      outboxID,
+		Kind:          outbox.KindSlackUpdate,
+		ApprovalID:    approvalID,
+		Target:        channel,
+		PayloadJSON:   payload,
+		Status:        outbox.StatusPending,
+		MaxAttempts:   s.outboxMaxAttempts(),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.Ledger.PutOutbox(rec); err != nil {
+		return
+	}
+	s.processOutbox(outbox.KindSlackUpdate, outboxID)
+}
+
+// deliverSlackUpdate is the outbox delivery func for slack_update records.
+func (s *AuthorizeService) deliverSlackUpdate(ctx stdcontext.Context, rec ledger.OutboxRecord) error {
+	var update slackUpdate
+	if err := json.Unmarshal(rec.PayloadJSON, &update); err != nil {
+		return outbox.Permanent(fmt.Errorf("invalid payload_json: %w", err))
+	}
+	if update.ThreadText != "" {
+		threader, ok := s.Slack.(SlackThreader)
+		if !ok {
+			return outbox.Permanent(errors.New("slack notifier cannot reply in threads"))
+		}
+		return threader.PostThreadReply(rec.Target, update.TS, update.ThreadText)
+	}
+	updater, ok := s.Slack.(SlackUpdater)
+	if !ok {
+		return outbox.Permanent(errors.New("slack notifier cannot update messages"))
+	}
+	if update.Message == nil || update.Resolution == nil {
+		return outbox.Permanent(errors.New("slack update has no message or resolution"))
+	}
+	return updater.UpdateMessage(rec.Target, update.TS, *update.Message, *update.Resolution)
+}
+
+// verifyURL links to the public verify page for receiptID, or returns "" when the
+// service has no PublicURL.
+func (s *AuthorizeService) verifyURL(receiptID string) string {
+	if s.PublicURL == "" || receiptID == "" {
+		return ""
+	}
+	return strings.TrimRight(s.PublicURL, "/") + "/verify/" + receiptID
+}
+
+// slackApproverName names an approver in a Slack message: Slack users as a mention,
+// anyone else by subject.
+func slackApproverName(approver types.Approver) string {
+	if approver.Kind == "slack" && approver.ID != "" {
+		return "<@" + approver.ID + ">"
+	}
+	if approver.Display != "" {
+		return approver.Display
+	}
+	return approver.Subject()
+}
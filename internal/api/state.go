@@ -20,6 +20,7 @@ const (
 	ApprovalPending  ApprovalStatus = "pending"
 	ApprovalApproved ApprovalStatus = "approved"
 	ApprovalDenied   ApprovalStatus = "denied"
+	ApprovalExpired  ApprovalStatus = "expired"
 )
 
 const (
@@ -58,7 +59,7 @@ func DetermineNextAction(idem IdemState, approval *ApprovalState) NextAction {
 		switch approval.Status {
 		case ApprovalPending:
 			return ActionReturnPending
-		case ApprovalDenied:
+		case ApprovalDenied, ApprovalExpired:
 			return ActionReturnDenied
 		case ApprovalApproved:
 			return ActionIssueCredentials
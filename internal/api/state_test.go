@@ -35,6 +35,10 @@ func TestDetermineNextActionPendingApproval(t *testing.T) {
 		t.Fatalf("expected return_denied, got %s", got)
 	}
 
+	if got := DetermineNextAction(idem, &ApprovalState{Status: ApprovalExpired}); got != ActionReturnDenied {
+		t.Fatalf("expected return_denied for expired, got %s", got)
+	}
+
 	if got := DetermineNextAction(idem, &ApprovalState{Status: ApprovalApproved}); got != ActionIssueCredentials {
 		t.Fatalf("expected issue_credentials, got %s", got)
 	}
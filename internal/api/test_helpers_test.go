@@ -2,6 +2,8 @@ package api
 
 import (
 	"crypto/ed25519"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/davidahmann/relia/internal/aws"
@@ -10,6 +12,12 @@ import (
 
 func newTestService(t *testing.T, policyPath string) *AuthorizeService {
 	t.Helper()
+	return newStoreService(t, policyPath, ledger.NewInMemoryStore())
+}
+
+// newStoreService is newTestService on the given ledger store.
+func newStoreService(t *testing.T, policyPath string, store ledger.Store) *AuthorizeService {
+	t.Helper()
 
 	seed := make([]byte, ed25519.SeedSize)
 	priv := ed25519.NewKeyFromSeed(seed)
@@ -17,7 +25,7 @@ func newTestService(t *testing.T, policyPath string) *AuthorizeService {
 
 	service, err := NewAuthorizeService(NewAuthorizeServiceInput{
 		PolicyPath: policyPath,
-		Ledger:     ledger.NewInMemoryStore(),
+		Ledger:     store,
 		Signer:     devSigner{keyID: "test", priv: priv},
 		PublicKey:  pub,
 		Broker:     aws.DevBroker{},
@@ -27,3 +35,53 @@ func newTestService(t *testing.T, policyPath string) *AuthorizeService {
 	}
 	return service
 }
+
+// approvalPolicy requires approval for terraform.apply in prod.
+const approvalPolicy = `policy_id: approval
+rules:
+  - id: prod_apply
+    match: {action: terraform.apply, env: prod}
+    effect:
+      require_approval: true
+      aws_role_arn: "arn:aws:iam::123456789012:role/prod-apply"
+`
+
+// approvalClaims is the workload that requests approvals in tests; "octocat" is the
+// GitHub actor separation-of-duties policies map.
+var approvalClaims = ActorContext{Subject: "repo:org/repo:ref:refs/heads/main", Issuer: "relia-dev", Repo: "org/repo", RunID: "1", Actor: "octocat", Token: "jwt"}
+
+// approvalRequestedAt is when requestApproval authorizes.
+const approvalRequestedAt = "2025-12-20T16:00:00Z"
+
+// newPolicyService builds a test service on policyText, written to a temp file.
+func newPolicyService(t *testing.T, policyText string) *AuthorizeService {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "relia.yaml")
+	if err := os.WriteFile(path, []byte(policyText), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return newTestService(t, path)
+}
+
+// requestApproval authorizes req as approvalClaims at approvalRequestedAt and returns
+// the ID of the approval it must leave pending.
+func requestApproval(t *testing.T, svc *AuthorizeService, req AuthorizeRequest) string {
+	t.Helper()
+	resp, err := svc.Authorize(approvalClaims, req, approvalRequestedAt)
+	if err != nil || resp.Approval == nil {
+		t.Fatalf("expected pending approval: %+v err=%v", resp, err)
+	}
+	return resp.Approval.ApprovalID
+}
+
+// newPendingApproval builds a service on policyText and opens an approval for req.
+func newPendingApproval(t *testing.T, policyText string, req AuthorizeRequest) (*AuthorizeService, string) {
+	t.Helper()
+	svc := newPolicyService(t, policyText)
+	return svc, requestApproval(t, svc, req)
+}
+
+// waitDeliveries waits for the outbox deliveries the service started in the background.
+func (s *AuthorizeService) waitDeliveries() {
+	s.deliveries.Wait()
+}
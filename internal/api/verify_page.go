@@ -103,6 +103,9 @@ func (h *Handler) VerifyPage(w http.ResponseWriter, r *http.Request) {
 		BodyJSON:   receiptRec.BodyJSON,
 		KeyID:      receiptRec.KeyID,
 		Sig:        receiptRec.Sig,
+
+		IdemKey:             receiptRec.IdemKey,
+		SupersedesReceiptID: receiptRec.SupersedesReceiptID,
 	}
 	pub, ok := h.AuthorizeService.Ledger.GetKey(receiptRec.KeyID)
 	var verifyKey ed25519.PublicKey
@@ -337,24 +340,20 @@ func (h *Handler) PackPublic(w http.ResponseWriter, r *http.Request) {
 		baseURL = scheme + "://" + r.Host
 	}
 
-	storedReceipt := ledger.StoredReceipt{
-		ReceiptID:  receiptRec.ReceiptID,
-		BodyDigest: receiptRec.BodyDigest,
-		BodyJSON:   receiptRec.BodyJSON,
-		KeyID:      receiptRec.KeyID,
-		Sig:        receiptRec.Sig,
-		PolicyHash: receiptRec.PolicyHash,
-		ApprovalID: receiptRec.ApprovalID,
-		ExpiresAt:  receiptRec.ExpiresAt,
+	chain, err := h.storedReceiptChain(receiptRec.ReceiptID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	zipBytes, err := pack.BuildZip(pack.Input{
-		Receipt:   storedReceipt,
+		Receipt:   storedReceiptFromRecord(receiptRec),
 		Context:   ctx,
 		Decision:  dec,
 		Policy:    []byte(policyVersion.PolicyYAML),
 		Approvals: approvals,
 		CreatedAt: receiptRec.CreatedAt,
+		Chain:     chain,
 	}, baseURL)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -64,6 +64,7 @@ func TestVerifyPageAndPublicPack(t *testing.T) {
 		types.DecisionPolicy{PolicyID: "relia-default", PolicyVersion: "2025-12-20", PolicyHash: policyHash},
 		"allow",
 		nil,
+		nil,
 		false,
 		"high",
 		createdAt,
@@ -101,7 +102,7 @@ func TestVerifyPageAndPublicPack(t *testing.T) {
 	}
 
 	svc, err := NewAuthorizeService(NewAuthorizeServiceInput{
-		PolicyPath: "policies/relia.yaml",
+		PolicyPath: "../../policies/relia.yaml",
 		Ledger:     store,
 		Signer:     fixedSigner{keyID: "k1", priv: priv},
 		PublicKey:  pub,
@@ -186,6 +187,7 @@ func TestVerifyPageInvalidSignatureStillRenders(t *testing.T) {
 		types.DecisionPolicy{PolicyID: "relia-default", PolicyVersion: "2025-12-20", PolicyHash: policyHash},
 		"allow",
 		nil,
+		nil,
 		false,
 		"high",
 		createdAt,
@@ -218,7 +220,7 @@ func TestVerifyPageInvalidSignatureStillRenders(t *testing.T) {
 	}
 
 	svc, err := NewAuthorizeService(NewAuthorizeServiceInput{
-		PolicyPath: "policies/relia.yaml",
+		PolicyPath: "../../policies/relia.yaml",
 		Ledger:     store,
 		Signer:     fixedSigner{keyID: "k1", priv: priv},
 		PublicKey:  pub,
@@ -270,6 +272,7 @@ func TestPublicPackMissingPolicyVersionIs404(t *testing.T) {
 		types.DecisionPolicy{PolicyID: "relia-default", PolicyVersion: "2025-12-20", PolicyHash: policyHash},
 		"allow",
 		nil,
+		nil,
 		false,
 		"high",
 		createdAt,
@@ -300,7 +303,7 @@ func TestPublicPackMissingPolicyVersionIs404(t *testing.T) {
 	}
 
 	svc, err := NewAuthorizeService(NewAuthorizeServiceInput{
-		PolicyPath: "policies/relia.yaml",
+		PolicyPath: "../../policies/relia.yaml",
 		Ledger:     store,
 		Signer:     fixedSigner{keyID: "k1", priv: priv},
 		PublicKey:  pub,
@@ -346,6 +349,7 @@ func TestPublicPackInvalidContextIs500(t *testing.T) {
 		types.DecisionPolicy{PolicyID: "relia-default", PolicyVersion: "2025-12-20", PolicyHash: policyHash},
 		"allow",
 		nil,
+		nil,
 		false,
 		"high",
 		createdAt,
@@ -376,7 +380,7 @@ func TestPublicPackInvalidContextIs500(t *testing.T) {
 	}
 
 	svc, err := NewAuthorizeService(NewAuthorizeServiceInput{
-		PolicyPath: "policies/relia.yaml",
+		PolicyPath: "../../policies/relia.yaml",
 		Ledger:     store,
 		Signer:     fixedSigner{keyID: "k1", priv: priv},
 		PublicKey:  pub,
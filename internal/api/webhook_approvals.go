@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/relia/internal/webhook"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+// WebhookVoteRequest is the body of POST /v1/webhooks/approvals, sent by an external
+// approval system once it has decided.
+type WebhookVoteRequest struct {
+	ApprovalID string `json:"approval_id"`
+	Action     string `json:"action"` // approve | deny
+	Approver   struct {
+		ID      string   `json:"id"`
+		Display string   `json:"display,omitempty"`
+		Groups  []string `json:"groups,omitempty"`
+	} `json:"approver"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// WebhookApprovals serves POST /v1/webhooks/approvals. The request must carry a valid
+// X-Relia-Signature for WebhookVerifier; the vote is then recorded as kind "webhook"
+// with the approver ID the external system reports.
+func (h *Handler) WebhookApprovals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if h.WebhookVerifier == nil || h.AuthorizeService == nil {
+		writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "webhook approvals not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid body"})
+		return
+	}
+	if err := h.WebhookVerifier.Verify(webhook.PurposeApprovalCallback, r.Header.Get(webhook.HeaderSignature), r.Header.Get(webhook.HeaderTimestamp), body, time.Now()); err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+
+	var req WebhookVoteRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+	var status ApprovalStatus
+	switch req.Action {
+	case "approve":
+		status = ApprovalApproved
+	case "deny":
+		status = ApprovalDenied
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "action must be approve or deny"})
+		return
+	}
+	if req.ApprovalID == "" || req.Approver.ID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "approval_id and approver.id are required"})
+		return
+	}
+
+	if _, ok := h.AuthorizeService.GetApproval(req.ApprovalID); !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "approval not found"})
+		return
+	}
+
+	approver := types.Approver{
+		Kind:    "webhook",
+		ID:      req.Approver.ID,
+		Display: req.Approver.Display,
+		Groups:  req.Approver.Groups,
+	}
+	receiptID, err := h.AuthorizeService.ApproveWithComment(req.ApprovalID, approver, string(status), strings.TrimSpace(req.Comment), time.Now().UTC().Format(time.RFC3339))
+	var rejected *VoteRejectedError
+	if errors.As(err, &rejected) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	approval, _ := h.AuthorizeService.GetApproval(req.ApprovalID)
+	writeJSON(w, http.StatusOK, map[string]string{
+		"approval_id": req.ApprovalID,
+		"status":      approval.Status,
+		"receipt_id":  receiptID,
+	})
+}
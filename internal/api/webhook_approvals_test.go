@@ -0,0 +1,128 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/internal/webhook"
+)
+
+// postCallback sends a vote to the gateway the way the external system would.
+func postCallback(t *testing.T, gatewayURL string, signer webhook.Signer, body string) (int, map[string]string) {
+	t.Helper()
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig, err := signer.Sign(webhook.PurposeApprovalCallback, ts, []byte(body))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, gatewayURL+"/v1/webhooks/approvals", bytes.NewBufferString(body))
+	req.Header.Set(webhook.HeaderTimestamp, ts)
+	req.Header.Set(webhook.HeaderSignature, sig)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("callback: %v", err)
+	}
+	defer res.Body.Close()
+	var out map[string]string
+	_ = json.NewDecoder(res.Body).Decode(&out)
+	return res.StatusCode, out
+}
+
+func TestWebhookApprovalRoundTrip(t *testing.T) {
+	svc := newPolicyService(t, approvalPolicy)
+	callbackSecret := webhook.HMAC{Secret: []byte("cab-secret")}
+
+	// The stand-in change management tool checks the gateway's Ed25519 signature with
+	// the receipt public key and queues the request.
+	received := make(chan webhook.ApprovalRequest, 1)
+	tool := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifier := webhook.Ed25519Verifier{PublicKey: svc.PublicKey}
+		if err := verifier.Verify(webhook.PurposeApprovalRequest, r.Header.Get(webhook.HeaderSignature), r.Header.Get(webhook.HeaderTimestamp), body, time.Now()); err != nil {
+			t.Errorf("tool: %v", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(webhook.HeaderKeyID) != "test" {
+			t.Errorf("tool: unexpected key id %q", r.Header.Get(webhook.HeaderKeyID))
+		}
+		var req webhook.ApprovalRequest
+		_ = json.Unmarshal(body, &req)
+		received <- req
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer tool.Close()
+	svc.Webhook = &webhook.Client{URL: tool.URL, Signer: webhook.Ed25519Signer{Key: svc.Signer}, HTTP: tool.Client()}
+
+	gateway := httptest.NewServer(NewRouter(&Handler{AuthorizeService: svc, WebhookVerifier: callbackSecret}))
+	defer gateway.Close()
+
+	approvalID := requestApproval(t, svc, waitRequest)
+	var req webhook.ApprovalRequest
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the tool never received the approval request")
+	}
+	if req.ApprovalID != approvalID || req.Action != "terraform.apply" || req.Env != "prod" || req.ReceiptID == "" {
+		t.Fatalf("unexpected approval request: %+v", req)
+	}
+	svc.waitDeliveries()
+	if outbox, ok := svc.Ledger.GetOutbox("webhook:" + approvalID); !ok || outbox.Kind != "webhook" || outbox.Status != "sent" {
+		t.Fatalf("expected a sent webhook notification, got %+v ok=%v", outbox, ok)
+	}
+
+	code, out := postCallback(t, gateway.URL, callbackSecret, `{"approval_id":"`+approvalID+`","action":"approve","approver":{"id":"cab:jdoe","display":"Jane Doe"},"comment":"CHG0031 approved"}`)
+	if code != http.StatusOK || out["status"] != "approved" {
+		t.Fatalf("expected approved, got %d %v", code, out)
+	}
+	_, signed := signedApproval(t, svc, out["receipt_id"])
+	if signed.Approver == nil || signed.Approver.Kind != "webhook" || signed.Approver.ID != "cab:jdoe" || signed.Approver.Display != "Jane Doe" || signed.Approver.Comment != "CHG0031 approved" {
+		t.Fatalf("unexpected signed approver: %+v", signed.Approver)
+	}
+}
+
+func TestWebhookApprovalsRejects(t *testing.T) {
+	svc, approvalID := newPendingApproval(t, approvalPolicy, waitRequest)
+	secret := webhook.HMAC{Secret: []byte("cab-secret")}
+	gateway := httptest.NewServer(NewRouter(&Handler{AuthorizeService: svc, WebhookVerifier: secret}))
+	defer gateway.Close()
+
+	vote := `{"approval_id":"` + approvalID + `","action":"approve","approver":{"id":"cab:jdoe"}}`
+	if code, _ := postCallback(t, gateway.URL, webhook.HMAC{Secret: []byte("wrong")}, vote); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a bad signature, got %d", code)
+	}
+	if code, _ := postCallback(t, gateway.URL, secret, `{"approval_id":"missing","action":"approve","approver":{"id":"cab:jdoe"}}`); code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+	if code, _ := postCallback(t, gateway.URL, secret, `{"approval_id":"`+approvalID+`","action":"escalate","approver":{"id":"cab:jdoe"}}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown action, got %d", code)
+	}
+	if code, _ := postCallback(t, gateway.URL, secret, `{"approval_id":"`+approvalID+`","action":"approve"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without an approver, got %d", code)
+	}
+	if approval, _ := svc.GetApproval(approvalID); approval.Status != string(ApprovalPending) {
+		t.Fatalf("expected the approval still pending, got %s", approval.Status)
+	}
+
+	res, err := http.Get(gateway.URL + "/v1/webhooks/approvals")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", res.StatusCode)
+	}
+
+	unconfigured := httptest.NewServer(NewRouter(&Handler{AuthorizeService: svc}))
+	defer unconfigured.Close()
+	if code, _ := postCallback(t, unconfigured.URL, secret, vote); code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 without a verifier, got %d", code)
+	}
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+)
+
+// ErrAdminDisabled is returned when no admin token is configured.
+var ErrAdminDisabled = errors.New("admin api disabled: RELIA_ADMIN_TOKEN is not set")
+
+// AdminAuthenticator guards the /v1/admin endpoints with a token of their own. Workload
+// credentials are not accepted: a workflow that may request actions must not be able
+// to reload policy or requeue deliveries.
+type AdminAuthenticator struct {
+	Token string
+}
+
+func NewAdminAuthenticatorFromEnv() *AdminAuthenticator {
+	return &AdminAuthenticator{Token: os.Getenv("RELIA_ADMIN_TOKEN")}
+}
+
+func (a *AdminAuthenticator) AuthenticateAdmin(r *http.Request) error {
+	if a == nil || a.Token == "" {
+		return ErrAdminDisabled
+	}
+	bearer, err := extractBearer(r)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(bearer), []byte(a.Token)) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ApproverClaims identifies a person approving through the REST API. They come from an
+// SSO ID token, never from a workload token, so a workflow cannot approve itself.
+type ApproverClaims struct {
+	Issuer  string
+	Subject string
+	Email   string
+	Name    string
+	Groups  []string
+}
+
+// ID returns the approver ID recorded on votes and matched by policy approver
+// subjects: "<issuer>|<subject>".
+func (c ApproverClaims) ID() string {
+	return c.Issuer + "|" + c.Subject
+}
+
+type ApproverAuthenticator interface {
+	AuthenticateApprover(r *http.Request) (ApproverClaims, error)
+}
+
+// OIDCApproverAuthenticator verifies RS256 ID tokens from an SSO provider (Okta, Entra
+// ID, Google, Dex, ...). Issuer must equal the tokens' iss claim exactly, including any
+// trailing slash. JWKSURL is discovered from the issuer when empty.
+type OIDCApproverAuthenticator struct {
+	Audience string
+	Issuer   string
+	JWKSURL  string
+
+	http *http.Client
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+func NewOIDCApproverAuthenticator(issuer, audience string) *OIDCApproverAuthenticator {
+	if audience == "" {
+		audience = "relia-approvals"
+	}
+	return &OIDCApproverAuthenticator{
+		Audience: audience,
+		Issuer:   issuer,
+		http:     &http.Client{Timeout: 5 * time.Second},
+		keys:     make(map[string]*rsa.PublicKey),
+	}
+}
+
+// NewApproverAuthenticatorFromEnv returns an authenticator for RELIA_APPROVER_OIDC_ISSUER,
+// or nil when it is unset and API approvals are disabled.
+func NewApproverAuthenticatorFromEnv() *OIDCApproverAuthenticator {
+	issuer := os.Getenv("RELIA_APPROVER_OIDC_ISSUER")
+	if issuer == "" {
+		return nil
+	}
+	a := NewOIDCApproverAuthenticator(issuer, os.Getenv("RELIA_APPROVER_OIDC_AUDIENCE"))
+	a.JWKSURL = os.Getenv("RELIA_APPROVER_OIDC_JWKS_URL")
+	return a
+}
+
+func (a *OIDCApproverAuthenticator) AuthenticateApprover(r *http.Request) (ApproverClaims, error) {
+	bearer, err := extractBearer(r)
+	if err != nil {
+		return ApproverClaims{}, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithAudience(a.Audience),
+		jwt.WithIssuer(a.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	claims := &approverTokenClaims{}
+	_, err = parser.ParseWithClaims(bearer, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("missing kid")
+		}
+		return a.keyForKID(kid)
+	})
+	if err != nil || claims.Subject == "" {
+		return ApproverClaims{}, ErrInvalidToken
+	}
+
+	return ApproverClaims{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+		Name:    claims.Name,
+		Groups:  claims.Groups,
+	}, nil
+}
+
+func (a *OIDCApproverAuthenticator) keyForKID(kid string) (*rsa.PublicKey, error) {
+	a.mu.Lock()
+	if key, ok := a.keys[kid]; ok {
+		a.mu.Unlock()
+		return key, nil
+	}
+	a.mu.Unlock()
+
+	jwksURL, err := a.jwksURL()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := fetchJWKS(a.http, jwksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for k, v := range keys {
+		a.keys[k] = v
+	}
+	if key, ok := a.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("kid not found")
+}
+
+// jwksURL returns JWKSURL, reading jwks_uri from the issuer's discovery document the
+// first time when it is unset.
+func (a *OIDCApproverAuthenticator) jwksURL() (string, error) {
+	a.mu.Lock()
+	jwksURL := a.JWKSURL
+	a.mu.Unlock()
+	if jwksURL != "" {
+		return jwksURL, nil
+	}
+
+	res, err := a.http.Get(strings.TrimRight(a.Issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&discovery); err != nil {
+		return "", err
+	}
+	if discovery.JWKSURI == "" {
+		return "", fmt.Errorf("missing jwks_uri")
+	}
+
+	a.mu.Lock()
+	a.JWKSURL = discovery.JWKSURI
+	a.mu.Unlock()
+	return discovery.JWKSURI, nil
+}
+
+type approverTokenClaims struct {
+	jwt.RegisteredClaims
+
+	Email  string   `json:"email"`
+	Name   string   `json:"name"`
+	Groups []string `json:"groups"`
+}
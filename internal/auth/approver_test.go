@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestOIDCApproverAuthenticator_Discovery(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("gen rsa: %v", err)
+	}
+
+	var (
+		srv    *httptest.Server
+		issuer string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"issuer": issuer, "jwks_uri": srv.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		n := base64.RawURLEncoding.EncodeToString(priv.PublicKey.N.Bytes())
+		e := base64.RawURLEncoding.EncodeToString([]byte{1, 0, 1})
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{
+				{"kid": "sso", "kty": "RSA", "alg": "RS256", "use": "sig", "n": n, "e": e},
+			},
+		})
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+	// Some providers (Auth0, for one) issue tokens whose iss ends in a slash.
+	issuer = srv.URL + "/"
+
+	a := NewOIDCApproverAuthenticator(issuer, "")
+	a.http = srv.Client()
+
+	sign := func(claims approverTokenClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "sso"
+		signed, err := token.SignedString(priv)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return signed
+	}
+	now := time.Now().UTC()
+	valid := approverTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   "00u1",
+			Audience:  jwt.ClaimStrings{"relia-approvals"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Email:  "alice@example.com",
+		Groups: []string{"platform"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+sign(valid))
+	out, err := a.AuthenticateApprover(req)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if out.ID() != issuer+"|00u1" || out.Email != "alice@example.com" || len(out.Groups) != 1 {
+		t.Fatalf("unexpected claims: %+v", out)
+	}
+	if a.JWKSURL != srv.URL+"/keys" {
+		t.Fatalf("expected discovered jwks url, got %q", a.JWKSURL)
+	}
+
+	// A token for another audience (e.g. a workload token) is rejected.
+	other := valid
+	other.Audience = jwt.ClaimStrings{"relia"}
+	req.Header.Set("Authorization", "Bearer "+sign(other))
+	if _, err := a.AuthenticateApprover(req); err != ErrInvalidToken {
+		t.Fatalf("expected invalid token for wrong audience, got %v", err)
+	}
+
+	// The issuer is compared verbatim.
+	trimmed := valid
+	trimmed.Issuer = srv.URL
+	req.Header.Set("Authorization", "Bearer "+sign(trimmed))
+	if _, err := a.AuthenticateApprover(req); err != ErrInvalidToken {
+		t.Fatalf("expected invalid token for a different issuer string, got %v", err)
+	}
+
+	noExp := valid
+	noExp.ExpiresAt = nil
+	req.Header.Set("Authorization", "Bearer "+sign(noExp))
+	if _, err := a.AuthenticateApprover(req); err != ErrInvalidToken {
+		t.Fatalf("expected invalid token without exp, got %v", err)
+	}
+
+	req.Header.Del("Authorization")
+	if _, err := a.AuthenticateApprover(req); err != ErrMissingBearer {
+		t.Fatalf("expected missing bearer, got %v", err)
+	}
+}
+
+func TestNewApproverAuthenticatorFromEnv(t *testing.T) {
+	t.Setenv("RELIA_APPROVER_OIDC_ISSUER", "")
+	if a := NewApproverAuthenticatorFromEnv(); a != nil {
+		t.Fatalf("expected no authenticator without an issuer")
+	}
+	t.Setenv("RELIA_APPROVER_OIDC_ISSUER", "https://sso.example.com")
+	t.Setenv("RELIA_APPROVER_OIDC_AUDIENCE", "relia-portal")
+	t.Setenv("RELIA_APPROVER_OIDC_JWKS_URL", "https://sso.example.com/keys")
+	a := NewApproverAuthenticatorFromEnv()
+	if a == nil || a.Audience != "relia-portal" || a.JWKSURL != "https://sso.example.com/keys" {
+		t.Fatalf("unexpected authenticator: %+v", a)
+	}
+}
@@ -20,6 +20,11 @@ type Claims struct {
 	RunID    string
 	SHA      string
 	Token    string
+
+	// Optional GitHub OIDC claims used for policy matching.
+	Ref         string
+	Environment string
+	Actor       string
 }
 
 type Authenticator interface {
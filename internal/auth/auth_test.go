@@ -76,3 +76,25 @@ func TestAuthenticateEmptyBearer(t *testing.T) {
 		t.Fatalf("expected ErrInvalidToken, got %v", err)
 	}
 }
+
+func TestAuthenticateAdmin(t *testing.T) {
+	t.Setenv("RELIA_ADMIN_TOKEN", "admin-token")
+	admin := NewAdminAuthenticatorFromEnv()
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	if err := admin.AuthenticateAdmin(req); err != ErrMissingBearer {
+		t.Fatalf("expected ErrMissingBearer, got %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer test-token")
+	if err := admin.AuthenticateAdmin(req); err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer admin-token")
+	if err := admin.AuthenticateAdmin(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := (&AdminAuthenticator{}).AuthenticateAdmin(req); err != ErrAdminDisabled {
+		t.Fatalf("expected ErrAdminDisabled, got %v", err)
+	}
+}
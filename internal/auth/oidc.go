@@ -87,6 +87,10 @@ func (a *GitHubOIDCAuthenticator) AuthenticateBearer(token string) (Claims, erro
 		Workflow: workflow,
 		RunID:    claims.RunID,
 		SHA:      claims.SHA,
+
+		Ref:         claims.Ref,
+		Environment: claims.Environment,
+		Actor:       claims.Actor,
 	}, nil
 }
 
@@ -98,7 +102,7 @@ func (a *GitHubOIDCAuthenticator) keyForKID(kid string) (*rsa.PublicKey, error)
 	}
 	a.mu.Unlock()
 
-	keys, err := a.fetchKeys()
+	keys, err := fetchJWKS(a.http, a.JWKSURL)
 	if err != nil {
 		return nil, err
 	}
@@ -114,12 +118,13 @@ func (a *GitHubOIDCAuthenticator) keyForKID(kid string) (*rsa.PublicKey, error)
 	return nil, fmt.Errorf("kid not found")
 }
 
-func (a *GitHubOIDCAuthenticator) fetchKeys() (map[string]*rsa.PublicKey, error) {
-	req, err := http.NewRequest(http.MethodGet, a.JWKSURL, nil)
+// fetchJWKS fetches the RS256 keys published at jwksURL, by kid.
+func fetchJWKS(client *http.Client, jwksURL string) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequest(http.MethodGet, jwksURL, nil)
 	if err != nil {
 		return nil, err
 	}
-	res, err := a.http.Do(req)
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -192,6 +197,10 @@ type githubClaims struct {
 
 	RunID string `json:"run_id"`
 	SHA   string `json:"sha"`
+
+	Ref         string `json:"ref"`
+	Environment string `json:"environment"`
+	Actor       string `json:"actor"`
 }
 
 func firstNonEmpty(values ...string) string {
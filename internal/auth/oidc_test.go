@@ -53,6 +53,9 @@ func TestGitHubOIDCAuthenticator_SuccessAndCache(t *testing.T) {
 		SHA:            "abcdef",
 		Workflow:       "",
 		JobWorkflowRef: "",
+		Ref:            "refs/heads/main",
+		Environment:    "production",
+		Actor:          "octocat",
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
 	token.Header["kid"] = kid
@@ -68,6 +71,9 @@ func TestGitHubOIDCAuthenticator_SuccessAndCache(t *testing.T) {
 	if out.Repo != "org/repo" || out.Workflow != "terraform-prod" || out.RunID != "123" || out.SHA != "abcdef" {
 		t.Fatalf("unexpected claims: %+v", out)
 	}
+	if out.Ref != "refs/heads/main" || out.Environment != "production" || out.Actor != "octocat" {
+		t.Fatalf("unexpected optional claims: %+v", out)
+	}
 	if jwksCalls != 1 {
 		t.Fatalf("expected 1 jwks call, got %d", jwksCalls)
 	}
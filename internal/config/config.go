@@ -4,17 +4,25 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	ListenAddr string           `yaml:"listen_addr"`
-	DB         DBConfig         `yaml:"db"`
-	PolicyPath string           `yaml:"policy_path"`
-	SigningKey SigningKeyConfig `yaml:"signing_key"`
-	Slack      SlackConfig      `yaml:"slack"`
-	AWS        AWSConfig        `yaml:"aws"`
+	ListenAddr string   `yaml:"listen_addr"`
+	DB         DBConfig `yaml:"db"`
+	PolicyPath string   `yaml:"policy_path"`
+	// PublicURL is the gateway's externally reachable base URL (e.g. "https://relia.example.com"),
+	// used to link receipts from Slack messages.
+	PublicURL string `yaml:"public_url"`
+	// PolicyReloadInterval polls policy_path for changes (e.g. "10s"); empty disables polling.
+	PolicyReloadInterval string           `yaml:"policy_reload_interval"`
+	SigningKey           SigningKeyConfig `yaml:"signing_key"`
+	Slack                SlackConfig      `yaml:"slack"`
+	Teams                TeamsConfig      `yaml:"teams"`
+	Webhook              WebhookConfig    `yaml:"webhook"`
+	AWS                  AWSConfig        `yaml:"aws"`
 }
 
 type DBConfig struct {
@@ -36,6 +44,31 @@ type SlackConfig struct {
 	ApprovalChannel string `yaml:"approval_channel"`
 }
 
+type TeamsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// WebhookURL is the approval channel's incoming webhook URL.
+	WebhookURL string `yaml:"webhook_url"`
+	// Channels maps the aliases that policy rules name in approval_channel to incoming
+	// webhook URLs, so the URLs stay out of policy.
+	Channels map[string]string `yaml:"channels"`
+	// SecurityToken is the outgoing webhook's security token, used to verify votes.
+	SecurityToken string `yaml:"security_token"`
+}
+
+// WebhookConfig sends approval requests to, and takes votes from, an external approval
+// system such as a change management tool.
+type WebhookConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	URL     string `yaml:"url"`
+	// Signing is "hmac" (the default), signing with HMACSecret, or "ed25519", signing
+	// with the gateway's signing key.
+	Signing    string `yaml:"signing"`
+	HMACSecret string `yaml:"hmac_secret"`
+	// CallbackPublicKeyPath verifies callbacks signed with Ed25519; without it callbacks
+	// are verified with HMACSecret.
+	CallbackPublicKeyPath string `yaml:"callback_public_key_path"`
+}
+
 type AWSConfig struct {
 	STSRegionDefault string `yaml:"sts_region_default"`
 }
@@ -64,10 +97,41 @@ func (c Config) Validate() error {
 	if c.PolicyPath == "" {
 		return fmt.Errorf("policy_path is required")
 	}
+	if c.PolicyReloadInterval != "" {
+		if d, err := time.ParseDuration(c.PolicyReloadInterval); err != nil || d <= 0 {
+			return fmt.Errorf("policy_reload_interval must be a positive duration")
+		}
+	}
 
 	if c.Slack.Enabled && c.Slack.SigningSecret == "" {
 		return fmt.Errorf("slack.signing_secret is required when slack.enabled=true")
 	}
+	if c.Teams.Enabled && c.Teams.SecurityToken == "" {
+		return fmt.Errorf("teams.security_token is required when teams.enabled=true")
+	}
+	for alias, url := range c.Teams.Channels {
+		if alias == "" || url == "" {
+			return fmt.Errorf("teams.channels needs an alias and a webhook URL for every entry")
+		}
+	}
+
+	if c.Webhook.Enabled {
+		if c.Webhook.URL == "" {
+			return fmt.Errorf("webhook.url is required when webhook.enabled=true")
+		}
+		switch c.Webhook.Signing {
+		case "", "hmac":
+			if c.Webhook.HMACSecret == "" {
+				return fmt.Errorf("webhook.hmac_secret is required for hmac signing")
+			}
+		case "ed25519":
+			if c.SigningKey.PrivateKeyPath == "" {
+				return fmt.Errorf("signing_key.private_key_path is required for ed25519 webhook signing")
+			}
+		default:
+			return fmt.Errorf("webhook.signing must be hmac or ed25519")
+		}
+	}
 
 	if c.DB.Driver != "" && c.DB.DSN == "" {
 		return fmt.Errorf("db.dsn is required when db.driver is set")
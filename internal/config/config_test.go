@@ -46,6 +46,46 @@ func TestValidateSlackRequiresSecret(t *testing.T) {
 	}
 }
 
+func TestValidateTeamsRequiresSecurityToken(t *testing.T) {
+	cfg := Config{ListenAddr: ":8080", PolicyPath: "policies/relia.yaml", Teams: TeamsConfig{Enabled: true, WebhookURL: "https://example.com/hook"}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestValidateTeamsChannels(t *testing.T) {
+	cfg := Config{ListenAddr: ":8080", PolicyPath: "policies/relia.yaml", Teams: TeamsConfig{Channels: map[string]string{"platform": "https://example.com/hook"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.Teams.Channels["data"] = ""
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected error for a channel without a webhook URL")
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	base := Config{ListenAddr: ":8080", PolicyPath: "policies/relia.yaml"}
+	cases := map[string]WebhookConfig{
+		"missing url":         {Enabled: true, HMACSecret: "s"},
+		"missing secret":      {Enabled: true, URL: "https://example.com/hook"},
+		"ed25519 without key": {Enabled: true, URL: "https://example.com/hook", Signing: "ed25519"},
+		"unknown signing":     {Enabled: true, URL: "https://example.com/hook", Signing: "rsa"},
+	}
+	for name, webhook := range cases {
+		cfg := base
+		cfg.Webhook = webhook
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+
+	base.Webhook = WebhookConfig{Enabled: true, URL: "https://example.com/hook", HMACSecret: "s"}
+	if err := base.Validate(); err != nil {
+		t.Fatalf("expected valid hmac webhook, got %v", err)
+	}
+}
+
 func TestValidateDBRequiresDSN(t *testing.T) {
 	cfg := Config{ListenAddr: ":8080", PolicyPath: "policies/relia.yaml", DB: DBConfig{Driver: "sqlite"}}
 	if err := cfg.Validate(); err == nil {
@@ -58,3 +98,16 @@ func TestLoadMissingFile(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestValidatePolicyReloadInterval(t *testing.T) {
+	cfg := Config{ListenAddr: ":8080", PolicyPath: "policies/relia.yaml", PolicyReloadInterval: "10s"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid interval: %v", err)
+	}
+	for _, raw := range []string{"soon", "0s", "-5s"} {
+		cfg.PolicyReloadInterval = raw
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
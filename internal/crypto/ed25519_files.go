@@ -39,6 +39,27 @@ func LoadEd25519PrivateKey(path string) (ed25519.PrivateKey, ed25519.PublicKey,
 	}
 }
 
+// LoadEd25519PublicKey loads an Ed25519 public key from a file.
+// Supported formats:
+// - raw 32-byte public key
+// - hex or base64 encoding (as written by `relia keys gen --public`)
+func LoadEd25519PublicKey(path string) (ed25519.PublicKey, error) {
+	// #nosec G304 -- path is operator-provided.
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodeBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("unsupported public key length: %d", len(data))
+	}
+	return ed25519.PublicKey(data), nil
+}
+
 func decodeBytes(raw []byte) ([]byte, error) {
 	trim := strings.TrimSpace(string(raw))
 	if trim == "" {
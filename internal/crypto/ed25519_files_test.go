@@ -54,3 +54,32 @@ func TestDecodeBytesErrors(t *testing.T) {
 		t.Fatalf("expected error for unrecognized encoding")
 	}
 }
+
+func TestLoadEd25519PublicKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	pub := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+
+	dir := t.TempDir()
+	hexPath := filepath.Join(dir, "pub.hex")
+	if err := os.WriteFile(hexPath, []byte("hex:"+hex.EncodeToString(pub)+"\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, err := LoadEd25519PublicKey(hexPath)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !got.Equal(pub) {
+		t.Fatalf("public key mismatch")
+	}
+
+	shortPath := filepath.Join(dir, "pub.short")
+	if err := os.WriteFile(shortPath, []byte("hex:abcd"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := LoadEd25519PublicKey(shortPath); err == nil {
+		t.Fatalf("expected length error")
+	}
+	if _, err := LoadEd25519PublicKey(filepath.Join(dir, "missing")); err == nil {
+		t.Fatalf("expected read error")
+	}
+}
@@ -7,8 +7,10 @@ import (
 
 const DecisionSchema = "relia.decision.v0.1"
 
-// BuildDecision builds a decision record and computes its decision_id.
-func BuildDecision(contextID string, policy types.DecisionPolicy, verdict string, reasonCodes []string, requiresApproval bool, risk string, createdAt string) (types.DecisionRecord, error) {
+// BuildDecision builds a decision record and computes its decision_id. matchedRules
+// lists the policy rules that applied under all_matching evaluation (nil otherwise); it
+// is hashed only when non-empty, so first-match records keep their IDs.
+func BuildDecision(contextID string, policy types.DecisionPolicy, verdict string, reasonCodes []string, matchedRules []string, requiresApproval bool, risk string, createdAt string) (types.DecisionRecord, error) {
 	record := types.DecisionRecord{
 		Schema:           DecisionSchema,
 		CreatedAt:        createdAt,
@@ -16,6 +18,7 @@ func BuildDecision(contextID string, policy types.DecisionPolicy, verdict string
 		Policy:           policy,
 		Verdict:          verdict,
 		ReasonCodes:      reasonCodes,
+		MatchedRules:     matchedRules,
 		RequiresApproval: requiresApproval,
 		Risk:             risk,
 	}
@@ -34,6 +37,9 @@ func BuildDecision(contextID string, policy types.DecisionPolicy, verdict string
 		"requires_approval": record.RequiresApproval,
 		"risk":              record.Risk,
 	}
+	if len(record.MatchedRules) > 0 {
+		signingView["matched_rules"] = record.MatchedRules
+	}
 
 	canonical, err := crypto.Canonicalize(signingView)
 	if err != nil {
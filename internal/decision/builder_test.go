@@ -13,12 +13,12 @@ func TestBuildDecisionDeterministicID(t *testing.T) {
 		PolicyHash:    "sha256:policy",
 	}
 
-	recA, err := BuildDecision("sha256:ctx", policy, "allow", []string{"POLICY_MATCH:rule"}, false, "low", "2025-12-20T16:34:13Z")
+	recA, err := BuildDecision("sha256:ctx", policy, "allow", []string{"POLICY_MATCH:rule"}, nil, false, "low", "2025-12-20T16:34:13Z")
 	if err != nil {
 		t.Fatalf("build decision: %v", err)
 	}
 
-	recB, err := BuildDecision("sha256:ctx", policy, "allow", []string{"POLICY_MATCH:rule"}, false, "low", "2025-12-20T16:34:13Z")
+	recB, err := BuildDecision("sha256:ctx", policy, "allow", []string{"POLICY_MATCH:rule"}, nil, false, "low", "2025-12-20T16:34:13Z")
 	if err != nil {
 		t.Fatalf("build decision: %v", err)
 	}
@@ -30,7 +30,7 @@ func TestBuildDecisionDeterministicID(t *testing.T) {
 		t.Fatalf("decision id not deterministic")
 	}
 
-	recC, err := BuildDecision("sha256:ctx", policy, "allow", []string{"POLICY_MATCH:rule"}, false, "high", "2025-12-20T16:34:13Z")
+	recC, err := BuildDecision("sha256:ctx", policy, "allow", []string{"POLICY_MATCH:rule"}, nil, false, "high", "2025-12-20T16:34:13Z")
 	if err != nil {
 		t.Fatalf("build decision: %v", err)
 	}
@@ -38,3 +38,30 @@ func TestBuildDecisionDeterministicID(t *testing.T) {
 		t.Fatalf("decision id should change when risk changes")
 	}
 }
+
+func TestBuildDecisionMatchedRules(t *testing.T) {
+	policy := types.DecisionPolicy{PolicyID: "relia-default", PolicyHash: "sha256:policy"}
+
+	without, err := BuildDecision("sha256:ctx", policy, "allow", nil, nil, false, "", "2025-12-20T16:34:13Z")
+	if err != nil {
+		t.Fatalf("build decision: %v", err)
+	}
+	empty, err := BuildDecision("sha256:ctx", policy, "allow", nil, []string{}, false, "", "2025-12-20T16:34:13Z")
+	if err != nil {
+		t.Fatalf("build decision: %v", err)
+	}
+	if without.DecisionID != empty.DecisionID {
+		t.Fatalf("empty matched rules should not change the decision id")
+	}
+
+	with, err := BuildDecision("sha256:ctx", policy, "allow", nil, []string{"a", "b"}, false, "", "2025-12-20T16:34:13Z")
+	if err != nil {
+		t.Fatalf("build decision: %v", err)
+	}
+	if with.DecisionID == without.DecisionID {
+		t.Fatalf("matched rules should be covered by the decision id")
+	}
+	if len(with.MatchedRules) != 2 {
+		t.Fatalf("matched rules not recorded: %+v", with)
+	}
+}
@@ -21,6 +21,7 @@ type Input struct {
 }
 
 type receiptBody struct {
+	Schema          string                        `json:"schema"`
 	Policy          types.ReceiptPolicy           `json:"policy"`
 	Approval        *types.ReceiptApproval        `json:"approval,omitempty"`
 	CredentialGrant *types.ReceiptCredentialGrant `json:"credential_grant,omitempty"`
@@ -35,6 +36,11 @@ func Evaluate(in Input) Result {
 	var body receiptBody
 	_ = json.Unmarshal(in.Receipt.BodyJSON, &body)
 
+	// Both v0.1 and v0.2 receipts are graded the same way; anything else is unverifiable.
+	if body.Schema != "" && !ledger.KnownReceiptSchema(body.Schema) {
+		return Result{Grade: "F", Reasons: []string{"unsupported_schema"}}
+	}
+
 	missing := map[string]bool{}
 
 	if body.Policy.PolicyHash == "" && in.Receipt.PolicyHash == "" {
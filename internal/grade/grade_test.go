@@ -44,3 +44,20 @@ func TestEvaluateHeuristics(t *testing.T) {
 		t.Fatalf("expected D, got %s reasons=%v", got.Grade, got.Reasons)
 	}
 }
+
+func TestEvaluateReceiptSchemas(t *testing.T) {
+	body := `"policy":{"policy_hash":"sha256:x"},"credential_grant":{"role_arn":"arn:aws:iam::123:role/test","ttl_seconds":900}`
+	for _, schema := range []string{ledger.ReceiptSchemaV01, ledger.ReceiptSchemaV02} {
+		receipt := ledger.StoredReceipt{BodyJSON: []byte(`{"schema":"` + schema + `",` + body + `}`)}
+		got := Evaluate(Input{Valid: true, Receipt: receipt})
+		if got.Grade != "A" {
+			t.Fatalf("%s: expected A, got %s reasons=%v", schema, got.Grade, got.Reasons)
+		}
+	}
+
+	receipt := ledger.StoredReceipt{BodyJSON: []byte(`{"schema":"relia.receipt.v9",` + body + `}`)}
+	got := Evaluate(Input{Valid: true, Receipt: receipt})
+	if got.Grade != "F" || len(got.Reasons) != 1 || got.Reasons[0] != "unsupported_schema" {
+		t.Fatalf("expected F unsupported_schema, got %s reasons=%v", got.Grade, got.Reasons)
+	}
+}
@@ -0,0 +1,69 @@
+package ledger
+
+import "errors"
+
+var ErrReceiptNotFound = errors.New("receipt not found")
+
+// ReceiptChain returns the receipts linked to receiptID through supersedes_receipt_id,
+// ordered from the first receipt of the intent to the latest. It walks backward from
+// receiptID to the root and forward through every receipt that supersedes it.
+func ReceiptChain(store Store, receiptID string) ([]ReceiptRecord, error) {
+	start, ok := store.GetReceipt(receiptID)
+	if !ok {
+		return nil, ErrReceiptNotFound
+	}
+
+	siblings, err := store.ListReceiptsByIdemKey(start.IdemKey)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]ReceiptRecord{start.ReceiptID: start}
+	successor := map[string]ReceiptRecord{}
+	for _, rec := range siblings {
+		byID[rec.ReceiptID] = rec
+		if rec.SupersedesReceiptID == nil {
+			continue
+		}
+		// Siblings are oldest first; keep the earliest successor if the chain forks.
+		if _, exists := successor[*rec.SupersedesReceiptID]; !exists {
+			successor[*rec.SupersedesReceiptID] = rec
+		}
+	}
+
+	seen := map[string]bool{start.ReceiptID: true}
+	backward := []ReceiptRecord{}
+	cur := start
+	for cur.SupersedesReceiptID != nil && *cur.SupersedesReceiptID != "" {
+		prevID := *cur.SupersedesReceiptID
+		if seen[prevID] {
+			break
+		}
+		prev, ok := byID[prevID]
+		if !ok {
+			if prev, ok = store.GetReceipt(prevID); !ok {
+				break
+			}
+		}
+		seen[prevID] = true
+		backward = append(backward, prev)
+		cur = prev
+	}
+
+	chain := make([]ReceiptRecord, 0, len(backward)+1)
+	for i := len(backward) - 1; i >= 0; i-- {
+		chain = append(chain, backward[i])
+	}
+	chain = append(chain, start)
+
+	cur = start
+	for {
+		next, ok := successor[cur.ReceiptID]
+		if !ok || seen[next.ReceiptID] {
+			break
+		}
+		seen[next.ReceiptID] = true
+		chain = append(chain, next)
+		cur = next
+	}
+	return chain, nil
+}
@@ -0,0 +1,60 @@
+package ledger
+
+import "testing"
+
+func TestReceiptChainWalksBothDirections(t *testing.T) {
+	s := NewInMemoryStore()
+
+	r1, r2, r3 := "r1", "r2", "r3"
+	records := []ReceiptRecord{
+		{ReceiptID: r1, IdemKey: "i1", CreatedAt: "2025-12-20T00:00:00Z"},
+		{ReceiptID: r2, IdemKey: "i1", CreatedAt: "2025-12-20T00:00:01Z", SupersedesReceiptID: &r1},
+		// Same second as r2: ordering must come from the links, not timestamps.
+		{ReceiptID: r3, IdemKey: "i1", CreatedAt: "2025-12-20T00:00:01Z", SupersedesReceiptID: &r2},
+		{ReceiptID: "other", IdemKey: "i2", CreatedAt: "2025-12-20T00:00:00Z"},
+	}
+	for _, rec := range records {
+		if err := s.PutReceipt(rec); err != nil {
+			t.Fatalf("put receipt: %v", err)
+		}
+	}
+
+	list, err := s.ListReceiptsByIdemKey("i1")
+	if err != nil || len(list) != 3 || list[0].ReceiptID != r1 {
+		t.Fatalf("list by idem: err=%v list=%+v", err, list)
+	}
+
+	for _, start := range []string{r1, r2, r3} {
+		chain, err := ReceiptChain(s, start)
+		if err != nil {
+			t.Fatalf("chain from %s: %v", start, err)
+		}
+		got := []string{}
+		for _, rec := range chain {
+			got = append(got, rec.ReceiptID)
+		}
+		if len(got) != 3 || got[0] != r1 || got[1] != r2 || got[2] != r3 {
+			t.Fatalf("chain from %s: got %v", start, got)
+		}
+	}
+
+	if _, err := ReceiptChain(s, "missing"); err != ErrReceiptNotFound {
+		t.Fatalf("expected ErrReceiptNotFound, got %v", err)
+	}
+}
+
+func TestReceiptChainStopsOnCycle(t *testing.T) {
+	s := NewInMemoryStore()
+
+	a, b := "a", "b"
+	_ = s.PutReceipt(ReceiptRecord{ReceiptID: a, IdemKey: "i1", SupersedesReceiptID: &b})
+	_ = s.PutReceipt(ReceiptRecord{ReceiptID: b, IdemKey: "i1", SupersedesReceiptID: &a})
+
+	chain, err := ReceiptChain(s, a)
+	if err != nil {
+		t.Fatalf("chain: %v", err)
+	}
+	if len(chain) != 2 {
+		t.Fatalf("expected 2 receipts, got %d", len(chain))
+	}
+}
@@ -1,12 +1,15 @@
 package ledger
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 type InMemoryStore struct {
 	mu sync.Mutex
 
 	keys      map[string]KeyRecord
-	outbox    map[string]SlackOutboxRecord
+	outbox    map[string]OutboxRecord
 	policies  map[string]PolicyVersionRecord
 	contexts  map[string]ContextRecord
 	decisions map[string]DecisionRecord
@@ -18,7 +21,7 @@ type InMemoryStore struct {
 func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
 		keys:      make(map[string]KeyRecord),
-		outbox:    make(map[string]SlackOutboxRecord),
+		outbox:    make(map[string]OutboxRecord),
 		policies:  make(map[string]PolicyVersionRecord),
 		contexts:  make(map[string]ContextRecord),
 		decisions: make(map[string]DecisionRecord),
@@ -50,39 +53,106 @@ func (s *InMemoryStore) GetKey(keyID string) (KeyRecord, bool) {
 	return key, ok
 }
 
-func (s *InMemoryStore) PutSlackOutbox(rec SlackOutboxRecord) error {
+func (s *InMemoryStore) PutOutbox(rec OutboxRecord) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.outbox[rec.NotificationID] = rec
+	s.outbox[rec.OutboxID] = rec
 	return nil
 }
 
-func (s *InMemoryStore) GetSlackOutbox(notificationID string) (SlackOutboxRecord, bool) {
+func (s *InMemoryStore) GetOutbox(outboxID string) (OutboxRecord, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	rec, ok := s.outbox[notificationID]
+	rec, ok := s.outbox[outboxID]
 	return rec, ok
 }
 
-func (s *InMemoryStore) ListSlackOutboxDue(now string, limit int) ([]SlackOutboxRecord, error) {
+func (s *InMemoryStore) LeaseOutboxDue(kind string, lease OutboxLease, limit int) ([]OutboxRecord, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	out := []SlackOutboxRecord{}
+	due := []OutboxRecord{}
 	for _, rec := range s.outbox {
-		if rec.Status != "pending" {
-			continue
+		if rec.Kind == kind && outboxLeasable(rec, lease.Now) {
+			due = append(due, rec)
 		}
-		if rec.NextAttemptAt > now {
+	}
+	sortOutbox(due, func(rec OutboxRecord) string { return rec.NextAttemptAt })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i] = leaseOutbox(due[i], lease)
+		s.outbox[due[i].OutboxID] = due[i]
+	}
+	return due, nil
+}
+
+func (s *InMemoryStore) LeaseOutbox(outboxID string, lease OutboxLease) (OutboxRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.outbox[outboxID]
+	if !ok || !outboxLeasable(rec, lease.Now) {
+		return OutboxRecord{}, false, nil
+	}
+	rec = leaseOutbox(rec, lease)
+	s.outbox[outboxID] = rec
+	return rec, true, nil
+}
+
+// outboxLeasable reports whether rec is pending, due at now and not leased.
+func outboxLeasable(rec OutboxRecord, now string) bool {
+	if rec.Status != "pending" || rec.NextAttemptAt > now {
+		return false
+	}
+	return rec.LeaseExpiresAt == nil || *rec.LeaseExpiresAt <= now
+}
+
+func leaseOutbox(rec OutboxRecord, lease OutboxLease) OutboxRecord {
+	owner, token, until := lease.Owner, lease.Token, lease.Until
+	rec.LeaseOwner = &owner
+	rec.LeaseToken = &token
+	rec.LeaseExpiresAt = &until
+	return rec
+}
+
+func (s *InMemoryStore) SettleOutbox(rec OutboxRecord, leaseToken string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.outbox[rec.OutboxID]
+	if !ok || current.LeaseToken == nil || *current.LeaseToken != leaseToken {
+		return false, nil
+	}
+	s.outbox[rec.OutboxID] = rec
+	return true, nil
+}
+
+func (s *InMemoryStore) ListOutbox(status string, limit int) ([]OutboxRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []OutboxRecord{}
+	for _, rec := range s.outbox {
+		if status != "" && rec.Status != status {
 			continue
 		}
 		out = append(out, rec)
-		if limit > 0 && len(out) >= limit {
-			break
-		}
+	}
+	sortOutbox(out, func(rec OutboxRecord) string { return rec.CreatedAt })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
 	}
 	return out, nil
 }
 
+// sortOutbox orders records by key, then by ID.
+func sortOutbox(recs []OutboxRecord, key func(OutboxRecord) string) {
+	sort.Slice(recs, func(i, j int) bool {
+		if a, b := key(recs[i]), key(recs[j]); a != b {
+			return a < b
+		}
+		return recs[i].OutboxID < recs[j].OutboxID
+	})
+}
+
 func (s *InMemoryStore) PutPolicyVersion(policy PolicyVersionRecord) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -111,6 +181,27 @@ func (s *InMemoryStore) GetContext(contextID string) (ContextRecord, bool) {
 	return ctx, ok
 }
 
+func (s *InMemoryStore) ListContexts(since string, limit int) ([]ContextRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []ContextRecord{}
+	for _, rec := range s.contexts {
+		if rec.CreatedAt >= since {
+			out = append(out, rec)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return out[i].ContextID < out[j].ContextID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
 func (s *InMemoryStore) PutDecision(decision DecisionRecord) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -139,6 +230,24 @@ func (s *InMemoryStore) GetReceipt(receiptID string) (ReceiptRecord, bool) {
 	return receipt, ok
 }
 
+func (s *InMemoryStore) ListReceiptsByIdemKey(idemKey string) ([]ReceiptRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []ReceiptRecord{}
+	for _, rec := range s.receipts {
+		if rec.IdemKey == idemKey {
+			out = append(out, rec)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return out[i].ReceiptID < out[j].ReceiptID
+	})
+	return out, nil
+}
+
 func (s *InMemoryStore) PutApproval(approval ApprovalRecord) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -146,6 +255,20 @@ func (s *InMemoryStore) PutApproval(approval ApprovalRecord) error {
 	return nil
 }
 
+func (s *InMemoryStore) SetApprovalSlackMessage(approvalID, channel, msgTS, updatedAt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	approval, ok := s.approvals[approvalID]
+	if !ok {
+		return nil
+	}
+	approval.SlackChannel = &channel
+	approval.SlackMsgTS = &msgTS
+	approval.UpdatedAt = updatedAt
+	s.approvals[approvalID] = approval
+	return nil
+}
+
 func (s *InMemoryStore) GetApproval(approvalID string) (ApprovalRecord, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -164,6 +287,50 @@ func (s *InMemoryStore) GetApprovalByIdemKey(idemKey string) (ApprovalRecord, bo
 	return ApprovalRecord{}, false
 }
 
+func (s *InMemoryStore) ListExpiredApprovals(now string, limit int) ([]ApprovalRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []ApprovalRecord{}
+	for _, approval := range s.approvals {
+		if approval.Status != "pending" || approval.ExpiresAt == nil || *approval.ExpiresAt > now {
+			continue
+		}
+		out = append(out, approval)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if *out[i].ExpiresAt != *out[j].ExpiresAt {
+			return *out[i].ExpiresAt < *out[j].ExpiresAt
+		}
+		return out[i].ApprovalID < out[j].ApprovalID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *InMemoryStore) ListApprovals(status string, limit int) ([]ApprovalRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []ApprovalRecord{}
+	for _, approval := range s.approvals {
+		if status != "" && approval.Status != status {
+			continue
+		}
+		out = append(out, approval)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt != out[j].CreatedAt {
+			return out[i].CreatedAt < out[j].CreatedAt
+		}
+		return out[i].ApprovalID < out[j].ApprovalID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
 func (s *InMemoryStore) PutIdempotencyKey(key IdempotencyKey) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -193,13 +360,13 @@ func (t *memTx) GetKey(keyID string) (KeyRecord, bool) {
 	return key, ok
 }
 
-func (t *memTx) PutSlackOutbox(rec SlackOutboxRecord) error {
-	(*InMemoryStore)(t).outbox[rec.NotificationID] = rec
+func (t *memTx) PutOutbox(rec OutboxRecord) error {
+	(*InMemoryStore)(t).outbox[rec.OutboxID] = rec
 	return nil
 }
 
-func (t *memTx) GetSlackOutbox(notificationID string) (SlackOutboxRecord, bool) {
-	rec, ok := (*InMemoryStore)(t).outbox[notificationID]
+func (t *memTx) GetOutbox(outboxID string) (OutboxRecord, bool) {
+	rec, ok := (*InMemoryStore)(t).outbox[outboxID]
 	return rec, ok
 }
 
@@ -248,6 +415,12 @@ func (t *memTx) GetApproval(approvalID string) (ApprovalRecord, bool) {
 	return approval, ok
 }
 
+// LockApproval needs no lock of its own: WithTx holds the store's mutex.
+func (t *memTx) LockApproval(approvalID string) (ApprovalRecord, bool, error) {
+	approval, ok := t.GetApproval(approvalID)
+	return approval, ok, nil
+}
+
 func (t *memTx) GetApprovalByIdemKey(idemKey string) (ApprovalRecord, bool) {
 	for _, approval := range (*InMemoryStore)(t).approvals {
 		if approval.IdemKey == idemKey {
@@ -16,25 +16,55 @@ func TestInMemoryStore_CRUD(t *testing.T) {
 		t.Fatalf("get key mismatch: ok=%v got=%+v", ok, got)
 	}
 
-	outbox := SlackOutboxRecord{
-		NotificationID: "n1",
-		ApprovalID:     "a1",
-		Channel:        "C1",
-		MessageJSON:    []byte(`{"approval_id":"a1"}`),
-		Status:         "pending",
-		AttemptCount:   0,
-		NextAttemptAt:  "now",
-		CreatedAt:      "now",
-		UpdatedAt:      "now",
-	}
-	if err := s.PutSlackOutbox(outbox); err != nil {
+	outbox := OutboxRecord{
+		OutboxID:      "n1",
+		Kind:          "slack_post",
+		ApprovalID:    "a1",
+		Target:        "C1",
+		PayloadJSON:   []byte(`{"approval_id":"a1"}`),
+		Status:        "pending",
+		AttemptCount:  0,
+		MaxAttempts:   3,
+		NextAttemptAt: "now",
+		CreatedAt:     "now",
+		UpdatedAt:     "now",
+	}
+	if err := s.PutOutbox(outbox); err != nil {
 		t.Fatalf("put outbox: %v", err)
 	}
-	if got, ok := s.GetSlackOutbox("n1"); !ok || got.ApprovalID != "a1" {
+	if got, ok := s.GetOutbox("n1"); !ok || got.ApprovalID != "a1" {
 		t.Fatalf("get outbox mismatch: ok=%v got=%+v", ok, got)
 	}
-	if due, err := s.ListSlackOutboxDue("now", 10); err != nil || len(due) != 1 {
-		t.Fatalf("list due mismatch: err=%v len=%d", err, len(due))
+	if due, err := s.LeaseOutboxDue("teams_post", OutboxLease{Owner: "w1", Token: "t1", Now: "now", Until: "now+1"}, 10); err != nil || len(due) != 0 {
+		t.Fatalf("expected no teams records: err=%v len=%d", err, len(due))
+	}
+	due, err := s.LeaseOutboxDue("slack_post", OutboxLease{Owner: "w1", Token: "t1", Now: "now", Until: "now+1"}, 10)
+	if err != nil || len(due) != 1 || due[0].LeaseOwner == nil || *due[0].LeaseOwner != "w1" || *due[0].LeaseToken != "t1" {
+		t.Fatalf("lease due mismatch: err=%v due=%+v", err, due)
+	}
+	if due, err := s.LeaseOutboxDue("slack_post", OutboxLease{Owner: "w2", Token: "t2", Now: "now", Until: "now+1"}, 10); err != nil || len(due) != 0 {
+		t.Fatalf("expected the lease to hold off other workers: err=%v len=%d", err, len(due))
+	}
+	if _, ok, err := s.LeaseOutbox("n1", OutboxLease{Owner: "w2", Token: "t2", Now: "now", Until: "now+1"}); err != nil || ok {
+		t.Fatalf("expected the lease to hold off a claim by id: ok=%v err=%v", ok, err)
+	}
+	if due, err := s.LeaseOutboxDue("slack_post", OutboxLease{Owner: "w2", Token: "t2", Now: "now+2", Until: "now+3"}, 10); err != nil || len(due) != 1 {
+		t.Fatalf("expected a lapsed lease to be reclaimed: err=%v len=%d", err, len(due))
+	}
+	if ok, err := s.SettleOutbox(outbox, "t1"); err != nil || ok {
+		t.Fatalf("expected the lapsed lease's settle to be refused: ok=%v err=%v", ok, err)
+	}
+	if _, ok, err := s.LeaseOutbox("missing", OutboxLease{Owner: "w2", Token: "t3", Now: "now+4", Until: "now+5"}); err != nil || ok {
+		t.Fatalf("expected no claim on a missing record: ok=%v err=%v", ok, err)
+	}
+	if rec, ok, err := s.LeaseOutbox("n1", OutboxLease{Owner: "w2", Token: "t3", Now: "now+4", Until: "now+5"}); err != nil || !ok || *rec.LeaseToken != "t3" {
+		t.Fatalf("expected a claim by id: ok=%v err=%v rec=%+v", ok, err, rec)
+	}
+	if list, err := s.ListOutbox("dead", 10); err != nil || len(list) != 0 {
+		t.Fatalf("expected no dead records: err=%v len=%d", err, len(list))
+	}
+	if list, err := s.ListOutbox("", 10); err != nil || len(list) != 1 {
+		t.Fatalf("expected one record: err=%v len=%d", err, len(list))
 	}
 
 	policy := PolicyVersionRecord{PolicyHash: "ph", PolicyID: "pid", PolicyVersion: "1", PolicyYAML: "y", CreatedAt: "now"}
@@ -52,6 +82,15 @@ func TestInMemoryStore_CRUD(t *testing.T) {
 	if got, ok := s.GetContext("c1"); !ok || string(got.BodyJSON) != "{}" {
 		t.Fatalf("get context mismatch: ok=%v got=%+v", ok, got)
 	}
+	if err := s.PutContext(ContextRecord{ContextID: "c0", BodyJSON: []byte(`{}`), CreatedAt: "earlier"}); err != nil {
+		t.Fatalf("put context: %v", err)
+	}
+	if list, err := s.ListContexts("", 0); err != nil || len(list) != 2 || list[0].ContextID != "c0" {
+		t.Fatalf("list contexts mismatch: err=%v list=%+v", err, list)
+	}
+	if list, err := s.ListContexts("now", 1); err != nil || len(list) != 1 || list[0].ContextID != "c1" {
+		t.Fatalf("list contexts since mismatch: err=%v list=%+v", err, list)
+	}
 
 	dec := DecisionRecord{DecisionID: "d1", ContextID: "c1", PolicyHash: "ph", Verdict: "allow", BodyJSON: []byte(`{}`), CreatedAt: "now"}
 	if err := s.PutDecision(dec); err != nil {
@@ -98,20 +137,21 @@ func TestInMemoryStore_WithTx(t *testing.T) {
 		if _, ok := tx.GetKey("tx-k"); !ok {
 			t.Fatalf("expected key in tx")
 		}
-		if err := tx.PutSlackOutbox(SlackOutboxRecord{
-			NotificationID: "tx-n1",
-			ApprovalID:     "a1",
-			Channel:        "C1",
-			MessageJSON:    []byte(`{}`),
-			Status:         "pending",
-			AttemptCount:   0,
-			NextAttemptAt:  "now",
-			CreatedAt:      "now",
-			UpdatedAt:      "now",
+		if err := tx.PutOutbox(OutboxRecord{
+			OutboxID:      "tx-n1",
+			Kind:          "slack_post",
+			ApprovalID:    "a1",
+			Target:        "C1",
+			PayloadJSON:   []byte(`{}`),
+			Status:        "pending",
+			AttemptCount:  0,
+			NextAttemptAt: "now",
+			CreatedAt:     "now",
+			UpdatedAt:     "now",
 		}); err != nil {
 			return err
 		}
-		if _, ok := tx.GetSlackOutbox("tx-n1"); !ok {
+		if _, ok := tx.GetOutbox("tx-n1"); !ok {
 			t.Fatalf("expected outbox in tx")
 		}
 		if err := tx.PutPolicyVersion(PolicyVersionRecord{PolicyHash: "tx-ph", PolicyID: "pid", PolicyVersion: "1", PolicyYAML: "y", CreatedAt: "now"}); err != nil {
@@ -2,6 +2,8 @@ package ledger
 
 import (
 	"database/sql"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	_ "modernc.org/sqlite"
@@ -23,10 +25,10 @@ func TestMigrateSQLiteIdempotent(t *testing.T) {
 
 	// Ensure the outbox table exists.
 	var name string
-	if err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type='table' AND name='slack_outbox'`).Scan(&name); err != nil {
-		t.Fatalf("expected slack_outbox table: %v", err)
+	if err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type='table' AND name='outbox'`).Scan(&name); err != nil {
+		t.Fatalf("expected outbox table: %v", err)
 	}
-	if name != "slack_outbox" {
+	if name != "outbox" {
 		t.Fatalf("unexpected table name: %s", name)
 	}
 
@@ -39,6 +41,58 @@ func TestMigrateSQLiteIdempotent(t *testing.T) {
 	}
 }
 
+func TestMigrateMovesSlackOutbox(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:migrate-outbox?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	// Apply the migrations before the generalized outbox by hand, as an older gateway did.
+	if err := ensureMigrationsTable(db, DBSQLite, "schema_migrations"); err != nil {
+		t.Fatalf("migrations table: %v", err)
+	}
+	files, err := listMigrationFiles("migrations/sqlite")
+	if err != nil {
+		t.Fatalf("list migrations: %v", err)
+	}
+	for _, file := range files {
+		version := strings.TrimSuffix(filepath.Base(file), ".sql")
+		if version >= "0006" {
+			break
+		}
+		contents, _ := migrationsFS.ReadFile(file)
+		if _, err := db.Exec(string(contents)); err != nil {
+			t.Fatalf("apply %s: %v", version, err)
+		}
+		if _, err := db.Exec(`INSERT INTO schema_migrations(version, applied_at) VALUES(?, 'now')`, version); err != nil {
+			t.Fatalf("record %s: %v", version, err)
+		}
+	}
+	if _, err := db.Exec(`PRAGMA foreign_keys = OFF`); err != nil {
+		t.Fatalf("pragma: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO slack_outbox(notification_id, approval_id, transport, channel, message_json, status, attempt_count, next_attempt_at, created_at, updated_at)
+VALUES('teams:a1', 'a1', 'teams', 'https://example.test/hook', '{}', 'pending', 2, 'now', 'now', 'now')`); err != nil {
+		t.Fatalf("insert outbox: %v", err)
+	}
+
+	if err := Migrate(db, DBSQLite); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	var id, kind, target string
+	var attempts, maxAttempts int
+	if err := db.QueryRow(`SELECT outbox_id, kind, target, attempt_count, max_attempts FROM outbox`).Scan(&id, &kind, &target, &attempts, &maxAttempts); err != nil {
+		t.Fatalf("read outbox: %v", err)
+	}
+	if id != "teams_post:a1" || kind != "teams_post" || target != "https://example.test/hook" || attempts != 2 || maxAttempts != 10 {
+		t.Fatalf("unexpected migrated record: %s %s %s %d %d", id, kind, target, attempts, maxAttempts)
+	}
+	if err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type='table' AND name='slack_outbox'`).Scan(&id); err != sql.ErrNoRows {
+		t.Fatalf("expected slack_outbox to be dropped, got %v", err)
+	}
+}
+
 func TestMigrationHelpers(t *testing.T) {
 	if _, _, err := migrationConfig(DBPostgres); err != nil {
 		t.Fatalf("expected postgres config, got %v", err)
@@ -0,0 +1,5 @@
+-- Approval quorum: required approver count, allowed groups, requester, and individual votes.
+ALTER TABLE relia_approvals ADD COLUMN IF NOT EXISTS required_approvals INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE relia_approvals ADD COLUMN IF NOT EXISTS approver_groups JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE relia_approvals ADD COLUMN IF NOT EXISTS requested_by TEXT;
+ALTER TABLE relia_approvals ADD COLUMN IF NOT EXISTS votes_json JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,6 @@
+-- Approval expiry: approvals gain expires_at and an 'expired' status, and receipts an
+-- 'approval_expired' outcome.
+ALTER TYPE relia_approval_status ADD VALUE IF NOT EXISTS 'expired';
+ALTER TYPE relia_outcome_status ADD VALUE IF NOT EXISTS 'approval_expired' AFTER 'approval_denied';
+ALTER TABLE relia_approvals ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_rel_approvals_expiry ON relia_approvals(status, expires_at);
@@ -0,0 +1,5 @@
+-- Teams approvals: outbox notifications record the transport that delivers them.
+ALTER TABLE relia_slack_outbox ADD COLUMN IF NOT EXISTS transport TEXT NOT NULL DEFAULT 'slack';
+
+DROP INDEX IF EXISTS idx_rel_slack_outbox_due;
+CREATE INDEX IF NOT EXISTS idx_rel_slack_outbox_due ON relia_slack_outbox(transport, status, next_attempt_at);
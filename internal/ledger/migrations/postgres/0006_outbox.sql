@@ -0,0 +1,33 @@
+-- Generalized outbox: every kind of outbound delivery, with a retry limit, dead-lettering,
+-- worker leases, and attempt history. Replaces relia_slack_outbox.
+CREATE TABLE IF NOT EXISTS relia_outbox (
+  outbox_id        TEXT PRIMARY KEY,
+  kind             TEXT NOT NULL CHECK (kind IN ('slack_post','slack_update','teams_post','webhook','event_export')),
+  approval_id      TEXT NOT NULL DEFAULT '',
+  target           TEXT NOT NULL DEFAULT '',
+  payload_json     JSONB NOT NULL,
+  status           TEXT NOT NULL CHECK (status IN ('pending','sent','dead')),
+  attempt_count    INTEGER NOT NULL,
+  max_attempts     INTEGER NOT NULL,
+  next_attempt_at  TIMESTAMPTZ NOT NULL,
+  lease_owner      TEXT,
+  lease_expires_at TIMESTAMPTZ,
+  last_error       TEXT,
+  attempts_json    JSONB NOT NULL DEFAULT '[]'::jsonb,
+  sent_at          TIMESTAMPTZ,
+  created_at       TIMESTAMPTZ NOT NULL,
+  updated_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rel_outbox_due ON relia_outbox(kind, status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_rel_outbox_status ON relia_outbox(status, created_at);
+
+INSERT INTO relia_outbox(outbox_id, kind, approval_id, target, payload_json, status, attempt_count, max_attempts, next_attempt_at, last_error, sent_at, created_at, updated_at)
+SELECT kind || ':' || approval_id, kind, approval_id, channel, message_json, status, attempt_count, 10, next_attempt_at, last_error, sent_at, created_at, updated_at
+FROM (
+  SELECT o.*, CASE o.transport WHEN 'teams' THEN 'teams_post' WHEN 'webhook' THEN 'webhook' ELSE 'slack_post' END AS kind
+  FROM relia_slack_outbox o
+) old
+ON CONFLICT (outbox_id) DO NOTHING;
+
+DROP TABLE IF EXISTS relia_slack_outbox;
@@ -0,0 +1,3 @@
+-- Outbox leases carry a token unique to each claim, so a worker settles a record only
+-- while its own lease holds, even when another worker shares its owner name.
+ALTER TABLE relia_outbox ADD COLUMN IF NOT EXISTS lease_token TEXT;
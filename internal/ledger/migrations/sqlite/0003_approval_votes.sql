@@ -0,0 +1,5 @@
+-- Approval quorum: required approver count, allowed groups, requester, and individual votes.
+ALTER TABLE approvals ADD COLUMN required_approvals INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE approvals ADD COLUMN approver_groups TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE approvals ADD COLUMN requested_by TEXT;
+ALTER TABLE approvals ADD COLUMN votes_json TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,82 @@
+-- Approval expiry: approvals gain expires_at and an 'expired' status, and receipts an
+-- 'approval_expired' outcome. SQLite cannot alter CHECK constraints, so both tables are
+-- rebuilt; foreign keys are checked at commit, once the rows are back.
+PRAGMA defer_foreign_keys = ON;
+
+CREATE TABLE approvals_0004 AS SELECT * FROM approvals;
+DROP TABLE approvals;
+CREATE TABLE approvals (
+  approval_id    TEXT PRIMARY KEY,
+  idem_key       TEXT NOT NULL UNIQUE,
+  status         TEXT NOT NULL CHECK (status IN ('pending','approved','denied','expired')),
+  slack_channel  TEXT,
+  slack_msg_ts   TEXT,
+
+  approved_by    TEXT,
+  approved_at    TEXT,
+
+  required_approvals INTEGER NOT NULL DEFAULT 1,
+  approver_groups    TEXT NOT NULL DEFAULT '[]', -- JSON array
+  requested_by       TEXT,
+  votes_json         TEXT NOT NULL DEFAULT '[]',
+
+  expires_at     TEXT,
+
+  created_at     TEXT NOT NULL,
+  updated_at     TEXT NOT NULL,
+
+  FOREIGN KEY(idem_key) REFERENCES idempotency_keys(idem_key)
+);
+INSERT INTO approvals(approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, required_approvals, approver_groups, requested_by, votes_json, created_at, updated_at)
+SELECT approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, required_approvals, approver_groups, requested_by, votes_json, created_at, updated_at FROM approvals_0004;
+DROP TABLE approvals_0004;
+
+CREATE INDEX IF NOT EXISTS idx_approvals_status ON approvals(status);
+CREATE INDEX IF NOT EXISTS idx_approvals_expiry ON approvals(status, expires_at);
+
+CREATE TABLE receipts_0004 AS SELECT * FROM receipts;
+DROP TABLE receipts;
+CREATE TABLE receipts (
+  receipt_id             TEXT PRIMARY KEY,
+  idem_key               TEXT NOT NULL,
+  created_at             TEXT NOT NULL,
+
+  supersedes_receipt_id  TEXT,
+
+  context_id             TEXT NOT NULL,
+  decision_id            TEXT NOT NULL,
+  policy_hash            TEXT NOT NULL,
+
+  approval_id            TEXT,
+  outcome_status         TEXT NOT NULL CHECK (outcome_status IN (
+                         'approval_pending','approval_approved','approval_denied',
+                         'approval_expired',
+                         'issuing_credentials','issued_credentials',
+                         'denied','issue_failed'
+                       )),
+  final                  INTEGER NOT NULL CHECK (final IN (0,1)),
+  expires_at             TEXT,
+
+  body_json              TEXT NOT NULL,
+  body_digest            TEXT NOT NULL,
+  key_id                 TEXT NOT NULL,
+  sig                    BLOB NOT NULL,
+
+  FOREIGN KEY(idem_key) REFERENCES idempotency_keys(idem_key),
+  FOREIGN KEY(supersedes_receipt_id) REFERENCES receipts(receipt_id),
+  FOREIGN KEY(context_id) REFERENCES contexts(context_id),
+  FOREIGN KEY(decision_id) REFERENCES decisions(decision_id),
+  FOREIGN KEY(policy_hash) REFERENCES policy_versions(policy_hash),
+  FOREIGN KEY(approval_id) REFERENCES approvals(approval_id),
+  FOREIGN KEY(key_id) REFERENCES keys(key_id)
+);
+INSERT INTO receipts SELECT * FROM receipts_0004;
+DROP TABLE receipts_0004;
+
+CREATE INDEX IF NOT EXISTS idx_receipts_idem_created ON receipts(idem_key, created_at);
+CREATE INDEX IF NOT EXISTS idx_receipts_supersedes   ON receipts(supersedes_receipt_id);
+CREATE INDEX IF NOT EXISTS idx_receipts_outcome      ON receipts(outcome_status);
+CREATE INDEX IF NOT EXISTS idx_receipts_context      ON receipts(context_id);
+CREATE INDEX IF NOT EXISTS idx_receipts_decision     ON receipts(decision_id);
+CREATE INDEX IF NOT EXISTS idx_receipts_policy       ON receipts(policy_hash);
+CREATE INDEX IF NOT EXISTS idx_receipts_final        ON receipts(final);
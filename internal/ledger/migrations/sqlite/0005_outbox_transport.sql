@@ -0,0 +1,5 @@
+-- Teams approvals: outbox notifications record the transport that delivers them.
+ALTER TABLE slack_outbox ADD COLUMN transport TEXT NOT NULL DEFAULT 'slack';
+
+DROP INDEX IF EXISTS idx_slack_outbox_due;
+CREATE INDEX IF NOT EXISTS idx_slack_outbox_due ON slack_outbox(transport, status, next_attempt_at);
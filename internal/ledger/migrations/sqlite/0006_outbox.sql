@@ -0,0 +1,32 @@
+-- Generalized outbox: every kind of outbound delivery, with a retry limit, dead-lettering,
+-- worker leases, and attempt history. Replaces slack_outbox.
+CREATE TABLE IF NOT EXISTS outbox (
+  outbox_id        TEXT PRIMARY KEY,
+  kind             TEXT NOT NULL CHECK (kind IN ('slack_post','slack_update','teams_post','webhook','event_export')),
+  approval_id      TEXT NOT NULL DEFAULT '',
+  target           TEXT NOT NULL DEFAULT '',
+  payload_json     TEXT NOT NULL,
+  status           TEXT NOT NULL CHECK (status IN ('pending','sent','dead')),
+  attempt_count    INTEGER NOT NULL,
+  max_attempts     INTEGER NOT NULL,
+  next_attempt_at  TEXT NOT NULL,
+  lease_owner      TEXT,
+  lease_expires_at TEXT,
+  last_error       TEXT,
+  attempts_json    TEXT NOT NULL DEFAULT '[]',
+  sent_at          TEXT,
+  created_at       TEXT NOT NULL,
+  updated_at       TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox(kind, status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_status ON outbox(status, created_at);
+
+INSERT INTO outbox(outbox_id, kind, approval_id, target, payload_json, status, attempt_count, max_attempts, next_attempt_at, last_error, sent_at, created_at, updated_at)
+SELECT kind || ':' || approval_id, kind, approval_id, channel, message_json, status, attempt_count, 10, next_attempt_at, last_error, sent_at, created_at, updated_at
+FROM (
+  SELECT *, CASE transport WHEN 'teams' THEN 'teams_post' WHEN 'webhook' THEN 'webhook' ELSE 'slack_post' END AS kind
+  FROM slack_outbox
+);
+
+DROP TABLE IF EXISTS slack_outbox;
@@ -0,0 +1,3 @@
+-- Outbox leases carry a token unique to each claim, so a worker settles a record only
+-- while its own lease holds, even when another worker shares its owner name.
+ALTER TABLE outbox ADD COLUMN lease_token TEXT;
@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
 
 	_ "github.com/lib/pq"
 
@@ -63,47 +66,87 @@ func (s *Store) GetKey(keyID string) (ledger.KeyRecord, bool) {
 	return rec, true
 }
 
-func (s *Store) PutSlackOutbox(rec ledger.SlackOutboxRecord) error {
-	return s.WithTx(func(tx ledger.Tx) error { return tx.PutSlackOutbox(rec) })
+func (s *Store) PutOutbox(rec ledger.OutboxRecord) error {
+	return s.WithTx(func(tx ledger.Tx) error { return tx.PutOutbox(rec) })
 }
 
-func (s *Store) GetSlackOutbox(notificationID string) (ledger.SlackOutboxRecord, bool) {
-	var rec ledger.SlackOutboxRecord
-	row := s.db.QueryRow(`SELECT notification_id, approval_id, channel, message_json::text, status, attempt_count, next_attempt_at::text, last_error, sent_at::text, created_at::text, updated_at::text
-FROM relia_slack_outbox WHERE notification_id = $1`, notificationID)
-	var msg string
-	if err := row.Scan(&rec.NotificationID, &rec.ApprovalID, &rec.Channel, &msg, &rec.Status, &rec.AttemptCount, &rec.NextAttemptAt, &rec.LastError, &rec.SentAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.SlackOutboxRecord{}, false
-	}
-	rec.MessageJSON = []byte(msg)
-	return rec, true
+func (s *Store) GetOutbox(outboxID string) (ledger.OutboxRecord, bool) {
+	return scanOutbox(s.db.QueryRow(`SELECT `+outboxColumns+` FROM relia_outbox WHERE outbox_id = $1`, outboxID))
 }
 
-func (s *Store) ListSlackOutboxDue(now string, limit int) ([]ledger.SlackOutboxRecord, error) {
+// LeaseOutboxDue claims due records with SELECT ... FOR UPDATE SKIP LOCKED, so gateway
+// replicas polling at the same time claim disjoint records instead of double-posting.
+func (s *Store) LeaseOutboxDue(kind string, lease ledger.OutboxLease, limit int) ([]ledger.OutboxRecord, error) {
 	if limit <= 0 {
 		limit = 100
 	}
-	rows, err := s.db.Query(`SELECT notification_id, approval_id, channel, message_json::text, status, attempt_count, next_attempt_at::text, last_error, sent_at::text, created_at::text, updated_at::text
-FROM relia_slack_outbox
-WHERE status = 'pending' AND next_attempt_at <= $1::timestamptz
-ORDER BY created_at ASC
-LIMIT $2`, now, limit)
+	rows, err := s.db.Query(`WITH due AS (
+  SELECT outbox_id AS due_id FROM relia_outbox
+  WHERE status = 'pending' AND kind = $1 AND next_attempt_at <= $2::timestamptz
+    AND (lease_expires_at IS NULL OR lease_expires_at <= $2::timestamptz)
+  ORDER BY next_attempt_at ASC
+  LIMIT $3
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE relia_outbox SET lease_owner = $4, lease_token = $5, lease_expires_at = $6::timestamptz
+FROM due WHERE outbox_id = due.due_id
+RETURNING `+outboxColumns, kind, lease.Now, limit, lease.Owner, lease.Token, lease.Until)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	out, err := scanOutboxRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NextAttemptAt < out[j].NextAttemptAt })
+	return out, nil
+}
+
+// LeaseOutbox claims one record in a single conditional UPDATE; Postgres re-checks the
+// WHERE clause after waiting on a concurrent claim, so only one worker wins.
+func (s *Store) LeaseOutbox(outboxID string, lease ledger.OutboxLease) (ledger.OutboxRecord, bool, error) {
+	rec, err := scanOutboxRow(s.db.QueryRow(`UPDATE relia_outbox SET lease_owner = $1, lease_token = $2, lease_expires_at = $3::timestamptz
+WHERE outbox_id = $4 AND status = 'pending' AND next_attempt_at <= $5::timestamptz
+  AND (lease_expires_at IS NULL OR lease_expires_at <= $5::timestamptz)
+RETURNING `+outboxColumns, lease.Owner, lease.Token, lease.Until, outboxID, lease.Now))
+	if err == sql.ErrNoRows {
+		return ledger.OutboxRecord{}, false, nil
+	}
+	if err != nil {
+		return ledger.OutboxRecord{}, false, err
+	}
+	return rec, true, nil
+}
 
-	out := []ledger.SlackOutboxRecord{}
-	for rows.Next() {
-		var rec ledger.SlackOutboxRecord
-		var msg string
-		if err := rows.Scan(&rec.NotificationID, &rec.ApprovalID, &rec.Channel, &msg, &rec.Status, &rec.AttemptCount, &rec.NextAttemptAt, &rec.LastError, &rec.SentAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-			return nil, err
-		}
-		rec.MessageJSON = []byte(msg)
-		out = append(out, rec)
+func (s *Store) SettleOutbox(rec ledger.OutboxRecord, leaseToken string) (bool, error) {
+	attempts, err := outboxAttemptsJSON(rec)
+	if err != nil {
+		return false, err
 	}
-	return out, rows.Err()
+	res, err := s.db.Exec(`UPDATE relia_outbox SET status = $1, attempt_count = $2, next_attempt_at = $3::timestamptz, lease_owner = $4, lease_token = $5, lease_expires_at = $6::timestamptz, last_error = $7, attempts_json = $8::jsonb, sent_at = $9::timestamptz, updated_at = $10::timestamptz
+WHERE outbox_id = $11 AND lease_token = $12`,
+		rec.Status, rec.AttemptCount, rec.NextAttemptAt, rec.LeaseOwner, rec.LeaseToken, rec.LeaseExpiresAt, rec.LastError, attempts, rec.SentAt, rec.UpdatedAt,
+		rec.OutboxID, leaseToken)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (s *Store) ListOutbox(status string, limit int) ([]ledger.OutboxRecord, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.Query(`SELECT `+outboxColumns+`
+FROM relia_outbox
+WHERE $1 = '' OR status = $1
+ORDER BY created_at ASC, outbox_id ASC
+LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboxRows(rows)
 }
 
 func (s *Store) PutPolicyVersion(policy ledger.PolicyVersionRecord) error {
@@ -134,6 +177,35 @@ func (s *Store) GetContext(contextID string) (ledger.ContextRecord, bool) {
 	return rec, true
 }
 
+func (s *Store) ListContexts(since string, limit int) ([]ledger.ContextRecord, error) {
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	var rows *sql.Rows
+	var err error
+	if since == "" {
+		rows, err = s.db.Query(`SELECT context_id, body_json::text, created_at::text FROM relia_contexts ORDER BY created_at ASC, context_id ASC LIMIT $1`, limit)
+	} else {
+		rows, err = s.db.Query(`SELECT context_id, body_json::text, created_at::text FROM relia_contexts WHERE created_at >= $1 ORDER BY created_at ASC, context_id ASC LIMIT $2`, since, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ledger.ContextRecord{}
+	for rows.Next() {
+		var rec ledger.ContextRecord
+		var body string
+		if err := rows.Scan(&rec.ContextID, &body, &rec.CreatedAt); err != nil {
+			return nil, err
+		}
+		rec.BodyJSON = []byte(body)
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
 func (s *Store) PutDecision(decision ledger.DecisionRecord) error {
 	return s.WithTx(func(tx ledger.Tx) error { return tx.PutDecision(decision) })
 }
@@ -181,26 +253,94 @@ FROM relia_receipts WHERE receipt_id = $1`, receiptID)
 	return rec, true
 }
 
+func (s *Store) ListReceiptsByIdemKey(idemKey string) ([]ledger.ReceiptRecord, error) {
+	rows, err := s.db.Query(`SELECT receipt_id, idem_key, created_at::text, supersedes_receipt_id, context_id, decision_id, policy_hash, approval_id, outcome_status::text, final, expires_at::text, body_json::text, body_digest, key_id, sig
+FROM relia_receipts
+WHERE idem_key = $1
+ORDER BY created_at ASC, receipt_id ASC`, idemKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ledger.ReceiptRecord{}
+	for rows.Next() {
+		var rec ledger.ReceiptRecord
+		var body string
+		if err := rows.Scan(&rec.ReceiptID, &rec.IdemKey, &rec.CreatedAt, &rec.SupersedesReceiptID, &rec.ContextID, &rec.DecisionID, &rec.PolicyHash, &rec.ApprovalID, &rec.OutcomeStatus, &rec.Final, &rec.ExpiresAt, &body, &rec.BodyDigest, &rec.KeyID, &rec.Sig); err != nil {
+			return nil, err
+		}
+		rec.BodyJSON = []byte(body)
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
 func (s *Store) PutApproval(approval ledger.ApprovalRecord) error {
 	return s.WithTx(func(tx ledger.Tx) error { return tx.PutApproval(approval) })
 }
 
+func (s *Store) SetApprovalSlackMessage(approvalID, channel, msgTS, updatedAt string) error {
+	_, err := s.db.Exec(`UPDATE relia_approvals SET slack_channel = $1, slack_msg_ts = $2, updated_at = $3::timestamptz WHERE approval_id = $4`, channel, msgTS, updatedAt, approvalID)
+	return err
+}
+
 func (s *Store) GetApproval(approvalID string) (ledger.ApprovalRecord, bool) {
-	var rec ledger.ApprovalRecord
-	row := s.db.QueryRow(`SELECT approval_id, idem_key, status::text, slack_channel, slack_msg_ts, approved_by, approved_at::text, created_at::text, updated_at::text FROM relia_approvals WHERE approval_id = $1`, approvalID)
-	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.ApprovalRecord{}, false
-	}
-	return rec, true
+	return scanApproval(s.db.QueryRow(`SELECT `+approvalColumns+` FROM relia_approvals WHERE approval_id = $1`, approvalID))
 }
 
 func (s *Store) GetApprovalByIdemKey(idemKey string) (ledger.ApprovalRecord, bool) {
-	var rec ledger.ApprovalRecord
-	row := s.db.QueryRow(`SELECT approval_id, idem_key, status::text, slack_channel, slack_msg_ts, approved_by, approved_at::text, created_at::text, updated_at::text FROM relia_approvals WHERE idem_key = $1`, idemKey)
-	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.ApprovalRecord{}, false
+	return scanApproval(s.db.QueryRow(`SELECT `+approvalColumns+` FROM relia_approvals WHERE idem_key = $1`, idemKey))
+}
+
+func (s *Store) ListExpiredApprovals(now string, limit int) ([]ledger.ApprovalRecord, error) {
+	if limit <= 0 {
+		limit = 100
 	}
-	return rec, true
+	rows, err := s.db.Query(`SELECT `+approvalColumns+`
+FROM relia_approvals
+WHERE status = 'pending' AND expires_at IS NOT NULL AND expires_at <= $1::timestamptz
+ORDER BY expires_at ASC, approval_id ASC
+LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ledger.ApprovalRecord{}
+	for rows.Next() {
+		rec, ok := scanApproval(rows)
+		if !ok {
+			return nil, fmt.Errorf("scan approval")
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) ListApprovals(status string, limit int) ([]ledger.ApprovalRecord, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.Query(`SELECT `+approvalColumns+`
+FROM relia_approvals
+WHERE $1 = '' OR status::text = $1
+ORDER BY created_at ASC, approval_id ASC
+LIMIT $2`, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ledger.ApprovalRecord{}
+	for rows.Next() {
+		rec, ok := scanApproval(rows)
+		if !ok {
+			return nil, fmt.Errorf("scan approval")
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
 }
 
 func (s *Store) PutIdempotencyKey(key ledger.IdempotencyKey) error {
@@ -244,45 +384,52 @@ func (t *Tx) GetKey(keyID string) (ledger.KeyRecord, bool) {
 	return rec, true
 }
 
-func (t *Tx) PutSlackOutbox(rec ledger.SlackOutboxRecord) error {
-	if !json.Valid(rec.MessageJSON) {
-		return errors.New("invalid message_json")
+func (t *Tx) PutOutbox(rec ledger.OutboxRecord) error {
+	if !json.Valid(rec.PayloadJSON) {
+		return errors.New("invalid payload_json")
 	}
-	_, err := t.tx.Exec(
-		`INSERT INTO relia_slack_outbox(notification_id, approval_id, channel, message_json, status, attempt_count, next_attempt_at, last_error, sent_at, created_at, updated_at)
-VALUES($1,$2,$3,$4::jsonb,$5,$6,$7::timestamptz,$8,$9::timestamptz,$10::timestamptz,$11::timestamptz)
-ON CONFLICT(notification_id) DO UPDATE SET
+	attempts, err := outboxAttemptsJSON(rec)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(
+		`INSERT INTO relia_outbox(outbox_id, kind, approval_id, target, payload_json, status, attempt_count, max_attempts, next_attempt_at, lease_owner, lease_expires_at, last_error, attempts_json, sent_at, created_at, updated_at, lease_token)
+VALUES($1,$2,$3,$4,$5::jsonb,$6,$7,$8,$9::timestamptz,$10,$11::timestamptz,$12,$13::jsonb,$14::timestamptz,$15::timestamptz,$16::timestamptz,$17)
+ON CONFLICT(outbox_id) DO UPDATE SET
   status=excluded.status,
   attempt_count=excluded.attempt_count,
+  max_attempts=excluded.max_attempts,
   next_attempt_at=excluded.next_attempt_at,
+  lease_owner=excluded.lease_owner,
+  lease_token=excluded.lease_token,
+  lease_expires_at=excluded.lease_expires_at,
   last_error=excluded.last_error,
+  attempts_json=excluded.attempts_json,
   sent_at=excluded.sent_at,
   updated_at=excluded.updated_at`,
-		rec.NotificationID,
+		rec.OutboxID,
+		rec.Kind,
 		rec.ApprovalID,
-		rec.Channel,
-		string(rec.MessageJSON),
+		rec.Target,
+		string(rec.PayloadJSON),
 		rec.Status,
 		rec.AttemptCount,
+		rec.MaxAttempts,
 		rec.NextAttemptAt,
+		rec.LeaseOwner,
+		rec.LeaseExpiresAt,
 		rec.LastError,
+		attempts,
 		rec.SentAt,
 		rec.CreatedAt,
 		rec.UpdatedAt,
+		rec.LeaseToken,
 	)
 	return err
 }
 
-func (t *Tx) GetSlackOutbox(notificationID string) (ledger.SlackOutboxRecord, bool) {
-	var rec ledger.SlackOutboxRecord
-	row := t.tx.QueryRow(`SELECT notification_id, approval_id, channel, message_json::text, status, attempt_count, next_attempt_at::text, last_error, sent_at::text, created_at::text, updated_at::text
-FROM relia_slack_outbox WHERE notification_id = $1`, notificationID)
-	var msg string
-	if err := row.Scan(&rec.NotificationID, &rec.ApprovalID, &rec.Channel, &msg, &rec.Status, &rec.AttemptCount, &rec.NextAttemptAt, &rec.LastError, &rec.SentAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.SlackOutboxRecord{}, false
-	}
-	rec.MessageJSON = []byte(msg)
-	return rec, true
+func (t *Tx) GetOutbox(outboxID string) (ledger.OutboxRecord, bool) {
+	return scanOutbox(t.tx.QueryRow(`SELECT `+outboxColumns+` FROM relia_outbox WHERE outbox_id = $1`, outboxID))
 }
 
 func (t *Tx) PutPolicyVersion(policy ledger.PolicyVersionRecord) error {
@@ -400,15 +547,24 @@ FROM relia_receipts WHERE receipt_id = $1`, receiptID)
 }
 
 func (t *Tx) PutApproval(approval ledger.ApprovalRecord) error {
-	_, err := t.tx.Exec(
-		`INSERT INTO relia_approvals(approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, created_at, updated_at)
-VALUES($1,$2,$3::relia_approval_status,$4,$5,$6,$7::timestamptz,$8::timestamptz,$9::timestamptz)
+	groups, votes, err := approvalJSON(approval)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(
+		`INSERT INTO relia_approvals(approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, required_approvals, approver_groups, requested_by, votes_json, expires_at, created_at, updated_at)
+VALUES($1,$2,$3::relia_approval_status,$4,$5,$6,$7::timestamptz,$8,$9::jsonb,$10,$11::jsonb,$12::timestamptz,$13::timestamptz,$14::timestamptz)
 ON CONFLICT(approval_id) DO UPDATE SET
   status=excluded.status,
   slack_channel=COALESCE(excluded.slack_channel, relia_approvals.slack_channel),
   slack_msg_ts=COALESCE(excluded.slack_msg_ts, relia_approvals.slack_msg_ts),
   approved_by=COALESCE(excluded.approved_by, relia_approvals.approved_by),
   approved_at=COALESCE(excluded.approved_at, relia_approvals.approved_at),
+  required_approvals=excluded.required_approvals,
+  approver_groups=excluded.approver_groups,
+  requested_by=COALESCE(excluded.requested_by, relia_approvals.requested_by),
+  votes_json=excluded.votes_json,
+  expires_at=COALESCE(excluded.expires_at, relia_approvals.expires_at),
   updated_at=excluded.updated_at`,
 		approval.ApprovalID,
 		approval.IdemKey,
@@ -417,6 +573,11 @@ ON CONFLICT(approval_id) DO UPDATE SET
 		approval.SlackMsgTS,
 		approval.ApprovedBy,
 		approval.ApprovedAt,
+		requiredApprovals(approval),
+		groups,
+		approval.RequestedBy,
+		votes,
+		approval.ExpiresAt,
 		approval.CreatedAt,
 		approval.UpdatedAt,
 	)
@@ -424,21 +585,24 @@ ON CONFLICT(approval_id) DO UPDATE SET
 }
 
 func (t *Tx) GetApproval(approvalID string) (ledger.ApprovalRecord, bool) {
-	var rec ledger.ApprovalRecord
-	row := t.tx.QueryRow(`SELECT approval_id, idem_key, status::text, slack_channel, slack_msg_ts, approved_by, approved_at::text, created_at::text, updated_at::text FROM relia_approvals WHERE approval_id = $1`, approvalID)
-	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.ApprovalRecord{}, false
+	return scanApproval(t.tx.QueryRow(`SELECT `+approvalColumns+` FROM relia_approvals WHERE approval_id = $1`, approvalID))
+}
+
+func (t *Tx) LockApproval(approvalID string) (ledger.ApprovalRecord, bool, error) {
+	var id string
+	err := t.tx.QueryRow(`SELECT approval_id FROM relia_approvals WHERE approval_id = $1 FOR UPDATE`, approvalID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ledger.ApprovalRecord{}, false, nil
 	}
-	return rec, true
+	if err != nil {
+		return ledger.ApprovalRecord{}, false, err
+	}
+	approval, ok := t.GetApproval(approvalID)
+	return approval, ok, nil
 }
 
 func (t *Tx) GetApprovalByIdemKey(idemKey string) (ledger.ApprovalRecord, bool) {
-	var rec ledger.ApprovalRecord
-	row := t.tx.QueryRow(`SELECT approval_id, idem_key, status::text, slack_channel, slack_msg_ts, approved_by, approved_at::text, created_at::text, updated_at::text FROM relia_approvals WHERE idem_key = $1`, idemKey)
-	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.ApprovalRecord{}, false
-	}
-	return rec, true
+	return scanApproval(t.tx.QueryRow(`SELECT `+approvalColumns+` FROM relia_approvals WHERE idem_key = $1`, idemKey))
 }
 
 func (t *Tx) PutIdempotencyKey(key ledger.IdempotencyKey) error {
@@ -471,3 +635,102 @@ func (t *Tx) GetIdempotencyKey(idemKey string) (ledger.IdempotencyKey, bool) {
 	}
 	return rec, true
 }
+
+// approvalColumns reads expires_at back as RFC3339 UTC so callers can compare it with
+// request times.
+const approvalColumns = `approval_id, idem_key, status::text, slack_channel, slack_msg_ts, approved_by, approved_at::text, required_approvals, approver_groups::text, requested_by, votes_json::text, to_char(expires_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), created_at::text, updated_at::text, lease_token`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApproval(row rowScanner) (ledger.ApprovalRecord, bool) {
+	var rec ledger.ApprovalRecord
+	var groups, votes string
+	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.RequiredApprovals, &groups, &rec.RequestedBy, &votes, &rec.ExpiresAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
+		return ledger.ApprovalRecord{}, false
+	}
+	if err := json.Unmarshal([]byte(groups), &rec.ApproverGroups); err != nil {
+		return ledger.ApprovalRecord{}, false
+	}
+	if err := json.Unmarshal([]byte(votes), &rec.Votes); err != nil {
+		return ledger.ApprovalRecord{}, false
+	}
+	return rec, true
+}
+
+// approvalJSON encodes the approver groups and votes as JSON arrays.
+func approvalJSON(approval ledger.ApprovalRecord) (string, string, error) {
+	groups := approval.ApproverGroups
+	if groups == nil {
+		groups = []string{}
+	}
+	votes := approval.Votes
+	if votes == nil {
+		votes = []ledger.ApprovalVote{}
+	}
+	groupsJSON, err := json.Marshal(groups)
+	if err != nil {
+		return "", "", err
+	}
+	votesJSON, err := json.Marshal(votes)
+	if err != nil {
+		return "", "", err
+	}
+	return string(groupsJSON), string(votesJSON), nil
+}
+
+// outboxColumns reads next_attempt_at back as RFC3339 UTC, like the times workers
+// write.
+const outboxColumns = `outbox_id, kind, approval_id, target, payload_json::text, status, attempt_count, max_attempts, to_char(next_attempt_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), lease_owner, lease_expires_at::text, last_error, attempts_json::text, sent_at::text, created_at::text, updated_at::text`
+
+func scanOutbox(row rowScanner) (ledger.OutboxRecord, bool) {
+	rec, err := scanOutboxRow(row)
+	return rec, err == nil
+}
+
+func scanOutboxRow(row rowScanner) (ledger.OutboxRecord, error) {
+	var rec ledger.OutboxRecord
+	var payload, attempts string
+	if err := row.Scan(&rec.OutboxID, &rec.Kind, &rec.ApprovalID, &rec.Target, &payload, &rec.Status, &rec.AttemptCount, &rec.MaxAttempts, &rec.NextAttemptAt, &rec.LeaseOwner, &rec.LeaseExpiresAt, &rec.LastError, &attempts, &rec.SentAt, &rec.CreatedAt, &rec.UpdatedAt, &rec.LeaseToken); err != nil {
+		return ledger.OutboxRecord{}, err
+	}
+	rec.PayloadJSON = []byte(payload)
+	if err := json.Unmarshal([]byte(attempts), &rec.Attempts); err != nil {
+		return ledger.OutboxRecord{}, err
+	}
+	return rec, nil
+}
+
+func scanOutboxRows(rows *sql.Rows) ([]ledger.OutboxRecord, error) {
+	defer rows.Close()
+	out := []ledger.OutboxRecord{}
+	for rows.Next() {
+		rec, err := scanOutboxRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// outboxAttemptsJSON encodes the attempt history as a JSON array.
+func outboxAttemptsJSON(rec ledger.OutboxRecord) (string, error) {
+	attempts := rec.Attempts
+	if attempts == nil {
+		attempts = []ledger.OutboxAttempt{}
+	}
+	data, err := json.Marshal(attempts)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func requiredApprovals(approval ledger.ApprovalRecord) int {
+	if approval.RequiredApprovals < 1 {
+		return 1
+	}
+	return approval.RequiredApprovals
+}
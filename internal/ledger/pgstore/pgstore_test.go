@@ -2,6 +2,7 @@ package pgstore
 
 import (
 	"errors"
+	"math"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -93,7 +94,13 @@ func TestKeyCRUD(t *testing.T) {
 	}
 }
 
-func TestSlackOutboxCRUDAndList(t *testing.T) {
+func outboxRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{
+		"outbox_id", "kind", "approval_id", "target", "payload_json", "status", "attempt_count", "max_attempts", "next_attempt_at", "lease_owner", "lease_expires_at", "last_error", "attempts_json", "sent_at", "created_at", "updated_at", "lease_token",
+	})
+}
+
+func TestOutboxCRUDLeaseAndList(t *testing.T) {
 	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
 	if err != nil {
 		t.Fatalf("sqlmock: %v", err)
@@ -105,37 +112,71 @@ func TestSlackOutboxCRUDAndList(t *testing.T) {
 	// Invalid JSON should rollback.
 	mock.ExpectBegin()
 	mock.ExpectRollback()
-	if err := s.PutSlackOutbox(ledger.SlackOutboxRecord{NotificationID: "n1", ApprovalID: "a1", Channel: "C1", MessageJSON: []byte("bad"), Status: "pending", NextAttemptAt: "now", CreatedAt: "now", UpdatedAt: "now"}); err == nil {
+	if err := s.PutOutbox(ledger.OutboxRecord{OutboxID: "n1", Kind: "slack_post", ApprovalID: "a1", Target: "C1", PayloadJSON: []byte("bad"), Status: "pending", NextAttemptAt: "now", CreatedAt: "now", UpdatedAt: "now"}); err == nil {
 		t.Fatalf("expected error")
 	}
 
 	// Successful upsert.
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT INTO relia_slack_outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO relia_outbox").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
-	if err := s.PutSlackOutbox(ledger.SlackOutboxRecord{NotificationID: "n1", ApprovalID: "a1", Channel: "C1", MessageJSON: []byte(`{"approval_id":"a1"}`), Status: "pending", NextAttemptAt: "2025-12-20T00:00:00Z", CreatedAt: "2025-12-20T00:00:00Z", UpdatedAt: "2025-12-20T00:00:00Z"}); err != nil {
+	if err := s.PutOutbox(ledger.OutboxRecord{OutboxID: "n1", Kind: "slack_post", ApprovalID: "a1", Target: "C1", PayloadJSON: []byte(`{"approval_id":"a1"}`), Status: "pending", MaxAttempts: 10, NextAttemptAt: "2025-12-20T00:00:00Z", CreatedAt: "2025-12-20T00:00:00Z", UpdatedAt: "2025-12-20T00:00:00Z"}); err != nil {
 		t.Fatalf("put outbox: %v", err)
 	}
 
-	rows := sqlmock.NewRows([]string{
-		"notification_id", "approval_id", "channel", "message_json", "status", "attempt_count", "next_attempt_at", "last_error", "sent_at", "created_at", "updated_at",
-	}).AddRow(
-		"n1", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, "2025-12-20T00:00:00Z", nil, nil, "2025-12-20T00:00:00Z", "2025-12-20T00:00:00Z",
-	)
-	mock.ExpectQuery("FROM relia_slack_outbox WHERE notification_id").WithArgs("n1").WillReturnRows(rows)
-	if got, ok := s.GetSlackOutbox("n1"); !ok || got.ApprovalID != "a1" {
+	mock.ExpectQuery("FROM relia_outbox WHERE outbox_id").WithArgs("n1").WillReturnRows(outboxRows().AddRow(
+		"n1", "slack_post", "a1", "C1", `{"approval_id":"a1"}`, "pending", 1, 10, "2025-12-20T00:00:00Z", nil, nil, "rate_limited", `[{"at":"2025-12-19T23:59:55Z","error":"rate_limited"}]`, nil, "2025-12-20T00:00:00Z", "2025-12-20T00:00:00Z", nil,
+	))
+	if got, ok := s.GetOutbox("n1"); !ok || got.ApprovalID != "a1" || len(got.Attempts) != 1 || got.Attempts[0].Error != "rate_limited" {
 		t.Fatalf("get outbox mismatch: ok=%v got=%+v", ok, got)
 	}
 
-	listRows := sqlmock.NewRows([]string{
-		"notification_id", "approval_id", "channel", "message_json", "status", "attempt_count", "next_attempt_at", "last_error", "sent_at", "created_at", "updated_at",
-	}).AddRow(
-		"n1", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, "2025-12-20T00:00:00Z", nil, nil, "2025-12-20T00:00:00Z", "2025-12-20T00:00:00Z",
-	)
-	mock.ExpectQuery("FROM relia_slack_outbox").WithArgs("2025-12-21T00:00:00Z", 10).WillReturnRows(listRows)
-	due, err := s.ListSlackOutboxDue("2025-12-21T00:00:00Z", 10)
-	if err != nil || len(due) != 1 {
-		t.Fatalf("list due: err=%v len=%d", err, len(due))
+	// Leasing skips records other replicas have locked.
+	lease := ledger.OutboxLease{Owner: "w1", Token: "t1", Now: "2025-12-21T00:00:00Z", Until: "2025-12-21T00:01:00Z"}
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED\s+\)\s+UPDATE relia_outbox SET lease_owner`).
+		WithArgs("slack_post", "2025-12-21T00:00:00Z", 10, "w1", "t1", "2025-12-21T00:01:00Z").
+		WillReturnRows(outboxRows().AddRow(
+			"n1", "slack_post", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, 10, "2025-12-20T00:00:00Z", "w1", "2025-12-21 00:01:00+00", nil, `[]`, nil, "2025-12-20T00:00:00Z", "2025-12-20T00:00:00Z", "t1",
+		))
+	due, err := s.LeaseOutboxDue("slack_post", lease, 10)
+	if err != nil || len(due) != 1 || due[0].LeaseOwner == nil || *due[0].LeaseOwner != "w1" || due[0].LeaseToken == nil || *due[0].LeaseToken != "t1" {
+		t.Fatalf("lease due: err=%v due=%+v", err, due)
+	}
+
+	// A record is claimed by id only while it is due and unleased.
+	mock.ExpectQuery(`UPDATE relia_outbox SET lease_owner = \$1, lease_token = \$2.*WHERE outbox_id = \$4 AND status = 'pending'`).
+		WithArgs("w1", "t1", "2025-12-21T00:01:00Z", "n1", "2025-12-21T00:00:00Z").
+		WillReturnRows(outboxRows().AddRow(
+			"n1", "slack_post", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, 10, "2025-12-20T00:00:00Z", "w1", "2025-12-21 00:01:00+00", nil, `[]`, nil, "2025-12-20T00:00:00Z", "2025-12-20T00:00:00Z", "t1",
+		))
+	if rec, ok, err := s.LeaseOutbox("n1", lease); err != nil || !ok || rec.LeaseToken == nil || *rec.LeaseToken != "t1" {
+		t.Fatalf("lease by id: ok=%v err=%v rec=%+v", ok, err, rec)
+	}
+	mock.ExpectQuery(`UPDATE relia_outbox SET lease_owner`).WithArgs("w1", "t1", "2025-12-21T00:01:00Z", "n1", "2025-12-21T00:00:00Z").WillReturnRows(outboxRows())
+	if _, ok, err := s.LeaseOutbox("n1", lease); err != nil || ok {
+		t.Fatalf("expected a held record not to be claimed: ok=%v err=%v", ok, err)
+	}
+
+	// Settling is conditional on the lease token, not just the owner name.
+	settled := due[0]
+	settled.Status, settled.LeaseOwner, settled.LeaseToken, settled.LeaseExpiresAt = "sent", nil, nil, nil
+	mock.ExpectExec(`UPDATE relia_outbox SET status = \$1.*WHERE outbox_id = \$11 AND lease_token = \$12`).
+		WithArgs("sent", 0, "2025-12-20T00:00:00Z", nil, nil, nil, nil, "[]", nil, "2025-12-20T00:00:00Z", "n1", "t1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if ok, err := s.SettleOutbox(settled, "t1"); err != nil || !ok {
+		t.Fatalf("settle: ok=%v err=%v", ok, err)
+	}
+	mock.ExpectExec("UPDATE relia_outbox SET status").WillReturnResult(sqlmock.NewResult(0, 0))
+	if ok, err := s.SettleOutbox(settled, "t0"); err != nil || ok {
+		t.Fatalf("expected a lost lease to settle nothing: ok=%v err=%v", ok, err)
+	}
+
+	mock.ExpectQuery("FROM relia_outbox").WithArgs("dead", 10).WillReturnRows(outboxRows().AddRow(
+		"n2", "webhook", "a2", "", `{}`, "dead", 10, 10, "2025-12-20T00:00:00Z", nil, nil, "status 500", `[]`, nil, "2025-12-20T00:00:00Z", "2025-12-20T00:00:00Z", nil,
+	))
+	dead, err := s.ListOutbox("dead", 10)
+	if err != nil || len(dead) != 1 || dead[0].Status != "dead" {
+		t.Fatalf("list dead: err=%v got=%+v", err, dead)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -200,6 +241,14 @@ func TestStoreCRUDAll(t *testing.T) {
 		t.Fatalf("put approval: %v", err)
 	}
 
+	// SetApprovalSlackMessage writes only the Slack columns.
+	mock.ExpectExec(`UPDATE relia_approvals SET slack_channel = \$1, slack_msg_ts = \$2, updated_at = \$3::timestamptz WHERE approval_id = \$4`).
+		WithArgs("C1", "1700000000.1234", "2025-12-20T00:00:05Z", "a1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := s.SetApprovalSlackMessage("a1", "C1", "1700000000.1234", "2025-12-20T00:00:05Z"); err != nil {
+		t.Fatalf("set slack message: %v", err)
+	}
+
 	// Update idempotency to link approval
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO relia_idempotency_keys").WillReturnResult(sqlmock.NewResult(1, 1))
@@ -231,20 +280,22 @@ func TestStoreCRUDAll(t *testing.T) {
 		t.Fatalf("put receipt: %v", err)
 	}
 
-	// PutSlackOutbox
+	// PutOutbox
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT INTO relia_slack_outbox").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO relia_outbox").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
-	if err := s.PutSlackOutbox(ledger.SlackOutboxRecord{
-		NotificationID: "n1",
-		ApprovalID:     "a1",
-		Channel:        "C1",
-		MessageJSON:    []byte(`{"approval_id":"a1"}`),
-		Status:         "pending",
-		AttemptCount:   0,
-		NextAttemptAt:  "2025-12-20T00:00:04Z",
-		CreatedAt:      "2025-12-20T00:00:04Z",
-		UpdatedAt:      "2025-12-20T00:00:04Z",
+	if err := s.PutOutbox(ledger.OutboxRecord{
+		OutboxID:      "n1",
+		Kind:          "slack_post",
+		ApprovalID:    "a1",
+		Target:        "C1",
+		PayloadJSON:   []byte(`{"approval_id":"a1"}`),
+		Status:        "pending",
+		AttemptCount:  0,
+		MaxAttempts:   10,
+		NextAttemptAt: "2025-12-20T00:00:04Z",
+		CreatedAt:     "2025-12-20T00:00:04Z",
+		UpdatedAt:     "2025-12-20T00:00:04Z",
 	}); err != nil {
 		t.Fatalf("put outbox: %v", err)
 	}
@@ -262,6 +313,14 @@ func TestStoreCRUDAll(t *testing.T) {
 	if _, ok := s.GetContext("ctx"); !ok {
 		t.Fatalf("expected context")
 	}
+	mock.ExpectQuery("FROM relia_contexts WHERE created_at").WithArgs("2025-12-20T00:00:00Z", 10).WillReturnRows(sqlmock.NewRows([]string{"context_id", "body_json", "created_at"}).AddRow("ctx", `{"context_id":"ctx"}`, "2025-12-20T00:00:01Z"))
+	if list, err := s.ListContexts("2025-12-20T00:00:00Z", 10); err != nil || len(list) != 1 {
+		t.Fatalf("list contexts: err=%v len=%d", err, len(list))
+	}
+	mock.ExpectQuery("FROM relia_contexts ORDER BY").WithArgs(math.MaxInt32).WillReturnRows(sqlmock.NewRows([]string{"context_id", "body_json", "created_at"}))
+	if list, err := s.ListContexts("", 0); err != nil || len(list) != 0 {
+		t.Fatalf("list all contexts: err=%v len=%d", err, len(list))
+	}
 	mock.ExpectQuery("FROM relia_decisions").WithArgs("dec").WillReturnRows(sqlmock.NewRows([]string{"decision_id", "created_at", "context_id", "policy_hash", "verdict", "body_json"}).AddRow("dec", "2025-12-20T00:00:02Z", "ctx", "ph", "allow", `{"decision_id":"dec"}`))
 	if _, ok := s.GetDecision("dec"); !ok {
 		t.Fatalf("expected decision")
@@ -270,11 +329,11 @@ func TestStoreCRUDAll(t *testing.T) {
 	if _, ok := s.GetIdempotencyKey("idem"); !ok {
 		t.Fatalf("expected idem")
 	}
-	mock.ExpectQuery("FROM relia_approvals WHERE approval_id").WithArgs("a1").WillReturnRows(sqlmock.NewRows([]string{"approval_id", "idem_key", "status", "slack_channel", "slack_msg_ts", "approved_by", "approved_at", "created_at", "updated_at"}).AddRow("a1", "idem", "pending", nil, nil, nil, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
+	mock.ExpectQuery("FROM relia_approvals WHERE approval_id").WithArgs("a1").WillReturnRows(approvalRows().AddRow("a1", "idem", "pending", nil, nil, nil, nil, 1, `[]`, nil, `[]`, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
 	if _, ok := s.GetApproval("a1"); !ok {
 		t.Fatalf("expected approval")
 	}
-	mock.ExpectQuery("FROM relia_approvals WHERE idem_key").WithArgs("idem").WillReturnRows(sqlmock.NewRows([]string{"approval_id", "idem_key", "status", "slack_channel", "slack_msg_ts", "approved_by", "approved_at", "created_at", "updated_at"}).AddRow("a1", "idem", "pending", nil, nil, nil, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
+	mock.ExpectQuery("FROM relia_approvals WHERE idem_key").WithArgs("idem").WillReturnRows(approvalRows().AddRow("a1", "idem", "pending", nil, nil, nil, nil, 1, `[]`, nil, `[]`, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
 	if _, ok := s.GetApprovalByIdemKey("idem"); !ok {
 		t.Fatalf("expected approval by idem")
 	}
@@ -282,14 +341,23 @@ func TestStoreCRUDAll(t *testing.T) {
 	if _, ok := s.GetReceipt("r1"); !ok {
 		t.Fatalf("expected receipt")
 	}
-	mock.ExpectQuery("FROM relia_slack_outbox WHERE notification_id").WithArgs("n1").WillReturnRows(sqlmock.NewRows([]string{"notification_id", "approval_id", "channel", "message_json", "status", "attempt_count", "next_attempt_at", "last_error", "sent_at", "created_at", "updated_at"}).AddRow("n1", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, "2025-12-20T00:00:04Z", nil, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
-	if _, ok := s.GetSlackOutbox("n1"); !ok {
+	mock.ExpectQuery("FROM relia_receipts\\s+WHERE idem_key").WithArgs("idem").WillReturnRows(sqlmock.NewRows([]string{"receipt_id", "idem_key", "created_at", "supersedes_receipt_id", "context_id", "decision_id", "policy_hash", "approval_id", "outcome_status", "final", "expires_at", "body_json", "body_digest", "key_id", "sig"}).AddRow("r1", "idem", "2025-12-20T00:00:06Z", nil, "ctx", "dec", "ph", "a1", "approval_pending", true, nil, `{"receipt_id":"r1"}`, "digest", "kid", []byte("sig")))
+	if list, err := s.ListReceiptsByIdemKey("idem"); err != nil || len(list) != 1 {
+		t.Fatalf("list receipts: err=%v len=%d", err, len(list))
+	}
+	mock.ExpectQuery("FROM relia_outbox WHERE outbox_id").WithArgs("n1").WillReturnRows(outboxRows().AddRow("n1", "slack_post", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, 10, "2025-12-20T00:00:04Z", nil, nil, nil, `[]`, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z", nil))
+	if _, ok := s.GetOutbox("n1"); !ok {
 		t.Fatalf("expected outbox")
 	}
-	mock.ExpectQuery("FROM relia_slack_outbox").WithArgs("2025-12-21T00:00:00Z", 10).WillReturnRows(sqlmock.NewRows([]string{"notification_id", "approval_id", "channel", "message_json", "status", "attempt_count", "next_attempt_at", "last_error", "sent_at", "created_at", "updated_at"}).AddRow("n1", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, "2025-12-20T00:00:04Z", nil, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
-	due, err := s.ListSlackOutboxDue("2025-12-21T00:00:00Z", 10)
-	if err != nil || len(due) != 1 {
-		t.Fatalf("list due: err=%v len=%d", err, len(due))
+	mock.ExpectQuery("FROM relia_approvals").WithArgs("2025-12-21T00:00:00Z", 10).WillReturnRows(approvalRows().AddRow("a1", "idem", "pending", nil, nil, nil, nil, 1, `[]`, nil, `[]`, "2025-12-20T01:00:00Z", "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
+	expired, err := s.ListExpiredApprovals("2025-12-21T00:00:00Z", 10)
+	if err != nil || len(expired) != 1 || *expired[0].ExpiresAt != "2025-12-20T01:00:00Z" {
+		t.Fatalf("list expired: err=%v got=%+v", err, expired)
+	}
+	mock.ExpectQuery("FROM relia_approvals").WithArgs("pending", 10).WillReturnRows(approvalRows().AddRow("a1", "idem", "pending", nil, nil, nil, nil, 1, `[]`, nil, `[]`, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
+	listed, err := s.ListApprovals("pending", 10)
+	if err != nil || len(listed) != 1 || listed[0].ApprovalID != "a1" {
+		t.Fatalf("list approvals: err=%v got=%+v", err, listed)
 	}
 
 	// Tx getters (exercise the Tx implementations too).
@@ -299,10 +367,13 @@ func TestStoreCRUDAll(t *testing.T) {
 	mock.ExpectQuery("FROM relia_contexts").WithArgs("ctx").WillReturnRows(sqlmock.NewRows([]string{"context_id", "body_json", "created_at"}).AddRow("ctx", `{"context_id":"ctx"}`, "2025-12-20T00:00:01Z"))
 	mock.ExpectQuery("FROM relia_decisions").WithArgs("dec").WillReturnRows(sqlmock.NewRows([]string{"decision_id", "created_at", "context_id", "policy_hash", "verdict", "body_json"}).AddRow("dec", "2025-12-20T00:00:02Z", "ctx", "ph", "allow", `{"decision_id":"dec"}`))
 	mock.ExpectQuery("FROM relia_idempotency_keys").WithArgs("idem").WillReturnRows(sqlmock.NewRows([]string{"idem_key", "status", "approval_id", "latest_receipt_id", "final_receipt_id", "created_at", "updated_at", "ttl_expires_at"}).AddRow("idem", "pending_approval", "a1", nil, nil, "2025-12-20T00:00:03Z", "2025-12-20T00:00:05Z", nil))
-	mock.ExpectQuery("FROM relia_approvals WHERE approval_id").WithArgs("a1").WillReturnRows(sqlmock.NewRows([]string{"approval_id", "idem_key", "status", "slack_channel", "slack_msg_ts", "approved_by", "approved_at", "created_at", "updated_at"}).AddRow("a1", "idem", "pending", nil, nil, nil, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
-	mock.ExpectQuery("FROM relia_approvals WHERE idem_key").WithArgs("idem").WillReturnRows(sqlmock.NewRows([]string{"approval_id", "idem_key", "status", "slack_channel", "slack_msg_ts", "approved_by", "approved_at", "created_at", "updated_at"}).AddRow("a1", "idem", "pending", nil, nil, nil, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
+	mock.ExpectQuery("FROM relia_approvals WHERE approval_id").WithArgs("a1").WillReturnRows(approvalRows().AddRow("a1", "idem", "pending", nil, nil, nil, nil, 1, `[]`, nil, `[]`, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
+	mock.ExpectQuery("FROM relia_approvals WHERE idem_key").WithArgs("idem").WillReturnRows(approvalRows().AddRow("a1", "idem", "pending", nil, nil, nil, nil, 1, `[]`, nil, `[]`, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
 	mock.ExpectQuery("FROM relia_receipts").WithArgs("r1").WillReturnRows(sqlmock.NewRows([]string{"receipt_id", "idem_key", "created_at", "supersedes_receipt_id", "context_id", "decision_id", "policy_hash", "approval_id", "outcome_status", "final", "expires_at", "body_json", "body_digest", "key_id", "sig"}).AddRow("r1", "idem", "2025-12-20T00:00:06Z", nil, "ctx", "dec", "ph", "a1", "approval_pending", true, nil, `{"receipt_id":"r1"}`, "digest", "kid", []byte("sig")))
-	mock.ExpectQuery("FROM relia_slack_outbox WHERE notification_id").WithArgs("n1").WillReturnRows(sqlmock.NewRows([]string{"notification_id", "approval_id", "channel", "message_json", "status", "attempt_count", "next_attempt_at", "last_error", "sent_at", "created_at", "updated_at"}).AddRow("n1", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, "2025-12-20T00:00:04Z", nil, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
+	mock.ExpectQuery("FROM relia_outbox WHERE outbox_id").WithArgs("n1").WillReturnRows(outboxRows().AddRow("n1", "slack_post", "a1", "C1", `{"approval_id":"a1"}`, "pending", 0, 10, "2025-12-20T00:00:04Z", nil, nil, nil, `[]`, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z", nil))
+	mock.ExpectQuery("FROM relia_approvals WHERE approval_id = \\$1 FOR UPDATE").WithArgs("a1").WillReturnRows(sqlmock.NewRows([]string{"approval_id"}).AddRow("a1"))
+	mock.ExpectQuery("FROM relia_approvals WHERE approval_id").WithArgs("a1").WillReturnRows(approvalRows().AddRow("a1", "idem", "pending", nil, nil, nil, nil, 1, `[]`, nil, `[]`, nil, "2025-12-20T00:00:04Z", "2025-12-20T00:00:04Z"))
+	mock.ExpectQuery("FOR UPDATE").WithArgs("missing").WillReturnRows(sqlmock.NewRows([]string{"approval_id"}))
 	mock.ExpectCommit()
 	if err := s.WithTx(func(tx ledger.Tx) error {
 		if _, ok := tx.GetKey("kid"); !ok {
@@ -329,9 +400,15 @@ func TestStoreCRUDAll(t *testing.T) {
 		if _, ok := tx.GetReceipt("r1"); !ok {
 			t.Fatalf("expected tx receipt")
 		}
-		if _, ok := tx.GetSlackOutbox("n1"); !ok {
+		if _, ok := tx.GetOutbox("n1"); !ok {
 			t.Fatalf("expected tx outbox")
 		}
+		if _, ok, err := tx.LockApproval("a1"); !ok || err != nil {
+			t.Fatalf("expected locked approval: ok=%v err=%v", ok, err)
+		}
+		if _, ok, err := tx.LockApproval("missing"); ok || err != nil {
+			t.Fatalf("expected no approval to lock: ok=%v err=%v", ok, err)
+		}
 		return nil
 	}); err != nil {
 		t.Fatalf("withtx getters: %v", err)
@@ -348,3 +425,7 @@ func TestStoreCRUDAll(t *testing.T) {
 		t.Fatalf("expectations: %v", err)
 	}
 }
+
+func approvalRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"approval_id", "idem_key", "status", "slack_channel", "slack_msg_ts", "approved_by", "approved_at", "required_approvals", "approver_groups", "requested_by", "votes_json", "expires_at", "created_at", "updated_at"})
+}
@@ -7,7 +7,21 @@ import (
 	"github.com/davidahmann/relia/pkg/types"
 )
 
-const ReceiptSchema = "relia.receipt.v0.1"
+const (
+	// ReceiptSchemaV01 receipts do not sign idem_key or supersedes_receipt_id.
+	ReceiptSchemaV01 = "relia.receipt.v0.1"
+	// ReceiptSchemaV02 receipts sign idem_key and supersedes_receipt_id so the
+	// receipt chain is tamper-evident.
+	ReceiptSchemaV02 = "relia.receipt.v0.2"
+
+	// ReceiptSchema is the schema used for newly minted receipts.
+	ReceiptSchema = ReceiptSchemaV02
+)
+
+// KnownReceiptSchema reports whether schema is a receipt schema this build can verify.
+func KnownReceiptSchema(schema string) bool {
+	return schema == ReceiptSchemaV01 || schema == ReceiptSchemaV02
+}
 
 type Signer interface {
 	KeyID() string
@@ -59,7 +73,7 @@ func MakeReceipt(in MakeReceiptInput, signer Signer) (StoredReceipt, error) {
 	if in.Schema == "" {
 		in.Schema = ReceiptSchema
 	}
-	if in.Schema != ReceiptSchema {
+	if !KnownReceiptSchema(in.Schema) {
 		return StoredReceipt{}, fmt.Errorf("invalid schema: %s", in.Schema)
 	}
 	if in.IdemKey == "" || in.ContextID == "" || in.DecisionID == "" || in.Policy.PolicyHash == "" {
@@ -108,6 +122,12 @@ func MakeReceipt(in MakeReceiptInput, signer Signer) (StoredReceipt, error) {
 			"error":      outcomeError,
 		},
 	}
+	if in.Schema != ReceiptSchemaV01 {
+		body["idem_key"] = in.IdemKey
+		if in.SupersedesReceiptID != nil && *in.SupersedesReceiptID != "" {
+			body["supersedes_receipt_id"] = *in.SupersedesReceiptID
+		}
+	}
 	if ir := interactionRefMap(in.InteractionRef); ir != nil {
 		body["interaction_ref"] = ir
 	}
@@ -170,16 +190,48 @@ func approvalMap(approval *types.ReceiptApproval) map[string]any {
 			"kind":    approval.Approver.Kind,
 			"id":      approval.Approver.ID,
 			"display": approval.Approver.Display,
+			"team_id": emptyToNil(approval.Approver.TeamID),
+		}
+		// Comments are only signed when present, like the optional fields below.
+		if approval.Approver.Comment != "" {
+			approver["comment"] = approval.Approver.Comment
 		}
 	}
 
-	return map[string]any{
+	m := map[string]any{
 		"required":    approval.Required,
 		"approval_id": emptyToNil(approval.ApprovalID),
 		"status":      emptyToNil(approval.Status),
 		"approved_at": emptyToNil(approval.ApprovedAt),
 		"approver":    approver,
 	}
+	// Quorum and expiry fields are only signed when present, so receipts without them
+	// keep their original body.
+	if approval.ExpiresAt != "" {
+		m["expires_at"] = approval.ExpiresAt
+	}
+	if approval.Quorum != 0 {
+		m["quorum"] = approval.Quorum
+	}
+	if len(approval.Approvers) > 0 {
+		approvers := make([]any, 0, len(approval.Approvers))
+		for _, a := range approval.Approvers {
+			entry := map[string]any{
+				"kind":    a.Kind,
+				"id":      a.ID,
+				"display": a.Display,
+				"team_id": emptyToNil(a.TeamID),
+				"vote":    emptyToNil(a.Vote),
+				"at":      emptyToNil(a.At),
+			}
+			if a.Comment != "" {
+				entry["comment"] = a.Comment
+			}
+			approvers = append(approvers, entry)
+		}
+		m["approvers"] = approvers
+	}
+	return m
 }
 
 func refsMap(refs *types.ReceiptRefs) map[string]any {
@@ -291,6 +343,7 @@ func validOutcome(status types.OutcomeStatus) bool {
 	case types.OutcomeApprovalPending,
 		types.OutcomeApprovalApproved,
 		types.OutcomeApprovalDenied,
+		types.OutcomeApprovalExpired,
 		types.OutcomeIssuingCredentials,
 		types.OutcomeIssuedCredentials,
 		types.OutcomeDenied,
@@ -303,7 +356,7 @@ func validOutcome(status types.OutcomeStatus) bool {
 
 func isFinalOutcome(status types.OutcomeStatus) bool {
 	switch status {
-	case types.OutcomeIssuedCredentials, types.OutcomeDenied, types.OutcomeIssueFailed:
+	case types.OutcomeIssuedCredentials, types.OutcomeDenied, types.OutcomeIssueFailed, types.OutcomeApprovalExpired:
 		return true
 	default:
 		return false
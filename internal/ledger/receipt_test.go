@@ -289,6 +289,57 @@ func TestMakeReceiptFinalAndApproval(t *testing.T) {
 	}
 }
 
+func TestMakeReceiptSignsApprovers(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x01}, 32)
+	priv, pub, err := crypto.KeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("keypair: %v", err)
+	}
+	signer := testSigner{keyID: "test-key", priv: priv}
+
+	input := MakeReceiptInput{
+		CreatedAt:  "2025-12-20T16:34:14Z",
+		IdemKey:    "idem:v1:sha256:abc",
+		ContextID:  "sha256:ctx",
+		DecisionID: "sha256:dec",
+		Actor:      types.ReceiptActor{Kind: "approval"},
+		Request:    types.ReceiptRequest{Action: "approve"},
+		Policy:     types.ReceiptPolicy{PolicyHash: "sha256:policy"},
+		Approval: &types.ReceiptApproval{
+			Required:   true,
+			ApprovalID: "appr-1",
+			Status:     "pending",
+		},
+		Outcome: types.ReceiptOutcome{Status: types.OutcomeApprovalPending},
+	}
+	single, err := MakeReceipt(input, signer)
+	if err != nil {
+		t.Fatalf("make receipt: %v", err)
+	}
+	if bytes.Contains(single.BodyJSON, []byte("approvers")) || bytes.Contains(single.BodyJSON, []byte("quorum")) {
+		t.Fatalf("single-approver body should not carry quorum fields: %s", single.BodyJSON)
+	}
+
+	input.Approval.Quorum = 2
+	input.Approval.Approvers = []types.ReceiptApprover{{Kind: "slack", ID: "U1", Display: "alice", Vote: "approved", At: "2025-12-20T16:35:00Z"}}
+	receipt, err := MakeReceipt(input, signer)
+	if err != nil {
+		t.Fatalf("make receipt: %v", err)
+	}
+	if err := VerifyReceipt(receipt, pub); err != nil {
+		t.Fatalf("verify receipt: %v", err)
+	}
+	var body struct {
+		Approval types.ReceiptApproval `json:"approval"`
+	}
+	if err := json.Unmarshal(receipt.BodyJSON, &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Approval.Quorum != 2 || len(body.Approval.Approvers) != 1 || body.Approval.Approvers[0].ID != "U1" || body.Approval.Approvers[0].Vote != "approved" {
+		t.Fatalf("unexpected signed approval: %+v", body.Approval)
+	}
+}
+
 func TestMakeReceiptInvalidSchema(t *testing.T) {
 	seed := bytes.Repeat([]byte{0x01}, 32)
 	priv, _, err := crypto.KeyPairFromSeed(seed)
@@ -315,3 +366,122 @@ func TestMakeReceiptInvalidSchema(t *testing.T) {
 		t.Fatalf("expected error for invalid schema")
 	}
 }
+
+func TestMakeReceiptV02SignsChain(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x01}, 32)
+	priv, pub, err := crypto.KeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("keypair: %v", err)
+	}
+
+	signer := testSigner{keyID: "test-key", priv: priv}
+	prev := "sha256:prev"
+
+	receipt, err := MakeReceipt(MakeReceiptInput{
+		CreatedAt:           "2025-12-20T16:34:14Z",
+		IdemKey:             "idem:v1:sha256:abc",
+		SupersedesReceiptID: &prev,
+		ContextID:           "sha256:ctx",
+		DecisionID:          "sha256:dec",
+		Actor:               types.ReceiptActor{Kind: "workload"},
+		Request:             types.ReceiptRequest{Action: "deploy", Resource: "res", Env: "prod"},
+		Policy:              types.ReceiptPolicy{PolicyHash: "sha256:policy"},
+		Outcome:             types.ReceiptOutcome{Status: types.OutcomeDenied},
+	}, signer)
+	if err != nil {
+		t.Fatalf("make receipt: %v", err)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(receipt.BodyJSON, &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if body["schema"] != ReceiptSchemaV02 || body["idem_key"] != "idem:v1:sha256:abc" || body["supersedes_receipt_id"] != prev {
+		t.Fatalf("expected chain fields in signed body, got %v", body)
+	}
+	if err := VerifyReceipt(receipt, pub); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	relinked := receipt
+	other := "sha256:other"
+	relinked.SupersedesReceiptID = &other
+	if err := VerifyReceipt(relinked, pub); err != ErrReceiptChainMismatch {
+		t.Fatalf("expected ErrReceiptChainMismatch, got %v", err)
+	}
+
+	unlinked := receipt
+	unlinked.SupersedesReceiptID = nil
+	if err := VerifyReceipt(unlinked, pub); err != ErrReceiptChainMismatch {
+		t.Fatalf("expected ErrReceiptChainMismatch, got %v", err)
+	}
+
+	rekeyed := receipt
+	rekeyed.IdemKey = "idem:other"
+	if err := VerifyReceipt(rekeyed, pub); err != ErrReceiptChainMismatch {
+		t.Fatalf("expected ErrReceiptChainMismatch, got %v", err)
+	}
+}
+
+func TestMakeReceiptV01StillVerifies(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x01}, 32)
+	priv, pub, err := crypto.KeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("keypair: %v", err)
+	}
+
+	signer := testSigner{keyID: "test-key", priv: priv}
+	prev := "sha256:prev"
+
+	receipt, err := MakeReceipt(MakeReceiptInput{
+		Schema:              ReceiptSchemaV01,
+		CreatedAt:           "2025-12-20T16:34:14Z",
+		IdemKey:             "idem:v1:sha256:abc",
+		SupersedesReceiptID: &prev,
+		ContextID:           "sha256:ctx",
+		DecisionID:          "sha256:dec",
+		Actor:               types.ReceiptActor{Kind: "workload"},
+		Request:             types.ReceiptRequest{Action: "deploy", Resource: "res", Env: "prod"},
+		Policy:              types.ReceiptPolicy{PolicyHash: "sha256:policy"},
+		Outcome:             types.ReceiptOutcome{Status: types.OutcomeDenied},
+	}, signer)
+	if err != nil {
+		t.Fatalf("make receipt: %v", err)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(receipt.BodyJSON, &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if _, ok := body["idem_key"]; ok {
+		t.Fatalf("v0.1 body must not include idem_key")
+	}
+	if _, ok := body["supersedes_receipt_id"]; ok {
+		t.Fatalf("v0.1 body must not include supersedes_receipt_id")
+	}
+
+	// v0.1 receipts never signed the chain, so column edits are not detectable.
+	receipt.SupersedesReceiptID = nil
+	if err := VerifyReceipt(receipt, pub); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func TestVerifyReceiptUnknownSchema(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x01}, 32)
+	priv, pub, err := crypto.KeyPairFromSeed(seed)
+	if err != nil {
+		t.Fatalf("keypair: %v", err)
+	}
+
+	body := []byte(`{"schema":"relia.receipt.v9"}`)
+	sig, err := crypto.SignEd25519(priv, crypto.DigestBytes(body))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	digest := crypto.DigestWithPrefix(body)
+	receipt := StoredReceipt{ReceiptID: digest, BodyDigest: digest, BodyJSON: body, Sig: sig}
+	if err := VerifyReceipt(receipt, pub); err != ErrReceiptSchema {
+		t.Fatalf("expected ErrReceiptSchema, got %v", err)
+	}
+}
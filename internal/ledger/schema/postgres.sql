@@ -8,12 +8,12 @@ EXCEPTION WHEN duplicate_object THEN NULL; END $$;
 
 DO $$ BEGIN
   CREATE TYPE relia_approval_status AS ENUM
-    ('pending','approved','denied');
+    ('pending','approved','denied','expired');
 EXCEPTION WHEN duplicate_object THEN NULL; END $$;
 
 DO $$ BEGIN
   CREATE TYPE relia_outcome_status AS ENUM
-    ('approval_pending','approval_approved','approval_denied',
+    ('approval_pending','approval_approved','approval_denied','approval_expired',
      'issuing_credentials','issued_credentials','denied','issue_failed');
 EXCEPTION WHEN duplicate_object THEN NULL; END $$;
 
@@ -86,11 +86,17 @@ CREATE TABLE IF NOT EXISTS relia_approvals (
   slack_msg_ts   TEXT,
   approved_by    TEXT,
   approved_at    TIMESTAMPTZ,
+  required_approvals INTEGER NOT NULL DEFAULT 1,
+  approver_groups    JSONB NOT NULL DEFAULT '[]'::jsonb,
+  requested_by       TEXT,
+  votes_json         JSONB NOT NULL DEFAULT '[]'::jsonb,
+  expires_at         TIMESTAMPTZ,
   created_at     TIMESTAMPTZ NOT NULL,
   updated_at     TIMESTAMPTZ NOT NULL
 );
 
 CREATE INDEX IF NOT EXISTS idx_rel_approvals_status ON relia_approvals(status);
+CREATE INDEX IF NOT EXISTS idx_rel_approvals_expiry ON relia_approvals(status, expires_at);
 
 DO $$ BEGIN
   ALTER TABLE relia_idempotency_keys
@@ -147,20 +153,26 @@ DO $$ BEGIN
 EXCEPTION WHEN duplicate_object THEN NULL; END $$;
 
 -- =========================
--- Slack outbox
--- =========================
-CREATE TABLE IF NOT EXISTS relia_slack_outbox (
-  notification_id TEXT PRIMARY KEY,
-  approval_id     TEXT NOT NULL UNIQUE REFERENCES relia_approvals(approval_id),
-  channel         TEXT NOT NULL,
-  message_json    JSONB NOT NULL,
-  status          TEXT NOT NULL CHECK (status IN ('pending','sent')),
-  attempt_count   INTEGER NOT NULL,
-  next_attempt_at TIMESTAMPTZ NOT NULL,
-  last_error      TEXT,
-  sent_at         TIMESTAMPTZ,
-  created_at      TIMESTAMPTZ NOT NULL,
-  updated_at      TIMESTAMPTZ NOT NULL
+-- Outbox
+-- =========================
+CREATE TABLE IF NOT EXISTS relia_outbox (
+  outbox_id        TEXT PRIMARY KEY,
+  kind             TEXT NOT NULL CHECK (kind IN ('slack_post','slack_update','teams_post','webhook','event_export')),
+  approval_id      TEXT NOT NULL DEFAULT '',
+  target           TEXT NOT NULL DEFAULT '',
+  payload_json     JSONB NOT NULL,
+  status           TEXT NOT NULL CHECK (status IN ('pending','sent','dead')),
+  attempt_count    INTEGER NOT NULL,
+  max_attempts     INTEGER NOT NULL,
+  next_attempt_at  TIMESTAMPTZ NOT NULL,
+  lease_owner      TEXT,
+  lease_expires_at TIMESTAMPTZ,
+  last_error       TEXT,
+  attempts_json    JSONB NOT NULL DEFAULT '[]'::jsonb,
+  sent_at          TIMESTAMPTZ,
+  created_at       TIMESTAMPTZ NOT NULL,
+  updated_at       TIMESTAMPTZ NOT NULL
 );
 
-CREATE INDEX IF NOT EXISTS idx_rel_slack_outbox_due ON relia_slack_outbox(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_rel_outbox_due ON relia_outbox(kind, status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_rel_outbox_status ON relia_outbox(status, created_at);
@@ -72,13 +72,20 @@ CREATE INDEX IF NOT EXISTS idx_idem_status ON idempotency_keys(status);
 CREATE TABLE IF NOT EXISTS approvals (
   approval_id    TEXT PRIMARY KEY,
   idem_key       TEXT NOT NULL UNIQUE,
-  status         TEXT NOT NULL CHECK (status IN ('pending','approved','denied')),
+  status         TEXT NOT NULL CHECK (status IN ('pending','approved','denied','expired')),
   slack_channel  TEXT,
   slack_msg_ts   TEXT,
 
   approved_by    TEXT,
   approved_at    TEXT,
 
+  required_approvals INTEGER NOT NULL DEFAULT 1,
+  approver_groups    TEXT NOT NULL DEFAULT '[]', -- JSON array
+  requested_by       TEXT,
+  votes_json         TEXT NOT NULL DEFAULT '[]',
+
+  expires_at     TEXT,
+
   created_at     TEXT NOT NULL,
   updated_at     TEXT NOT NULL,
 
@@ -86,6 +93,7 @@ CREATE TABLE IF NOT EXISTS approvals (
 );
 
 CREATE INDEX IF NOT EXISTS idx_approvals_status ON approvals(status);
+CREATE INDEX IF NOT EXISTS idx_approvals_expiry ON approvals(status, expires_at);
 
 -- =========================
 -- Receipts
@@ -104,6 +112,7 @@ CREATE TABLE IF NOT EXISTS receipts (
   approval_id            TEXT,
   outcome_status         TEXT NOT NULL CHECK (outcome_status IN (
                          'approval_pending','approval_approved','approval_denied',
+                         'approval_expired',
                          'issuing_credentials','issued_credentials',
                          'denied','issue_failed'
                        )),
@@ -133,21 +142,26 @@ CREATE INDEX IF NOT EXISTS idx_receipts_policy       ON receipts(policy_hash);
 CREATE INDEX IF NOT EXISTS idx_receipts_final        ON receipts(final);
 
 -- =========================
--- Slack outbox
+-- Outbox
 -- =========================
-CREATE TABLE IF NOT EXISTS slack_outbox (
-  notification_id TEXT PRIMARY KEY,
-  approval_id     TEXT NOT NULL UNIQUE,
-  channel         TEXT NOT NULL,
-  message_json    TEXT NOT NULL,
-  status          TEXT NOT NULL CHECK (status IN ('pending','sent')),
-  attempt_count   INTEGER NOT NULL,
-  next_attempt_at TEXT NOT NULL,
-  last_error      TEXT,
-  sent_at         TEXT,
-  created_at      TEXT NOT NULL,
-  updated_at      TEXT NOT NULL,
-  FOREIGN KEY(approval_id) REFERENCES approvals(approval_id)
+CREATE TABLE IF NOT EXISTS outbox (
+  outbox_id        TEXT PRIMARY KEY,
+  kind             TEXT NOT NULL CHECK (kind IN ('slack_post','slack_update','teams_post','webhook','event_export')),
+  approval_id      TEXT NOT NULL DEFAULT '',
+  target           TEXT NOT NULL DEFAULT '',
+  payload_json     TEXT NOT NULL,
+  status           TEXT NOT NULL CHECK (status IN ('pending','sent','dead')),
+  attempt_count    INTEGER NOT NULL,
+  max_attempts     INTEGER NOT NULL,
+  next_attempt_at  TEXT NOT NULL,
+  lease_owner      TEXT,
+  lease_expires_at TEXT,
+  last_error       TEXT,
+  attempts_json    TEXT NOT NULL DEFAULT '[]',
+  sent_at          TEXT,
+  created_at       TEXT NOT NULL,
+  updated_at       TEXT NOT NULL
 );
 
-CREATE INDEX IF NOT EXISTS idx_slack_outbox_due ON slack_outbox(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox(kind, status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_status ON outbox(status, created_at);
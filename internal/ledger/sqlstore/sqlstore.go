@@ -3,7 +3,10 @@ package sqlstore
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
 
 	_ "modernc.org/sqlite"
 
@@ -52,7 +55,9 @@ func (s *Store) WithTx(fn func(ledger.Tx) error) error {
 	if err != nil {
 		return err
 	}
-	if _, err := tx.Exec("PRAGMA foreign_keys = ON;"); err != nil {
+	// Pragmas are per connection, so set them on the one this transaction holds. The
+	// busy timeout makes a writer wait for the database lock instead of failing.
+	if _, err := tx.Exec("PRAGMA foreign_keys = ON; PRAGMA busy_timeout = 5000;"); err != nil {
 		_ = tx.Rollback()
 		return err
 	}
@@ -77,47 +82,81 @@ func (s *Store) GetKey(keyID string) (ledger.KeyRecord, bool) {
 	return rec, true
 }
 
-func (s *Store) PutSlackOutbox(rec ledger.SlackOutboxRecord) error {
-	return s.WithTx(func(tx ledger.Tx) error { return tx.PutSlackOutbox(rec) })
+func (s *Store) PutOutbox(rec ledger.OutboxRecord) error {
+	return s.WithTx(func(tx ledger.Tx) error { return tx.PutOutbox(rec) })
 }
 
-func (s *Store) GetSlackOutbox(notificationID string) (ledger.SlackOutboxRecord, bool) {
-	var rec ledger.SlackOutboxRecord
-	var msg string
-	row := s.db.QueryRow(`SELECT notification_id, approval_id, channel, message_json, status, attempt_count, next_attempt_at, last_error, sent_at, created_at, updated_at
-FROM slack_outbox WHERE notification_id = ?`, notificationID)
-	if err := row.Scan(&rec.NotificationID, &rec.ApprovalID, &rec.Channel, &msg, &rec.Status, &rec.AttemptCount, &rec.NextAttemptAt, &rec.LastError, &rec.SentAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.SlackOutboxRecord{}, false
-	}
-	rec.MessageJSON = []byte(msg)
-	return rec, true
+func (s *Store) GetOutbox(outboxID string) (ledger.OutboxRecord, bool) {
+	return scanOutbox(s.db.QueryRow(`SELECT `+outboxColumns+` FROM outbox WHERE outbox_id = ?`, outboxID))
 }
 
-func (s *Store) ListSlackOutboxDue(now string, limit int) ([]ledger.SlackOutboxRecord, error) {
+// LeaseOutboxDue claims due records in a single UPDATE, which SQLite runs under its
+// write lock, so concurrent workers never lease the same record.
+func (s *Store) LeaseOutboxDue(kind string, lease ledger.OutboxLease, limit int) ([]ledger.OutboxRecord, error) {
 	if limit <= 0 {
 		limit = 100
 	}
-	rows, err := s.db.Query(`SELECT notification_id, approval_id, channel, message_json, status, attempt_count, next_attempt_at, last_error, sent_at, created_at, updated_at
-FROM slack_outbox
-WHERE status = 'pending' AND next_attempt_at <= ?
-ORDER BY created_at ASC
-LIMIT ?`, now, limit)
+	rows, err := s.db.Query(`UPDATE outbox SET lease_owner = ?, lease_token = ?, lease_expires_at = ?
+WHERE outbox_id IN (
+  SELECT outbox_id FROM outbox
+  WHERE status = 'pending' AND kind = ? AND next_attempt_at <= ? AND (lease_expires_at IS NULL OR lease_expires_at <= ?)
+  ORDER BY next_attempt_at ASC
+  LIMIT ?
+)
+RETURNING `+outboxColumns, lease.Owner, lease.Token, lease.Until, kind, lease.Now, lease.Now, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	out, err := scanOutboxRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NextAttemptAt < out[j].NextAttemptAt })
+	return out, nil
+}
 
-	out := []ledger.SlackOutboxRecord{}
-	for rows.Next() {
-		var rec ledger.SlackOutboxRecord
-		var msg string
-		if err := rows.Scan(&rec.NotificationID, &rec.ApprovalID, &rec.Channel, &msg, &rec.Status, &rec.AttemptCount, &rec.NextAttemptAt, &rec.LastError, &rec.SentAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-			return nil, err
-		}
-		rec.MessageJSON = []byte(msg)
-		out = append(out, rec)
+func (s *Store) LeaseOutbox(outboxID string, lease ledger.OutboxLease) (ledger.OutboxRecord, bool, error) {
+	rec, err := scanOutboxRow(s.db.QueryRow(`UPDATE outbox SET lease_owner = ?, lease_token = ?, lease_expires_at = ?
+WHERE outbox_id = ? AND status = 'pending' AND next_attempt_at <= ? AND (lease_expires_at IS NULL OR lease_expires_at <= ?)
+RETURNING `+outboxColumns, lease.Owner, lease.Token, lease.Until, outboxID, lease.Now, lease.Now))
+	if err == sql.ErrNoRows {
+		return ledger.OutboxRecord{}, false, nil
 	}
-	return out, rows.Err()
+	if err != nil {
+		return ledger.OutboxRecord{}, false, err
+	}
+	return rec, true, nil
+}
+
+func (s *Store) SettleOutbox(rec ledger.OutboxRecord, leaseToken string) (bool, error) {
+	attempts, err := outboxAttemptsJSON(rec)
+	if err != nil {
+		return false, err
+	}
+	res, err := s.db.Exec(`UPDATE outbox SET status = ?, attempt_count = ?, next_attempt_at = ?, lease_owner = ?, lease_token = ?, lease_expires_at = ?, last_error = ?, attempts_json = ?, sent_at = ?, updated_at = ?
+WHERE outbox_id = ? AND lease_token = ?`,
+		rec.Status, rec.AttemptCount, rec.NextAttemptAt, rec.LeaseOwner, rec.LeaseToken, rec.LeaseExpiresAt, rec.LastError, attempts, rec.SentAt, rec.UpdatedAt,
+		rec.OutboxID, leaseToken)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (s *Store) ListOutbox(status string, limit int) ([]ledger.OutboxRecord, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.Query(`SELECT `+outboxColumns+`
+FROM outbox
+WHERE (? = '' OR status = ?)
+ORDER BY created_at ASC, outbox_id ASC
+LIMIT ?`, status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutboxRows(rows)
 }
 
 func (s *Store) PutPolicyVersion(policy ledger.PolicyVersionRecord) error {
@@ -148,6 +187,35 @@ func (s *Store) GetContext(contextID string) (ledger.ContextRecord, bool) {
 	return rec, true
 }
 
+func (s *Store) ListContexts(since string, limit int) ([]ledger.ContextRecord, error) {
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	var rows *sql.Rows
+	var err error
+	if since == "" {
+		rows, err = s.db.Query(`SELECT context_id, body_json, created_at FROM contexts ORDER BY created_at ASC, context_id ASC LIMIT ?`, limit)
+	} else {
+		rows, err = s.db.Query(`SELECT context_id, body_json, created_at FROM contexts WHERE created_at >= ? ORDER BY created_at ASC, context_id ASC LIMIT ?`, since, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ledger.ContextRecord{}
+	for rows.Next() {
+		var rec ledger.ContextRecord
+		var body string
+		if err := rows.Scan(&rec.ContextID, &body, &rec.CreatedAt); err != nil {
+			return nil, err
+		}
+		rec.BodyJSON = []byte(body)
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
 func (s *Store) PutDecision(decision ledger.DecisionRecord) error {
 	return s.WithTx(func(tx ledger.Tx) error { return tx.PutDecision(decision) })
 }
@@ -197,26 +265,96 @@ FROM receipts WHERE receipt_id = ?`, receiptID)
 	return rec, true
 }
 
+func (s *Store) ListReceiptsByIdemKey(idemKey string) ([]ledger.ReceiptRecord, error) {
+	rows, err := s.db.Query(`SELECT receipt_id, idem_key, created_at, supersedes_receipt_id, context_id, decision_id, policy_hash, approval_id, outcome_status, final, expires_at, body_json, body_digest, key_id, sig
+FROM receipts
+WHERE idem_key = ?
+ORDER BY created_at ASC, receipt_id ASC`, idemKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ledger.ReceiptRecord{}
+	for rows.Next() {
+		var rec ledger.ReceiptRecord
+		var finalInt int
+		var body string
+		if err := rows.Scan(&rec.ReceiptID, &rec.IdemKey, &rec.CreatedAt, &rec.SupersedesReceiptID, &rec.ContextID, &rec.DecisionID, &rec.PolicyHash, &rec.ApprovalID, &rec.OutcomeStatus, &finalInt, &rec.ExpiresAt, &body, &rec.BodyDigest, &rec.KeyID, &rec.Sig); err != nil {
+			return nil, err
+		}
+		rec.Final = finalInt != 0
+		rec.BodyJSON = []byte(body)
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
 func (s *Store) PutApproval(approval ledger.ApprovalRecord) error {
 	return s.WithTx(func(tx ledger.Tx) error { return tx.PutApproval(approval) })
 }
 
+func (s *Store) SetApprovalSlackMessage(approvalID, channel, msgTS, updatedAt string) error {
+	_, err := s.db.Exec(`UPDATE approvals SET slack_channel = ?, slack_msg_ts = ?, updated_at = ? WHERE approval_id = ?`, channel, msgTS, updatedAt, approvalID)
+	return err
+}
+
 func (s *Store) GetApproval(approvalID string) (ledger.ApprovalRecord, bool) {
-	var rec ledger.ApprovalRecord
-	row := s.db.QueryRow(`SELECT approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, created_at, updated_at FROM approvals WHERE approval_id = ?`, approvalID)
-	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.ApprovalRecord{}, false
-	}
-	return rec, true
+	return scanApproval(s.db.QueryRow(`SELECT `+approvalColumns+` FROM approvals WHERE approval_id = ?`, approvalID))
 }
 
 func (s *Store) GetApprovalByIdemKey(idemKey string) (ledger.ApprovalRecord, bool) {
-	var rec ledger.ApprovalRecord
-	row := s.db.QueryRow(`SELECT approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, created_at, updated_at FROM approvals WHERE idem_key = ?`, idemKey)
-	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.ApprovalRecord{}, false
+	return scanApproval(s.db.QueryRow(`SELECT `+approvalColumns+` FROM approvals WHERE idem_key = ?`, idemKey))
+}
+
+func (s *Store) ListExpiredApprovals(now string, limit int) ([]ledger.ApprovalRecord, error) {
+	if limit <= 0 {
+		limit = 100
 	}
-	return rec, true
+	rows, err := s.db.Query(`SELECT `+approvalColumns+`
+FROM approvals
+WHERE status = 'pending' AND expires_at IS NOT NULL AND expires_at <= ?
+ORDER BY expires_at ASC, approval_id ASC
+LIMIT ?`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ledger.ApprovalRecord{}
+	for rows.Next() {
+		rec, ok := scanApproval(rows)
+		if !ok {
+			return nil, fmt.Errorf("scan approval")
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) ListApprovals(status string, limit int) ([]ledger.ApprovalRecord, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.Query(`SELECT `+approvalColumns+`
+FROM approvals
+WHERE ? = '' OR status = ?
+ORDER BY created_at ASC, approval_id ASC
+LIMIT ?`, status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []ledger.ApprovalRecord{}
+	for rows.Next() {
+		rec, ok := scanApproval(rows)
+		if !ok {
+			return nil, fmt.Errorf("scan approval")
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
 }
 
 func (s *Store) PutIdempotencyKey(key ledger.IdempotencyKey) error {
@@ -258,42 +396,49 @@ func (t *Tx) GetKey(keyID string) (ledger.KeyRecord, bool) {
 	return rec, true
 }
 
-func (t *Tx) PutSlackOutbox(rec ledger.SlackOutboxRecord) error {
-	_, err := t.tx.Exec(
-		`INSERT INTO slack_outbox(notification_id, approval_id, channel, message_json, status, attempt_count, next_attempt_at, last_error, sent_at, created_at, updated_at)
-VALUES(?,?,?,?,?,?,?,?,?,?,?)
-ON CONFLICT(notification_id) DO UPDATE SET
+func (t *Tx) PutOutbox(rec ledger.OutboxRecord) error {
+	attempts, err := outboxAttemptsJSON(rec)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(
+		`INSERT INTO outbox(outbox_id, kind, approval_id, target, payload_json, status, attempt_count, max_attempts, next_attempt_at, lease_owner, lease_expires_at, last_error, attempts_json, sent_at, created_at, updated_at, lease_token)
+VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+ON CONFLICT(outbox_id) DO UPDATE SET
   status=excluded.status,
   attempt_count=excluded.attempt_count,
+  max_attempts=excluded.max_attempts,
   next_attempt_at=excluded.next_attempt_at,
+  lease_owner=excluded.lease_owner,
+  lease_token=excluded.lease_token,
+  lease_expires_at=excluded.lease_expires_at,
   last_error=excluded.last_error,
+  attempts_json=excluded.attempts_json,
   sent_at=excluded.sent_at,
   updated_at=excluded.updated_at`,
-		rec.NotificationID,
+		rec.OutboxID,
+		rec.Kind,
 		rec.ApprovalID,
-		rec.Channel,
-		string(rec.MessageJSON),
+		rec.Target,
+		string(rec.PayloadJSON),
 		rec.Status,
 		rec.AttemptCount,
+		rec.MaxAttempts,
 		rec.NextAttemptAt,
+		rec.LeaseOwner,
+		rec.LeaseExpiresAt,
 		rec.LastError,
+		attempts,
 		rec.SentAt,
 		rec.CreatedAt,
 		rec.UpdatedAt,
+		rec.LeaseToken,
 	)
 	return err
 }
 
-func (t *Tx) GetSlackOutbox(notificationID string) (ledger.SlackOutboxRecord, bool) {
-	var rec ledger.SlackOutboxRecord
-	var msg string
-	row := t.tx.QueryRow(`SELECT notification_id, approval_id, channel, message_json, status, attempt_count, next_attempt_at, last_error, sent_at, created_at, updated_at
-FROM slack_outbox WHERE notification_id = ?`, notificationID)
-	if err := row.Scan(&rec.NotificationID, &rec.ApprovalID, &rec.Channel, &msg, &rec.Status, &rec.AttemptCount, &rec.NextAttemptAt, &rec.LastError, &rec.SentAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.SlackOutboxRecord{}, false
-	}
-	rec.MessageJSON = []byte(msg)
-	return rec, true
+func (t *Tx) GetOutbox(outboxID string) (ledger.OutboxRecord, bool) {
+	return scanOutbox(t.tx.QueryRow(`SELECT `+outboxColumns+` FROM outbox WHERE outbox_id = ?`, outboxID))
 }
 
 func (t *Tx) PutPolicyVersion(policy ledger.PolicyVersionRecord) error {
@@ -388,14 +533,23 @@ func (t *Tx) GetReceipt(receiptID string) (ledger.ReceiptRecord, bool) {
 }
 
 func (t *Tx) PutApproval(approval ledger.ApprovalRecord) error {
-	_, err := t.tx.Exec(`INSERT INTO approvals(approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, created_at, updated_at)
-VALUES(?,?,?,?,?,?,?,?,?)
+	groups, votes, err := approvalJSON(approval)
+	if err != nil {
+		return err
+	}
+	_, err = t.tx.Exec(`INSERT INTO approvals(approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, required_approvals, approver_groups, requested_by, votes_json, expires_at, created_at, updated_at)
+VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?)
 ON CONFLICT(approval_id) DO UPDATE SET
   status=excluded.status,
   slack_channel=COALESCE(excluded.slack_channel, approvals.slack_channel),
   slack_msg_ts=COALESCE(excluded.slack_msg_ts, approvals.slack_msg_ts),
   approved_by=COALESCE(excluded.approved_by, approvals.approved_by),
   approved_at=COALESCE(excluded.approved_at, approvals.approved_at),
+  required_approvals=excluded.required_approvals,
+  approver_groups=excluded.approver_groups,
+  requested_by=COALESCE(excluded.requested_by, approvals.requested_by),
+  votes_json=excluded.votes_json,
+  expires_at=COALESCE(excluded.expires_at, approvals.expires_at),
   updated_at=excluded.updated_at`,
 		approval.ApprovalID,
 		approval.IdemKey,
@@ -404,6 +558,11 @@ ON CONFLICT(approval_id) DO UPDATE SET
 		approval.SlackMsgTS,
 		approval.ApprovedBy,
 		approval.ApprovedAt,
+		requiredApprovals(approval),
+		groups,
+		approval.RequestedBy,
+		votes,
+		approval.ExpiresAt,
 		approval.CreatedAt,
 		approval.UpdatedAt,
 	)
@@ -411,21 +570,25 @@ ON CONFLICT(approval_id) DO UPDATE SET
 }
 
 func (t *Tx) GetApproval(approvalID string) (ledger.ApprovalRecord, bool) {
-	var rec ledger.ApprovalRecord
-	row := t.tx.QueryRow(`SELECT approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, created_at, updated_at FROM approvals WHERE approval_id = ?`, approvalID)
-	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.ApprovalRecord{}, false
+	return scanApproval(t.tx.QueryRow(`SELECT `+approvalColumns+` FROM approvals WHERE approval_id = ?`, approvalID))
+}
+
+// LockApproval takes SQLite's database write lock with a no-op update before reading,
+// so the read cannot come from a snapshot another writer is about to replace.
+func (t *Tx) LockApproval(approvalID string) (ledger.ApprovalRecord, bool, error) {
+	res, err := t.tx.Exec(`UPDATE approvals SET approval_id = approval_id WHERE approval_id = ?`, approvalID)
+	if err != nil {
+		return ledger.ApprovalRecord{}, false, err
 	}
-	return rec, true
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return ledger.ApprovalRecord{}, false, err
+	}
+	approval, ok := t.GetApproval(approvalID)
+	return approval, ok, nil
 }
 
 func (t *Tx) GetApprovalByIdemKey(idemKey string) (ledger.ApprovalRecord, bool) {
-	var rec ledger.ApprovalRecord
-	row := t.tx.QueryRow(`SELECT approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, created_at, updated_at FROM approvals WHERE idem_key = ?`, idemKey)
-	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
-		return ledger.ApprovalRecord{}, false
-	}
-	return rec, true
+	return scanApproval(t.tx.QueryRow(`SELECT `+approvalColumns+` FROM approvals WHERE idem_key = ?`, idemKey))
 }
 
 func (t *Tx) PutIdempotencyKey(key ledger.IdempotencyKey) error {
@@ -465,3 +628,98 @@ func boolToInt(b bool) int {
 	}
 	return 0
 }
+
+const approvalColumns = `approval_id, idem_key, status, slack_channel, slack_msg_ts, approved_by, approved_at, required_approvals, approver_groups, requested_by, votes_json, expires_at, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApproval(row rowScanner) (ledger.ApprovalRecord, bool) {
+	var rec ledger.ApprovalRecord
+	var groups, votes string
+	if err := row.Scan(&rec.ApprovalID, &rec.IdemKey, &rec.Status, &rec.SlackChannel, &rec.SlackMsgTS, &rec.ApprovedBy, &rec.ApprovedAt, &rec.RequiredApprovals, &groups, &rec.RequestedBy, &votes, &rec.ExpiresAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
+		return ledger.ApprovalRecord{}, false
+	}
+	if err := json.Unmarshal([]byte(groups), &rec.ApproverGroups); err != nil {
+		return ledger.ApprovalRecord{}, false
+	}
+	if err := json.Unmarshal([]byte(votes), &rec.Votes); err != nil {
+		return ledger.ApprovalRecord{}, false
+	}
+	return rec, true
+}
+
+// approvalJSON encodes the approver groups and votes as JSON arrays.
+func approvalJSON(approval ledger.ApprovalRecord) (string, string, error) {
+	groups := approval.ApproverGroups
+	if groups == nil {
+		groups = []string{}
+	}
+	votes := approval.Votes
+	if votes == nil {
+		votes = []ledger.ApprovalVote{}
+	}
+	groupsJSON, err := json.Marshal(groups)
+	if err != nil {
+		return "", "", err
+	}
+	votesJSON, err := json.Marshal(votes)
+	if err != nil {
+		return "", "", err
+	}
+	return string(groupsJSON), string(votesJSON), nil
+}
+
+const outboxColumns = `outbox_id, kind, approval_id, target, payload_json, status, attempt_count, max_attempts, next_attempt_at, lease_owner, lease_expires_at, last_error, attempts_json, sent_at, created_at, updated_at, lease_token`
+
+func scanOutbox(row rowScanner) (ledger.OutboxRecord, bool) {
+	rec, err := scanOutboxRow(row)
+	return rec, err == nil
+}
+
+func scanOutboxRow(row rowScanner) (ledger.OutboxRecord, error) {
+	var rec ledger.OutboxRecord
+	var payload, attempts string
+	if err := row.Scan(&rec.OutboxID, &rec.Kind, &rec.ApprovalID, &rec.Target, &payload, &rec.Status, &rec.AttemptCount, &rec.MaxAttempts, &rec.NextAttemptAt, &rec.LeaseOwner, &rec.LeaseExpiresAt, &rec.LastError, &attempts, &rec.SentAt, &rec.CreatedAt, &rec.UpdatedAt, &rec.LeaseToken); err != nil {
+		return ledger.OutboxRecord{}, err
+	}
+	rec.PayloadJSON = []byte(payload)
+	if err := json.Unmarshal([]byte(attempts), &rec.Attempts); err != nil {
+		return ledger.OutboxRecord{}, err
+	}
+	return rec, nil
+}
+
+func scanOutboxRows(rows *sql.Rows) ([]ledger.OutboxRecord, error) {
+	defer rows.Close()
+	out := []ledger.OutboxRecord{}
+	for rows.Next() {
+		rec, err := scanOutboxRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// outboxAttemptsJSON encodes the attempt history as a JSON array.
+func outboxAttemptsJSON(rec ledger.OutboxRecord) (string, error) {
+	attempts := rec.Attempts
+	if attempts == nil {
+		attempts = []ledger.OutboxAttempt{}
+	}
+	data, err := json.Marshal(attempts)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func requiredApprovals(approval ledger.ApprovalRecord) int {
+	if approval.RequiredApprovals < 1 {
+		return 1
+	}
+	return approval.RequiredApprovals
+}
@@ -56,6 +56,12 @@ func TestStoreCRUD(t *testing.T) {
 	if got, ok := s.GetContext("ctx1"); !ok || string(got.BodyJSON) != string(ctx.BodyJSON) {
 		t.Fatalf("get context mismatch: ok=%v got=%+v", ok, got)
 	}
+	if list, err := s.ListContexts("", 0); err != nil || len(list) != 1 || list[0].ContextID != "ctx1" {
+		t.Fatalf("list contexts mismatch: err=%v list=%+v", err, list)
+	}
+	if list, err := s.ListContexts("2025-12-21T00:00:00Z", 10); err != nil || len(list) != 0 {
+		t.Fatalf("list contexts since mismatch: err=%v list=%+v", err, list)
+	}
 
 	dec := ledger.DecisionRecord{DecisionID: "dec1", ContextID: "ctx1", PolicyHash: "ph", Verdict: "allow", BodyJSON: []byte(`{"decision_id":"dec1"}`), CreatedAt: "2025-12-20T00:00:02Z"}
 	if err := s.PutDecision(dec); err != nil {
@@ -92,25 +98,67 @@ func TestStoreCRUD(t *testing.T) {
 		t.Fatalf("get approval by idem mismatch: ok=%v got=%+v", ok, got)
 	}
 
-	outbox := ledger.SlackOutboxRecord{
-		NotificationID: "slack:a1",
-		ApprovalID:     "a1",
-		Channel:        "C123",
-		MessageJSON:    []byte(`{"approval_id":"a1"}`),
-		Status:         "pending",
-		AttemptCount:   0,
-		NextAttemptAt:  "2025-12-20T00:00:04Z",
-		CreatedAt:      "2025-12-20T00:00:04Z",
-		UpdatedAt:      "2025-12-20T00:00:04Z",
-	}
-	if err := s.PutSlackOutbox(outbox); err != nil {
+	outbox := ledger.OutboxRecord{
+		OutboxID:      "slack_post:a1",
+		Kind:          "slack_post",
+		ApprovalID:    "a1",
+		Target:        "C123",
+		PayloadJSON:   []byte(`{"approval_id":"a1"}`),
+		Status:        "pending",
+		AttemptCount:  0,
+		MaxAttempts:   3,
+		NextAttemptAt: "2025-12-20T00:00:04Z",
+		Attempts:      []ledger.OutboxAttempt{{At: "2025-12-20T00:00:03Z", Error: "rate_limited"}},
+		CreatedAt:     "2025-12-20T00:00:04Z",
+		UpdatedAt:     "2025-12-20T00:00:04Z",
+	}
+	if err := s.PutOutbox(outbox); err != nil {
 		t.Fatalf("put outbox: %v", err)
 	}
-	if got, ok := s.GetSlackOutbox("slack:a1"); !ok || got.ApprovalID != "a1" {
+	if got, ok := s.GetOutbox("slack_post:a1"); !ok || got.ApprovalID != "a1" || len(got.Attempts) != 1 || got.Attempts[0].Error != "rate_limited" {
 		t.Fatalf("get outbox mismatch: ok=%v got=%+v", ok, got)
 	}
-	if due, err := s.ListSlackOutboxDue("2025-12-21T00:00:00Z", 10); err != nil || len(due) != 1 {
-		t.Fatalf("list due mismatch: err=%v len=%d", err, len(due))
+	if due, err := s.LeaseOutboxDue("teams_post", ledger.OutboxLease{Owner: "w1", Token: "t1", Now: "2025-12-21T00:00:00Z", Until: "2025-12-21T00:01:00Z"}, 10); err != nil || len(due) != 0 {
+		t.Fatalf("expected no teams records: err=%v len=%d", err, len(due))
+	}
+	due, err := s.LeaseOutboxDue("slack_post", ledger.OutboxLease{Owner: "w1", Token: "t1", Now: "2025-12-21T00:00:00Z", Until: "2025-12-21T00:01:00Z"}, 10)
+	if err != nil || len(due) != 1 || due[0].LeaseOwner == nil || *due[0].LeaseOwner != "w1" || due[0].LeaseToken == nil || *due[0].LeaseToken != "t1" || due[0].MaxAttempts != 3 {
+		t.Fatalf("lease due mismatch: err=%v due=%+v", err, due)
+	}
+	if due, err := s.LeaseOutboxDue("slack_post", ledger.OutboxLease{Owner: "w2", Token: "t2", Now: "2025-12-21T00:00:30Z", Until: "2025-12-21T00:01:30Z"}, 10); err != nil || len(due) != 0 {
+		t.Fatalf("expected the lease to hold off other workers: err=%v len=%d", err, len(due))
+	}
+	if _, ok, err := s.LeaseOutbox("slack_post:a1", ledger.OutboxLease{Owner: "w2", Token: "t2", Now: "2025-12-21T00:00:30Z", Until: "2025-12-21T00:01:30Z"}); err != nil || ok {
+		t.Fatalf("expected the lease to hold off a claim by id: ok=%v err=%v", ok, err)
+	}
+	// The lapsed lease is reclaimed by a worker sharing the first one's owner name; only
+	// the token tells the two claims apart.
+	reclaimed, ok, err := s.LeaseOutbox("slack_post:a1", ledger.OutboxLease{Owner: "w1", Token: "t2", Now: "2025-12-21T00:02:00Z", Until: "2025-12-21T00:03:00Z"})
+	if err != nil || !ok || *reclaimed.LeaseToken != "t2" {
+		t.Fatalf("expected a lapsed lease to be reclaimed: ok=%v err=%v rec=%+v", ok, err, reclaimed)
+	}
+	settled := reclaimed
+	settled.Status, settled.LeaseOwner, settled.LeaseToken, settled.LeaseExpiresAt = "sent", nil, nil, nil
+	if ok, err := s.SettleOutbox(settled, "t1"); err != nil || ok {
+		t.Fatalf("expected the lapsed lease's settle to be refused: ok=%v err=%v", ok, err)
+	}
+	if got, _ := s.GetOutbox("slack_post:a1"); got.Status != "pending" || got.LeaseToken == nil || *got.LeaseToken != "t2" {
+		t.Fatalf("expected the record left to the second lease, got %+v", got)
+	}
+	if ok, err := s.SettleOutbox(settled, "t2"); err != nil || !ok {
+		t.Fatalf("expected the lease holder's settle to apply: ok=%v err=%v", ok, err)
+	}
+	if got, _ := s.GetOutbox("slack_post:a1"); got.Status != "sent" || got.LeaseOwner != nil || got.LeaseToken != nil {
+		t.Fatalf("expected the record settled, got %+v", got)
+	}
+	if _, ok, err := s.LeaseOutbox("slack_post:a1", ledger.OutboxLease{Owner: "w1", Token: "t3", Now: "2025-12-21T00:04:00Z", Until: "2025-12-21T00:05:00Z"}); err != nil || ok {
+		t.Fatalf("expected a sent record not to be claimed: ok=%v err=%v", ok, err)
+	}
+	if list, err := s.ListOutbox("dead", 10); err != nil || len(list) != 0 {
+		t.Fatalf("expected no dead records: err=%v len=%d", err, len(list))
+	}
+	if list, err := s.ListOutbox("", 10); err != nil || len(list) != 1 {
+		t.Fatalf("expected one record: err=%v len=%d", err, len(list))
 	}
 
 	receipt := ledger.ReceiptRecord{
@@ -134,6 +182,9 @@ func TestStoreCRUD(t *testing.T) {
 	if got, ok := s.GetReceipt("r1"); !ok || got.BodyDigest != "digest" || !got.Final {
 		t.Fatalf("get receipt mismatch: ok=%v got=%+v", ok, got)
 	}
+	if list, err := s.ListReceiptsByIdemKey("idem1"); err != nil || len(list) != 1 || list[0].ReceiptID != "r1" || !list[0].Final {
+		t.Fatalf("list receipts mismatch: err=%v list=%+v", err, list)
+	}
 
 	channel := "C123"
 	ts := "1700000000.1234"
@@ -147,6 +198,50 @@ func TestStoreCRUD(t *testing.T) {
 		t.Fatalf("approval update mismatch: ok=%v got=%+v", ok, got)
 	}
 
+	requester := "octocat"
+	approval.RequiredApprovals = 2
+	approval.ApproverGroups = []string{"sre", "security"}
+	approval.RequestedBy = &requester
+	approval.Votes = []ledger.ApprovalVote{{ApproverKind: "slack", ApproverID: "U1", Status: "approved", ReceiptID: "r1", CreatedAt: "2025-12-20T00:00:05Z"}}
+	if err := s.PutApproval(approval); err != nil {
+		t.Fatalf("put approval votes: %v", err)
+	}
+	if got, ok := s.GetApproval("a1"); !ok || got.RequiredApprovals != 2 || len(got.ApproverGroups) != 2 || *got.RequestedBy != requester || len(got.Votes) != 1 || got.Votes[0].ApproverID != "U1" {
+		t.Fatalf("approval votes mismatch: ok=%v got=%+v", ok, got)
+	}
+
+	if err := s.SetApprovalSlackMessage("a1", "C456", "1700000001.0001", "2025-12-20T00:00:06Z"); err != nil {
+		t.Fatalf("set slack message: %v", err)
+	}
+	if got, ok := s.GetApproval("a1"); !ok || *got.SlackChannel != "C456" || *got.SlackMsgTS != "1700000001.0001" || len(got.Votes) != 1 || got.RequiredApprovals != 2 {
+		t.Fatalf("expected only the slack message updated: ok=%v got=%+v", ok, got)
+	}
+
+	expiresAt := "2025-12-20T01:00:00Z"
+	approval.ExpiresAt = &expiresAt
+	if err := s.PutApproval(approval); err != nil {
+		t.Fatalf("put approval expiry: %v", err)
+	}
+	if due, err := s.ListExpiredApprovals("2025-12-20T00:59:59Z", 10); err != nil || len(due) != 0 {
+		t.Fatalf("expected nothing expired yet: err=%v due=%+v", err, due)
+	}
+	if due, err := s.ListExpiredApprovals(expiresAt, 10); err != nil || len(due) != 1 || *due[0].ExpiresAt != expiresAt {
+		t.Fatalf("expected expired approval: err=%v due=%+v", err, due)
+	}
+	approval.Status = "expired"
+	if err := s.PutApproval(approval); err != nil {
+		t.Fatalf("put expired approval: %v", err)
+	}
+	if due, err := s.ListExpiredApprovals(expiresAt, 10); err != nil || len(due) != 0 {
+		t.Fatalf("expected expired approvals to be skipped: err=%v due=%+v", err, due)
+	}
+	if listed, err := s.ListApprovals("pending", 10); err != nil || len(listed) != 0 {
+		t.Fatalf("expected no pending approvals: err=%v listed=%+v", err, listed)
+	}
+	if listed, err := s.ListApprovals("", 10); err != nil || len(listed) != 1 || listed[0].Status != "expired" {
+		t.Fatalf("expected all approvals: err=%v listed=%+v", err, listed)
+	}
+
 	idem.Status = "allowed"
 	idem.ApprovalID = &approval.ApprovalID
 	idem.LatestReceiptID = &receipt.ReceiptID
@@ -243,25 +338,33 @@ func TestTxGetters(t *testing.T) {
 		if _, ok := tx.GetApproval("a-tx"); !ok {
 			t.Fatalf("expected approval")
 		}
+		if approval, ok, err := tx.LockApproval("a-tx"); !ok || err != nil || approval.ApprovalID != "a-tx" {
+			t.Fatalf("expected locked approval: ok=%v err=%v", ok, err)
+		}
+		if _, ok, err := tx.LockApproval("missing"); ok || err != nil {
+			t.Fatalf("expected no approval to lock: ok=%v err=%v", ok, err)
+		}
 		if _, ok := tx.GetApprovalByIdemKey("idem-tx"); !ok {
 			t.Fatalf("expected approval by idem")
 		}
 
-		outbox := ledger.SlackOutboxRecord{
-			NotificationID: "slack:a-tx",
-			ApprovalID:     "a-tx",
-			Channel:        "C1",
-			MessageJSON:    []byte(`{"approval_id":"a-tx"}`),
-			Status:         "pending",
-			AttemptCount:   0,
-			NextAttemptAt:  "now",
-			CreatedAt:      "now",
-			UpdatedAt:      "now",
+		outbox := ledger.OutboxRecord{
+			OutboxID:      "slack_post:a-tx",
+			Kind:          "slack_post",
+			ApprovalID:    "a-tx",
+			Target:        "C1",
+			PayloadJSON:   []byte(`{"approval_id":"a-tx"}`),
+			Status:        "pending",
+			AttemptCount:  0,
+			MaxAttempts:   10,
+			NextAttemptAt: "now",
+			CreatedAt:     "now",
+			UpdatedAt:     "now",
 		}
-		if err := tx.PutSlackOutbox(outbox); err != nil {
+		if err := tx.PutOutbox(outbox); err != nil {
 			return err
 		}
-		if _, ok := tx.GetSlackOutbox("slack:a-tx"); !ok {
+		if _, ok := tx.GetOutbox("slack_post:a-tx"); !ok {
 			t.Fatalf("expected outbox")
 		}
 
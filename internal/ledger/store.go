@@ -6,25 +6,53 @@ type Store interface {
 	PutKey(key KeyRecord) error
 	GetKey(keyID string) (KeyRecord, bool)
 
-	PutSlackOutbox(rec SlackOutboxRecord) error
-	GetSlackOutbox(notificationID string) (SlackOutboxRecord, bool)
-	ListSlackOutboxDue(now string, limit int) ([]SlackOutboxRecord, error)
+	PutOutbox(rec OutboxRecord) error
+	GetOutbox(outboxID string) (OutboxRecord, bool)
+	// LeaseOutboxDue claims up to limit pending records of kind whose next attempt is at
+	// or before lease.Now and that no other worker holds, leasing them until
+	// lease.Until. Records leased to another worker are skipped until the lease lapses.
+	LeaseOutboxDue(kind string, lease OutboxLease, limit int) ([]OutboxRecord, error)
+	// LeaseOutbox claims the one record outboxID on the same terms. It reports false
+	// when the record is missing, not due, or held by another worker.
+	LeaseOutbox(outboxID string, lease OutboxLease) (OutboxRecord, bool, error)
+	// SettleOutbox stores the outcome of a delivery attempt, but only while the lease
+	// with leaseToken still holds the record. It reports false, writing nothing, when
+	// the lease lapsed and another worker took the record over.
+	SettleOutbox(rec OutboxRecord, leaseToken string) (bool, error)
+	// ListOutbox returns up to limit records with the given status (all when empty),
+	// oldest first.
+	ListOutbox(status string, limit int) ([]OutboxRecord, error)
 
 	PutPolicyVersion(policy PolicyVersionRecord) error
 	GetPolicyVersion(policyHash string) (PolicyVersionRecord, bool)
 
 	PutContext(ctx ContextRecord) error
 	GetContext(contextID string) (ContextRecord, bool)
+	// ListContexts returns up to limit contexts created at or after since (RFC3339; empty
+	// for all), oldest first.
+	ListContexts(since string, limit int) ([]ContextRecord, error)
 
 	PutDecision(decision DecisionRecord) error
 	GetDecision(decisionID string) (DecisionRecord, bool)
 
 	PutReceipt(receipt ReceiptRecord) error
 	GetReceipt(receiptID string) (ReceiptRecord, bool)
+	// ListReceiptsByIdemKey returns every receipt for an idempotency key, oldest first.
+	ListReceiptsByIdemKey(idemKey string) ([]ReceiptRecord, error)
 
 	PutApproval(approval ApprovalRecord) error
 	GetApproval(approvalID string) (ApprovalRecord, bool)
 	GetApprovalByIdemKey(idemKey string) (ApprovalRecord, bool)
+	// SetApprovalSlackMessage records where an approval's Slack message was posted. It
+	// writes only those columns, so votes and status changes committed while the
+	// message was being posted are kept.
+	SetApprovalSlackMessage(approvalID, channel, msgTS, updatedAt string) error
+	// ListExpiredApprovals returns up to limit pending approvals whose expires_at is at
+	// or before now (RFC3339), soonest expiry first.
+	ListExpiredApprovals(now string, limit int) ([]ApprovalRecord, error)
+	// ListApprovals returns up to limit approvals with the given status (all when empty),
+	// oldest first.
+	ListApprovals(status string, limit int) ([]ApprovalRecord, error)
 
 	PutIdempotencyKey(key IdempotencyKey) error
 	GetIdempotencyKey(idemKey string) (IdempotencyKey, bool)
@@ -34,8 +62,8 @@ type Tx interface {
 	PutKey(key KeyRecord) error
 	GetKey(keyID string) (KeyRecord, bool)
 
-	PutSlackOutbox(rec SlackOutboxRecord) error
-	GetSlackOutbox(notificationID string) (SlackOutboxRecord, bool)
+	PutOutbox(rec OutboxRecord) error
+	GetOutbox(outboxID string) (OutboxRecord, bool)
 
 	PutPolicyVersion(policy PolicyVersionRecord) error
 	GetPolicyVersion(policyHash string) (PolicyVersionRecord, bool)
@@ -51,6 +79,10 @@ type Tx interface {
 
 	PutApproval(approval ApprovalRecord) error
 	GetApproval(approvalID string) (ApprovalRecord, bool)
+	// LockApproval is GetApproval for a read-modify-write: it holds the approval's row
+	// lock until the transaction ends, so concurrent votes and expiry on one approval
+	// run one after another instead of overwriting each other.
+	LockApproval(approvalID string) (ApprovalRecord, bool, error)
 	GetApprovalByIdemKey(idemKey string) (ApprovalRecord, bool)
 
 	PutIdempotencyKey(key IdempotencyKey) error
@@ -72,18 +104,48 @@ type KeyRecord struct {
 	RotatedAt *string
 }
 
-type SlackOutboxRecord struct {
-	NotificationID string
-	ApprovalID     string
-	Channel        string
-	MessageJSON    []byte
-	Status         string // pending | sent
-	AttemptCount   int
-	NextAttemptAt  string
+// OutboxRecord is a queued delivery to an external system: an approval request, an
+// update to a posted message, a webhook call. Workers lease due records of their kind,
+// deliver them, and record every attempt; a record that fails MaxAttempts times is
+// dead-lettered.
+type OutboxRecord struct {
+	OutboxID      string
+	Kind          string // slack_post | slack_update | teams_post | webhook | event_export
+	ApprovalID    string // empty when the record concerns no approval
+	Target        string // channel or URL, depending on kind; empty for the default
+	PayloadJSON   []byte
+	Status        string // pending | sent | dead
+	AttemptCount  int
+	MaxAttempts   int
+	NextAttemptAt string
+	// LeaseOwner, LeaseToken and LeaseExpiresAt mark a record claimed by a worker;
+	// the token is unique to the claim.
+	LeaseOwner     *string
+	LeaseToken     *string
+	LeaseExpiresAt *string
 	LastError      *string
-	SentAt         *string
-	CreatedAt      string
-	UpdatedAt      string
+	// Attempts lists every delivery attempt, oldest first.
+	Attempts  []OutboxAttempt
+	SentAt    *string
+	CreatedAt string
+	UpdatedAt string
+}
+
+// OutboxLease is a worker's claim on outbox records. Token is unique to the claim, so
+// settling can tell a worker's current lease from one it lost, even to a worker with
+// the same Owner. Now and Until are RFC3339.
+type OutboxLease struct {
+	Owner string
+	Token string
+	Now   string
+	Until string
+}
+
+// OutboxAttempt is one delivery attempt of an outbox record.
+type OutboxAttempt struct {
+	At    string `json:"at"`
+	Owner string `json:"owner,omitempty"`
+	Error string `json:"error,omitempty"`
 }
 
 type ContextRecord struct {
@@ -125,10 +187,38 @@ type ApprovalRecord struct {
 	Status       string
 	SlackChannel *string
 	SlackMsgTS   *string
-	ApprovedBy   *string
-	ApprovedAt   *string
-	CreatedAt    string
-	UpdatedAt    string
+	// ApprovedBy ("kind:id") and ApprovedAt record the vote that decided the approval.
+	ApprovedBy *string
+	ApprovedAt *string
+	CreatedAt  string
+	UpdatedAt  string
+
+	// RequiredApprovals is the number of distinct approve votes that complete the
+	// approval; zero means one.
+	RequiredApprovals int
+	// ApproverGroups, when set, limits votes to approvers in at least one group.
+	ApproverGroups []string
+	// RequestedBy is the GitHub login (the workload's actor claim) that triggered the
+	// request. Votes are checked against it only when a matched rule sets
+	// separation_of_duties, through the policy's identities mapping.
+	RequestedBy *string
+	// Votes lists every vote cast, oldest first, including rejected attempts.
+	Votes []ApprovalVote
+	// ExpiresAt (RFC3339) is when a pending approval expires; nil means never.
+	ExpiresAt *string
+}
+
+// ApprovalVote is one approver's vote on an approval, or a rejected attempt to vote.
+type ApprovalVote struct {
+	ApproverKind    string `json:"approver_kind,omitempty"`
+	ApproverID      string `json:"approver_id,omitempty"`
+	ApproverDisplay string `json:"approver_display,omitempty"`
+	ApproverTeamID  string `json:"approver_team_id,omitempty"`
+	Status          string `json:"status"` // approved | denied | rejected
+	Reason          string `json:"reason,omitempty"`
+	Comment         string `json:"comment,omitempty"`
+	ReceiptID       string `json:"receipt_id"`
+	CreatedAt       string `json:"created_at"`
 }
 
 type IdempotencyKey struct {
@@ -2,6 +2,7 @@ package ledger
 
 import (
 	"crypto/ed25519"
+	"encoding/json"
 	"errors"
 
 	"github.com/davidahmann/relia/internal/crypto"
@@ -10,9 +11,19 @@ import (
 var (
 	ErrReceiptDigestMismatch = errors.New("receipt digest mismatch")
 	ErrReceiptSignature      = errors.New("receipt signature invalid")
+	ErrReceiptSchema         = errors.New("receipt schema unsupported")
+	ErrReceiptChainMismatch  = errors.New("receipt chain mismatch")
 )
 
-// VerifyReceipt validates digest consistency and signature.
+type receiptChainFields struct {
+	Schema              string  `json:"schema"`
+	IdemKey             string  `json:"idem_key"`
+	SupersedesReceiptID *string `json:"supersedes_receipt_id"`
+}
+
+// VerifyReceipt validates digest consistency and signature. For v0.2 receipts it
+// also checks that the stored idem_key and supersedes_receipt_id columns (when
+// populated) match the signed body.
 func VerifyReceipt(receipt StoredReceipt, publicKey ed25519.PublicKey) error {
 	digestBytes := crypto.DigestBytes(receipt.BodyJSON)
 	digest := crypto.DigestWithPrefix(receipt.BodyJSON)
@@ -27,5 +38,26 @@ func VerifyReceipt(receipt StoredReceipt, publicKey ed25519.PublicKey) error {
 	if !ok {
 		return ErrReceiptSignature
 	}
+
+	var body receiptChainFields
+	if err := json.Unmarshal(receipt.BodyJSON, &body); err != nil {
+		return err
+	}
+	if !KnownReceiptSchema(body.Schema) {
+		return ErrReceiptSchema
+	}
+	if body.Schema == ReceiptSchemaV01 || receipt.IdemKey == "" {
+		return nil
+	}
+	if body.IdemKey != receipt.IdemKey || derefString(body.SupersedesReceiptID) != derefString(receipt.SupersedesReceiptID) {
+		return ErrReceiptChainMismatch
+	}
 	return nil
 }
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
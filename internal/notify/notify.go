@@ -0,0 +1,28 @@
+// Package notify holds what the approval transports (Slack, Teams, webhooks) have in
+// common.
+package notify
+
+// Transports an approval request can be posted to.
+const (
+	TransportSlack   = "slack"
+	TransportTeams   = "teams"
+	TransportWebhook = "webhook"
+)
+
+// ApprovalMessage is what an approval request shows, independent of the transport that
+// renders it. It is stored as JSON in the outbox, so fields must stay JSON-compatible.
+type ApprovalMessage struct {
+	ApprovalID string
+	ReceiptID  string
+	PolicyHash string
+	ContextID  string
+	DecisionID string
+	Action     string
+	Resource   string
+	Env        string
+	Risk       string
+	DiffURL    string
+	RunURL     string
+	// Mentions are Slack user group or user IDs to mention in the request.
+	Mentions []string
+}
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/outbox"
+)
+
+// OutboxPoster delivers an approval message; a transport's client implements it.
+type OutboxPoster interface {
+	PostApproval(channel string, message ApprovalMessage) (ref string, err error)
+}
+
+// DeliverPost returns the outbox delivery func for approval requests on transports that
+// return no message reference (Teams, webhooks), so the approval itself is left
+// untouched; Slack records the posted message and has its own in slack.DeliverPost.
+// Approvals resolved (e.g. expired) before they could be posted are skipped.
+func DeliverPost(store ledger.Store, poster OutboxPoster) outbox.DeliverFunc {
+	return func(ctx context.Context, rec ledger.OutboxRecord) error {
+		if approval, ok := store.GetApproval(rec.ApprovalID); ok && approval.Status != "pending" {
+			return nil
+		}
+
+		var input ApprovalMessage
+		if err := json.Unmarshal(rec.PayloadJSON, &input); err != nil {
+			return outbox.Permanent(fmt.Errorf("invalid payload_json: %w", err))
+		}
+
+		_, err := poster.PostApproval(rec.Target, input)
+		return err
+	}
+}
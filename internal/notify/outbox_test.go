@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/outbox"
+)
+
+type flakyPoster struct {
+	calls int
+	fail  int
+}
+
+func (p *flakyPoster) PostApproval(channel string, message ApprovalMessage) (string, error) {
+	p.calls++
+	if p.calls <= p.fail {
+		return "", errors.New("throttled")
+	}
+	return "", nil
+}
+
+func TestDeliverPost(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	if err := store.PutApproval(ledger.ApprovalRecord{ApprovalID: "a1", IdemKey: "idem1", Status: "pending", CreatedAt: "now", UpdatedAt: "now"}); err != nil {
+		t.Fatalf("put approval: %v", err)
+	}
+	msgBytes, _ := json.Marshal(ApprovalMessage{ApprovalID: "a1", Action: "x", Env: "prod"})
+	rec := ledger.OutboxRecord{OutboxID: "teams_post:a1", Kind: outbox.KindTeamsPost, ApprovalID: "a1", PayloadJSON: msgBytes}
+
+	poster := &flakyPoster{fail: 1}
+	deliver := DeliverPost(store, poster)
+	if err := deliver(context.Background(), rec); err == nil || outbox.IsPermanent(err) {
+		t.Fatalf("expected a retryable error, got %v", err)
+	}
+	if err := deliver(context.Background(), rec); err != nil || poster.calls != 2 {
+		t.Fatalf("expected delivery: calls=%d err=%v", poster.calls, err)
+	}
+
+	bad := rec
+	bad.PayloadJSON = []byte("not-json")
+	if err := deliver(context.Background(), bad); !outbox.IsPermanent(err) {
+		t.Fatalf("expected a permanent error for a bad payload, got %v", err)
+	}
+}
+
+func TestDeliverPostSkipsResolvedApprovals(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	if err := store.PutApproval(ledger.ApprovalRecord{ApprovalID: "a1", IdemKey: "idem1", Status: "expired", CreatedAt: "now", UpdatedAt: "now"}); err != nil {
+		t.Fatalf("put approval: %v", err)
+	}
+	now := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+	if err := store.PutOutbox(ledger.OutboxRecord{OutboxID: "teams_post:a1", Kind: outbox.KindTeamsPost, ApprovalID: "a1", PayloadJSON: []byte(`{}`), Status: outbox.StatusPending, NextAttemptAt: now.Format(time.RFC3339)}); err != nil {
+		t.Fatalf("put outbox: %v", err)
+	}
+
+	poster := &flakyPoster{}
+	w := &outbox.Worker{Store: store, Kind: outbox.KindTeamsPost, Deliver: DeliverPost(store, poster)}
+	if n, err := w.ProcessDue(context.Background(), now, 10); err != nil || n != 1 || poster.calls != 0 {
+		t.Fatalf("process: n=%d calls=%d err=%v", n, poster.calls, err)
+	}
+	if rec, _ := store.GetOutbox("teams_post:a1"); rec.Status != outbox.StatusSent {
+		t.Fatalf("expected sent, got %+v", rec)
+	}
+}
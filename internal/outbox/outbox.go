@@ -0,0 +1,269 @@
+// Package outbox delivers queued records from the ledger's outbox: approval requests,
+// Slack message updates and webhook calls. A worker per kind leases due records,
+// hands each to a delivery func, and records the attempt; records that keep failing
+// are dead-lettered for an operator to inspect and retry.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+)
+
+// Kinds of outbox record.
+const (
+	KindSlackPost   = "slack_post"
+	KindSlackUpdate = "slack_update"
+	KindTeamsPost   = "teams_post"
+	KindWebhook     = "webhook"
+	// KindEventExport is reserved for exporting ledger events; nothing enqueues it yet.
+	KindEventExport = "event_export"
+)
+
+// Record statuses.
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusDead    = "dead"
+)
+
+// DefaultMaxAttempts is used for records whose MaxAttempts is zero.
+const DefaultMaxAttempts = 10
+
+// DefaultLease is how long a worker holds the records it claims when Worker.Lease is
+// zero. A worker that dies mid-delivery releases its records when the lease lapses.
+const DefaultLease = time.Minute
+
+var (
+	ErrNotFound = errors.New("outbox record not found")
+	ErrNotDead  = errors.New("outbox record is not dead")
+)
+
+// DeliverFunc delivers one record. Returning nil marks the record sent; an error
+// schedules a retry, unless it is wrapped with Permanent.
+type DeliverFunc func(ctx context.Context, rec ledger.OutboxRecord) error
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a delivery error that retrying cannot fix, such as a payload that does
+// not decode. The record is dead-lettered at once.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var perm permanentError
+	return errors.As(err, &perm)
+}
+
+// Worker delivers the outbox records of one kind.
+type Worker struct {
+	Store   ledger.Store
+	Kind    string
+	Deliver DeliverFunc
+	// Owner names this worker in leases and attempt history; empty means host-pid.
+	Owner string
+	// Lease is how long claimed records are held; zero means DefaultLease.
+	Lease time.Duration
+}
+
+// ProcessDue leases up to limit due records and delivers them, returning how many were
+// attempted. An outcome is recorded only while the worker's lease still holds, so a
+// worker whose lease lapsed mid-delivery cannot overwrite the record's new holder.
+func (w *Worker) ProcessDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	if w.Store == nil {
+		return 0, fmt.Errorf("missing store")
+	}
+	if w.Deliver == nil {
+		return 0, nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	now = now.UTC()
+	lease := w.newLease(now)
+	due, err := w.Store.LeaseOutboxDue(w.Kind, lease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, rec := range due {
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+		if err := w.deliver(ctx, rec, lease, now); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// ProcessOne leases the record outboxID and delivers it, reporting whether it was
+// attempted. A record that is not due, already delivered, or held by another worker is
+// left alone.
+func (w *Worker) ProcessOne(ctx context.Context, outboxID string, now time.Time) (bool, error) {
+	if w.Store == nil {
+		return false, fmt.Errorf("missing store")
+	}
+	if w.Deliver == nil {
+		return false, nil
+	}
+
+	now = now.UTC()
+	lease := w.newLease(now)
+	rec, ok, err := w.Store.LeaseOutbox(outboxID, lease)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := w.deliver(ctx, rec, lease, now); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (w *Worker) deliver(ctx context.Context, rec ledger.OutboxRecord, lease ledger.OutboxLease, now time.Time) error {
+	deliverErr := w.Deliver(ctx, rec)
+	_, err := w.Store.SettleOutbox(settle(rec, lease.Owner, now, deliverErr), lease.Token)
+	return err
+}
+
+// newLease starts a claim at now with a token no other claim shares.
+func (w *Worker) newLease(now time.Time) ledger.OutboxLease {
+	d := w.Lease
+	if d <= 0 {
+		d = DefaultLease
+	}
+	return ledger.OutboxLease{
+		Owner: w.owner(),
+		Token: newLeaseToken(),
+		Now:   now.Format(time.RFC3339),
+		Until: now.Add(d).Format(time.RFC3339),
+	}
+}
+
+// Run processes due records every interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			_, _ = w.ProcessDue(ctx, now, 25)
+		}
+	}
+}
+
+func (w *Worker) owner() string {
+	if w.Owner != "" {
+		return w.Owner
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+func newLeaseToken() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("lease-%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%x", buf)
+}
+
+// settle records the outcome of one delivery attempt and releases the lease.
+func settle(rec ledger.OutboxRecord, owner string, now time.Time, deliverErr error) ledger.OutboxRecord {
+	at := now.Format(time.RFC3339)
+	rec.LeaseOwner = nil
+	rec.LeaseToken = nil
+	rec.LeaseExpiresAt = nil
+	rec.AttemptCount++
+	rec.UpdatedAt = at
+
+	attempt := ledger.OutboxAttempt{At: at, Owner: owner}
+	if deliverErr == nil {
+		rec.Status = StatusSent
+		rec.SentAt = &at
+		rec.Attempts = append(rec.Attempts, attempt)
+		return rec
+	}
+
+	msg := deliverErr.Error()
+	attempt.Error = msg
+	rec.Attempts = append(rec.Attempts, attempt)
+	rec.LastError = &msg
+
+	maxAttempts := rec.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if IsPermanent(deliverErr) || rec.AttemptCount >= maxAttempts {
+		rec.Status = StatusDead
+		return rec
+	}
+	rec.NextAttemptAt = now.Add(backoff(rec.AttemptCount - 1)).Format(time.RFC3339)
+	return rec
+}
+
+func backoff(attemptCount int) time.Duration {
+	// 5s, 10s, 20s, 40s, 80s, 160s, ... capped at 5m.
+	base := 5 * time.Second
+	if attemptCount <= 0 {
+		return base
+	}
+	if attemptCount > 10 {
+		attemptCount = 10
+	}
+	d := base << attemptCount
+	max := 5 * time.Minute
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// Retry puts a dead record back in the queue, due at now with a fresh attempt budget.
+// Its attempt history is kept.
+func Retry(store ledger.Store, outboxID string, now time.Time) (ledger.OutboxRecord, error) {
+	var out ledger.OutboxRecord
+	err := store.WithTx(func(tx ledger.Tx) error {
+		rec, ok := tx.GetOutbox(outboxID)
+		if !ok {
+			return ErrNotFound
+		}
+		if rec.Status != StatusDead {
+			return ErrNotDead
+		}
+		at := now.UTC().Format(time.RFC3339)
+		rec.Status = StatusPending
+		rec.AttemptCount = 0
+		rec.NextAttemptAt = at
+		rec.LeaseOwner = nil
+		rec.LeaseToken = nil
+		rec.LeaseExpiresAt = nil
+		rec.UpdatedAt = at
+		out = rec
+		return tx.PutOutbox(rec)
+	})
+	return out, err
+}
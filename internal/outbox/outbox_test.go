@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+)
+
+func putPending(t *testing.T, store ledger.Store, id, kind string, maxAttempts int, now time.Time) {
+	t.Helper()
+	at := now.Format(time.RFC3339)
+	rec := ledger.OutboxRecord{
+		OutboxID:      id,
+		Kind:          kind,
+		PayloadJSON:   []byte(`{}`),
+		Status:        StatusPending,
+		MaxAttempts:   maxAttempts,
+		NextAttemptAt: at,
+		CreatedAt:     at,
+		UpdatedAt:     at,
+	}
+	if err := store.PutOutbox(rec); err != nil {
+		t.Fatalf("put outbox: %v", err)
+	}
+}
+
+func TestProcessDueRetriesThenSends(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	now := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+	putPending(t, store, "w1", KindWebhook, 0, now)
+	putPending(t, store, "s1", KindSlackPost, 0, now)
+
+	calls := 0
+	w := &Worker{Store: store, Kind: KindWebhook, Owner: "w", Deliver: func(ctx context.Context, rec ledger.OutboxRecord) error {
+		calls++
+		if calls == 1 {
+			return errors.New("throttled")
+		}
+		return nil
+	}}
+
+	if n, err := w.ProcessDue(context.Background(), now, 10); err != nil || n != 1 {
+		t.Fatalf("process: n=%d err=%v", n, err)
+	}
+	rec, _ := store.GetOutbox("w1")
+	if rec.Status != StatusPending || rec.AttemptCount != 1 || rec.LastError == nil || rec.LeaseOwner != nil || rec.NextAttemptAt != now.Add(5*time.Second).Format(time.RFC3339) {
+		t.Fatalf("unexpected after failure: %+v", rec)
+	}
+
+	// Not due yet.
+	if n, _ := w.ProcessDue(context.Background(), now.Add(time.Second), 10); n != 0 {
+		t.Fatalf("expected nothing due, got %d", n)
+	}
+
+	if n, err := w.ProcessDue(context.Background(), now.Add(10*time.Second), 10); err != nil || n != 1 {
+		t.Fatalf("process2: n=%d err=%v", n, err)
+	}
+	rec, _ = store.GetOutbox("w1")
+	if rec.Status != StatusSent || rec.SentAt == nil || len(rec.Attempts) != 2 || rec.Attempts[0].Error != "throttled" || rec.Attempts[1].Owner != "w" {
+		t.Fatalf("expected sent with two attempts, got %+v", rec)
+	}
+	if other, _ := store.GetOutbox("s1"); other.Status != StatusPending || other.AttemptCount != 0 {
+		t.Fatalf("expected the slack record untouched, got %+v", other)
+	}
+}
+
+func TestProcessDueKeepsLostLease(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	now := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+	putPending(t, store, "w1", KindWebhook, 0, now)
+
+	// The lease lapses mid-delivery and another worker with the same owner name claims
+	// the record.
+	w := &Worker{Store: store, Kind: KindWebhook, Owner: "gw", Deliver: func(ctx context.Context, rec ledger.OutboxRecord) error {
+		lease := ledger.OutboxLease{Owner: "gw", Token: "other", Now: now.Add(2 * time.Minute).Format(time.RFC3339), Until: now.Add(3 * time.Minute).Format(time.RFC3339)}
+		if _, err := store.LeaseOutboxDue(KindWebhook, lease, 10); err != nil {
+			t.Fatalf("lease: %v", err)
+		}
+		return errors.New("timeout")
+	}}
+	if _, err := w.ProcessDue(context.Background(), now, 10); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	rec, _ := store.GetOutbox("w1")
+	if rec.Status != StatusPending || rec.AttemptCount != 0 || rec.LeaseToken == nil || *rec.LeaseToken != "other" {
+		t.Fatalf("expected the record left to the new lease, got %+v", rec)
+	}
+}
+
+func TestProcessOneDeliversTheNamedRecord(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	now := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+	putPending(t, store, "w1", KindWebhook, 0, now.Add(-time.Minute))
+	putPending(t, store, "w2", KindWebhook, 0, now)
+
+	var delivered []string
+	w := &Worker{Store: store, Kind: KindWebhook, Owner: "w", Deliver: func(ctx context.Context, rec ledger.OutboxRecord) error {
+		delivered = append(delivered, rec.OutboxID)
+		return nil
+	}}
+	if ok, err := w.ProcessOne(context.Background(), "w2", now); err != nil || !ok {
+		t.Fatalf("process: ok=%v err=%v", ok, err)
+	}
+	if ok, err := w.ProcessOne(context.Background(), "w2", now); err != nil || ok {
+		t.Fatalf("expected a sent record to be skipped: ok=%v err=%v", ok, err)
+	}
+	if len(delivered) != 1 || delivered[0] != "w2" {
+		t.Fatalf("expected only w2 delivered, got %v", delivered)
+	}
+	if rec, _ := store.GetOutbox("w2"); rec.Status != StatusSent || rec.LeaseToken != nil {
+		t.Fatalf("expected w2 sent, got %+v", rec)
+	}
+	if rec, _ := store.GetOutbox("w1"); rec.Status != StatusPending || rec.LeaseOwner != nil {
+		t.Fatalf("expected the older record untouched, got %+v", rec)
+	}
+}
+
+func TestProcessDueDeadLetters(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	now := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+	putPending(t, store, "w1", KindWebhook, 2, now)
+	putPending(t, store, "w2", KindWebhook, 0, now)
+
+	w := &Worker{Store: store, Kind: KindWebhook, Deliver: func(ctx context.Context, rec ledger.OutboxRecord) error {
+		if rec.OutboxID == "w2" {
+			return Permanent(errors.New("bad payload"))
+		}
+		return errors.New("down")
+	}}
+
+	if _, err := w.ProcessDue(context.Background(), now, 10); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if rec, _ := store.GetOutbox("w2"); rec.Status != StatusDead || rec.AttemptCount != 1 {
+		t.Fatalf("expected a permanent failure to dead-letter at once, got %+v", rec)
+	}
+	if _, err := w.ProcessDue(context.Background(), now.Add(time.Minute), 10); err != nil {
+		t.Fatalf("process2: %v", err)
+	}
+	rec, _ := store.GetOutbox("w1")
+	if rec.Status != StatusDead || rec.AttemptCount != 2 || len(rec.Attempts) != 2 {
+		t.Fatalf("expected dead after max attempts, got %+v", rec)
+	}
+
+	dead, err := store.ListOutbox(StatusDead, 10)
+	if err != nil || len(dead) != 2 {
+		t.Fatalf("expected two dead records: %v err=%v", dead, err)
+	}
+
+	retried, err := Retry(store, "w1", now.Add(2*time.Minute))
+	if err != nil || retried.Status != StatusPending || retried.AttemptCount != 0 || len(retried.Attempts) != 2 {
+		t.Fatalf("unexpected retry: %+v err=%v", retried, err)
+	}
+	if _, err := Retry(store, "w1", now); err != ErrNotDead {
+		t.Fatalf("expected ErrNotDead, got %v", err)
+	}
+	if _, err := Retry(store, "missing", now); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if backoff(0) != 5*time.Second || backoff(1) != 10*time.Second || backoff(50) != 5*time.Minute {
+		t.Fatalf("unexpected backoff: %s %s %s", backoff(0), backoff(1), backoff(50))
+	}
+}
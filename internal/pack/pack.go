@@ -32,6 +32,15 @@ type Input struct {
 	Policy    []byte
 	Approvals []ApprovalRecord
 	CreatedAt string
+
+	// Chain holds every receipt for the intent, oldest first, including Receipt.
+	// When set, each one is written under receipts/.
+	Chain []ledger.StoredReceipt
+}
+
+// ChainReceiptName returns the pack path for the i-th (zero-based) receipt of a chain.
+func ChainReceiptName(i int, receiptID string) string {
+	return fmt.Sprintf("receipts/%02d-%s.json", i+1, strings.TrimPrefix(receiptID, "sha256:"))
 }
 
 func BuildZip(input Input, baseURL string) ([]byte, error) {
@@ -69,7 +78,8 @@ func BuildFiles(input Input, baseURL string) (map[string][]byte, error) {
 		"receipt.json":  append(receiptJSON, '\n'),
 		"context.json":  append(contextJSON, '\n'),
 		"decision.json": append(decisionJSON, '\n'),
-		"policy.yaml":   append(bytes.TrimRight(input.Policy, "\n"), '\n'),
+		// Stored verbatim so policy.yaml hashes to the receipt's policy_hash.
+		"policy.yaml": input.Policy,
 	}
 
 	if len(input.Approvals) > 0 {
@@ -80,6 +90,14 @@ func BuildFiles(input Input, baseURL string) (map[string][]byte, error) {
 		files["approvals.json"] = append(approvalsJSON, '\n')
 	}
 
+	for i, receipt := range input.Chain {
+		chainJSON, err := buildReceiptJSON(receipt, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		files[ChainReceiptName(i, receipt.ReceiptID)] = append(chainJSON, '\n')
+	}
+
 	summary, summaryHTML, err := BuildSummary(input, baseURL)
 	if err != nil {
 		return nil, err
@@ -108,7 +126,7 @@ func BuildFiles(input Input, baseURL string) (map[string][]byte, error) {
 		Schemas: types.PackSchemas{
 			Context:  input.Context.Schema,
 			Decision: input.Decision.Schema,
-			Receipt:  ledger.ReceiptSchema,
+			Receipt:  extractReceiptSchema(input.Receipt.BodyJSON),
 		},
 		Files: fileEntries,
 	}
@@ -131,6 +149,18 @@ func BuildFiles(input Input, baseURL string) (map[string][]byte, error) {
 	return files, nil
 }
 
+// extractReceiptSchema reports the schema the receipt was signed under, defaulting
+// to the current schema for bodies that do not declare one.
+func extractReceiptSchema(body []byte) string {
+	var payload struct {
+		Schema string `json:"schema"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Schema == "" {
+		return ledger.ReceiptSchema
+	}
+	return payload.Schema
+}
+
 func extractReceiptRefs(body []byte) *types.ReceiptRefs {
 	if len(body) == 0 {
 		return nil
@@ -43,7 +43,7 @@ func TestBuildZipIncludesArtifacts(t *testing.T) {
 	}
 
 	policy := types.DecisionPolicy{PolicyID: "relia-default", PolicyVersion: "2025-12-20", PolicyHash: "sha256:policy"}
-	dec, err := decision.BuildDecision(ctx.ContextID, policy, "allow", nil, false, "high", createdAt)
+	dec, err := decision.BuildDecision(ctx.ContextID, policy, "allow", nil, nil, false, "high", createdAt)
 	if err != nil {
 		t.Fatalf("decision: %v", err)
 	}
@@ -115,7 +115,7 @@ func TestBuildZipManifestIncludesRefs(t *testing.T) {
 		t.Fatalf("context: %v", err)
 	}
 	policy := types.DecisionPolicy{PolicyID: "relia-default", PolicyVersion: "2025-12-20", PolicyHash: "sha256:policy"}
-	dec, err := decision.BuildDecision(ctx.ContextID, policy, "deny", nil, false, "low", createdAt)
+	dec, err := decision.BuildDecision(ctx.ContextID, policy, "deny", nil, nil, false, "low", createdAt)
 	if err != nil {
 		t.Fatalf("decision: %v", err)
 	}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/davidahmann/relia/internal/grade"
+	"github.com/davidahmann/relia/internal/ledger"
 	"github.com/davidahmann/relia/pkg/types"
 )
 
@@ -30,6 +31,19 @@ type Summary struct {
 	DiffURL        string                `json:"diff_url,omitempty"`
 	VerifyURL      string                `json:"verify_url,omitempty"`
 	PackURL        string                `json:"pack_url,omitempty"`
+	Chain          []ChainEntry          `json:"chain,omitempty"`
+}
+
+// ChainEntry summarizes one receipt of the intent's chain.
+type ChainEntry struct {
+	ReceiptID           string `json:"receipt_id"`
+	SupersedesReceiptID string `json:"supersedes_receipt_id,omitempty"`
+	CreatedAt           string `json:"created_at,omitempty"`
+	OutcomeStatus       string `json:"outcome_status"`
+	ApprovalStatus      string `json:"approval_status,omitempty"`
+	Approver            string `json:"approver,omitempty"`
+	File                string `json:"file"`
+	Current             bool   `json:"current,omitempty"`
 }
 
 const SummarySchema = "relia.pack_summary.v0.1"
@@ -75,6 +89,10 @@ func BuildSummary(input Input, baseURL string) (Summary, []byte, error) {
 		s.ApprovalState = rb.Approval.Status
 	}
 
+	for i, receipt := range input.Chain {
+		s.Chain = append(s.Chain, buildChainEntry(i, receipt, input.Receipt.ReceiptID))
+	}
+
 	if baseURL != "" {
 		base := strings.TrimRight(baseURL, "/")
 		s.VerifyURL = base + "/verify/" + input.Receipt.ReceiptID
@@ -88,6 +106,36 @@ func BuildSummary(input Input, baseURL string) (Summary, []byte, error) {
 	return s, htmlBytes, nil
 }
 
+func buildChainEntry(i int, receipt ledger.StoredReceipt, currentID string) ChainEntry {
+	var rb struct {
+		CreatedAt string                 `json:"created_at"`
+		Approval  *types.ReceiptApproval `json:"approval,omitempty"`
+		Outcome   types.ReceiptOutcome   `json:"outcome"`
+	}
+	_ = json.Unmarshal(receipt.BodyJSON, &rb)
+
+	entry := ChainEntry{
+		ReceiptID:     receipt.ReceiptID,
+		CreatedAt:     rb.CreatedAt,
+		OutcomeStatus: string(rb.Outcome.Status),
+		File:          ChainReceiptName(i, receipt.ReceiptID),
+		Current:       receipt.ReceiptID == currentID,
+	}
+	if receipt.SupersedesReceiptID != nil {
+		entry.SupersedesReceiptID = *receipt.SupersedesReceiptID
+	}
+	if rb.Approval != nil {
+		entry.ApprovalStatus = rb.Approval.Status
+		if rb.Approval.Approver != nil {
+			entry.Approver = rb.Approval.Approver.Display
+			if entry.Approver == "" {
+				entry.Approver = rb.Approval.Approver.ID
+			}
+		}
+	}
+	return entry
+}
+
 var summaryHTMLTmpl = template.Must(template.New("summary").Parse(`<!doctype html>
 <html lang="en">
 <head>
@@ -122,6 +170,7 @@ var summaryHTMLTmpl = template.Must(template.New("summary").Parse(`<!doctype htm
     <div class="kv"><div class="k">Plan Digest</div><div class="v">{{if .PlanDigest}}<code>{{.PlanDigest}}</code>{{else}}n/a{{end}}</div></div>
     <div class="kv"><div class="k">Diff URL</div><div class="v">{{if .DiffURL}}<a href="{{.DiffURL}}">{{.DiffURL}}</a>{{else}}n/a{{end}}</div></div>
     <div class="kv"><div class="k">Verify / Pack</div><div class="v">{{if .VerifyURL}}<a href="{{.VerifyURL}}">{{.VerifyURL}}</a>{{end}} {{if .PackURL}}<br/><a href="{{.PackURL}}">{{.PackURL}}</a>{{end}}</div></div>
+    {{if .Chain}}<div class="kv"><div class="k">Receipt Chain</div><div class="v">{{range .Chain}}<div>{{if .Current}}<strong>{{.OutcomeStatus}}</strong>{{else}}{{.OutcomeStatus}}{{end}} <code>{{.ReceiptID}}</code>{{if .CreatedAt}} at {{.CreatedAt}}{{end}}{{if .ApprovalStatus}} approval={{.ApprovalStatus}}{{end}}{{if .Approver}} by {{.Approver}}{{end}}</div>{{end}}</div></div>{{end}}
   </div>
 </body>
 </html>`))
@@ -26,7 +26,7 @@ func TestBuildSummaryIncludesLinksAndHTML(t *testing.T) {
 		t.Fatalf("context: %v", err)
 	}
 	policyMeta := types.DecisionPolicy{PolicyID: "p", PolicyVersion: "v", PolicyHash: "sha256:ph"}
-	dec, err := decision.BuildDecision(ctx.ContextID, policyMeta, "allow", nil, false, "low", createdAt)
+	dec, err := decision.BuildDecision(ctx.ContextID, policyMeta, "allow", nil, nil, false, "low", createdAt)
 	if err != nil {
 		t.Fatalf("decision: %v", err)
 	}
@@ -87,7 +87,7 @@ func TestBuildSummaryNoLinksWhenNoBaseURL(t *testing.T) {
 		t.Fatalf("context: %v", err)
 	}
 	policyMeta := types.DecisionPolicy{PolicyID: "p", PolicyVersion: "v", PolicyHash: "sha256:ph"}
-	dec, err := decision.BuildDecision(ctx.ContextID, policyMeta, "deny", nil, true, "high", createdAt)
+	dec, err := decision.BuildDecision(ctx.ContextID, policyMeta, "deny", nil, nil, true, "high", createdAt)
 	if err != nil {
 		t.Fatalf("decision: %v", err)
 	}
@@ -0,0 +1,467 @@
+package pack
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	reliactx "github.com/davidahmann/relia/internal/context"
+	"github.com/davidahmann/relia/internal/crypto"
+	"github.com/davidahmann/relia/internal/decision"
+	"github.com/davidahmann/relia/internal/grade"
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+// maxZipEntryBytes bounds a single pack entry when reading untrusted zips.
+const maxZipEntryBytes = 16 << 20
+
+type VerifyCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+type VerifyResult struct {
+	ReceiptID string        `json:"receipt_id"`
+	Valid     bool          `json:"valid"`
+	Grade     string        `json:"grade,omitempty"`
+	Checks    []VerifyCheck `json:"checks"`
+
+	// Receipt is the re-canonicalized receipt that was verified.
+	Receipt ledger.StoredReceipt `json:"-"`
+}
+
+// Error returns the first failed check as "name: error", or "" when valid.
+func (r VerifyResult) Error() string {
+	for _, check := range r.Checks {
+		if !check.OK {
+			return check.Name + ": " + check.Error
+		}
+	}
+	return ""
+}
+
+func (r *VerifyResult) add(name string, err error) bool {
+	check := VerifyCheck{Name: name, OK: err == nil}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	r.Checks = append(r.Checks, check)
+	return err == nil
+}
+
+func (r *VerifyResult) finish() {
+	r.Valid = len(r.Checks) > 0
+	for _, check := range r.Checks {
+		if !check.OK {
+			r.Valid = false
+			return
+		}
+	}
+}
+
+// ReadZip reads every entry of a pack zip into memory.
+func ReadZip(data []byte) (map[string][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(reader.File))
+	for _, f := range reader.File {
+		if _, ok := files[f.Name]; ok {
+			return nil, fmt.Errorf("duplicate zip entry: %s", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := io.ReadAll(io.LimitReader(rc, maxZipEntryBytes+1))
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(contents) > maxZipEntryBytes {
+			return nil, fmt.Errorf("zip entry too large: %s", f.Name)
+		}
+		files[f.Name] = contents
+	}
+	return files, nil
+}
+
+// ParseReceiptJSON rebuilds a stored receipt from its rendered JSON form (body + integrity).
+// The body is re-canonicalized so the digest and signature can be checked without trusting
+// the issuer's serialization.
+func ParseReceiptJSON(data []byte) (ledger.StoredReceipt, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var body map[string]any
+	if err := dec.Decode(&body); err != nil {
+		return ledger.StoredReceipt{}, err
+	}
+
+	integrityAny, ok := body["integrity"]
+	if !ok {
+		return ledger.StoredReceipt{}, fmt.Errorf("missing integrity")
+	}
+	integrityJSON, err := json.Marshal(integrityAny)
+	if err != nil {
+		return ledger.StoredReceipt{}, err
+	}
+	var integrity struct {
+		BodyDigest string `json:"body_digest"`
+		Signatures []struct {
+			Alg   string `json:"alg"`
+			KeyID string `json:"key_id"`
+			Sig   string `json:"sig"`
+		} `json:"signatures"`
+	}
+	if err := json.Unmarshal(integrityJSON, &integrity); err != nil {
+		return ledger.StoredReceipt{}, err
+	}
+	if integrity.BodyDigest == "" {
+		return ledger.StoredReceipt{}, fmt.Errorf("missing integrity.body_digest")
+	}
+
+	var keyID string
+	var sig []byte
+	for _, s := range integrity.Signatures {
+		if s.Alg != "Ed25519" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s.Sig, "base64:"))
+		if err != nil {
+			return ledger.StoredReceipt{}, fmt.Errorf("invalid signature encoding: %w", err)
+		}
+		keyID = s.KeyID
+		sig = decoded
+		break
+	}
+	if sig == nil {
+		return ledger.StoredReceipt{}, fmt.Errorf("missing Ed25519 signature")
+	}
+
+	delete(body, "integrity")
+	delete(body, "links")
+
+	canonical, err := crypto.Canonicalize(body)
+	if err != nil {
+		return ledger.StoredReceipt{}, err
+	}
+
+	var meta struct {
+		IdemKey             string  `json:"idem_key"`
+		SupersedesReceiptID *string `json:"supersedes_receipt_id"`
+		ContextID           string  `json:"context_id"`
+		DecisionID          string  `json:"decision_id"`
+		Policy              struct {
+			PolicyHash string `json:"policy_hash"`
+		} `json:"policy"`
+		Approval *struct {
+			ApprovalID string `json:"approval_id"`
+		} `json:"approval"`
+		Outcome struct {
+			Status types.OutcomeStatus `json:"status"`
+		} `json:"outcome"`
+	}
+	if err := json.Unmarshal(canonical, &meta); err != nil {
+		return ledger.StoredReceipt{}, err
+	}
+
+	stored := ledger.StoredReceipt{
+		ReceiptID:           integrity.BodyDigest,
+		BodyDigest:          integrity.BodyDigest,
+		BodyJSON:            canonical,
+		KeyID:               keyID,
+		Sig:                 sig,
+		IdemKey:             meta.IdemKey,
+		SupersedesReceiptID: meta.SupersedesReceiptID,
+		ContextID:           meta.ContextID,
+		DecisionID:          meta.DecisionID,
+		OutcomeStatus:       meta.Outcome.Status,
+		PolicyHash:          meta.Policy.PolicyHash,
+	}
+	if meta.Approval != nil && meta.Approval.ApprovalID != "" {
+		stored.ApprovalID = &meta.Approval.ApprovalID
+	}
+	return stored, nil
+}
+
+// VerifyReceiptJSON verifies a standalone receipt.json against publicKey.
+func VerifyReceiptJSON(data []byte, publicKey ed25519.PublicKey) VerifyResult {
+	result := VerifyResult{}
+	receipt, err := ParseReceiptJSON(data)
+	if !result.add("receipt_parse", err) {
+		result.finish()
+		return result
+	}
+	result.ReceiptID = receipt.ReceiptID
+	result.Receipt = receipt
+
+	if result.add("receipt_signature", ledger.VerifyReceipt(receipt, publicKey)) {
+		result.Grade = grade.Evaluate(grade.Input{Valid: true, Receipt: receipt}).Grade
+	}
+	result.finish()
+	return result
+}
+
+// VerifyFiles verifies an unpacked audit pack offline: checksums, manifest entries, the
+// receipt signature, and that context/decision/policy hash to the IDs the receipt commits to.
+func VerifyFiles(files map[string][]byte, publicKey ed25519.PublicKey) VerifyResult {
+	result := VerifyResult{}
+
+	result.add("sha256sums", verifyChecksums(files))
+
+	var manifest types.PackManifest
+	manifestErr := unmarshalFile(files, "manifest.json", &manifest)
+	if manifestErr == nil {
+		manifestErr = verifyManifestFiles(files, manifest)
+	}
+	result.add("manifest", manifestErr)
+
+	receiptJSON, ok := files["receipt.json"]
+	if !ok {
+		result.add("receipt_parse", fmt.Errorf("missing receipt.json"))
+		result.finish()
+		return result
+	}
+	receipt, err := ParseReceiptJSON(receiptJSON)
+	if !result.add("receipt_parse", err) {
+		result.finish()
+		return result
+	}
+	result.ReceiptID = receipt.ReceiptID
+	result.Receipt = receipt
+
+	sigOK := result.add("receipt_signature", ledger.VerifyReceipt(receipt, publicKey))
+	if manifestErr == nil && manifest.ReceiptID != receipt.ReceiptID {
+		result.add("manifest_receipt_id", fmt.Errorf("manifest receipt_id %s does not match receipt %s", manifest.ReceiptID, receipt.ReceiptID))
+	}
+
+	var ctx types.ContextRecord
+	ctxErr := unmarshalFile(files, "context.json", &ctx)
+	if ctxErr == nil {
+		ctxErr = verifyContext(ctx, receipt.ContextID)
+	}
+	result.add("context", ctxErr)
+
+	var dec types.DecisionRecord
+	decErr := unmarshalFile(files, "decision.json", &dec)
+	if decErr == nil {
+		decErr = verifyDecision(dec, receipt)
+	}
+	result.add("decision", decErr)
+
+	policyErr := fmt.Errorf("missing policy.yaml")
+	if policyBytes, ok := files["policy.yaml"]; ok {
+		policyErr = nil
+		if got := crypto.DigestWithPrefix(policyBytes); got != receipt.PolicyHash {
+			policyErr = fmt.Errorf("policy.yaml hashes to %s, receipt policy_hash is %s", got, receipt.PolicyHash)
+		}
+	}
+	result.add("policy", policyErr)
+
+	if hasChainFiles(files) {
+		result.add("receipt_chain", verifyChain(files, receipt.ReceiptID, publicKey))
+	}
+
+	if sigOK {
+		in := grade.Input{Valid: true, Receipt: receipt}
+		if ctxErr == nil {
+			in.Context = &ctx
+		}
+		if decErr == nil {
+			in.Decision = &dec
+		}
+		result.Grade = grade.Evaluate(in).Grade
+	}
+
+	result.finish()
+	return result
+}
+
+func hasChainFiles(files map[string][]byte) bool {
+	for name := range files {
+		if strings.HasPrefix(name, "receipts/") {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyChain checks every receipts/ entry is signed and that, in chain order, each one
+// supersedes the previous and the chain contains the pack's receipt.
+func verifyChain(files map[string][]byte, receiptID string, publicKey ed25519.PublicKey) error {
+	names := []string{}
+	for name := range files {
+		if strings.HasPrefix(name, "receipts/") {
+			names = append(names, name)
+		}
+	}
+	// Order by the numeric prefix, not the name: "100-…" sorts before "11-…".
+	sort.Slice(names, func(i, j int) bool {
+		a, b := chainIndex(names[i]), chainIndex(names[j])
+		if a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+
+	prevID := ""
+	found := false
+	for _, name := range names {
+		rec, err := ParseReceiptJSON(files[name])
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := ledger.VerifyReceipt(rec, publicKey); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		supersedes := ""
+		if rec.SupersedesReceiptID != nil {
+			supersedes = *rec.SupersedesReceiptID
+		}
+		// v0.1 receipts do not sign the link, so only v0.2+ links can be checked.
+		if supersedes != prevID && extractReceiptSchema(rec.BodyJSON) != ledger.ReceiptSchemaV01 {
+			return fmt.Errorf("%s: supersedes %q, expected %q", name, supersedes, prevID)
+		}
+		if rec.ReceiptID == receiptID {
+			found = true
+		}
+		prevID = rec.ReceiptID
+	}
+	if !found {
+		return fmt.Errorf("chain does not include receipt %s", receiptID)
+	}
+	return nil
+}
+
+// chainIndex parses the position from a ChainReceiptName; names without one sort last.
+func chainIndex(name string) int {
+	prefix, _, _ := strings.Cut(strings.TrimPrefix(name, "receipts/"), "-")
+	i, err := strconv.Atoi(prefix)
+	if err != nil {
+		return math.MaxInt
+	}
+	return i
+}
+
+func verifyChecksums(files map[string][]byte) error {
+	sums, ok := files["sha256sums.txt"]
+	if !ok {
+		return fmt.Errorf("missing sha256sums.txt")
+	}
+
+	listed := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		digest, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			return fmt.Errorf("malformed line: %q", line)
+		}
+		contents, ok := files[name]
+		if !ok {
+			return fmt.Errorf("missing file: %s", name)
+		}
+		if got := crypto.DigestWithPrefix(contents); got != digest {
+			return fmt.Errorf("checksum mismatch: %s", name)
+		}
+		listed[name] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name != "sha256sums.txt" && !listed[name] {
+			return fmt.Errorf("unlisted file: %s", name)
+		}
+	}
+	return nil
+}
+
+func verifyManifestFiles(files map[string][]byte, manifest types.PackManifest) error {
+	if manifest.Schema != PackSchema {
+		return fmt.Errorf("unsupported schema: %s", manifest.Schema)
+	}
+	for _, entry := range manifest.Files {
+		contents, ok := files[entry.Name]
+		if !ok {
+			return fmt.Errorf("missing file: %s", entry.Name)
+		}
+		if got := crypto.DigestWithPrefix(contents); got != entry.SHA256 {
+			return fmt.Errorf("checksum mismatch: %s", entry.Name)
+		}
+		if entry.SizeBytes != int64(len(contents)) {
+			return fmt.Errorf("size mismatch: %s", entry.Name)
+		}
+	}
+	return nil
+}
+
+func verifyContext(ctx types.ContextRecord, receiptContextID string) error {
+	rebuilt, err := reliactx.BuildContext(ctx.Source, ctx.Inputs, ctx.Evidence, ctx.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if rebuilt.ContextID != ctx.ContextID {
+		return fmt.Errorf("context.json hashes to %s, claims %s", rebuilt.ContextID, ctx.ContextID)
+	}
+	if ctx.ContextID != receiptContextID {
+		return fmt.Errorf("context_id %s does not match receipt %s", ctx.ContextID, receiptContextID)
+	}
+	return nil
+}
+
+func verifyDecision(dec types.DecisionRecord, receipt ledger.StoredReceipt) error {
+	rebuilt, err := decision.BuildDecision(dec.ContextID, dec.Policy, dec.Verdict, dec.ReasonCodes, dec.MatchedRules, dec.RequiresApproval, dec.Risk, dec.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if rebuilt.DecisionID != dec.DecisionID {
+		return fmt.Errorf("decision.json hashes to %s, claims %s", rebuilt.DecisionID, dec.DecisionID)
+	}
+	if dec.DecisionID != receipt.DecisionID {
+		return fmt.Errorf("decision_id %s does not match receipt %s", dec.DecisionID, receipt.DecisionID)
+	}
+	if dec.ContextID != receipt.ContextID {
+		return fmt.Errorf("decision context_id %s does not match receipt %s", dec.ContextID, receipt.ContextID)
+	}
+	if dec.Policy.PolicyHash != receipt.PolicyHash {
+		return fmt.Errorf("decision policy_hash %s does not match receipt %s", dec.Policy.PolicyHash, receipt.PolicyHash)
+	}
+	return nil
+}
+
+func unmarshalFile(files map[string][]byte, name string, v any) error {
+	contents, ok := files[name]
+	if !ok {
+		return fmt.Errorf("missing %s", name)
+	}
+	dec := json.NewDecoder(bytes.NewReader(contents))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package pack
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/internal/context"
+	"github.com/davidahmann/relia/internal/crypto"
+	"github.com/davidahmann/relia/internal/decision"
+	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+func buildVerifiablePack(t *testing.T, priv ed25519.PrivateKey) []byte {
+	t.Helper()
+
+	zipBytes, err := BuildZip(buildVerifiableInput(t, priv, nil), "http://localhost:8080")
+	if err != nil {
+		t.Fatalf("build zip: %v", err)
+	}
+	return zipBytes
+}
+
+func buildVerifiableInput(t *testing.T, priv ed25519.PrivateKey, supersedes *string) Input {
+	t.Helper()
+
+	createdAt := time.Now().UTC().Format(time.RFC3339)
+	policyBytes := []byte("policy_id: relia-default\npolicy_version: \"2025-12-20\"\n\n")
+	policyHash := crypto.DigestWithPrefix(policyBytes)
+
+	ctx, err := context.BuildContext(
+		types.ContextSource{Kind: "github_actions", Repo: "org/repo", Workflow: "wf", RunID: "1", Actor: "dev", SHA: "abc"},
+		types.ContextInputs{Action: "terraform.apply", Resource: "stack/prod", Env: "prod", Intent: map[string]any{"destroy_count": 2, "note": "a<b"}},
+		types.ContextEvidence{PlanDigest: "sha256:plan", DiffURL: "https://example.com/diff"},
+		createdAt,
+	)
+	if err != nil {
+		t.Fatalf("context: %v", err)
+	}
+	policyMeta := types.DecisionPolicy{PolicyID: "relia-default", PolicyVersion: "2025-12-20", PolicyHash: policyHash}
+	dec, err := decision.BuildDecision(ctx.ContextID, policyMeta, "allow", []string{"POLICY_MATCH:r1"}, nil, false, "low", createdAt)
+	if err != nil {
+		t.Fatalf("decision: %v", err)
+	}
+
+	receipt, err := ledger.MakeReceipt(ledger.MakeReceiptInput{
+		CreatedAt:           createdAt,
+		IdemKey:             "idem",
+		SupersedesReceiptID: supersedes,
+		ContextID:           ctx.ContextID,
+		DecisionID:          dec.DecisionID,
+		Actor:               types.ReceiptActor{Kind: "workload", Subject: "dev"},
+		Request:             types.ReceiptRequest{Action: "terraform.apply", Resource: "stack/prod", Env: "prod", Intent: map[string]any{"destroy_count": 2}},
+		Policy:              types.ReceiptPolicy{PolicyID: "relia-default", PolicyVersion: "2025-12-20", PolicyHash: policyHash},
+		CredentialGrant: &types.ReceiptCredentialGrant{
+			Provider:   "aws_sts",
+			RoleARN:    "arn:aws:iam::123456789012:role/test",
+			TTLSeconds: 900,
+		},
+		Outcome: types.ReceiptOutcome{Status: types.OutcomeIssuedCredentials, ExpiresAt: createdAt},
+	}, testSigner{keyID: "k1", priv: priv})
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+
+	return Input{
+		Receipt:  receipt,
+		Context:  ctx,
+		Decision: dec,
+		Policy:   policyBytes,
+	}
+}
+
+func TestVerifyFilesRoundTrip(t *testing.T) {
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+
+	files, err := ReadZip(buildVerifiablePack(t, priv))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+
+	result := VerifyFiles(files, pub)
+	if !result.Valid {
+		t.Fatalf("expected valid pack, got %+v", result.Checks)
+	}
+	if result.Grade != "A" {
+		t.Fatalf("expected grade A, got %s", result.Grade)
+	}
+	if result.Error() != "" {
+		t.Fatalf("unexpected error: %s", result.Error())
+	}
+
+	receiptOnly := VerifyReceiptJSON(files["receipt.json"], pub)
+	if !receiptOnly.Valid || receiptOnly.ReceiptID != result.ReceiptID {
+		t.Fatalf("expected valid receipt, got %+v", receiptOnly)
+	}
+}
+
+func TestVerifyFilesDetectsTampering(t *testing.T) {
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+	zipBytes := buildVerifiablePack(t, priv)
+
+	cases := []struct {
+		name      string
+		mutate    func(files map[string][]byte)
+		wantCheck string
+	}{
+		{
+			name: "receipt_body",
+			mutate: func(files map[string][]byte) {
+				files["receipt.json"] = []byte(strings.Replace(string(files["receipt.json"]), "stack/prod", "stack/dev", 1))
+			},
+			wantCheck: "sha256sums",
+		},
+		{
+			name: "policy",
+			mutate: func(files map[string][]byte) {
+				files["policy.yaml"] = append(files["policy.yaml"], '#')
+			},
+			wantCheck: "sha256sums",
+		},
+		{
+			name: "extra_file",
+			mutate: func(files map[string][]byte) {
+				files["extra.txt"] = []byte("x")
+			},
+			wantCheck: "sha256sums",
+		},
+		{
+			name: "missing_checksums",
+			mutate: func(files map[string][]byte) {
+				delete(files, "sha256sums.txt")
+			},
+			wantCheck: "sha256sums",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := ReadZip(zipBytes)
+			if err != nil {
+				t.Fatalf("read zip: %v", err)
+			}
+			tc.mutate(files)
+			result := VerifyFiles(files, pub)
+			if result.Valid {
+				t.Fatalf("expected invalid pack")
+			}
+			if !strings.HasPrefix(result.Error(), tc.wantCheck+":") {
+				t.Fatalf("expected %s failure, got %q", tc.wantCheck, result.Error())
+			}
+		})
+	}
+}
+
+func TestVerifyFilesRecomputesDigestsBehindChecksums(t *testing.T) {
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+
+	files, err := ReadZip(buildVerifiablePack(t, priv))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+
+	// A forger who also rewrites the checksum file must still fail on the signed receipt.
+	files["receipt.json"] = []byte(strings.Replace(string(files["receipt.json"]), "stack/prod", "stack/dev", 1))
+	files["context.json"] = []byte(strings.Replace(string(files["context.json"]), "org/repo", "org/other", 1))
+	files["policy.yaml"] = append(files["policy.yaml"], '#')
+	delete(files, "sha256sums.txt")
+	files["sha256sums.txt"] = buildChecksums(files)
+
+	result := VerifyFiles(files, pub)
+	if result.Valid {
+		t.Fatalf("expected invalid pack")
+	}
+	failed := map[string]bool{}
+	for _, check := range result.Checks {
+		if !check.OK {
+			failed[check.Name] = true
+		}
+	}
+	for _, name := range []string{"manifest", "receipt_signature", "context", "policy"} {
+		if !failed[name] {
+			t.Fatalf("expected %s check to fail, got %+v", name, result.Checks)
+		}
+	}
+}
+
+func TestVerifyFilesWrongKey(t *testing.T) {
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	otherSeed := make([]byte, ed25519.SeedSize)
+	otherSeed[0] = 1
+	other := ed25519.NewKeyFromSeed(otherSeed).Public().(ed25519.PublicKey)
+
+	files, err := ReadZip(buildVerifiablePack(t, priv))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	result := VerifyFiles(files, other)
+	if result.Valid || !strings.HasPrefix(result.Error(), "receipt_signature:") {
+		t.Fatalf("expected signature failure, got %q", result.Error())
+	}
+	if result.Grade != "" {
+		t.Fatalf("expected no grade for invalid signature, got %s", result.Grade)
+	}
+}
+
+func TestParseReceiptJSONErrors(t *testing.T) {
+	cases := map[string]string{
+		"invalid_json":      "not-json",
+		"missing_integrity": `{"schema":"relia.receipt.v0.1"}`,
+		"missing_digest":    `{"integrity":{"signatures":[]}}`,
+		"missing_sig":       `{"integrity":{"body_digest":"sha256:x","signatures":[]}}`,
+		"bad_sig_encoding":  `{"integrity":{"body_digest":"sha256:x","signatures":[{"alg":"Ed25519","sig":"base64:!!"}]}}`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseReceiptJSON([]byte(body)); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+
+	result := VerifyReceiptJSON([]byte("not-json"), nil)
+	if result.Valid || !strings.HasPrefix(result.Error(), "receipt_parse:") {
+		t.Fatalf("expected parse failure, got %+v", result)
+	}
+}
+
+func TestReadZipInvalid(t *testing.T) {
+	if _, err := ReadZip([]byte("not-a-zip")); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestVerifyFilesReceiptChain(t *testing.T) {
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+
+	first := buildVerifiableInput(t, priv, nil).Receipt
+	input := buildVerifiableInput(t, priv, &first.ReceiptID)
+	input.Chain = []ledger.StoredReceipt{first, input.Receipt}
+
+	files, err := BuildFiles(input, "")
+	if err != nil {
+		t.Fatalf("build files: %v", err)
+	}
+	if _, ok := files[ChainReceiptName(0, first.ReceiptID)]; !ok {
+		t.Fatalf("expected chain receipt file")
+	}
+	var summary Summary
+	if err := unmarshalFile(files, "summary.json", &summary); err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if len(summary.Chain) != 2 || summary.Chain[1].SupersedesReceiptID != first.ReceiptID || !summary.Chain[1].Current {
+		t.Fatalf("unexpected chain summary: %+v", summary.Chain)
+	}
+	if result := VerifyFiles(files, pub); !result.Valid {
+		t.Fatalf("expected valid chain pack, got %s", result.Error())
+	}
+
+	// Out-of-order chain files no longer link up.
+	input.Chain = []ledger.StoredReceipt{input.Receipt, first}
+	files, err = BuildFiles(input, "")
+	if err != nil {
+		t.Fatalf("build files: %v", err)
+	}
+	result := VerifyFiles(files, pub)
+	if result.Valid || !strings.HasPrefix(result.Error(), "receipt_chain:") {
+		t.Fatalf("expected receipt_chain failure, got %q", result.Error())
+	}
+}
+
+func TestVerifyChainOrdersByIndex(t *testing.T) {
+	priv := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+
+	first := buildVerifiableInput(t, priv, nil).Receipt
+	second := buildVerifiableInput(t, priv, &first.ReceiptID).Receipt
+	files := map[string][]byte{}
+	for i, rec := range []ledger.StoredReceipt{first, second} {
+		data, err := buildReceiptJSON(rec, "")
+		if err != nil {
+			t.Fatalf("receipt json: %v", err)
+		}
+		// Positions 99 and 100: "receipts/100-…" sorts before "receipts/99-…" by name.
+		files[ChainReceiptName(98+i, rec.ReceiptID)] = data
+	}
+	if err := verifyChain(files, second.ReceiptID, pub); err != nil {
+		t.Fatalf("expected chain to verify past 99 receipts: %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ApprovalQuorum is how many distinct approvers a request needs. When Groups is set,
+// only approvers in at least one of the groups may vote. SeparationOfDuties rejects
+// votes from the requester's own approver identities, as mapped by Policy.Identities.
+type ApprovalQuorum struct {
+	Required           int      `yaml:"required"`
+	Groups             []string `yaml:"groups"`
+	SeparationOfDuties bool     `yaml:"separation_of_duties"`
+}
+
+func (q *ApprovalQuorum) UnmarshalYAML(node *yaml.Node) error {
+	type raw ApprovalQuorum
+	var r raw
+	if err := node.Decode(&r); err != nil {
+		return err
+	}
+	if r.Required < 1 {
+		return fmt.Errorf("line %d: approvals.required must be at least 1", node.Line)
+	}
+	for _, group := range r.Groups {
+		if group == "" {
+			return fmt.Errorf("line %d: approvals.groups has an empty group", node.Line)
+		}
+	}
+	*q = ApprovalQuorum(r)
+	return nil
+}
+
+// RequiredApprovals returns the number of approvals needed; a nil quorum needs one.
+func (q *ApprovalQuorum) RequiredApprovals() int {
+	if q == nil || q.Required < 1 {
+		return 1
+	}
+	return q.Required
+}
+
+// mergeQuorum combines the quorum of another matched rule into current: the larger
+// required count wins, groups come from the first rule that lists them, and separation
+// of duties applies if any rule asks for it.
+func mergeQuorum(current, next *ApprovalQuorum) *ApprovalQuorum {
+	if next == nil {
+		return current
+	}
+	if current == nil {
+		q := *next
+		return &q
+	}
+	merged := *current
+	if next.Required > merged.Required {
+		merged.Required = next.Required
+	}
+	if len(merged.Groups) == 0 {
+		merged.Groups = next.Groups
+	}
+	merged.SeparationOfDuties = merged.SeparationOfDuties || next.SeparationOfDuties
+	return &merged
+}
+
+// Identities maps a GitHub login, the workflow actor that triggers a request, to the
+// approver identities of the same person, written "kind:id" as in approval receipts:
+// slack:<user ID>, teams:<Entra ID object ID>, oidc:<issuer>|<sub>, webhook:<id>.
+// Separation of duties relies on it, since a login and an approver ID never match on
+// their own.
+type Identities map[string][]string
+
+// identityKinds are the approver kinds an identity may name.
+var identityKinds = []string{"slack", "teams", "oidc", "webhook"}
+
+func (m *Identities) UnmarshalYAML(node *yaml.Node) error {
+	var raw map[string][]string
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	for login, ids := range raw {
+		if strings.TrimSpace(login) == "" {
+			return fmt.Errorf("line %d: identities has an empty login", node.Line)
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("line %d: identities[%s] lists no approver identities", node.Line, login)
+		}
+		for _, id := range ids {
+			kind, subject, ok := strings.Cut(id, ":")
+			if !ok || subject == "" || !slices.Contains(identityKinds, kind) {
+				return fmt.Errorf("line %d: identities[%s]: %q must be kind:id with kind %s", node.Line, login, id, strings.Join(identityKinds, ", "))
+			}
+		}
+	}
+	*m = raw
+	return nil
+}
+
+// Of returns the approver identities mapped to a GitHub login; logins compare
+// case-insensitively, as on GitHub.
+func (m Identities) Of(login string) []string {
+	if login == "" {
+		return nil
+	}
+	var ids []string
+	for key, mapped := range m {
+		if strings.EqualFold(key, login) {
+			ids = append(ids, mapped...)
+		}
+	}
+	return ids
+}
+
+// RequiresSeparationOfDuties reports whether any of the listed rules sets
+// approvals.separation_of_duties.
+func (p Policy) RequiresSeparationOfDuties(ruleIDs []string) bool {
+	for _, rule := range p.Rules {
+		if rule.Effect.Approvals != nil && rule.Effect.Approvals.SeparationOfDuties && slices.Contains(ruleIDs, rule.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSeparationOfDuties rejects separation_of_duties without an identities map:
+// with nothing to map the requester to, the setting could never take effect.
+func (p Policy) validateSeparationOfDuties() error {
+	if len(p.Identities) > 0 {
+		return nil
+	}
+	for _, rule := range p.Rules {
+		if rule.Effect.Approvals != nil && rule.Effect.Approvals.SeparationOfDuties {
+			return fmt.Errorf("%s: rule %q sets separation_of_duties but the policy has no identities mapping requesters to approvers", rule.positions["effect.approvals"], rule.ID)
+		}
+	}
+	return nil
+}
+
+// ApproverAllowlist limits who may vote on a rule's approvals: Slack user IDs, Slack
+// user group IDs, and approver subjects (patterns) for API approvals. An approver
+// listed in any of them is allowed.
+type ApproverAllowlist struct {
+	SlackUsers  []string `yaml:"slack_users"`
+	SlackGroups []string `yaml:"slack_groups"`
+	Subjects    Patterns `yaml:"subjects"`
+}
+
+func (a *ApproverAllowlist) UnmarshalYAML(node *yaml.Node) error {
+	type raw ApproverAllowlist
+	var r raw
+	if err := node.Decode(&r); err != nil {
+		return err
+	}
+	if len(r.SlackUsers) == 0 && len(r.SlackGroups) == 0 && len(r.Subjects) == 0 {
+		return fmt.Errorf("line %d: approvers lists no slack_users, slack_groups, or subjects", node.Line)
+	}
+	for _, id := range append(append([]string(nil), r.SlackUsers...), r.SlackGroups...) {
+		if id == "" {
+			return fmt.Errorf("line %d: approvers has an empty Slack ID", node.Line)
+		}
+	}
+	*a = ApproverAllowlist(r)
+	return nil
+}
+
+// Allows reports whether an approver of the given kind ("slack" or an API kind such
+// as "oidc"), ID, and group memberships is on the list. Slack approvers match by user
+// or group ID; every other kind matches by subject.
+func (a ApproverAllowlist) Allows(kind, id string, groups []string) bool {
+	if id == "" {
+		return false
+	}
+	if kind == "slack" {
+		if slices.Contains(a.SlackUsers, id) {
+			return true
+		}
+		for _, group := range groups {
+			if slices.Contains(a.SlackGroups, group) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(a.Subjects) == 0 {
+		return false
+	}
+	_, ok := a.Subjects.Match(id)
+	return ok
+}
+
+// RuleApprovers returns the approver allowlists of the listed rules, in policy order.
+// Rules without one are skipped, so an empty result means anyone may approve.
+func (p Policy) RuleApprovers(ruleIDs []string) []ApproverAllowlist {
+	var lists []ApproverAllowlist
+	for _, rule := range p.Rules {
+		if rule.Effect.Approvers != nil && slices.Contains(ruleIDs, rule.ID) {
+			lists = append(lists, *rule.Effect.Approvers)
+		}
+	}
+	return lists
+}
+
+// ApprovalTimeout is how long an approval may stay pending before it expires, written
+// as a duration such as "30m" or "4h".
+type ApprovalTimeout time.Duration
+
+func (t *ApprovalTimeout) UnmarshalYAML(node *yaml.Node) error {
+	d, err := time.ParseDuration(node.Value)
+	if node.Kind != yaml.ScalarNode || err != nil || d <= 0 {
+		return fmt.Errorf("line %d: approval_timeout must be a positive duration such as 30m or 4h", node.Line)
+	}
+	*t = ApprovalTimeout(d)
+	return nil
+}
+
+// ApprovalTransport routes a rule's approval requests to a chat transport.
+type ApprovalTransport string
+
+const (
+	ApprovalTransportSlack   ApprovalTransport = "slack"
+	ApprovalTransportTeams   ApprovalTransport = "teams"
+	ApprovalTransportWebhook ApprovalTransport = "webhook"
+)
+
+func (t *ApprovalTransport) UnmarshalYAML(node *yaml.Node) error {
+	switch transport := ApprovalTransport(node.Value); {
+	case node.Kind == yaml.ScalarNode && (transport == ApprovalTransportSlack || transport == ApprovalTransportTeams || transport == ApprovalTransportWebhook):
+		*t = transport
+		return nil
+	default:
+		return fmt.Errorf("line %d: approval_transport must be %s, %s or %s", node.Line, ApprovalTransportSlack, ApprovalTransportTeams, ApprovalTransportWebhook)
+	}
+}
+
+// ApprovalChannel names where a rule's approval requests go: a Slack channel ID, or a
+// Teams channel alias that the gateway's teams.channels maps to an incoming webhook
+// URL. URLs are rejected: a webhook URL is a credential and does not belong in policy.
+type ApprovalChannel string
+
+func (c *ApprovalChannel) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode || strings.Contains(node.Value, "://") {
+		return fmt.Errorf("line %d: approval_channel must be a Slack channel ID or a Teams channel alias, not a URL; map Teams aliases to webhook URLs in the gateway's teams.channels", node.Line)
+	}
+	*c = ApprovalChannel(node.Value)
+	return nil
+}
@@ -0,0 +1,324 @@
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluateApprovalQuorum(t *testing.T) {
+	data := `policy_id: quorum
+rules:
+  - id: prod_destroy
+    match: {action: terraform.destroy, env: prod}
+    effect:
+      require_approval: true
+      approvals: {required: 2, groups: [sre, security]}
+  - id: prod_apply
+    match: {action: terraform.apply, env: prod}
+    effect: {require_approval: true}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.destroy", Env: "prod"})
+	if d.Verdict != "require_approval" {
+		t.Fatalf("expected require_approval, got %s", d.Verdict)
+	}
+	want := &ApprovalQuorum{Required: 2, Groups: []string{"sre", "security"}}
+	if !reflect.DeepEqual(d.Approvals, want) {
+		t.Fatalf("unexpected quorum: %+v", d.Approvals)
+	}
+	if d.Approvals.RequiredApprovals() != 2 {
+		t.Fatalf("expected 2 required approvals")
+	}
+
+	d = Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.apply", Env: "prod"})
+	if d.Approvals != nil || d.Approvals.RequiredApprovals() != 1 {
+		t.Fatalf("expected the single-approver default, got %+v", d.Approvals)
+	}
+}
+
+func TestEvaluateAllMatchingApprovalQuorum(t *testing.T) {
+	data := `policy_id: quorum
+evaluation: all_matching
+combining: deny_overrides
+rules:
+  - id: prod
+    match: {env: prod}
+    effect:
+      require_approval: true
+      approvals: {required: 1, groups: [sre]}
+  - id: destroy
+    match: {action: terraform.destroy}
+    effect:
+      approvals: {required: 3, groups: [security]}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.destroy", Env: "prod"})
+	want := &ApprovalQuorum{Required: 3, Groups: []string{"sre"}}
+	if !reflect.DeepEqual(d.Approvals, want) {
+		t.Fatalf("unexpected quorum: %+v", d.Approvals)
+	}
+}
+
+func TestLoadPolicyValidatesApprovals(t *testing.T) {
+	cases := map[string]string{
+		"approvals: {required: 0}":                             "approvals.required must be at least 1",
+		"approvals: {groups: [sre]}":                           "approvals.required must be at least 1",
+		`approvals: {required: 2, groups: [""]}`:               "empty group",
+		"approvals: {required: 2, group: sre}":                 `unknown field "group"`,
+		"approvals: {required: 2, separation_of_duties: true}": `5:7: rule "r" sets separation_of_duties but the policy has no identities`,
+	}
+	for effect, want := range cases {
+		data := "policy_id: bad\nrules:\n  - id: r\n    effect:\n      " + effect + "\n"
+		_, err := LoadPolicyFromBytes([]byte(data))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", effect, want, err)
+		}
+	}
+}
+
+func TestLoadPolicyIdentities(t *testing.T) {
+	data := `policy_id: sod
+identities:
+  OctoCat: [slack:U1, "oidc:https://sso.example.com|octo"]
+rules:
+  - id: r
+    effect:
+      approvals: {required: 1, separation_of_duties: true}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := loaded.Policy.Identities.Of("octocat"); !reflect.DeepEqual(got, []string{"slack:U1", "oidc:https://sso.example.com|octo"}) {
+		t.Fatalf("unexpected identities: %v", got)
+	}
+	if loaded.Policy.Identities.Of("hubot") != nil || !loaded.Policy.RequiresSeparationOfDuties([]string{"r"}) {
+		t.Fatalf("unexpected separation of duties lookup")
+	}
+
+	for body, want := range map[string]string{
+		"identities: {octocat: [U1]}":       `"U1" must be kind:id`,
+		"identities: {octocat: [github:x]}": `"github:x" must be kind:id`,
+		"identities: {octocat: []}":         "lists no approver identities",
+	} {
+		if _, err := LoadPolicyFromBytes([]byte("policy_id: bad\n" + body + "\n")); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", body, want, err)
+		}
+	}
+}
+
+func TestLintUnusedApprovals(t *testing.T) {
+	data := `policy_id: lint
+rules:
+  - id: dev
+    match: {env: dev}
+    effect:
+      approvals: {required: 2}
+      approvers: {slack_users: [U1]}
+      approval_timeout: 1h
+      approval_transport: teams
+      approval_channel: C_DEV
+      approval_mentions: [S_DEV]
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := lintCodes(Lint(loaded.Policy)); got != "unused_approvals@6:7,unused_approvals@7:7,unused_approvals@8:7,unused_approvals@9:7,unused_approvals@10:7,unused_approvals@11:7" {
+		t.Fatalf("unexpected issues: %s", got)
+	}
+}
+
+func TestApproverAllowlist(t *testing.T) {
+	data := `policy_id: approvers
+evaluation: all_matching
+combining: deny_overrides
+rules:
+  - id: prod
+    match: {env: prod}
+    effect:
+      require_approval: true
+      approvers:
+        slack_users: [U1]
+        slack_groups: [S_SRE]
+        subjects: ["https://token.actions.githubusercontent.com|repo:org/*"]
+  - id: destroy
+    match: {action: terraform.destroy}
+    effect:
+      approvers: {slack_groups: [S_SEC]}
+  - id: all
+    effect: {ttl_seconds: 900}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	lists := loaded.Policy.RuleApprovers([]string{"destroy", "all", "prod"})
+	if len(lists) != 2 || lists[1].SlackGroups[0] != "S_SEC" {
+		t.Fatalf("unexpected allowlists: %+v", lists)
+	}
+	if got := loaded.Policy.RuleApprovers([]string{"all"}); got != nil {
+		t.Fatalf("expected no allowlists, got %+v", got)
+	}
+
+	prod := lists[0]
+	cases := []struct {
+		kind, id string
+		groups   []string
+		want     bool
+	}{
+		{"slack", "U1", nil, true},
+		{"slack", "U2", []string{"S_SRE"}, true},
+		{"slack", "U2", []string{"S_DEV"}, false},
+		{"slack", "", []string{"S_SRE"}, false},
+		{"oidc", "https://token.actions.githubusercontent.com|repo:org/app", nil, true},
+		{"oidc", "https://token.actions.githubusercontent.com|repo:other/app", nil, false},
+		{"oidc", "U1", nil, false},
+		{"slack", "https://token.actions.githubusercontent.com|repo:org/app", nil, false},
+	}
+	for _, tc := range cases {
+		if got := prod.Allows(tc.kind, tc.id, tc.groups); got != tc.want {
+			t.Fatalf("Allows(%s, %s, %v) = %v, want %v", tc.kind, tc.id, tc.groups, got, tc.want)
+		}
+	}
+}
+
+func TestLoadPolicyValidatesApprovers(t *testing.T) {
+	cases := map[string]string{
+		"approvers: {}":                  "approvers lists no slack_users",
+		`approvers: {slack_users: [""]}`: "empty Slack ID",
+		"approvers: {users: [U1]}":       `unknown field "users"`,
+		`approvers: {subjects: ["[a"]}`:  "invalid pattern",
+	}
+	for effect, want := range cases {
+		data := "policy_id: bad\nrules:\n  - id: r\n    effect:\n      " + effect + "\n"
+		_, err := LoadPolicyFromBytes([]byte(data))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", effect, want, err)
+		}
+	}
+}
+
+func TestEvaluateApprovalTimeout(t *testing.T) {
+	data := `policy_id: timeout
+defaults:
+  require_approval: true
+  approval_timeout: 4h
+rules:
+  - id: prod
+    match: {env: prod}
+    effect: {approval_timeout: 30m}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if d := Evaluate(loaded.Policy, loaded.Hash, Input{Env: "prod"}); d.ApprovalTimeout != 30*time.Minute {
+		t.Fatalf("expected the rule timeout, got %s", d.ApprovalTimeout)
+	}
+	if d := Evaluate(loaded.Policy, loaded.Hash, Input{Env: "dev"}); d.ApprovalTimeout != 4*time.Hour {
+		t.Fatalf("expected the default timeout, got %s", d.ApprovalTimeout)
+	}
+
+	data = `policy_id: timeout
+evaluation: all_matching
+combining: deny_overrides
+defaults: {approval_timeout: 10m}
+rules:
+  - id: prod
+    match: {env: prod}
+    effect: {require_approval: true, approval_timeout: 2h}
+  - id: destroy
+    match: {action: terraform.destroy}
+    effect: {approval_timeout: 1h}
+`
+	loaded, err = LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.destroy", Env: "prod"}); d.ApprovalTimeout != time.Hour {
+		t.Fatalf("expected the shortest rule timeout, got %s", d.ApprovalTimeout)
+	}
+
+	for _, bad := range []string{"0s", "-5m", "soon", "[1h]"} {
+		_, err := LoadPolicyFromBytes([]byte("policy_id: bad\ndefaults:\n  approval_timeout: " + bad + "\n"))
+		if err == nil || !strings.Contains(err.Error(), "approval_timeout must be a positive duration") {
+			t.Fatalf("%s: expected a timeout error, got %v", bad, err)
+		}
+	}
+}
+
+func TestEvaluateApprovalTransport(t *testing.T) {
+	data := `policy_id: transport
+defaults: {require_approval: true}
+rules:
+  - id: platform
+    match: {resource: "platform/*"}
+    effect: {approval_transport: teams}
+  - id: prod
+    match: {env: prod}
+    effect: {approval_transport: slack}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if d := Evaluate(loaded.Policy, loaded.Hash, Input{Resource: "platform/vpc", Env: "prod"}); d.ApprovalTransport != ApprovalTransportTeams {
+		t.Fatalf("expected teams from the first matching rule, got %q", d.ApprovalTransport)
+	}
+	if d := Evaluate(loaded.Policy, loaded.Hash, Input{Resource: "app", Env: "dev"}); d.ApprovalTransport != "" {
+		t.Fatalf("expected the gateway default, got %q", d.ApprovalTransport)
+	}
+
+	_, err = LoadPolicyFromBytes([]byte("policy_id: bad\nrules:\n  - id: r\n    effect: {approval_transport: email}\n"))
+	if err == nil || !strings.Contains(err.Error(), "approval_transport must be slack, teams or webhook") {
+		t.Fatalf("expected a transport error, got %v", err)
+	}
+}
+
+func TestEvaluateApprovalChannel(t *testing.T) {
+	data := `policy_id: channel
+defaults: {require_approval: true}
+rules:
+  - id: migrations
+    match: {action: "db.*"}
+    effect: {approval_channel: C_DATA, approval_mentions: [S_DBA]}
+  - id: prod
+    match: {env: prod}
+    effect: {approval_mentions: [S_SRE]}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "db.migrate", Env: "prod"})
+	if d.ApprovalChannel != "C_DATA" || strings.Join(d.ApprovalMentions, ",") != "S_DBA" {
+		t.Fatalf("expected the first matching rule's routing, got %q %v", d.ApprovalChannel, d.ApprovalMentions)
+	}
+	if d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.apply", Env: "dev"}); d.ApprovalChannel != "" || d.ApprovalMentions != nil {
+		t.Fatalf("expected the gateway default, got %q %v", d.ApprovalChannel, d.ApprovalMentions)
+	}
+
+	loaded, err = LoadPolicyFromBytes([]byte("evaluation: all_matching\ncombining: deny_overrides\n" + data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	d = Evaluate(loaded.Policy, loaded.Hash, Input{Action: "db.migrate", Env: "prod"})
+	if d.ApprovalChannel != "C_DATA" || strings.Join(d.ApprovalMentions, ",") != "S_DBA,S_SRE" {
+		t.Fatalf("expected the first channel and every rule's mentions, got %q %v", d.ApprovalChannel, d.ApprovalMentions)
+	}
+
+	_, err = LoadPolicyFromBytes([]byte("policy_id: bad\nrules:\n  - id: r\n    effect: {approval_channel: \"https://example.test/hook\"}\n"))
+	if err == nil || !strings.Contains(err.Error(), "approval_channel must be a Slack channel ID or a Teams channel alias, not a URL") {
+		t.Fatalf("expected a URL channel to be rejected, got %v", err)
+	}
+}
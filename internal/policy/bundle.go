@@ -0,0 +1,233 @@
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BundleSchema marks a serialized policy bundle: the flattened, ordered set of files
+// a multi-file policy was loaded from. The bundle bytes are what gets hashed and
+// stored, so a snapshot reloads without touching the filesystem.
+const BundleSchema = "relia.policy_bundle.v1"
+
+// BundleFile is one source file of a bundle; Path is relative to the bundle root.
+type BundleFile struct {
+	Path    string `yaml:"path"`
+	Content string `yaml:"content"`
+}
+
+type bundleDoc struct {
+	Bundle string       `yaml:"relia_bundle"`
+	Files  []BundleFile `yaml:"files"`
+}
+
+// isBundle reports whether data is a serialized bundle rather than a policy file.
+func isBundle(data []byte) bool {
+	var probe struct {
+		Bundle string `yaml:"relia_bundle"`
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Bundle != ""
+}
+
+func marshalBundle(files []BundleFile) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(bundleDoc{Bundle: BundleSchema, Files: files}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func parseBundle(data []byte) ([]BundleFile, error) {
+	var doc bundleDoc
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Bundle != BundleSchema {
+		return nil, fmt.Errorf("unsupported policy bundle %q", doc.Bundle)
+	}
+	if len(doc.Files) == 0 {
+		return nil, fmt.Errorf("policy bundle has no files")
+	}
+	return doc.Files, nil
+}
+
+// mergeBundle merges files in order. policy_id, policy_version, evaluation (with its
+// combining algorithm), defaults and identities may be set by at most one file; rules and freezes are concatenated and their IDs must be unique.
+func mergeBundle(files []BundleFile) (Policy, error) {
+	var merged Policy
+	headerFrom := map[string]string{}
+	ruleFrom := map[string]string{}
+	freezeFrom := map[string]string{}
+
+	setHeader := func(field, path string) error {
+		if prev, ok := headerFrom[field]; ok {
+			return fmt.Errorf("%s: %s already set in %s", path, field, prev)
+		}
+		headerFrom[field] = path
+		return nil
+	}
+
+	for _, f := range files {
+		part, err := decodePolicy([]byte(f.Content), f.Path)
+		if err != nil {
+			return Policy{}, err
+		}
+		if part.PolicyID != "" {
+			if err := setHeader("policy_id", f.Path); err != nil {
+				return Policy{}, err
+			}
+			merged.PolicyID = part.PolicyID
+		}
+		if part.PolicyVersion != "" {
+			if err := setHeader("policy_version", f.Path); err != nil {
+				return Policy{}, err
+			}
+			merged.PolicyVersion = part.PolicyVersion
+		}
+		if part.Evaluation != "" {
+			if err := setHeader("evaluation", f.Path); err != nil {
+				return Policy{}, err
+			}
+			merged.Evaluation = part.Evaluation
+			merged.Combining = part.Combining
+		}
+		if part.Defaults != (PolicyDefaults{}) {
+			if err := setHeader("defaults", f.Path); err != nil {
+				return Policy{}, err
+			}
+			merged.Defaults = part.Defaults
+			merged.positions = part.positions
+		}
+		if len(part.Identities) > 0 {
+			if err := setHeader("identities", f.Path); err != nil {
+				return Policy{}, err
+			}
+			merged.Identities = part.Identities
+		}
+		for _, rule := range part.Rules {
+			if rule.ID != "" {
+				if prev, ok := ruleFrom[rule.ID]; ok {
+					return Policy{}, fmt.Errorf("%s: duplicate rule id %q (also in %s)", f.Path, rule.ID, prev)
+				}
+				ruleFrom[rule.ID] = f.Path
+			}
+			merged.Rules = append(merged.Rules, rule)
+		}
+		merged.Tests = append(merged.Tests, part.Tests...)
+		for _, freeze := range part.Freezes {
+			if prev, ok := freezeFrom[freeze.ID]; ok {
+				return Policy{}, fmt.Errorf("%s: duplicate freeze id %q (also in %s)", f.Path, freeze.ID, prev)
+			}
+			freezeFrom[freeze.ID] = f.Path
+			merged.Freezes = append(merged.Freezes, freeze)
+		}
+	}
+	return merged, nil
+}
+
+// collectBundle reads path and everything it includes, in merge order: a file comes
+// before its includes, and a directory contributes its *.yaml/*.yml files sorted by
+// name, skipping *_test.yaml test files.
+func collectBundle(path string) ([]BundleFile, error) {
+	root := path
+	if info, err := os.Stat(path); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		root = filepath.Dir(path)
+	}
+
+	var files []BundleFile
+	seen := map[string]bool{}
+	var visit func(p string, chain []string) error
+	visit = func(p string, chain []string) error {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		for _, c := range chain {
+			if c == abs {
+				return fmt.Errorf("include cycle at %s", p)
+			}
+		}
+		if seen[abs] {
+			return fmt.Errorf("%s included more than once", p)
+		}
+
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			entries, err := os.ReadDir(p)
+			if err != nil {
+				return err
+			}
+			var names []string
+			for _, e := range entries {
+				ext := filepath.Ext(e.Name())
+				if !e.IsDir() && (ext == ".yaml" || ext == ".yml") && !IsTestFile(e.Name()) {
+					names = append(names, e.Name())
+				}
+			}
+			sort.Strings(names)
+			if len(names) == 0 {
+				return fmt.Errorf("%s: no policy files", p)
+			}
+			for _, name := range names {
+				if err := visit(filepath.Join(p, name), append(chain, abs)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		seen[abs] = true
+		// #nosec G304 -- paths come from the operator-configured policy and its includes.
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			rel = p
+		}
+		files = append(files, BundleFile{Path: filepath.ToSlash(rel), Content: string(data)})
+
+		var header struct {
+			Includes []string `yaml:"includes"`
+		}
+		if err := yaml.Unmarshal(data, &header); err != nil {
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+		for _, inc := range header.Includes {
+			target := inc
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(p), inc)
+			}
+			if err := visit(target, append(chain, abs)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := visit(path, nil); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
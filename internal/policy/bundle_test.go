@@ -0,0 +1,194 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePolicyFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	return dir
+}
+
+func ruleIDs(p Policy) []string {
+	var ids []string
+	for _, rule := range p.Rules {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+func TestLoadPolicyIncludes(t *testing.T) {
+	dir := writePolicyFiles(t, map[string]string{
+		"relia.yaml": `policy_id: org
+policy_version: "1"
+defaults:
+  ttl_seconds: 900
+includes:
+  - platform.yaml
+  - teams
+rules:
+  - id: root_rule
+    match: {env: dev}
+`,
+		"platform.yaml": `rules:
+  - id: platform_prod
+    match: {env: prod}
+    effect: {require_approval: true}
+`,
+		"teams/b-payments.yaml": "rules:\n  - id: payments\n    match: {action: pay.*}\n",
+		"teams/a-search.yaml":   "rules:\n  - id: search\n    match: {action: search.*}\n",
+		"teams/README.md":       "not a policy",
+	})
+
+	loaded, err := LoadPolicy(filepath.Join(dir, "relia.yaml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := strings.Join(ruleIDs(loaded.Policy), ","); got != "root_rule,platform_prod,search,payments" {
+		t.Fatalf("unexpected rule order: %s", got)
+	}
+	if got := strings.Join(loaded.Files, ","); got != "relia.yaml,platform.yaml,teams/a-search.yaml,teams/b-payments.yaml" {
+		t.Fatalf("unexpected files: %s", got)
+	}
+	if loaded.Policy.PolicyID != "org" || loaded.Policy.Defaults.TTLSeconds != 900 {
+		t.Fatalf("expected root header, got %+v", loaded.Policy)
+	}
+
+	// The stored bundle reloads to the same policy and hash without the filesystem.
+	reloaded, err := LoadPolicyFromBytes(loaded.Bytes)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if reloaded.Hash != loaded.Hash || strings.Join(ruleIDs(reloaded.Policy), ",") != strings.Join(ruleIDs(loaded.Policy), ",") {
+		t.Fatalf("reloaded bundle differs: %s vs %s", reloaded.Hash, loaded.Hash)
+	}
+	decision := Evaluate(reloaded.Policy, reloaded.Hash, Input{Env: "prod"})
+	if decision.MatchedRuleID != "platform_prod" || decision.PolicyHash != loaded.Hash {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+
+	// Any file change changes the bundle hash.
+	if err := os.WriteFile(filepath.Join(dir, "teams", "a-search.yaml"), []byte("rules:\n  - id: search\n    match: {action: find.*}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	changed, err := LoadPolicy(filepath.Join(dir, "relia.yaml"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if changed.Hash == loaded.Hash {
+		t.Fatalf("expected hash to change")
+	}
+}
+
+func TestLoadPolicyDirectory(t *testing.T) {
+	dir := writePolicyFiles(t, map[string]string{
+		"00-base.yaml": "policy_id: dir\ndefaults:\n  deny: true\n",
+		"10-prod.yml":  "rules:\n  - id: prod\n    match: {env: prod}\n    effect: {deny: false}\n",
+	})
+	loaded, err := LoadPolicy(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Policy.PolicyID != "dir" || !loaded.Policy.Defaults.Deny || len(loaded.Policy.Rules) != 1 {
+		t.Fatalf("unexpected policy: %+v", loaded.Policy)
+	}
+	again, err := LoadPolicy(dir)
+	if err != nil || again.Hash != loaded.Hash {
+		t.Fatalf("expected deterministic hash, got %s vs %s (%v)", again.Hash, loaded.Hash, err)
+	}
+}
+
+func TestLoadPolicySingleFileHashUnchanged(t *testing.T) {
+	data, err := os.ReadFile("../../policies/relia.yaml")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	loaded, err := LoadPolicy("../../policies/relia.yaml")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	fromBytes, _ := LoadPolicyFromBytes(data)
+	if loaded.Hash != fromBytes.Hash || loaded.Files != nil || string(loaded.Bytes) != string(data) {
+		t.Fatalf("single-file policy should keep raw bytes and hash")
+	}
+}
+
+func TestLoadPolicyBundleConflicts(t *testing.T) {
+	cases := map[string]struct {
+		files map[string]string
+		want  string
+	}{
+		"duplicate_rule": {
+			files: map[string]string{
+				"relia.yaml": "includes: [a.yaml]\nrules:\n  - id: r\n",
+				"a.yaml":     "rules:\n  - id: r\n",
+			},
+			want: `duplicate rule id "r"`,
+		},
+		"duplicate_header": {
+			files: map[string]string{
+				"relia.yaml": "policy_id: one\nincludes: [a.yaml]\n",
+				"a.yaml":     "policy_id: two\n",
+			},
+			want: "policy_id already set in relia.yaml",
+		},
+		"separation_of_duties_without_identities": {
+			files: map[string]string{
+				"relia.yaml": "includes: [a.yaml]\n",
+				"a.yaml":     "rules:\n  - id: r\n    effect:\n      approvals: {required: 2, separation_of_duties: true}\n",
+			},
+			want: `a.yaml:4:7: rule "r" sets separation_of_duties`,
+		},
+		"cycle": {
+			files: map[string]string{
+				"relia.yaml": "includes: [a.yaml]\n",
+				"a.yaml":     "includes: [relia.yaml]\n",
+			},
+			want: "include cycle",
+		},
+		"twice": {
+			files: map[string]string{
+				"relia.yaml": "includes: [a.yaml, a.yaml]\n",
+				"a.yaml":     "rules: []\n",
+			},
+			want: "included more than once",
+		},
+		"missing": {
+			files: map[string]string{"relia.yaml": "includes: [nope.yaml]\n"},
+			want:  "nope.yaml",
+		},
+		"invalid_include": {
+			files: map[string]string{
+				"relia.yaml": "includes: [a.yaml]\n",
+				"a.yaml":     "rules:\n  - id: r\n    match: {env: \"[\"}\n",
+			},
+			want: "a.yaml",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := writePolicyFiles(t, tc.files)
+			_, err := LoadPolicy(filepath.Join(dir, "relia.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	if _, err := LoadPolicyFromBytes([]byte("includes: [a.yaml]\n")); err == nil {
+		t.Fatalf("expected includes error without a path")
+	}
+}
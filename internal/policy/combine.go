@@ -0,0 +1,179 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Evaluation modes.
+const (
+	// EvaluationFirstMatch applies the first matching rule; it is the default.
+	EvaluationFirstMatch = "first_match"
+	// EvaluationAllMatching applies every matching rule, merged by the policy's
+	// combining algorithm.
+	EvaluationAllMatching = "all_matching"
+)
+
+// CombineDenyOverrides merges matched rules so the most restrictive outcome wins: any
+// deny denies, any rule requiring approval requires it, the shortest TTL and approval
+// timeout apply, and the largest approval quorum is needed.
+const CombineDenyOverrides = "deny_overrides"
+
+// validateEvaluation checks the evaluation mode and its combining algorithm.
+func (p Policy) validateEvaluation() error {
+	switch p.Evaluation {
+	case "", EvaluationFirstMatch:
+		if p.Combining != "" {
+			return fmt.Errorf("combining %q requires evaluation: %s", p.Combining, EvaluationAllMatching)
+		}
+	case EvaluationAllMatching:
+		if p.Combining == "" {
+			return fmt.Errorf("evaluation: %s requires a combining algorithm (%s)", EvaluationAllMatching, CombineDenyOverrides)
+		}
+		if p.Combining != CombineDenyOverrides {
+			return fmt.Errorf("unsupported combining algorithm %q (only %s is supported)", p.Combining, CombineDenyOverrides)
+		}
+	default:
+		return fmt.Errorf("unknown evaluation %q (want %s or %s)", p.Evaluation, EvaluationFirstMatch, EvaluationAllMatching)
+	}
+	return nil
+}
+
+// evaluateAllMatching applies every active matching rule with deny-overrides
+// combining. An explicit setting on any matched rule replaces the default; among
+// rules, deny beats allow, require_approval: true beats false, the shortest TTL and
+// approval timeout win, and the largest approval quorum applies. The role, risk,
+// reason and approval transport come from the first matched rule that sets them,
+// except that a denied decision takes its reason from the first denying rule.
+func evaluateAllMatching(p Policy, policyHash string, input Input) Decision {
+	decision := Decision{
+		RequireApproval: p.Defaults.RequireApproval,
+		TTLSeconds:      p.Defaults.TTLSeconds,
+		ApprovalTimeout: time.Duration(p.Defaults.ApprovalTimeout),
+		PolicyID:        p.PolicyID,
+		PolicyVersion:   p.PolicyVersion,
+		PolicyHash:      policyHash,
+	}
+	deny := p.Defaults.Deny
+
+	var (
+		denySet, approvalSet, ttlSet, timeoutSet bool
+		denyReason                               string
+		missing                                  []string
+	)
+	for _, rule := range p.Rules {
+		matched, patternCodes := matchRule(rule.Match, input)
+		if !matched || !rule.When.Active(input.At) {
+			continue
+		}
+		holds, err := rule.conditionHolds(input)
+		if err != nil {
+			conditionFailed(&decision, rule, err)
+			return decision
+		}
+		if !holds {
+			continue
+		}
+
+		if decision.MatchedRuleID == "" {
+			decision.MatchedRuleID = rule.ID
+		}
+		decision.MatchedRuleIDs = append(decision.MatchedRuleIDs, rule.ID)
+		decision.ReasonCodes = append(decision.ReasonCodes, "POLICY_MATCH:"+rule.ID)
+		decision.ReasonCodes = append(decision.ReasonCodes, patternCodes...)
+		if !rule.When.IsZero() {
+			decision.ReasonCodes = append(decision.ReasonCodes, "WHEN_MATCH:"+rule.ID)
+		}
+		if rule.Condition != "" {
+			decision.ReasonCodes = append(decision.ReasonCodes, "CONDITION_MATCH:"+rule.ID)
+		}
+
+		effect := rule.Effect
+		if effect.Deny != nil {
+			if !denySet {
+				deny, denySet = *effect.Deny, true
+			} else {
+				deny = deny || *effect.Deny
+			}
+			if *effect.Deny && denyReason == "" {
+				denyReason = effect.Reason
+			}
+		}
+		if effect.RequireApproval != nil {
+			if !approvalSet {
+				decision.RequireApproval, approvalSet = *effect.RequireApproval, true
+			} else {
+				decision.RequireApproval = decision.RequireApproval || *effect.RequireApproval
+			}
+		}
+		if effect.TTLSeconds != nil {
+			if !ttlSet || *effect.TTLSeconds < decision.TTLSeconds {
+				decision.TTLSeconds = *effect.TTLSeconds
+			}
+			ttlSet = true
+		}
+		if effect.ApprovalTimeout != nil {
+			if timeout := time.Duration(*effect.ApprovalTimeout); !timeoutSet || timeout < decision.ApprovalTimeout {
+				decision.ApprovalTimeout = timeout
+			}
+			timeoutSet = true
+		}
+		if decision.AWSRoleARN == "" {
+			decision.AWSRoleARN = effect.AWSRoleARN
+		}
+		if decision.Risk == "" {
+			decision.Risk = effect.Risk
+		}
+		if decision.ApprovalTransport == "" {
+			decision.ApprovalTransport = effect.ApprovalTransport
+		}
+		if decision.ApprovalChannel == "" {
+			decision.ApprovalChannel = string(effect.ApprovalChannel)
+		}
+		for _, mention := range effect.ApprovalMentions {
+			if !containsString(decision.ApprovalMentions, mention) {
+				decision.ApprovalMentions = append(decision.ApprovalMentions, mention)
+			}
+		}
+		if decision.Reason == "" {
+			decision.Reason = effect.Reason
+		}
+		decision.Approvals = mergeQuorum(decision.Approvals, effect.Approvals)
+		for _, name := range missingEvidence(effect.RequireEvidence, input) {
+			if !containsString(missing, name) {
+				missing = append(missing, name)
+			}
+		}
+	}
+
+	switch {
+	case len(missing) > 0:
+		decision.Verdict = "deny"
+		for _, name := range missing {
+			decision.ReasonCodes = append(decision.ReasonCodes, "EVIDENCE_MISSING:"+name)
+		}
+		if decision.Reason == "" {
+			decision.Reason = "missing required evidence: " + strings.Join(missing, ", ")
+		}
+	case deny:
+		decision.Verdict = "deny"
+		if denyReason != "" {
+			decision.Reason = denyReason
+		}
+	case decision.RequireApproval:
+		decision.Verdict = "require_approval"
+	default:
+		decision.Verdict = "allow"
+	}
+	return decision
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
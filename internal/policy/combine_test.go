@@ -0,0 +1,207 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+const allMatchingPolicy = `policy_id: combined
+policy_version: "1"
+evaluation: all_matching
+combining: deny_overrides
+defaults:
+  ttl_seconds: 3600
+rules:
+  - id: team_prod
+    match: {env: prod}
+    effect:
+      ttl_seconds: 1800
+      aws_role_arn: "arn:aws:iam::123456789012:role/team"
+      risk: medium
+  - id: baseline_tf_approval
+    match: {action: "terraform.*", env: prod}
+    effect:
+      require_approval: true
+      ttl_seconds: 900
+      risk: high
+  - id: dev_fast_path
+    match: {env: dev}
+    effect: {require_approval: false}
+  - id: baseline_destroy
+    match:
+      action: "terraform.*"
+      intent:
+        - {field: plan.destroy_count, op: gt, value: 0}
+    effect:
+      deny: true
+      reason: destroys need a break-glass change
+  - id: baseline_evidence
+    match: {action: terraform.apply, env: prod}
+    effect:
+      require_evidence: [plan_digest]
+`
+
+func TestEvaluateAllMatching(t *testing.T) {
+	loaded, err := LoadPolicyFromBytes([]byte(allMatchingPolicy))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	p := loaded.Policy
+
+	cases := []struct {
+		name     string
+		input    Input
+		verdict  string
+		ttl      int
+		role     string
+		risk     string
+		matched  string
+		reason   string
+		codeHint string
+	}{
+		{
+			name:    "approval if any, shortest ttl",
+			input:   Input{Action: "terraform.plan", Env: "prod"},
+			verdict: "require_approval", ttl: 900, role: "arn:aws:iam::123456789012:role/team", risk: "medium",
+			matched: "team_prod,baseline_tf_approval",
+		},
+		{
+			name:    "deny overrides regardless of order",
+			input:   Input{Action: "terraform.plan", Env: "prod", Intent: map[string]any{"plan": map[string]any{"destroy_count": 2}}},
+			verdict: "deny", ttl: 900, risk: "medium",
+			matched: "team_prod,baseline_tf_approval,baseline_destroy",
+			reason:  "destroys need a break-glass change",
+		},
+		{
+			name:    "missing evidence from any rule denies",
+			input:   Input{Action: "terraform.apply", Env: "prod"},
+			verdict: "deny", ttl: 900,
+			matched:  "team_prod,baseline_tf_approval,baseline_evidence",
+			codeHint: "EVIDENCE_MISSING:plan_digest",
+		},
+		{
+			name:    "evidence present",
+			input:   Input{Action: "terraform.apply", Env: "prod", PlanDigest: "sha256:plan"},
+			verdict: "require_approval", ttl: 900,
+			matched: "team_prod,baseline_tf_approval,baseline_evidence",
+		},
+		{
+			name:    "single match",
+			input:   Input{Action: "deploy", Env: "dev"},
+			verdict: "allow", ttl: 3600,
+			matched: "dev_fast_path",
+		},
+		{
+			name:    "defaults",
+			input:   Input{Action: "deploy", Env: "stage"},
+			verdict: "allow", ttl: 3600,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := Evaluate(p, loaded.Hash, tc.input)
+			if d.Verdict != tc.verdict || d.TTLSeconds != tc.ttl {
+				t.Fatalf("got verdict=%s ttl=%d, want %s/%d (%+v)", d.Verdict, d.TTLSeconds, tc.verdict, tc.ttl, d)
+			}
+			if got := strings.Join(d.MatchedRuleIDs, ","); got != tc.matched {
+				t.Fatalf("matched rules = %q, want %q", got, tc.matched)
+			}
+			for _, id := range d.MatchedRuleIDs {
+				if !containsString(d.ReasonCodes, "POLICY_MATCH:"+id) {
+					t.Fatalf("missing POLICY_MATCH:%s in %v", id, d.ReasonCodes)
+				}
+			}
+			if len(d.MatchedRuleIDs) > 0 && d.MatchedRuleID != d.MatchedRuleIDs[0] {
+				t.Fatalf("MatchedRuleID = %s, want first match", d.MatchedRuleID)
+			}
+			if tc.role != "" && d.AWSRoleARN != tc.role {
+				t.Fatalf("role = %s, want %s", d.AWSRoleARN, tc.role)
+			}
+			if tc.risk != "" && d.Risk != tc.risk {
+				t.Fatalf("risk = %s, want %s", d.Risk, tc.risk)
+			}
+			if tc.reason != "" && d.Reason != tc.reason {
+				t.Fatalf("reason = %q, want %q", d.Reason, tc.reason)
+			}
+			if tc.codeHint != "" && !containsString(d.ReasonCodes, tc.codeHint) {
+				t.Fatalf("missing %s in %v", tc.codeHint, d.ReasonCodes)
+			}
+		})
+	}
+}
+
+func TestEvaluateFirstMatchIsDefault(t *testing.T) {
+	data := strings.Replace(allMatchingPolicy, "evaluation: all_matching\ncombining: deny_overrides\n", "", 1)
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.plan", Env: "prod"})
+	if d.Verdict != "allow" || d.TTLSeconds != 1800 || strings.Join(d.MatchedRuleIDs, ",") != "team_prod" {
+		t.Fatalf("expected first match only, got %+v", d)
+	}
+}
+
+func TestEvaluateAllMatchingDenyFalseOverridesDefault(t *testing.T) {
+	data := `policy_id: p
+evaluation: all_matching
+combining: deny_overrides
+defaults: {deny: true}
+rules:
+  - id: allow_dev
+    match: {env: dev}
+    effect: {deny: false}
+  - id: audit
+    match: {action: read}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "read", Env: "dev"}); d.Verdict != "allow" {
+		t.Fatalf("expected allow, got %+v", d)
+	}
+	if d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "read", Env: "prod"}); d.Verdict != "deny" {
+		t.Fatalf("expected default deny, got %+v", d)
+	}
+}
+
+func TestLoadPolicyValidatesEvaluation(t *testing.T) {
+	cases := map[string]string{
+		"evaluation: all_matching\n":                              "requires a combining algorithm",
+		"evaluation: all_matching\ncombining: permit_overrides\n": `unsupported combining algorithm "permit_overrides" (only deny_overrides is supported)`,
+		"evaluation: best_match\n":                                `unknown evaluation "best_match"`,
+		"combining: deny_overrides\n":                             "requires evaluation: all_matching",
+	}
+	for header, want := range cases {
+		_, err := LoadPolicyFromBytes([]byte("policy_id: p\n" + header))
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.HasPrefix(err.Error(), "2:1: ") {
+			t.Fatalf("%q: expected %q at 2:1, got %v", header, want, err)
+		}
+	}
+}
+
+func TestMergeBundleEvaluationHeader(t *testing.T) {
+	dir := writePolicyFiles(t, map[string]string{
+		"a.yaml": "policy_id: p\nevaluation: all_matching\ncombining: deny_overrides\n",
+		"b.yaml": "rules:\n  - id: all\n  - id: prod\n    match: {env: prod}\n",
+	})
+	loaded, err := LoadPolicy(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Policy.Evaluation != EvaluationAllMatching || loaded.Policy.Combining != CombineDenyOverrides {
+		t.Fatalf("unexpected header: %+v", loaded.Policy)
+	}
+	if issues := Lint(loaded.Policy); len(issues) != 0 {
+		t.Fatalf("shadowing does not apply to all_matching: %+v", issues)
+	}
+
+	dir = writePolicyFiles(t, map[string]string{
+		"a.yaml": "policy_id: p\nevaluation: first_match\n",
+		"b.yaml": "evaluation: all_matching\ncombining: deny_overrides\n",
+	})
+	if _, err := LoadPolicy(dir); err == nil || !strings.Contains(err.Error(), "evaluation already set in a.yaml") {
+		t.Fatalf("expected duplicate header error, got %v", err)
+	}
+}
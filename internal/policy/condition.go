@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+// A rule `condition` is a CEL expression that must evaluate to true for the rule to
+// match. It sees these variables:
+//
+//	request  map(string, string)  action, resource, env
+//	actor    map(string, string)  issuer, subject, repo, workflow, sha, ref, environment, actor
+//	intent   map(string, dyn)     the request intent (empty when absent)
+//	evidence map(string, string)  plan_digest, diff_url
+//	now      timestamp            the evaluation time
+//
+// Conditions are compiled when the policy loads.
+
+var conditionEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("request", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("actor", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("intent", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("evidence", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("now", cel.TimestampType),
+		cel.CrossTypeNumericComparisons(true),
+		ext.Strings(),
+		ext.Lists(),
+		ext.Sets(),
+	)
+})
+
+// compileCondition type-checks expr and returns a program for it.
+func compileCondition(expr string) (cel.Program, error) {
+	env, err := conditionEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%s", strings.ReplaceAll(issues.Err().Error(), "\n", " "))
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("must evaluate to bool, not %s", ast.OutputType())
+	}
+	return env.Program(ast)
+}
+
+// compileConditions compiles every rule condition; positions locate errors.
+func (p *Policy) compileConditions() error {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if strings.TrimSpace(rule.Condition) == "" {
+			continue
+		}
+		program, err := compileCondition(rule.Condition)
+		if err != nil {
+			return fmt.Errorf("%s: rule %q: invalid condition: %w", rule.position("condition"), rule.ID, err)
+		}
+		rule.program = program
+	}
+	return nil
+}
+
+// conditionHolds evaluates the rule's condition against input. A rule without a
+// condition always holds.
+func (r PolicyRule) conditionHolds(input Input) (bool, error) {
+	if r.program == nil {
+		return true, nil
+	}
+	out, _, err := r.program.Eval(conditionVars(input))
+	if err != nil {
+		return false, err
+	}
+	holds, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition returned %T, not bool", out.Value())
+	}
+	return holds, nil
+}
+
+func conditionVars(input Input) map[string]any {
+	intent, _ := celValue(input.Intent).(map[string]any)
+	if intent == nil {
+		intent = map[string]any{}
+	}
+	return map[string]any{
+		"request": map[string]string{
+			"action":   input.Action,
+			"resource": input.Resource,
+			"env":      input.Env,
+		},
+		"actor": map[string]string{
+			"issuer":      input.Issuer,
+			"subject":     input.Subject,
+			"repo":        input.Repo,
+			"workflow":    input.Workflow,
+			"sha":         input.SHA,
+			"ref":         input.Ref,
+			"environment": input.Environment,
+			"actor":       input.Actor,
+		},
+		"intent": intent,
+		"evidence": map[string]string{
+			"plan_digest": input.PlanDigest,
+			"diff_url":    input.DiffURL,
+		},
+		"now": input.At,
+	}
+}
+
+// celValue normalizes decoded JSON/YAML values into types CEL understands.
+func celValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = celValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = celValue(item)
+		}
+		return out
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return n
+		}
+		f, _ := val.Float64()
+		return f
+	case int:
+		return int64(val)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const conditionPolicy = `policy_id: cond
+defaults:
+  ttl_seconds: 900
+rules:
+  - id: big_destroy
+    match: {action: "terraform.*"}
+    condition: >-
+      has(intent.plan) && intent.plan.destroy_count > intent.plan.create_count
+    effect: {deny: true, reason: more destroys than creates}
+  - id: prod_buckets
+    match: {env: prod}
+    condition: request.resource.matches('^arn:aws:s3:::prod-[a-z]+$')
+    effect: {require_approval: true}
+  - id: trusted_repos
+    condition: actor.repo in ['org/infra', 'org/platform'] && evidence.plan_digest != ''
+    effect: {ttl_seconds: 3600}
+  - id: weekend
+    condition: now.getDayOfWeek('UTC') == 6
+    effect: {deny: true}
+`
+
+func TestEvaluateConditions(t *testing.T) {
+	loaded, err := LoadPolicyFromBytes([]byte(conditionPolicy))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	p := loaded.Policy
+	saturday := mustTime(t, "2025-12-20T10:00:00Z")
+	monday := mustTime(t, "2025-12-22T10:00:00Z")
+
+	var jsonIntent map[string]any
+	dec := json.NewDecoder(strings.NewReader(`{"plan":{"destroy_count":3,"create_count":1.0}}`))
+	dec.UseNumber()
+	if err := dec.Decode(&jsonIntent); err != nil {
+		t.Fatalf("decode intent: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		input   Input
+		rule    string
+		verdict string
+	}{
+		{"intent comparison", Input{Action: "terraform.apply", Intent: jsonIntent, At: monday}, "big_destroy", "deny"},
+		{"guarded missing intent", Input{Action: "terraform.apply", At: monday}, "", "allow"},
+		{"regex over resource", Input{Action: "s3.put", Resource: "arn:aws:s3:::prod-logs", Env: "prod", At: monday}, "prod_buckets", "require_approval"},
+		{"regex miss", Input{Action: "s3.put", Resource: "arn:aws:s3:::dev-logs", Env: "prod", At: monday}, "", "allow"},
+		{"allowlist", Input{Action: "deploy", Repo: "org/infra", PlanDigest: "sha256:p", At: monday}, "trusted_repos", "allow"},
+		{"allowlist miss", Input{Action: "deploy", Repo: "org/app", PlanDigest: "sha256:p", At: monday}, "", "allow"},
+		{"time", Input{Action: "deploy", At: saturday}, "weekend", "deny"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := Evaluate(p, loaded.Hash, tc.input)
+			if d.MatchedRuleID != tc.rule || d.Verdict != tc.verdict {
+				t.Fatalf("got rule=%q verdict=%s, want %q/%s (%+v)", d.MatchedRuleID, d.Verdict, tc.rule, tc.verdict, d)
+			}
+			if tc.rule != "" && !containsString(d.ReasonCodes, "CONDITION_MATCH:"+tc.rule) {
+				t.Fatalf("missing CONDITION_MATCH in %v", d.ReasonCodes)
+			}
+		})
+	}
+
+	d := Evaluate(p, loaded.Hash, Input{Action: "deploy", Repo: "org/infra", PlanDigest: "sha256:p", At: monday})
+	if d.TTLSeconds != 3600 {
+		t.Fatalf("expected rule ttl, got %d", d.TTLSeconds)
+	}
+}
+
+func TestEvaluateConditionErrorDenies(t *testing.T) {
+	data := `policy_id: p
+rules:
+  - id: unguarded
+    condition: intent.plan.destroy_count > 0
+    effect: {require_approval: true}
+`
+	for _, mode := range []string{"", "evaluation: all_matching\ncombining: deny_overrides\n"} {
+		loaded, err := LoadPolicyFromBytes([]byte(mode + data))
+		if err != nil {
+			t.Fatalf("load: %v", err)
+		}
+		d := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "deploy"})
+		if d.Verdict != "deny" || !containsString(d.ReasonCodes, "CONDITION_ERROR:unguarded") || !strings.Contains(d.Reason, "unguarded") {
+			t.Fatalf("%q: expected fail-closed deny, got %+v", mode, d)
+		}
+	}
+}
+
+func TestLoadPolicyRejectsInvalidCondition(t *testing.T) {
+	cases := map[string]string{
+		"request.action ==":        "invalid condition",
+		"request.action":           "must evaluate to bool",
+		"unknown_var == 'x'":       "undeclared reference",
+		"request.action.size() > ": "invalid condition",
+	}
+	for expr, want := range cases {
+		data := "policy_id: p\nrules:\n  - id: r\n    condition: \"" + expr + "\"\n"
+		_, err := LoadPolicyFromBytes([]byte(data))
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.HasPrefix(err.Error(), "4:5: rule \"r\"") {
+			t.Fatalf("%q: expected %q at 4:5, got %v", expr, want, err)
+		}
+	}
+}
+
+func TestLintConditionShadowing(t *testing.T) {
+	data := `policy_id: p
+rules:
+  - id: conditional
+    match: {env: prod}
+    condition: request.action == 'deploy'
+  - id: prod
+    match: {env: prod}
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if issues := Lint(loaded.Policy); len(issues) != 0 {
+		t.Fatalf("a conditional rule should not shadow: %+v", issues)
+	}
+}
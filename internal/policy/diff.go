@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"sort"
+	"time"
+)
+
+// DiffCase is one replayed request; ID labels it in the report (e.g. a line number
+// or context_id).
+type DiffCase struct {
+	ID    string
+	Input Input
+}
+
+// DiffOutcome is the part of a decision a policy change can affect.
+type DiffOutcome struct {
+	Verdict         string `json:"verdict"`
+	RequireApproval bool   `json:"require_approval"`
+	TTLSeconds      int    `json:"ttl_seconds"`
+	AWSRoleARN      string `json:"aws_role_arn,omitempty"`
+	MatchedRuleID   string `json:"matched_rule_id,omitempty"`
+}
+
+// DiffChange is a request whose outcome differs between the two policies. Fields names
+// what changed: verdict, require_approval, ttl_seconds, aws_role_arn.
+type DiffChange struct {
+	ID     string      `json:"id"`
+	Input  Input       `json:"input"`
+	Fields []string    `json:"fields"`
+	Old    DiffOutcome `json:"old"`
+	New    DiffOutcome `json:"new"`
+}
+
+// DiffGroup collects changes by the rule that matched under each policy; an empty
+// rule means the defaults applied.
+type DiffGroup struct {
+	OldRule string       `json:"old_rule"`
+	NewRule string       `json:"new_rule"`
+	Changes []DiffChange `json:"changes"`
+}
+
+// DiffReport summarizes replaying a corpus against two policies.
+type DiffReport struct {
+	OldHash string      `json:"old_policy_hash"`
+	NewHash string      `json:"new_policy_hash"`
+	Total   int         `json:"total"`
+	Changed int         `json:"changed"`
+	Groups  []DiffGroup `json:"groups"`
+}
+
+// Diff evaluates every case against both policies and reports outcome changes.
+// Cases without an evaluation time use now, the same instant for both policies.
+func Diff(oldPolicy, newPolicy LoadedPolicy, cases []DiffCase) DiffReport {
+	report := DiffReport{OldHash: oldPolicy.Hash, NewHash: newPolicy.Hash, Total: len(cases), Groups: []DiffGroup{}}
+	now := time.Now()
+
+	index := map[[2]string]int{}
+	for _, c := range cases {
+		input := c.Input
+		if input.At.IsZero() {
+			input.At = now
+		}
+		oldOut := diffOutcome(Evaluate(oldPolicy.Policy, oldPolicy.Hash, input))
+		newOut := diffOutcome(Evaluate(newPolicy.Policy, newPolicy.Hash, input))
+		fields := changedFields(oldOut, newOut)
+		if len(fields) == 0 {
+			continue
+		}
+
+		key := [2]string{oldOut.MatchedRuleID, newOut.MatchedRuleID}
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Groups)
+			index[key] = i
+			report.Groups = append(report.Groups, DiffGroup{OldRule: key[0], NewRule: key[1]})
+		}
+		report.Groups[i].Changes = append(report.Groups[i].Changes, DiffChange{
+			ID:     c.ID,
+			Input:  c.Input,
+			Fields: fields,
+			Old:    oldOut,
+			New:    newOut,
+		})
+		report.Changed++
+	}
+
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		if report.Groups[i].OldRule != report.Groups[j].OldRule {
+			return report.Groups[i].OldRule < report.Groups[j].OldRule
+		}
+		return report.Groups[i].NewRule < report.Groups[j].NewRule
+	})
+	return report
+}
+
+func diffOutcome(d Decision) DiffOutcome {
+	return DiffOutcome{
+		Verdict:         d.Verdict,
+		RequireApproval: d.RequireApproval,
+		TTLSeconds:      d.TTLSeconds,
+		AWSRoleARN:      d.AWSRoleARN,
+		MatchedRuleID:   d.MatchedRuleID,
+	}
+}
+
+func changedFields(a, b DiffOutcome) []string {
+	var fields []string
+	if a.Verdict != b.Verdict {
+		fields = append(fields, "verdict")
+	}
+	if a.RequireApproval != b.RequireApproval {
+		fields = append(fields, "require_approval")
+	}
+	if a.TTLSeconds != b.TTLSeconds {
+		fields = append(fields, "ttl_seconds")
+	}
+	if a.AWSRoleARN != b.AWSRoleARN {
+		fields = append(fields, "aws_role_arn")
+	}
+	return fields
+}
@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"testing"
+)
+
+func TestDiffReportsChangesByRule(t *testing.T) {
+	oldPolicy, err := LoadPolicyFromBytes([]byte(`policy_id: p
+policy_version: "1"
+defaults:
+  ttl_seconds: 900
+rules:
+  - id: prod_terraform
+    match: {action: terraform.apply, env: prod}
+    effect:
+      require_approval: true
+      aws_role_arn: arn:aws:iam::1:role/prod
+  - id: prod_other
+    match: {env: prod}
+    effect: {deny: true}
+`))
+	if err != nil {
+		t.Fatalf("load old: %v", err)
+	}
+	newPolicy, err := LoadPolicyFromBytes([]byte(`policy_id: p
+policy_version: "2"
+defaults:
+  ttl_seconds: 900
+rules:
+  - id: prod_terraform
+    match: {action: terraform.apply, env: prod}
+    effect:
+      require_approval: true
+      ttl_seconds: 600
+      aws_role_arn: arn:aws:iam::1:role/prod
+  - id: prod_deploy
+    match: {action: deploy, env: prod}
+    effect: {require_approval: true}
+  - id: prod_other
+    match: {env: prod}
+    effect: {deny: true}
+`))
+	if err != nil {
+		t.Fatalf("load new: %v", err)
+	}
+
+	cases := []DiffCase{
+		{ID: "tf", Input: Input{Action: "terraform.apply", Env: "prod"}},
+		{ID: "deploy", Input: Input{Action: "deploy", Env: "prod"}},
+		{ID: "other", Input: Input{Action: "db.migrate", Env: "prod"}},
+		{ID: "dev", Input: Input{Action: "deploy", Env: "dev"}},
+		{ID: "deploy2", Input: Input{Action: "deploy", Env: "prod", Repo: "org/app"}},
+	}
+	report := Diff(oldPolicy, newPolicy, cases)
+	if report.Total != 5 || report.Changed != 3 || report.OldHash != oldPolicy.Hash || report.NewHash != newPolicy.Hash {
+		t.Fatalf("unexpected totals: %+v", report)
+	}
+	if len(report.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", report.Groups)
+	}
+
+	deny := report.Groups[0]
+	if deny.OldRule != "prod_other" || deny.NewRule != "prod_deploy" || len(deny.Changes) != 2 {
+		t.Fatalf("unexpected first group: %+v", deny)
+	}
+	change := deny.Changes[0]
+	if change.ID != "deploy" || change.Old.Verdict != "deny" || change.New.Verdict != "require_approval" {
+		t.Fatalf("unexpected change: %+v", change)
+	}
+	if len(change.Fields) != 2 || change.Fields[0] != "verdict" || change.Fields[1] != "require_approval" {
+		t.Fatalf("unexpected fields: %v", change.Fields)
+	}
+
+	ttl := report.Groups[1]
+	if ttl.OldRule != "prod_terraform" || ttl.NewRule != "prod_terraform" || len(ttl.Changes) != 1 {
+		t.Fatalf("unexpected second group: %+v", ttl)
+	}
+	if fields := ttl.Changes[0].Fields; len(fields) != 1 || fields[0] != "ttl_seconds" || ttl.Changes[0].New.TTLSeconds != 600 {
+		t.Fatalf("expected ttl change, got %+v", ttl.Changes[0])
+	}
+}
+
+func TestDiffIdenticalPolicies(t *testing.T) {
+	loaded, err := LoadPolicy("../../policies/relia.yaml")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	report := Diff(loaded, loaded, []DiffCase{{ID: "a", Input: Input{Action: "terraform.apply", Env: "prod"}}})
+	if report.Changed != 0 || len(report.Groups) != 0 || report.Total != 1 {
+		t.Fatalf("expected no changes, got %+v", report)
+	}
+}
@@ -1,9 +1,35 @@
 package policy
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Input is what a policy is evaluated against. The JSON form is used by policy
+// replay corpora.
 type Input struct {
-	Action   string
-	Resource string
-	Env      string
+	Action   string `json:"action" yaml:"action"`
+	Resource string `json:"resource" yaml:"resource"`
+	Env      string `json:"env" yaml:"env"`
+
+	// Actor identity from the authenticated caller (e.g. GitHub OIDC claims).
+	Issuer      string `json:"issuer,omitempty" yaml:"issuer"`
+	Subject     string `json:"subject,omitempty" yaml:"subject"`
+	Repo        string `json:"repo,omitempty" yaml:"repo"`
+	Workflow    string `json:"workflow,omitempty" yaml:"workflow"`
+	SHA         string `json:"sha,omitempty" yaml:"sha"`
+	Ref         string `json:"ref,omitempty" yaml:"ref"`
+	Environment string `json:"environment,omitempty" yaml:"environment"`
+	Actor       string `json:"actor,omitempty" yaml:"actor"`
+
+	// Request intent and evidence.
+	Intent     map[string]any `json:"intent,omitempty" yaml:"intent"`
+	PlanDigest string         `json:"plan_digest,omitempty" yaml:"plan_digest"`
+	DiffURL    string         `json:"diff_url,omitempty" yaml:"diff_url"`
+
+	// At is the evaluation time for `when` windows and freezes; zero means now.
+	At time.Time `json:"at,omitzero" yaml:"at"`
 }
 
 type Decision struct {
@@ -18,14 +44,52 @@ type Decision struct {
 	PolicyID        string
 	PolicyVersion   string
 	PolicyHash      string
+
+	// MatchedRuleIDs lists every rule that applied, in policy order; under first-match
+	// evaluation it holds at most MatchedRuleID.
+	MatchedRuleIDs []string
+
+	// Approvals is the quorum a require_approval verdict needs; nil means one approver.
+	Approvals *ApprovalQuorum
+
+	// ApprovalTimeout is how long a require_approval verdict may stay pending; zero
+	// means it never expires.
+	ApprovalTimeout time.Duration
+
+	// ApprovalTransport is where a require_approval verdict's request is posted; empty
+	// means the gateway default.
+	ApprovalTransport ApprovalTransport
+
+	// ApprovalChannel overrides the gateway's channel for the approval request; empty
+	// means the gateway default.
+	ApprovalChannel string
+
+	// ApprovalMentions are mentioned in the approval request.
+	ApprovalMentions []string
 }
 
-// Evaluate applies the first matching rule to input, otherwise defaults.
+// Evaluate applies the first matching rule to input (or, with evaluation: all_matching,
+// every matching rule), otherwise defaults, then any active freezes.
 func Evaluate(p Policy, policyHash string, input Input) Decision {
+	if input.At.IsZero() {
+		input.At = time.Now()
+	}
+	var decision Decision
+	if p.Evaluation == EvaluationAllMatching {
+		decision = evaluateAllMatching(p, policyHash, input)
+	} else {
+		decision = evaluateRules(p, policyHash, input)
+	}
+	applyFreezes(&decision, p.Freezes, input)
+	return decision
+}
+
+func evaluateRules(p Policy, policyHash string, input Input) Decision {
 	decision := Decision{
 		Verdict:         "allow",
 		RequireApproval: p.Defaults.RequireApproval,
 		TTLSeconds:      p.Defaults.TTLSeconds,
+		ApprovalTimeout: time.Duration(p.Defaults.ApprovalTimeout),
 		PolicyID:        p.PolicyID,
 		PolicyVersion:   p.PolicyVersion,
 		PolicyHash:      policyHash,
@@ -36,12 +100,29 @@ func Evaluate(p Policy, policyHash string, input Input) Decision {
 	}
 
 	for _, rule := range p.Rules {
-		if !matchRule(rule.Match, input) {
+		matched, patternCodes := matchRule(rule.Match, input)
+		if !matched || !rule.When.Active(input.At) {
+			continue
+		}
+		holds, err := rule.conditionHolds(input)
+		if err != nil {
+			conditionFailed(&decision, rule, err)
+			return decision
+		}
+		if !holds {
 			continue
 		}
 
 		decision.MatchedRuleID = rule.ID
+		decision.MatchedRuleIDs = []string{rule.ID}
 		decision.ReasonCodes = append(decision.ReasonCodes, "POLICY_MATCH:"+rule.ID)
+		decision.ReasonCodes = append(decision.ReasonCodes, patternCodes...)
+		if !rule.When.IsZero() {
+			decision.ReasonCodes = append(decision.ReasonCodes, "WHEN_MATCH:"+rule.ID)
+		}
+		if rule.Condition != "" {
+			decision.ReasonCodes = append(decision.ReasonCodes, "CONDITION_MATCH:"+rule.ID)
+		}
 
 		if rule.Effect.RequireApproval != nil {
 			decision.RequireApproval = *rule.Effect.RequireApproval
@@ -65,6 +146,29 @@ func Evaluate(p Policy, policyHash string, input Input) Decision {
 		if rule.Effect.Reason != "" {
 			decision.Reason = rule.Effect.Reason
 		}
+		if rule.Effect.Approvals != nil {
+			decision.Approvals = mergeQuorum(nil, rule.Effect.Approvals)
+		}
+		if rule.Effect.ApprovalTimeout != nil {
+			decision.ApprovalTimeout = time.Duration(*rule.Effect.ApprovalTimeout)
+		}
+		if rule.Effect.ApprovalTransport != "" {
+			decision.ApprovalTransport = rule.Effect.ApprovalTransport
+		}
+		if rule.Effect.ApprovalChannel != "" {
+			decision.ApprovalChannel = string(rule.Effect.ApprovalChannel)
+		}
+		decision.ApprovalMentions = rule.Effect.ApprovalMentions
+		if missing := missingEvidence(rule.Effect.RequireEvidence, input); len(missing) > 0 {
+			decision.Verdict = "deny"
+			for _, name := range missing {
+				decision.ReasonCodes = append(decision.ReasonCodes, "EVIDENCE_MISSING:"+name)
+			}
+			if decision.Reason == "" {
+				decision.Reason = "missing required evidence: " + strings.Join(missing, ", ")
+			}
+			return decision
+		}
 
 		if decision.Verdict != "deny" {
 			if decision.RequireApproval {
@@ -87,15 +191,99 @@ func Evaluate(p Policy, policyHash string, input Input) Decision {
 	return decision
 }
 
-func matchRule(match PolicyMatch, input Input) bool {
-	if match.Action != "" && match.Action != input.Action {
-		return false
+// conditionFailed denies the request when a rule condition cannot be evaluated (for
+// example, it reads an intent field the request lacks), so a broken condition never
+// lets a request through.
+func conditionFailed(decision *Decision, rule PolicyRule, err error) {
+	decision.Verdict = "deny"
+	decision.MatchedRuleID = rule.ID
+	decision.MatchedRuleIDs = append(decision.MatchedRuleIDs, rule.ID)
+	decision.ReasonCodes = append(decision.ReasonCodes, "CONDITION_ERROR:"+rule.ID)
+	decision.Reason = fmt.Sprintf("policy condition in rule %s failed: %v", rule.ID, err)
+}
+
+// applyFreezes forces deny or require_approval for every freeze active at input.At
+// whose match selects the request. A freeze never relaxes the verdict.
+func applyFreezes(decision *Decision, freezes []PolicyFreeze, input Input) {
+	for _, freeze := range freezes {
+		if !freeze.When.Active(input.At) {
+			continue
+		}
+		if matched, _ := matchRule(freeze.Match, input); !matched {
+			continue
+		}
+		decision.ReasonCodes = append(decision.ReasonCodes, "FREEZE:"+freeze.ID)
+		switch freeze.Effect {
+		case FreezeDeny:
+			decision.Verdict = "deny"
+		case FreezeRequireApproval:
+			if decision.Verdict == "allow" {
+				decision.Verdict = "require_approval"
+			}
+			if decision.Verdict == "require_approval" {
+				decision.RequireApproval = true
+			}
+		default:
+			continue
+		}
+		if freeze.Reason != "" {
+			decision.Reason = freeze.Reason
+		}
+	}
+}
+
+// matchRule reports whether input satisfies match, plus a reason code for every field
+// matched through a glob or list so the decision records which pattern applied.
+func matchRule(match PolicyMatch, input Input) (bool, []string) {
+	var codes []string
+	fields := []struct {
+		name     string
+		patterns Patterns
+		value    string
+	}{
+		{"action", match.Action, input.Action},
+		{"resource", match.Resource, input.Resource},
+		{"env", match.Env, input.Env},
+		{"issuer", match.Issuer, input.Issuer},
+		{"subject", match.Subject, input.Subject},
+		{"repo", match.Repo, input.Repo},
+		{"workflow", match.Workflow, input.Workflow},
+		{"sha", match.SHA, input.SHA},
+		{"ref", match.Ref, input.Ref},
+		{"environment", match.Environment, input.Environment},
+		{"actor", match.Actor, input.Actor},
+	}
+	for _, f := range fields {
+		pattern, ok := f.patterns.Match(f.value)
+		if !ok {
+			return false, nil
+		}
+		if len(f.patterns) > 0 && !f.patterns.exact() {
+			codes = append(codes, "PATTERN_MATCH:"+f.name+"="+pattern)
+		}
 	}
-	if match.Resource != "" && match.Resource != input.Resource {
-		return false
+	for _, pred := range match.Intent {
+		if !pred.Match(input.Intent) {
+			return false, nil
+		}
+		codes = append(codes, pred.ReasonCode())
 	}
-	if match.Env != "" && match.Env != input.Env {
-		return false
+	return true, codes
+}
+
+func missingEvidence(required EvidenceNames, input Input) []string {
+	var missing []string
+	for _, name := range required {
+		var value string
+		switch name {
+		case EvidencePlanDigest:
+			value = input.PlanDigest
+		case EvidenceDiffURL:
+			value = input.DiffURL
+		}
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, name)
+		}
 	}
-	return true
+	return missing
 }
@@ -39,8 +39,8 @@ func TestEvaluatePolicyRuleMatch(t *testing.T) {
 			{
 				ID: "rule-1",
 				Match: PolicyMatch{
-					Action: "terraform.apply",
-					Env:    "prod",
+					Action: Patterns{"terraform.apply"},
+					Env:    Patterns{"prod"},
 				},
 				Effect: PolicyEffect{
 					RequireApproval: &requireApproval,
@@ -51,7 +51,7 @@ func TestEvaluatePolicyRuleMatch(t *testing.T) {
 			{
 				ID: "rule-2",
 				Match: PolicyMatch{
-					Env: "prod",
+					Env: Patterns{"prod"},
 				},
 				Effect: PolicyEffect{
 					Deny: &deny,
@@ -105,7 +105,7 @@ func TestEvaluatePolicyNoMatchUsesDefaults(t *testing.T) {
 			{
 				ID: "resource-only",
 				Match: PolicyMatch{
-					Resource: "db",
+					Resource: Patterns{"db"},
 				},
 				Effect: PolicyEffect{
 					RequireApproval: boolPtr(false),
@@ -142,7 +142,7 @@ func TestEvaluatePolicyRuleDeny(t *testing.T) {
 			{
 				ID: "deny-prod",
 				Match: PolicyMatch{
-					Env: "prod",
+					Env: Patterns{"prod"},
 				},
 				Effect: PolicyEffect{
 					Deny:   &deny,
@@ -178,8 +178,8 @@ func TestEvaluatePolicyRuleClearsDefaultDeny(t *testing.T) {
 			{
 				ID: "override-deny",
 				Match: PolicyMatch{
-					Action: "deploy",
-					Env:    "prod",
+					Action: Patterns{"deploy"},
+					Env:    Patterns{"prod"},
 				},
 				Effect: PolicyEffect{
 					Deny:            &deny,
@@ -209,3 +209,58 @@ func TestEvaluatePolicyRuleClearsDefaultDeny(t *testing.T) {
 		t.Fatalf("unexpected reason codes: %v", decision.ReasonCodes)
 	}
 }
+
+func TestEvaluatePolicyActorConditions(t *testing.T) {
+	p := Policy{
+		PolicyID:      "relia-default",
+		PolicyVersion: "2025-12-20",
+		Defaults:      PolicyDefaults{Deny: true},
+		Rules: []PolicyRule{
+			{
+				ID: "infra-deploy",
+				Match: PolicyMatch{
+					Action:      Patterns{"terraform.apply"},
+					Env:         Patterns{"prod"},
+					Repo:        Patterns{"org/infra"},
+					Workflow:    Patterns{"org/infra/.github/workflows/deploy.yml@*"},
+					Ref:         Patterns{"refs/heads/main"},
+					Environment: Patterns{"production"},
+					Actor:       Patterns{"alice", "bob"},
+					Issuer:      Patterns{"https://token.actions.githubusercontent.com"},
+				},
+				Effect: PolicyEffect{Deny: boolPtr(false)},
+			},
+		},
+	}
+
+	input := Input{
+		Action:      "terraform.apply",
+		Env:         "prod",
+		Issuer:      "https://token.actions.githubusercontent.com",
+		Repo:        "org/infra",
+		Workflow:    "org/infra/.github/workflows/deploy.yml@refs/heads/main",
+		Ref:         "refs/heads/main",
+		Environment: "production",
+		Actor:       "bob",
+	}
+	decision := Evaluate(p, "sha256:policy", input)
+	if decision.Verdict != "allow" || decision.MatchedRuleID != "infra-deploy" {
+		t.Fatalf("expected allow via infra-deploy, got %+v", decision)
+	}
+
+	mutations := map[string]func(in *Input){
+		"repo":        func(in *Input) { in.Repo = "org/other" },
+		"workflow":    func(in *Input) { in.Workflow = "org/infra/.github/workflows/other.yml@refs/heads/main" },
+		"ref":         func(in *Input) { in.Ref = "refs/heads/feature" },
+		"environment": func(in *Input) { in.Environment = "" },
+		"actor":       func(in *Input) { in.Actor = "mallory" },
+		"issuer":      func(in *Input) { in.Issuer = "relia-dev" },
+	}
+	for name, mutate := range mutations {
+		in := input
+		mutate(&in)
+		if decision := Evaluate(p, "sha256:policy", in); decision.Verdict != "deny" {
+			t.Fatalf("%s: expected deny, got %s", name, decision.Verdict)
+		}
+	}
+}
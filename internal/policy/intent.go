@@ -0,0 +1,203 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IntentPredicate is a condition over a field of the request intent. Field is a
+// dotted path into the intent object (e.g. "plan.destroy_count").
+type IntentPredicate struct {
+	Field string `yaml:"field"`
+	Op    string `yaml:"op"`
+	Value any    `yaml:"value"`
+}
+
+// Evidence names accepted by require_evidence.
+const (
+	EvidencePlanDigest = "plan_digest"
+	EvidenceDiffURL    = "diff_url"
+)
+
+var intentOps = map[string]bool{
+	"eq": true, "ne": true,
+	"gt": true, "gte": true, "lt": true, "lte": true,
+	"in": true, "not_in": true,
+	"exists": true, "missing": true,
+}
+
+func (p *IntentPredicate) UnmarshalYAML(node *yaml.Node) error {
+	type raw IntentPredicate
+	var r raw
+	if err := node.Decode(&r); err != nil {
+		return err
+	}
+	pred := IntentPredicate(r)
+	if err := pred.Validate(); err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*p = pred
+	return nil
+}
+
+// Validate reports whether the predicate is well formed.
+func (p IntentPredicate) Validate() error {
+	if strings.TrimSpace(p.Field) == "" {
+		return fmt.Errorf("intent predicate missing field")
+	}
+	for _, part := range strings.Split(p.Field, ".") {
+		if part == "" {
+			return fmt.Errorf("intent field %q has an empty path segment", p.Field)
+		}
+	}
+	if !intentOps[p.Op] {
+		return fmt.Errorf("intent field %q: unknown op %q", p.Field, p.Op)
+	}
+	switch p.Op {
+	case "gt", "gte", "lt", "lte":
+		if _, ok := toFloat(p.Value); !ok {
+			return fmt.Errorf("intent field %q: op %s requires a numeric value", p.Field, p.Op)
+		}
+	case "in", "not_in":
+		if _, ok := p.Value.([]any); !ok {
+			return fmt.Errorf("intent field %q: op %s requires a list value", p.Field, p.Op)
+		}
+	case "eq", "ne":
+		if !isScalar(p.Value) {
+			return fmt.Errorf("intent field %q: op %s requires a scalar value", p.Field, p.Op)
+		}
+	case "exists", "missing":
+		if p.Value != nil {
+			return fmt.Errorf("intent field %q: op %s takes no value", p.Field, p.Op)
+		}
+	}
+	return nil
+}
+
+// Match evaluates the predicate against intent.
+func (p IntentPredicate) Match(intent map[string]any) bool {
+	value, present := lookupIntent(intent, p.Field)
+	switch p.Op {
+	case "exists":
+		return present
+	case "missing":
+		return !present
+	}
+	if !present {
+		return false
+	}
+
+	switch p.Op {
+	case "eq":
+		return valuesEqual(value, p.Value)
+	case "ne":
+		return !valuesEqual(value, p.Value)
+	case "in", "not_in":
+		found := false
+		list, _ := p.Value.([]any)
+		for _, candidate := range list {
+			if valuesEqual(value, candidate) {
+				found = true
+				break
+			}
+		}
+		return found == (p.Op == "in")
+	case "gt", "gte", "lt", "lte":
+		got, ok := toFloat(value)
+		if !ok {
+			return false
+		}
+		want, _ := toFloat(p.Value)
+		switch p.Op {
+		case "gt":
+			return got > want
+		case "gte":
+			return got >= want
+		case "lt":
+			return got < want
+		default:
+			return got <= want
+		}
+	}
+	return false
+}
+
+// ReasonCode renders the predicate for Decision.ReasonCodes.
+func (p IntentPredicate) ReasonCode() string {
+	code := "INTENT_MATCH:" + p.Field + ":" + p.Op
+	if p.Value != nil {
+		value, err := json.Marshal(p.Value)
+		if err == nil {
+			code += ":" + string(value)
+		}
+	}
+	return code
+}
+
+func lookupIntent(intent map[string]any, field string) (any, bool) {
+	var cur any = intent
+	for _, part := range strings.Split(field, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		cur, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return cur, cur != nil
+}
+
+func valuesEqual(a, b any) bool {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	}
+	return false
+}
+
+func isScalar(v any) bool {
+	if _, ok := toFloat(v); ok {
+		return true
+	}
+	switch v.(type) {
+	case string, bool:
+		return true
+	}
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, !math.IsNaN(n)
+	case float32:
+		return float64(n), true
+	case json.Number:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
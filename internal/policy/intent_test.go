@@ -0,0 +1,149 @@
+package policy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const intentPolicyYAML = `policy_id: p
+policy_version: "1"
+defaults:
+  ttl_seconds: 900
+rules:
+  - id: no_schema_drops
+    match:
+      action: db.migrate
+      intent:
+        - field: change_type
+          op: in
+          value: [schema_drop, table_drop]
+    effect:
+      deny: true
+      reason: schema drops are blocked
+  - id: destructive_plan
+    match:
+      action: terraform.apply
+      intent:
+        - field: plan.destroy_count
+          op: gt
+          value: 0
+    effect:
+      require_approval: true
+      require_evidence: [plan_digest]
+  - id: plan_needs_evidence
+    match:
+      action: terraform.apply
+    effect:
+      require_evidence: [plan_digest, diff_url]
+`
+
+func TestEvaluateIntentPredicates(t *testing.T) {
+	loaded, err := LoadPolicyFromBytes([]byte(intentPolicyYAML))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	decision := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "db.migrate", Intent: map[string]any{"change_type": "schema_drop"}})
+	if decision.Verdict != "deny" || decision.MatchedRuleID != "no_schema_drops" {
+		t.Fatalf("expected deny via no_schema_drops, got %+v", decision)
+	}
+	if !containsCode(decision.ReasonCodes, `INTENT_MATCH:change_type:in:["schema_drop","table_drop"]`) {
+		t.Fatalf("expected intent reason code, got %v", decision.ReasonCodes)
+	}
+
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Action: "db.migrate", Intent: map[string]any{"change_type": "add_column"}})
+	if decision.Verdict != "allow" || decision.MatchedRuleID != "" {
+		t.Fatalf("expected defaults, got %+v", decision)
+	}
+
+	// JSON-decoded intents carry float64 numbers.
+	var intent map[string]any
+	if err := json.Unmarshal([]byte(`{"plan":{"destroy_count":2}}`), &intent); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.apply", Intent: intent, PlanDigest: "sha256:p"})
+	if decision.Verdict != "require_approval" || decision.MatchedRuleID != "destructive_plan" {
+		t.Fatalf("expected require_approval via destructive_plan, got %+v", decision)
+	}
+	if !containsCode(decision.ReasonCodes, "INTENT_MATCH:plan.destroy_count:gt:0") {
+		t.Fatalf("expected intent reason code, got %v", decision.ReasonCodes)
+	}
+
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.apply", Intent: intent})
+	if decision.Verdict != "deny" || !containsCode(decision.ReasonCodes, "EVIDENCE_MISSING:plan_digest") {
+		t.Fatalf("expected deny for missing evidence, got %+v", decision)
+	}
+	if decision.Reason != "missing required evidence: plan_digest" {
+		t.Fatalf("unexpected reason: %s", decision.Reason)
+	}
+
+	// A harmless plan falls through to the next rule.
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.apply", Intent: map[string]any{"plan": map[string]any{"destroy_count": 0}}, PlanDigest: "sha256:p", DiffURL: "https://example.com"})
+	if decision.Verdict != "allow" || decision.MatchedRuleID != "plan_needs_evidence" {
+		t.Fatalf("expected allow via plan_needs_evidence, got %+v", decision)
+	}
+}
+
+func TestIntentPredicateOps(t *testing.T) {
+	intent := map[string]any{"n": 3, "s": "x", "b": true, "nested": map[string]any{"k": "v"}, "null": nil}
+	cases := []struct {
+		pred IntentPredicate
+		want bool
+	}{
+		{IntentPredicate{Field: "n", Op: "eq", Value: 3}, true},
+		{IntentPredicate{Field: "n", Op: "eq", Value: 3.0}, true},
+		{IntentPredicate{Field: "n", Op: "ne", Value: 4}, true},
+		{IntentPredicate{Field: "n", Op: "gte", Value: 3}, true},
+		{IntentPredicate{Field: "n", Op: "lt", Value: 3}, false},
+		{IntentPredicate{Field: "n", Op: "lte", Value: 3}, true},
+		{IntentPredicate{Field: "s", Op: "eq", Value: "x"}, true},
+		{IntentPredicate{Field: "s", Op: "gt", Value: 1}, false},
+		{IntentPredicate{Field: "b", Op: "eq", Value: true}, true},
+		{IntentPredicate{Field: "s", Op: "not_in", Value: []any{"y", "z"}}, true},
+		{IntentPredicate{Field: "nested.k", Op: "in", Value: []any{"v"}}, true},
+		{IntentPredicate{Field: "nested.k", Op: "exists"}, true},
+		{IntentPredicate{Field: "nested.missing", Op: "missing"}, true},
+		{IntentPredicate{Field: "null", Op: "missing"}, true},
+		{IntentPredicate{Field: "missing", Op: "ne", Value: "x"}, false},
+	}
+	for _, tc := range cases {
+		if err := tc.pred.Validate(); err != nil {
+			t.Fatalf("%+v: validate: %v", tc.pred, err)
+		}
+		if got := tc.pred.Match(intent); got != tc.want {
+			t.Fatalf("%+v: got %t want %t", tc.pred, got, tc.want)
+		}
+	}
+}
+
+func TestLoadPolicyRejectsInvalidIntentPredicates(t *testing.T) {
+	cases := map[string]string{
+		"unknown_op":      "op: like\n          value: x",
+		"non_numeric_gt":  "op: gt\n          value: lots",
+		"in_needs_list":   "op: in\n          value: x",
+		"exists_no_value": "op: exists\n          value: x",
+		"eq_needs_scalar": "op: eq\n          value: [x]",
+	}
+	for name, pred := range cases {
+		t.Run(name, func(t *testing.T) {
+			body := "rules:\n  - id: r\n    match:\n      intent:\n        - field: f\n          " + pred + "\n"
+			if _, err := LoadPolicyFromBytes([]byte(body)); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+
+	if _, err := LoadPolicyFromBytes([]byte("rules:\n  - id: r\n    effect:\n      require_evidence: [screenshot]\n")); err == nil || !strings.Contains(err.Error(), "unknown evidence") {
+		t.Fatalf("expected unknown evidence error, got %v", err)
+	}
+}
+
+func containsCode(codes []string, want string) bool {
+	for _, code := range codes {
+		if code == want {
+			return true
+		}
+	}
+	return false
+}
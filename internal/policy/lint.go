@@ -0,0 +1,281 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lint severities.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// STS AssumeRole session duration bounds.
+const (
+	MinTTLSeconds = 900
+	MaxTTLSeconds = 43200
+)
+
+// LintIssue is one finding from Lint. Position is zero when the source location is unknown.
+type LintIssue struct {
+	Position
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	RuleID   string `json:"rule_id,omitempty"`
+}
+
+var roleARNPattern = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::\d{12}:role/[\w+=,.@/-]{1,512}$`)
+
+// prodEnvs are the env values the approval_without_role check treats as production.
+var prodEnvs = []string{"prod", "production"}
+
+// Lint runs semantic checks over a loaded policy: duplicate rule IDs, rules that can
+// never match because an earlier rule covers them (first-match evaluation only), prod
+// approval rules without a role, approval settings (quorum, approvers, timeout) on
+// rules that never require approval, TTLs outside the STS range, and malformed role ARNs.
+// Issues are sorted by position.
+func Lint(p Policy) []LintIssue {
+	var issues []LintIssue
+
+	if ttl := p.Defaults.TTLSeconds; ttl != 0 && !ttlInRange(ttl) {
+		issues = append(issues, LintIssue{
+			Position: p.positions["defaults.ttl_seconds"],
+			Severity: LintError,
+			Code:     "ttl_out_of_range",
+			Message:  ttlMessage("defaults", ttl),
+		})
+	}
+
+	firstID := map[string]int{}
+	for i, rule := range p.Rules {
+		issue := func(field, severity, code, message string) {
+			issues = append(issues, LintIssue{
+				Position: rule.position(field),
+				Severity: severity,
+				Code:     code,
+				Message:  message,
+				RuleID:   rule.ID,
+			})
+		}
+
+		if rule.ID != "" {
+			if prev, ok := firstID[rule.ID]; ok {
+				issue("id", LintError, "duplicate_rule_id",
+					fmt.Sprintf("duplicate rule id %q (first defined at %s)", rule.ID, p.Rules[prev].position("id")))
+			} else {
+				firstID[rule.ID] = i
+			}
+		}
+
+		// Shadowing only matters when evaluation stops at the first match.
+		if p.Evaluation != EvaluationAllMatching {
+			for _, earlier := range p.Rules[:i] {
+				if ruleCovers(earlier, rule) {
+					issue("", LintWarning, "shadowed_rule",
+						fmt.Sprintf("rule %q can never match: earlier rule %q matches everything it does", rule.ID, earlier.ID))
+					break
+				}
+			}
+		}
+
+		if ttl := rule.Effect.TTLSeconds; ttl != nil && !ttlInRange(*ttl) {
+			issue("effect.ttl_seconds", LintError, "ttl_out_of_range", ttlMessage("rule "+strconv.Quote(rule.ID), *ttl))
+		}
+
+		if arn := rule.Effect.AWSRoleARN; arn != "" && !roleARNPattern.MatchString(arn) {
+			issue("effect.aws_role_arn", LintError, "invalid_arn",
+				fmt.Sprintf("rule %q: aws_role_arn %q is not an IAM role ARN", rule.ID, arn))
+		}
+
+		if requiresApproval(p, rule) && rule.Effect.AWSRoleARN == "" && matchesProd(rule.Match.Env) {
+			issue("effect", LintWarning, "approval_without_role",
+				fmt.Sprintf("rule %q requires approval in prod but sets no aws_role_arn", rule.ID))
+		}
+
+		// Under all_matching a rule's quorum and approvers also apply when another rule
+		// requires approval.
+		if p.Evaluation != EvaluationAllMatching && !requiresApproval(p, rule) {
+			if rule.Effect.Approvals != nil {
+				issue("effect.approvals", LintWarning, "unused_approvals",
+					fmt.Sprintf("rule %q sets approvals but does not require approval", rule.ID))
+			}
+			if rule.Effect.Approvers != nil {
+				issue("effect.approvers", LintWarning, "unused_approvals",
+					fmt.Sprintf("rule %q sets approvers but does not require approval", rule.ID))
+			}
+			if rule.Effect.ApprovalTimeout != nil {
+				issue("effect.approval_timeout", LintWarning, "unused_approvals",
+					fmt.Sprintf("rule %q sets approval_timeout but does not require approval", rule.ID))
+			}
+			if rule.Effect.ApprovalTransport != "" {
+				issue("effect.approval_transport", LintWarning, "unused_approvals",
+					fmt.Sprintf("rule %q sets approval_transport but does not require approval", rule.ID))
+			}
+			if rule.Effect.ApprovalChannel != "" {
+				issue("effect.approval_channel", LintWarning, "unused_approvals",
+					fmt.Sprintf("rule %q sets approval_channel but does not require approval", rule.ID))
+			}
+			if len(rule.Effect.ApprovalMentions) > 0 {
+				issue("effect.approval_mentions", LintWarning, "unused_approvals",
+					fmt.Sprintf("rule %q sets approval_mentions but does not require approval", rule.ID))
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Position, issues[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return issues
+}
+
+// LoadIssue converts a load error into a lint issue, recovering the position from
+// the message when it carries one.
+func LoadIssue(err error) LintIssue {
+	issue := LintIssue{Severity: LintError, Code: "load", Message: err.Error()}
+	if m := positionPrefix.FindStringSubmatch(issue.Message); m != nil {
+		issue.File = m[1]
+		issue.Line, _ = strconv.Atoi(m[2])
+		issue.Column, _ = strconv.Atoi(m[3])
+		issue.Message = issue.Message[len(m[0]):]
+	} else if m := linePattern.FindStringSubmatch(issue.Message); m != nil {
+		issue.Line, _ = strconv.Atoi(m[1])
+	}
+	return issue
+}
+
+var (
+	positionPrefix = regexp.MustCompile(`^(?:([^\s:]+):)?(\d+):(\d+): `)
+	linePattern    = regexp.MustCompile(`\bline (\d+)\b`)
+)
+
+// position returns the source position of field within the rule, falling back to
+// the rule itself.
+func (r PolicyRule) position(field string) Position {
+	if pos, ok := r.positions[field]; ok {
+		return pos
+	}
+	return r.positions[""]
+}
+
+func ttlInRange(ttl int) bool {
+	return ttl >= MinTTLSeconds && ttl <= MaxTTLSeconds
+}
+
+func ttlMessage(owner string, ttl int) string {
+	return fmt.Sprintf("%s: ttl_seconds %d is outside the STS range %d-%d", owner, ttl, MinTTLSeconds, MaxTTLSeconds)
+}
+
+func requiresApproval(p Policy, rule PolicyRule) bool {
+	if rule.Effect.Deny != nil && *rule.Effect.Deny {
+		return false
+	}
+	if rule.Effect.RequireApproval != nil {
+		return *rule.Effect.RequireApproval
+	}
+	return p.Defaults.RequireApproval
+}
+
+func matchesProd(env Patterns) bool {
+	if len(env) == 0 {
+		return false
+	}
+	for _, value := range prodEnvs {
+		if _, ok := env.Match(value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleCovers reports whether every request matching later also matches earlier, so
+// first-match evaluation never reaches later.
+func ruleCovers(earlier, later PolicyRule) bool {
+	if earlier.Condition != "" && earlier.Condition != later.Condition {
+		return false
+	}
+	if !earlier.When.IsZero() && !reflect.DeepEqual(earlier.When, later.When) {
+		return false
+	}
+	for _, pred := range earlier.Match.Intent {
+		if !containsPredicate(later.Match.Intent, pred) {
+			return false
+		}
+	}
+	pairs := [][2]Patterns{
+		{earlier.Match.Action, later.Match.Action},
+		{earlier.Match.Resource, later.Match.Resource},
+		{earlier.Match.Env, later.Match.Env},
+		{earlier.Match.Issuer, later.Match.Issuer},
+		{earlier.Match.Subject, later.Match.Subject},
+		{earlier.Match.Repo, later.Match.Repo},
+		{earlier.Match.Workflow, later.Match.Workflow},
+		{earlier.Match.SHA, later.Match.SHA},
+		{earlier.Match.Ref, later.Match.Ref},
+		{earlier.Match.Environment, later.Match.Environment},
+		{earlier.Match.Actor, later.Match.Actor},
+	}
+	for _, pair := range pairs {
+		if !patternsCover(pair[0], pair[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPredicate(preds []IntentPredicate, want IntentPredicate) bool {
+	for _, pred := range preds {
+		if reflect.DeepEqual(pred, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// patternsCover reports whether every value matched by later is matched by earlier.
+// It is conservative: a glob in later is only covered by an identical pattern or a
+// literal-prefix wildcard such as "deploy.*".
+func patternsCover(earlier, later Patterns) bool {
+	if len(earlier) == 0 {
+		return true
+	}
+	if len(later) == 0 {
+		return false
+	}
+	for _, l := range later {
+		covered := false
+		for _, e := range earlier {
+			if patternCovers(e, l) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func patternCovers(earlier, later string) bool {
+	if earlier == later {
+		return true
+	}
+	if !IsGlob(later) {
+		return globMatch(earlier, later)
+	}
+	prefix, ok := strings.CutSuffix(earlier, "*")
+	return ok && !IsGlob(prefix) && strings.HasPrefix(later, prefix)
+}
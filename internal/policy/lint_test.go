@@ -0,0 +1,148 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func lintCodes(issues []LintIssue) string {
+	var codes []string
+	for _, issue := range issues {
+		codes = append(codes, fmt.Sprintf("%s@%d:%d", issue.Code, issue.Line, issue.Column))
+	}
+	return strings.Join(codes, ",")
+}
+
+func TestLint(t *testing.T) {
+	data := `policy_id: lint
+defaults:
+  ttl_seconds: 60
+rules:
+  - id: prod_catch_all
+    match: {env: prod}
+    effect: {deny: true}
+  - id: prod_apply
+    match: {action: terraform.apply, env: prod}
+    effect:
+      require_approval: true
+      ttl_seconds: 50000
+  - id: prod_apply
+    match: {env: dev}
+    effect:
+      aws_role_arn: "arn:aws:iam::123:role/x"
+`
+	loaded, err := LoadPolicyFromBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	issues := Lint(loaded.Policy)
+	want := "ttl_out_of_range@3:3,shadowed_rule@8:5,approval_without_role@10:5,ttl_out_of_range@12:7,duplicate_rule_id@13:5,invalid_arn@16:7"
+	if got := lintCodes(issues); got != want {
+		t.Fatalf("unexpected issues:\n got %s\nwant %s", got, want)
+	}
+	if issues[1].Severity != LintWarning || issues[1].RuleID != "prod_apply" || !strings.Contains(issues[1].Message, `"prod_catch_all"`) {
+		t.Fatalf("unexpected shadow issue: %+v", issues[1])
+	}
+	if issues[4].Severity != LintError || !strings.Contains(issues[4].Message, "first defined at 8:5") {
+		t.Fatalf("unexpected duplicate issue: %+v", issues[4])
+	}
+}
+
+func TestLintSamplePolicies(t *testing.T) {
+	for _, path := range []string{
+		"../../policies/relia.yaml",
+		"../../policies/templates/db-migration-prod.yaml",
+		"../../policies/templates/deploy-prod-main.yaml",
+		"../../policies/templates/terraform-prod-apply.yaml",
+	} {
+		loaded, err := LoadPolicy(path)
+		if err != nil {
+			t.Fatalf("load %s: %v", path, err)
+		}
+		if issues := Lint(loaded.Policy); len(issues) != 0 {
+			t.Fatalf("%s: unexpected issues %+v", path, issues)
+		}
+	}
+}
+
+func TestLintBundlePositions(t *testing.T) {
+	dir := writePolicyFiles(t, map[string]string{
+		"a.yaml": "policy_id: p\nrules:\n  - id: all\n    match: {action: \"deploy.*\"}\n",
+		"b.yaml": "rules:\n  - id: web\n    match: {action: deploy.web}\n",
+	})
+	loaded, err := LoadPolicy(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	issues := Lint(loaded.Policy)
+	if len(issues) != 1 || issues[0].File != "b.yaml" || issues[0].Line != 2 || issues[0].Code != "shadowed_rule" {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+}
+
+func TestRuleCovers(t *testing.T) {
+	cases := []struct {
+		name           string
+		earlier, later string
+		want           bool
+	}{
+		{"empty match covers all", "{}", "{action: a, env: prod}", true},
+		{"narrower does not cover", "{action: a, env: prod}", "{env: prod}", false},
+		{"prefix glob covers literal", "{action: \"deploy.*\"}", "{action: deploy.web}", true},
+		{"prefix glob covers glob", "{action: \"deploy.*\"}", "{action: \"deploy.web.*\"}", true},
+		{"literal does not cover glob", "{action: deploy.web}", "{action: \"deploy.*\"}", false},
+		{"list covers subset", "{env: [prod, stage]}", "{env: prod}", true},
+		{"list not covered", "{env: prod}", "{env: [prod, stage]}", false},
+		{"intent subset", "{intent: [{field: a, op: exists}]}", "{intent: [{field: a, op: exists}, {field: b, op: missing}]}", true},
+		{"intent not subset", "{intent: [{field: b, op: missing}]}", "{intent: [{field: a, op: exists}]}", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := fmt.Sprintf("policy_id: p\nrules:\n  - id: first\n    match: %s\n  - id: second\n    match: %s\n", tc.earlier, tc.later)
+			loaded, err := LoadPolicyFromBytes([]byte(data))
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if got := ruleCovers(loaded.Policy.Rules[0], loaded.Policy.Rules[1]); got != tc.want {
+				t.Fatalf("ruleCovers = %t, want %t", got, tc.want)
+			}
+		})
+	}
+
+	windowed := "policy_id: p\nrules:\n  - id: first\n    when: {days: [sat]}\n  - id: second\n"
+	loaded, err := LoadPolicyFromBytes([]byte(windowed))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if ruleCovers(loaded.Policy.Rules[0], loaded.Policy.Rules[1]) {
+		t.Fatalf("a time-windowed rule should not shadow an unconditional one")
+	}
+}
+
+func TestLintRoleARN(t *testing.T) {
+	for arn, want := range map[string]bool{
+		"arn:aws:iam::123456789012:role/relia-prod":       true,
+		"arn:aws-us-gov:iam::123456789012:role/path/to/r": true,
+		"arn:aws:iam::123456789012:user/relia":            false,
+		"arn:aws:iam::12345:role/relia":                   false,
+		"arn:aws:s3:::bucket":                             false,
+		"relia-prod":                                      false,
+	} {
+		if got := roleARNPattern.MatchString(arn); got != want {
+			t.Fatalf("%s: got %t want %t", arn, got, want)
+		}
+	}
+}
+
+func TestLoadIssue(t *testing.T) {
+	issue := LoadIssue(errors.New(`b.yaml:3:14: unknown field "aws_role" in rules[0].effect`))
+	if issue.File != "b.yaml" || issue.Line != 3 || issue.Column != 14 || issue.Message != `unknown field "aws_role" in rules[0].effect` {
+		t.Fatalf("unexpected issue: %+v", issue)
+	}
+	issue = LoadIssue(errors.New(`line 5: invalid pattern "prod-[": unterminated character class`))
+	if issue.Line != 5 || issue.File != "" || issue.Code != "load" || issue.Severity != LintError {
+		t.Fatalf("unexpected issue: %+v", issue)
+	}
+}
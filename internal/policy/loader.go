@@ -1,22 +1,40 @@
 package policy
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/davidahmann/relia/internal/crypto"
-	"gopkg.in/yaml.v3"
 )
 
 type LoadedPolicy struct {
 	Policy Policy
 	Hash   string
 	Bytes  []byte
+
+	// Files lists the bundle's source files in merge order; nil for a single file.
+	Files []string
 }
 
-// LoadPolicyFromBytes parses policy YAML bytes and computes its hash from raw bytes.
+// LoadPolicyFromBytes parses policy YAML bytes (a single policy file or a serialized
+// bundle) and computes its hash from raw bytes.
 func LoadPolicyFromBytes(data []byte) (LoadedPolicy, error) {
-	var p Policy
-	if err := yaml.Unmarshal(data, &p); err != nil {
+	if isBundle(data) {
+		files, err := parseBundle(data)
+		if err != nil {
+			return LoadedPolicy{}, err
+		}
+		return loadBundle(files, data)
+	}
+
+	p, err := decodePolicy(data, "")
+	if err != nil {
+		return LoadedPolicy{}, err
+	}
+	if len(p.Includes) > 0 {
+		return LoadedPolicy{}, fmt.Errorf("policy includes can only be resolved with LoadPolicy")
+	}
+	if err := p.validateSeparationOfDuties(); err != nil {
 		return LoadedPolicy{}, err
 	}
 	return LoadedPolicy{
@@ -26,13 +44,43 @@ func LoadPolicyFromBytes(data []byte) (LoadedPolicy, error) {
 	}, nil
 }
 
-// LoadPolicy loads a YAML policy and computes its hash from raw bytes.
+// LoadPolicy loads a YAML policy and computes its hash from raw bytes. When path is a
+// directory or the policy has includes, the files are merged into a bundle and the
+// hash covers the serialized bundle.
 func LoadPolicy(path string) (LoadedPolicy, error) {
-	// #nosec G304 -- path comes from operator-configured policy path.
-	data, err := os.ReadFile(path)
+	files, err := collectBundle(path)
 	if err != nil {
 		return LoadedPolicy{}, err
 	}
 
-	return LoadPolicyFromBytes(data)
+	// A lone file keeps its raw bytes, so existing policy hashes do not change.
+	if info, err := os.Stat(path); err == nil && !info.IsDir() && len(files) == 1 {
+		return LoadPolicyFromBytes([]byte(files[0].Content))
+	}
+
+	data, err := marshalBundle(files)
+	if err != nil {
+		return LoadedPolicy{}, err
+	}
+	return loadBundle(files, data)
+}
+
+func loadBundle(files []BundleFile, data []byte) (LoadedPolicy, error) {
+	p, err := mergeBundle(files)
+	if err != nil {
+		return LoadedPolicy{}, err
+	}
+	if err := p.validateSeparationOfDuties(); err != nil {
+		return LoadedPolicy{}, err
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	return LoadedPolicy{
+		Policy: p,
+		Hash:   crypto.DigestWithPrefix(data),
+		Bytes:  data,
+		Files:  paths,
+	}, nil
 }
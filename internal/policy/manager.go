@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Manager holds the active policy for a path. It loads and validates once, then swaps
+// in a new policy only when a reload succeeds, so a broken edit keeps the last good
+// policy serving.
+type Manager struct {
+	path    string
+	current atomic.Pointer[LoadedPolicy]
+
+	mu      sync.Mutex // serializes reloads
+	lastErr error
+}
+
+// ReloadResult reports the outcome of a reload.
+type ReloadResult struct {
+	PolicyHash string `json:"policy_hash"`
+	Changed    bool   `json:"changed"`
+}
+
+// NewManager loads the policy at path and fails if it is invalid.
+func NewManager(path string) (*Manager, error) {
+	if path == "" {
+		return nil, fmt.Errorf("missing policy path")
+	}
+	loaded, err := LoadPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manager{path: path}
+	m.current.Store(&loaded)
+	return m, nil
+}
+
+// Path returns the policy path the manager loads from.
+func (m *Manager) Path() string {
+	return m.path
+}
+
+// Current returns the active policy.
+func (m *Manager) Current() LoadedPolicy {
+	return *m.current.Load()
+}
+
+// Hash returns the active policy hash.
+func (m *Manager) Hash() string {
+	return m.current.Load().Hash
+}
+
+// LastError returns the error from the most recent failed reload, or nil once a
+// reload succeeds.
+func (m *Manager) LastError() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastErr
+}
+
+// Reload re-reads the policy. On error the active policy is left in place.
+func (m *Manager) Reload() (ReloadResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	loaded, err := LoadPolicy(m.path)
+	if err != nil {
+		m.lastErr = err
+		return ReloadResult{PolicyHash: m.current.Load().Hash}, err
+	}
+	m.lastErr = nil
+
+	prev := m.current.Load()
+	if prev.Hash == loaded.Hash {
+		return ReloadResult{PolicyHash: prev.Hash}, nil
+	}
+	m.current.Store(&loaded)
+	return ReloadResult{PolicyHash: loaded.Hash, Changed: true}, nil
+}
+
+// Watch polls the policy every interval until ctx is done. Failed reloads are logged
+// once per distinct error.
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastLogged := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		result, err := m.Reload()
+		if err != nil {
+			if err.Error() != lastLogged {
+				log.Printf("policy reload failed, keeping %s: %v", result.PolicyHash, err)
+				lastLogged = err.Error()
+			}
+			continue
+		}
+		lastLogged = ""
+		if result.Changed {
+			log.Printf("policy reloaded: %s", result.PolicyHash)
+		}
+	}
+}
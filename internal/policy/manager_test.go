@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const managerPolicyV1 = "policy_id: p\npolicy_version: \"1\"\nrules:\n  - id: prod\n    match: {env: prod}\n    effect: {deny: true}\n"
+const managerPolicyV2 = "policy_id: p\npolicy_version: \"2\"\nrules:\n  - id: prod\n    match: {env: prod}\n    effect: {require_approval: true}\n"
+
+func TestManagerReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(managerPolicyV1), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	m, err := NewManager(path)
+	if err != nil {
+		t.Fatalf("manager: %v", err)
+	}
+	first := m.Hash()
+
+	result, err := m.Reload()
+	if err != nil || result.Changed || result.PolicyHash != first {
+		t.Fatalf("expected unchanged reload, got %+v err=%v", result, err)
+	}
+
+	// A broken edit is rejected and the last good policy keeps serving.
+	if err := os.WriteFile(path, []byte("rules:\n  - id: r\n    match: {env: \"[\"}\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	result, err = m.Reload()
+	if err == nil || result.Changed || m.Hash() != first || m.LastError() == nil {
+		t.Fatalf("expected rejected reload, got %+v err=%v", result, err)
+	}
+	if d := Evaluate(m.Current().Policy, m.Hash(), Input{Env: "prod"}); d.Verdict != "deny" {
+		t.Fatalf("expected last good policy, got %+v", d)
+	}
+
+	if err := os.WriteFile(path, []byte(managerPolicyV2), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	result, err = m.Reload()
+	if err != nil || !result.Changed || result.PolicyHash == first || m.Hash() != result.PolicyHash || m.LastError() != nil {
+		t.Fatalf("expected swapped policy, got %+v err=%v", result, err)
+	}
+	if d := Evaluate(m.Current().Policy, m.Hash(), Input{Env: "prod"}); d.Verdict != "require_approval" {
+		t.Fatalf("expected new policy, got %+v", d)
+	}
+}
+
+func TestManagerRejectsInvalidStartup(t *testing.T) {
+	if _, err := NewManager(""); err == nil {
+		t.Fatalf("expected error for empty path")
+	}
+	if _, err := NewManager(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatalf("expected error for missing policy")
+	}
+}
+
+func TestManagerWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(managerPolicyV1), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	m, err := NewManager(path)
+	if err != nil {
+		t.Fatalf("manager: %v", err)
+	}
+	first := m.Hash()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.Watch(ctx, 5*time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	if err := os.WriteFile(path, []byte(managerPolicyV2), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for m.Hash() == first {
+		if time.Now().After(deadline) {
+			t.Fatalf("watch did not pick up the new policy")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
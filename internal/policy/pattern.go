@@ -0,0 +1,200 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Patterns is a match value: a single pattern or a list of alternatives. Each pattern
+// is an exact string or a glob where `*` matches any run of characters (including
+// `/` and `:`), `?` matches one character, `[abc]`/`[a-z]`/`[!abc]` match a class,
+// and `\` escapes the next character.
+type Patterns []string
+
+func (p *Patterns) UnmarshalYAML(node *yaml.Node) error {
+	var values []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		// A null or empty scalar (`env: ""`) matches anything, as before globs.
+		if node.Tag == "!!null" || node.Value == "" {
+			*p = nil
+			return nil
+		}
+		values = []string{node.Value}
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			return fmt.Errorf("line %d: empty pattern list", node.Line)
+		}
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: pattern list entries must be strings", item.Line)
+			}
+			values = append(values, item.Value)
+		}
+	default:
+		return fmt.Errorf("line %d: expected a pattern or list of patterns", node.Line)
+	}
+
+	for _, v := range values {
+		if err := ValidatePattern(v); err != nil {
+			return fmt.Errorf("line %d: invalid pattern %q: %w", node.Line, v, err)
+		}
+	}
+	*p = values
+	return nil
+}
+
+// Match returns the first pattern matching s. An empty Patterns matches anything.
+func (p Patterns) Match(s string) (string, bool) {
+	if len(p) == 0 {
+		return "", true
+	}
+	for _, pattern := range p {
+		if globMatch(pattern, s) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// exact reports whether p is a single literal value, i.e. the pre-glob match form.
+func (p Patterns) exact() bool {
+	return len(p) == 1 && !IsGlob(p[0])
+}
+
+// IsGlob reports whether pattern contains glob metacharacters.
+func IsGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// ValidatePattern reports whether pattern is a well-formed glob.
+func ValidatePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if i+1 >= len(pattern) {
+				return fmt.Errorf("trailing escape")
+			}
+			i++
+		case '[':
+			end, err := classEnd(pattern, i)
+			if err != nil {
+				return err
+			}
+			i = end
+		case ']':
+			return fmt.Errorf("unmatched ']'")
+		}
+	}
+	return nil
+}
+
+// classEnd returns the index of the ']' closing the class that opens at start.
+func classEnd(pattern string, start int) (int, error) {
+	i := start + 1
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		i++
+	}
+	first := i
+	for ; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if i+1 >= len(pattern) {
+				return 0, fmt.Errorf("trailing escape")
+			}
+			i++
+		case ']':
+			if i == first {
+				return 0, fmt.Errorf("empty character class")
+			}
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated character class")
+}
+
+func globMatch(pattern, s string) bool {
+	if !IsGlob(pattern) {
+		return pattern == s
+	}
+
+	// Iterative matcher with single-star backtracking.
+	p, i := 0, 0
+	starP, starI := -1, 0
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starP, starI = p, i
+				p++
+				continue
+			case '?':
+				p++
+				i++
+				continue
+			case '[':
+				end, err := classEnd(pattern, p)
+				if err == nil && classMatch(pattern[p+1:end], s[i]) {
+					p = end + 1
+					i++
+					continue
+				}
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == s[i] {
+					p += 2
+					i++
+					continue
+				}
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+		if starP < 0 {
+			return false
+		}
+		starI++
+		p, i = starP+1, starI
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+func classMatch(class string, c byte) bool {
+	negate := false
+	if len(class) > 0 && (class[0] == '!' || class[0] == '^') {
+		negate = true
+		class = class[1:]
+	}
+	matched := false
+	for i := 0; i < len(class); i++ {
+		lo := class[i]
+		if lo == '\\' && i+1 < len(class) {
+			i++
+			lo = class[i]
+		}
+		hi := lo
+		if i+2 < len(class) && class[i+1] == '-' {
+			hi = class[i+2]
+			if hi == '\\' && i+3 < len(class) {
+				hi = class[i+3]
+				i++
+			}
+			i += 2
+		}
+		if lo <= c && c <= hi {
+			matched = true
+		}
+	}
+	return matched != negate
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"terraform.apply", "terraform.apply", true},
+		{"terraform.apply", "terraform.plan", false},
+		{"terraform.*", "terraform.apply", true},
+		{"terraform.*", "terraform.", true},
+		{"terraform.*", "terraform", false},
+		{"arn:aws:s3:::prod-*", "arn:aws:s3:::prod-logs/2025/01", true},
+		{"arn:aws:s3:::prod-*", "arn:aws:s3:::dev-logs", false},
+		{"*-eu", "prod-eu", true},
+		{"prod-??", "prod-eu", true},
+		{"prod-??", "prod-eu1", false},
+		{"prod-[0-9]", "prod-3", true},
+		{"prod-[!0-9]", "prod-3", false},
+		{"prod-[!0-9]", "prod-x", true},
+		{`literal\*`, "literal*", true},
+		{`literal\*`, "literalx", false},
+		{"*a*b*", "xaybz", true},
+		{"*a*b", "xaybz", false},
+	}
+	for _, tc := range cases {
+		if got := globMatch(tc.pattern, tc.value); got != tc.want {
+			t.Fatalf("globMatch(%q, %q) = %t, want %t", tc.pattern, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	for _, ok := range []string{"prod", "terraform.*", "prod-[a-z]", `a\*`} {
+		if err := ValidatePattern(ok); err != nil {
+			t.Fatalf("expected %q valid: %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"", "prod-[", "prod-[]", `trailing\`, "a]"} {
+		if err := ValidatePattern(bad); err == nil {
+			t.Fatalf("expected %q invalid", bad)
+		}
+	}
+}
+
+func TestLoadPolicyPatterns(t *testing.T) {
+	loaded, err := LoadPolicyFromBytes([]byte(`policy_id: p
+policy_version: "1"
+rules:
+  - id: tf_prod
+    match:
+      action: "terraform.*"
+      env: [prod, prod-eu]
+    effect:
+      require_approval: true
+  - id: exact
+    match:
+      action: deploy
+      env: prod
+    effect:
+      deny: true
+`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	decision := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.apply", Env: "prod-eu"})
+	if decision.MatchedRuleID != "tf_prod" || decision.Verdict != "require_approval" {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+	want := []string{"POLICY_MATCH:tf_prod", "PATTERN_MATCH:action=terraform.*", "PATTERN_MATCH:env=prod-eu"}
+	if strings.Join(decision.ReasonCodes, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected reason codes: %v", decision.ReasonCodes)
+	}
+
+	// Exact matches keep the original reason codes.
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Action: "deploy", Env: "prod"})
+	if decision.MatchedRuleID != "exact" || len(decision.ReasonCodes) != 1 {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Action: "terraform.apply", Env: "dev"})
+	if decision.MatchedRuleID != "" {
+		t.Fatalf("expected no match, got %s", decision.MatchedRuleID)
+	}
+}
+
+func TestLoadPolicyEmptyPatternMatchesAny(t *testing.T) {
+	loaded, err := LoadPolicyFromBytes([]byte(`policy_id: p
+rules:
+  - id: any_env
+    match:
+      action: deploy
+      env: ""
+    effect:
+      require_approval: true
+`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if env := loaded.Policy.Rules[0].Match.Env; env != nil {
+		t.Fatalf("expected an empty env to match anything, got %q", env)
+	}
+	decision := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "deploy", Env: "staging"})
+	if decision.MatchedRuleID != "any_env" || decision.Verdict != "require_approval" {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+}
+
+func TestLoadPolicyRejectsInvalidPatterns(t *testing.T) {
+	cases := map[string]string{
+		"unterminated_class": "rules:\n  - id: r\n    match:\n      env: \"prod-[\"\n",
+		"empty_list":         "rules:\n  - id: r\n    match:\n      env: []\n",
+		"nested_list":        "rules:\n  - id: r\n    match:\n      env: [[prod]]\n",
+		"map_value":          "rules:\n  - id: r\n    match:\n      env: {a: b}\n",
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadPolicyFromBytes([]byte(body)); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position locates a node in a policy file. File is empty for a single policy file and
+// the bundle-relative path for a bundle member.
+type Position struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+func (p Position) String() string {
+	loc := fmt.Sprintf("%d:%d", p.Line, p.Column)
+	if p.File != "" {
+		return p.File + ":" + loc
+	}
+	return loc
+}
+
+// decodePolicy strictly decodes one policy file: unknown fields anywhere in the
+// document are errors. Node positions are recorded for lint.
+func decodePolicy(data []byte, file string) (Policy, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return Policy{}, withFile(file, err)
+	}
+	if len(root.Content) == 0 {
+		return Policy{}, nil
+	}
+	doc := root.Content[0]
+	if err := checkKnownFields(doc, reflect.TypeOf(Policy{}), "", file); err != nil {
+		return Policy{}, err
+	}
+
+	var p Policy
+	if err := doc.Decode(&p); err != nil {
+		return Policy{}, withFile(file, err)
+	}
+
+	positions := map[string]Position{}
+	recordPositions(doc, "", file, positions)
+	p.positions = positions
+	if err := p.validateEvaluation(); err != nil {
+		pos, ok := positions["evaluation"]
+		if !ok {
+			pos = positions["combining"]
+		}
+		return Policy{}, fmt.Errorf("%s: %w", pos, err)
+	}
+	for i := range p.Rules {
+		prefix := "rules[" + strconv.Itoa(i) + "]"
+		rulePos := map[string]Position{}
+		for path, pos := range positions {
+			if path == prefix {
+				rulePos[""] = pos
+			} else if rest, ok := strings.CutPrefix(path, prefix+"."); ok {
+				rulePos[rest] = pos
+			}
+		}
+		p.Rules[i].positions = rulePos
+	}
+	for i := range p.Tests {
+		p.Tests[i].pos = positions["tests["+strconv.Itoa(i)+"]"]
+	}
+	if err := p.compileConditions(); err != nil {
+		return Policy{}, err
+	}
+	return p, nil
+}
+
+// checkKnownFields reports the first mapping key with no matching yaml field in t.
+func checkKnownFields(node *yaml.Node, t reflect.Type, path, file string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				where := "policy"
+				if path != "" {
+					where = path
+				}
+				pos := Position{File: file, Line: key.Line, Column: key.Column}
+				return fmt.Errorf("%s: unknown field %q in %s", pos, key.Value, where)
+			}
+			if err := checkKnownFields(value, field.Type, joinPath(path, key.Value), file); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, item := range node.Content {
+			if err := checkKnownFields(item, t.Elem(), path+"["+strconv.Itoa(i)+"]", file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+func recordPositions(node *yaml.Node, path, file string, out map[string]Position) {
+	out[path] = Position{File: file, Line: node.Line, Column: node.Column}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			child := joinPath(path, key.Value)
+			recordPositions(node.Content[i+1], child, file, out)
+			// Point at the key rather than its value.
+			out[child] = Position{File: file, Line: key.Line, Column: key.Column}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			recordPositions(item, path+"["+strconv.Itoa(i)+"]", file, out)
+		}
+	}
+}
+
+func withFile(file string, err error) error {
+	if file == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", file, err)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadPolicyRejectsUnknownFields(t *testing.T) {
+	cases := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"top level", "policy_id: p\nrule: []\n", `2:1: unknown field "rule" in policy`},
+		{"effect", "policy_id: p\nrules:\n  - id: r\n    effect:\n      require_aproval: true\n", `5:7: unknown field "require_aproval" in rules[0].effect`},
+		{"match", "policy_id: p\nrules:\n  - id: r\n    match: {action: a, enviroment: prod}\n", `unknown field "enviroment" in rules[0].match`},
+		{"intent", "policy_id: p\nrules:\n  - id: r\n    match:\n      intent:\n        - {field: a, op: exists, val: 1}\n", `unknown field "val" in rules[0].match.intent[0]`},
+		{"when", "policy_id: p\nrules:\n  - id: r\n    when: {tz: UTC, days: [mon]}\n", `unknown field "tz" in rules[0].when`},
+		{"freeze", "policy_id: p\nfreezes:\n  - id: f\n    effect: deny\n    when: {days: [sat]}\n    reasons: x\n", `unknown field "reasons" in freezes[0]`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadPolicyFromBytes([]byte(tc.yaml))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyAllowsFreeFormIntentValues(t *testing.T) {
+	data := "policy_id: p\nrules:\n  - id: r\n    match:\n      intent:\n        - {field: plan, op: in, value: [{any: thing}]}\n"
+	if _, err := LoadPolicyFromBytes([]byte(data)); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+}
+
+func TestLoadPolicyBundleUnknownFieldNamesFile(t *testing.T) {
+	dir := writePolicyFiles(t, map[string]string{
+		"a.yaml": "policy_id: p\n",
+		"b.yaml": "rules:\n  - id: r\n    effect: {aws_role: x}\n",
+	})
+	_, err := LoadPolicy(dir)
+	if err == nil || !strings.Contains(err.Error(), `b.yaml:3:14: unknown field "aws_role"`) {
+		t.Fatalf("expected positioned bundle error, got %v", err)
+	}
+
+	bundle := "relia_bundle: " + BundleSchema + "\nfiles:\n  - path: a.yaml\n    content: \"policy_id: p\\n\"\n    mode: 644\n"
+	if _, err := LoadPolicyFromBytes([]byte(bundle)); err == nil || !strings.Contains(err.Error(), "field mode not found") {
+		t.Fatalf("expected strict bundle error, got %v", err)
+	}
+}
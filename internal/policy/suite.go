@@ -0,0 +1,312 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyTest is a regression case: an input and the outcome the policy must produce.
+type PolicyTest struct {
+	Name   string     `yaml:"name"`
+	Input  Input      `yaml:"input"`
+	Expect TestExpect `yaml:"expect"`
+
+	pos Position
+}
+
+// TestExpect lists the expected outcome; unset fields are not checked.
+type TestExpect struct {
+	Verdict         string  `yaml:"verdict"`
+	RequireApproval *bool   `yaml:"require_approval"`
+	AWSRoleARN      *string `yaml:"aws_role_arn"`
+	TTLSeconds      *int    `yaml:"ttl_seconds"`
+	MatchedRule     *string `yaml:"matched_rule"`
+}
+
+func (t *PolicyTest) UnmarshalYAML(node *yaml.Node) error {
+	type raw PolicyTest
+	var r raw
+	if err := node.Decode(&r); err != nil {
+		return err
+	}
+	test := PolicyTest(r)
+	if err := test.Validate(); err != nil {
+		return fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	*t = test
+	return nil
+}
+
+// Validate reports whether the test case is well formed.
+func (t PolicyTest) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("policy test missing name")
+	}
+	switch t.Expect.Verdict {
+	case "", "allow", "deny", "require_approval":
+	default:
+		return fmt.Errorf("policy test %q: unknown verdict %q", t.Name, t.Expect.Verdict)
+	}
+	if t.Expect == (TestExpect{}) {
+		return fmt.Errorf("policy test %q: expect is empty", t.Name)
+	}
+	return nil
+}
+
+// TestMismatch is one expected field that did not match.
+type TestMismatch struct {
+	Field string `json:"field"`
+	Want  string `json:"want"`
+	Got   string `json:"got"`
+}
+
+// TestResult is the outcome of one policy test.
+type TestResult struct {
+	Position
+	Name       string         `json:"name"`
+	Passed     bool           `json:"passed"`
+	Mismatches []TestMismatch `json:"mismatches,omitempty"`
+}
+
+// SuiteTime is the evaluation time of a test case whose input has no `at`, so a case
+// never depends on the day it runs (a condition may read `now`).
+var SuiteTime = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// RunTests evaluates each test against the policy.
+func RunTests(loaded LoadedPolicy, tests []PolicyTest) []TestResult {
+	results := make([]TestResult, 0, len(tests))
+	for _, test := range tests {
+		input := test.Input
+		if input.At.IsZero() {
+			input.At = SuiteTime
+		}
+		d := Evaluate(loaded.Policy, loaded.Hash, input)
+		result := TestResult{Position: test.pos, Name: test.Name}
+		check := func(field, want, got string) {
+			if want != got {
+				result.Mismatches = append(result.Mismatches, TestMismatch{Field: field, Want: want, Got: got})
+			}
+		}
+		e := test.Expect
+		if e.Verdict != "" {
+			check("verdict", e.Verdict, d.Verdict)
+		}
+		if e.RequireApproval != nil {
+			check("require_approval", strconv.FormatBool(*e.RequireApproval), strconv.FormatBool(d.RequireApproval))
+		}
+		if e.AWSRoleARN != nil {
+			check("aws_role_arn", *e.AWSRoleARN, d.AWSRoleARN)
+		}
+		if e.TTLSeconds != nil {
+			check("ttl_seconds", strconv.Itoa(*e.TTLSeconds), strconv.Itoa(d.TTLSeconds))
+		}
+		if e.MatchedRule != nil {
+			check("matched_rule", *e.MatchedRule, d.MatchedRuleID)
+		}
+		result.Passed = len(result.Mismatches) == 0
+		results = append(results, result)
+	}
+	return results
+}
+
+// IsTestFile reports whether name is a sibling test file (*_test.yaml or *_test.yml).
+func IsTestFile(name string) bool {
+	ext := filepath.Ext(name)
+	return (ext == ".yaml" || ext == ".yml") && strings.HasSuffix(strings.TrimSuffix(name, ext), "_test")
+}
+
+// SuiteTarget is a policy to test: its path, loaded form, and the tests that apply
+// to it, embedded ones first and then those from sibling test files.
+type SuiteTarget struct {
+	Path   string
+	Policy LoadedPolicy
+	Tests  []PolicyTest
+}
+
+// LoadSuite finds the policies under path and their tests. A file is tested on its
+// own. A directory is searched recursively: every policy file not included by
+// another one is a root, and each *_test.yaml attaches to the policy file of the same
+// name (relia_test.yaml tests relia.yaml, against the root that file belongs to).
+func LoadSuite(path string) ([]SuiteTarget, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policyFiles, testFiles []string
+	if info.IsDir() {
+		err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			switch ext := filepath.Ext(p); {
+			case ext != ".yaml" && ext != ".yml":
+			case IsTestFile(d.Name()):
+				testFiles = append(testFiles, p)
+			default:
+				policyFiles = append(policyFiles, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		policyFiles = []string{path}
+	}
+
+	// members lists the files each policy loads; a file loaded by another is not a root.
+	members := map[string][]string{}
+	included := map[string]bool{}
+	for _, p := range policyFiles {
+		files, err := collectBundle(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			member := filepath.Join(filepath.Dir(p), filepath.FromSlash(f.Path))
+			members[p] = append(members[p], member)
+			if member != filepath.Clean(p) {
+				included[member] = true
+			}
+		}
+	}
+
+	if !info.IsDir() {
+		for _, m := range members[path] {
+			for _, ext := range []string{".yaml", ".yml"} {
+				sibling := strings.TrimSuffix(m, filepath.Ext(m)) + "_test" + ext
+				if _, err := os.Stat(sibling); err == nil {
+					testFiles = append(testFiles, sibling)
+				}
+			}
+		}
+	}
+
+	var targets []SuiteTarget
+	index := map[string]int{}
+	owner := map[string]string{} // policy file -> the root it is loaded with
+	for _, p := range policyFiles {
+		if included[filepath.Clean(p)] {
+			continue
+		}
+		loaded, err := LoadPolicy(p)
+		if err != nil {
+			return nil, err
+		}
+		target := SuiteTarget{Path: p, Policy: loaded}
+		for _, test := range loaded.Policy.Tests {
+			test.pos.File = resolveTestFile(p, test.pos.File)
+			if err := checkTestTime(loaded.Policy, test); err != nil {
+				return nil, err
+			}
+			target.Tests = append(target.Tests, test)
+		}
+		index[p] = len(targets)
+		for _, member := range members[p] {
+			owner[member] = p
+		}
+		targets = append(targets, target)
+	}
+
+	sort.Strings(testFiles)
+	for _, tf := range testFiles {
+		ext := filepath.Ext(tf)
+		base := strings.TrimSuffix(strings.TrimSuffix(tf, ext), "_test")
+		root, ok := "", false
+		for _, candidate := range []string{base + ".yaml", base + ".yml"} {
+			if root, ok = owner[filepath.Clean(candidate)]; ok {
+				break
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: no policy file %s.yaml to test", tf, filepath.Base(base))
+		}
+		tests, err := loadTestFile(tf)
+		if err != nil {
+			return nil, err
+		}
+		i := index[root]
+		for _, test := range tests {
+			if err := checkTestTime(targets[i].Policy.Policy, test); err != nil {
+				return nil, err
+			}
+		}
+		targets[i].Tests = append(targets[i].Tests, tests...)
+	}
+	return targets, nil
+}
+
+// checkTestTime requires an explicit `at` in cases for a policy with `when` windows or
+// freezes: what such a case checks depends on the time, so SuiteTime would be a guess.
+func checkTestTime(p Policy, test PolicyTest) error {
+	if !test.Input.At.IsZero() || !p.timeDependent() {
+		return nil
+	}
+	return fmt.Errorf("%s: policy test %q: input.at is required because the policy has when windows or freezes", test.pos, test.Name)
+}
+
+// timeDependent reports whether any rule has a `when` window or the policy has freezes.
+func (p Policy) timeDependent() bool {
+	if len(p.Freezes) > 0 {
+		return true
+	}
+	for _, rule := range p.Rules {
+		if !rule.When.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTestFile turns a test position's bundle-relative file into a path next to root.
+func resolveTestFile(root, file string) string {
+	if file == "" {
+		return root
+	}
+	return filepath.Join(filepath.Dir(root), filepath.FromSlash(file))
+}
+
+type testFile struct {
+	Tests []PolicyTest `yaml:"tests"`
+}
+
+func loadTestFile(path string) ([]PolicyTest, error) {
+	// #nosec G304 -- test files are found under an operator-supplied suite path.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, withFile(path, err)
+	}
+	if len(root.Content) == 0 {
+		return nil, fmt.Errorf("%s: no tests", path)
+	}
+	doc := root.Content[0]
+	if err := checkKnownFields(doc, reflect.TypeOf(testFile{}), "", path); err != nil {
+		return nil, err
+	}
+	var tf testFile
+	if err := doc.Decode(&tf); err != nil {
+		return nil, withFile(path, err)
+	}
+	if len(tf.Tests) == 0 {
+		return nil, fmt.Errorf("%s: no tests", path)
+	}
+	positions := map[string]Position{}
+	recordPositions(doc, "", path, positions)
+	for i := range tf.Tests {
+		tf.Tests[i].pos = positions["tests["+strconv.Itoa(i)+"]"]
+	}
+	return tf.Tests, nil
+}
@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSuiteDirectory(t *testing.T) {
+	dir := writePolicyFiles(t, map[string]string{
+		"relia.yaml": `policy_id: org
+includes: [teams]
+rules:
+  - id: prod
+    match: {env: prod}
+    effect: {require_approval: true, ttl_seconds: 900}
+tests:
+  - name: embedded
+    input: {action: deploy, env: prod}
+    expect: {verdict: require_approval}
+`,
+		"teams/payments.yaml": `rules:
+  - id: payments
+    match: {action: pay}
+    effect: {deny: true}
+tests:
+  - name: fragment embedded
+    input: {action: pay, env: dev}
+    expect: {verdict: deny, matched_rule: payments}
+`,
+		"teams/payments_test.yaml": `tests:
+  - name: sibling
+    input: {action: pay, env: prod}
+    expect: {matched_rule: payments}
+`,
+		"templates/other.yaml": "policy_id: other\n",
+	})
+
+	targets, err := LoadSuite(dir)
+	if err != nil {
+		t.Fatalf("load suite: %v", err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("expected 2 root policies, got %d", len(targets))
+	}
+	root := targets[0]
+	if root.Path != filepath.Join(dir, "relia.yaml") {
+		t.Fatalf("unexpected root: %s", root.Path)
+	}
+	var names []string
+	for _, test := range root.Tests {
+		names = append(names, test.Name)
+	}
+	if got := strings.Join(names, ","); got != "embedded,fragment embedded,sibling" {
+		t.Fatalf("unexpected tests: %s", got)
+	}
+
+	results := RunTests(root.Policy, root.Tests)
+	if !results[0].Passed || !results[1].Passed {
+		t.Fatalf("expected embedded tests to pass: %+v", results)
+	}
+	if results[1].File != filepath.Join(dir, "teams/payments.yaml") || results[1].Line != 6 {
+		t.Fatalf("unexpected fragment test position: %+v", results[1].Position)
+	}
+	// The first-match policy applies "prod" before "payments".
+	if results[2].Passed || len(results[2].Mismatches) != 1 {
+		t.Fatalf("expected sibling test to fail: %+v", results[2])
+	}
+	if m := results[2].Mismatches[0]; m.Field != "matched_rule" || m.Want != "payments" || m.Got != "prod" {
+		t.Fatalf("unexpected mismatch: %+v", m)
+	}
+	if results[2].File != filepath.Join(dir, "teams/payments_test.yaml") || results[2].Line != 2 {
+		t.Fatalf("unexpected sibling test position: %+v", results[2].Position)
+	}
+}
+
+func TestLoadSuiteFile(t *testing.T) {
+	dir := writePolicyFiles(t, map[string]string{
+		"relia.yaml":      "policy_id: p\ndefaults: {ttl_seconds: 900}\n",
+		"relia_test.yaml": "tests:\n  - name: ttl\n    input: {action: a}\n    expect: {ttl_seconds: 900, require_approval: false, aws_role_arn: \"\"}\n",
+	})
+	targets, err := LoadSuite(filepath.Join(dir, "relia.yaml"))
+	if err != nil {
+		t.Fatalf("load suite: %v", err)
+	}
+	if len(targets) != 1 || len(targets[0].Tests) != 1 {
+		t.Fatalf("unexpected targets: %+v", targets)
+	}
+	if results := RunTests(targets[0].Policy, targets[0].Tests); !results[0].Passed {
+		t.Fatalf("expected pass: %+v", results)
+	}
+
+	// Test files are not part of a directory bundle.
+	loaded, err := LoadPolicy(dir)
+	if err != nil {
+		t.Fatalf("load dir: %v", err)
+	}
+	if strings.Join(loaded.Files, ",") != "relia.yaml" {
+		t.Fatalf("unexpected bundle files: %v", loaded.Files)
+	}
+}
+
+func TestLoadSuiteErrors(t *testing.T) {
+	cases := map[string]struct {
+		files map[string]string
+		want  string
+	}{
+		"orphan test file": {
+			files: map[string]string{"relia.yaml": "policy_id: p\n", "other_test.yaml": "tests:\n  - {name: a, expect: {verdict: allow}}\n"},
+			want:  "no policy file other.yaml",
+		},
+		"empty expect": {
+			files: map[string]string{"relia.yaml": "policy_id: p\ntests:\n  - name: a\n    input: {action: x}\n"},
+			want:  `policy test "a": expect is empty`,
+		},
+		"bad verdict": {
+			files: map[string]string{"relia.yaml": "policy_id: p\ntests:\n  - {name: a, expect: {verdict: permit}}\n"},
+			want:  `unknown verdict "permit"`,
+		},
+		"unknown field": {
+			files: map[string]string{"relia.yaml": "policy_id: p\n", "relia_test.yaml": "tests:\n  - {name: a, expect: {verdit: allow}}\n"},
+			want:  `relia_test.yaml:2:24: unknown field "verdit" in tests[0].expect`,
+		},
+		"time-dependent without at": {
+			files: map[string]string{"relia.yaml": "policy_id: p\nfreezes:\n  - {id: f, effect: deny, when: {days: [sat]}}\n", "relia_test.yaml": "tests:\n  - {name: weekend, input: {env: prod}, expect: {verdict: deny}}\n"},
+			want:  `relia_test.yaml:2:5: policy test "weekend": input.at is required`,
+		},
+		"no tests": {
+			files: map[string]string{"relia.yaml": "policy_id: p\n", "relia_test.yaml": "tests: []\n"},
+			want:  "no tests",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := writePolicyFiles(t, tc.files)
+			if _, err := LoadSuite(dir); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"relia_test.yaml": true,
+		"relia_test.yml":  true,
+		"relia.yaml":      false,
+		"_test.go":        false,
+		"latest.yaml":     false,
+	} {
+		if got := IsTestFile(name); got != want {
+			t.Fatalf("%s: got %t want %t", name, got, want)
+		}
+	}
+}
+
+func TestRunTestsDefaultsToSuiteTime(t *testing.T) {
+	dir := writePolicyFiles(t, map[string]string{
+		"relia.yaml": `policy_id: p
+rules:
+  - id: new_year
+    match: {env: prod}
+    condition: now == timestamp("2025-01-01T00:00:00Z")
+    effect: {deny: true}
+freezes:
+  - id: weekend
+    effect: deny
+    when: {days: [sat, sun]}
+tests:
+  - name: saturday
+    input: {env: dev, at: "2026-01-03T12:00:00Z"}
+    expect: {verdict: deny}
+`,
+	})
+	targets, err := LoadSuite(dir)
+	if err != nil {
+		t.Fatalf("load suite: %v", err)
+	}
+	root := targets[0]
+	root.Tests = append(root.Tests, PolicyTest{Name: "no at", Input: Input{Env: "prod"}, Expect: TestExpect{Verdict: "deny"}})
+	for _, r := range RunTests(root.Policy, root.Tests) {
+		if !r.Passed {
+			t.Fatalf("expected %q to pass: %+v", r.Name, r.Mismatches)
+		}
+	}
+}
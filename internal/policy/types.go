@@ -1,28 +1,82 @@
 package policy
 
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"gopkg.in/yaml.v3"
+)
+
 type Policy struct {
 	PolicyID      string         `yaml:"policy_id"`
 	PolicyVersion string         `yaml:"policy_version"`
 	Defaults      PolicyDefaults `yaml:"defaults"`
 	Rules         []PolicyRule   `yaml:"rules"`
+
+	// Evaluation is first_match (the default) or all_matching; all_matching requires
+	// a Combining algorithm to merge the matched rules.
+	Evaluation string `yaml:"evaluation"`
+	Combining  string `yaml:"combining"`
+
+	// Includes lists further policy files or directories, relative to this file,
+	// merged after it into one bundle.
+	Includes []string `yaml:"includes"`
+
+	// Identities maps requesters to their approver identities for separation of duties.
+	Identities Identities `yaml:"identities"`
+
+	// Freezes override the rule verdict while their window is active.
+	Freezes []PolicyFreeze `yaml:"freezes"`
+
+	// Tests are regression cases run by `relia policy test --suite`; they do not
+	// affect evaluation.
+	Tests []PolicyTest `yaml:"tests"`
+
+	// positions maps dotted field paths (e.g. "defaults.ttl_seconds") to source positions.
+	positions map[string]Position
 }
 
 type PolicyDefaults struct {
 	TTLSeconds      int  `yaml:"ttl_seconds"`
 	RequireApproval bool `yaml:"require_approval"`
 	Deny            bool `yaml:"deny"`
+
+	// ApprovalTimeout expires pending approvals; zero leaves them pending until decided.
+	ApprovalTimeout ApprovalTimeout `yaml:"approval_timeout"`
 }
 
 type PolicyRule struct {
 	ID     string       `yaml:"id"`
 	Match  PolicyMatch  `yaml:"match"`
+	When   PolicyWhen   `yaml:"when"`
 	Effect PolicyEffect `yaml:"effect"`
+
+	// Condition is an optional CEL expression that must also hold; see condition.go.
+	Condition string `yaml:"condition"`
+	program   cel.Program
+
+	// positions maps field paths within the rule ("" for the rule itself) to source positions.
+	positions map[string]Position
 }
 
+// PolicyMatch fields are exact values, globs, or lists of either; empty fields match anything.
 type PolicyMatch struct {
-	Action   string `yaml:"action"`
-	Resource string `yaml:"resource"`
-	Env      string `yaml:"env"`
+	Action   Patterns `yaml:"action"`
+	Resource Patterns `yaml:"resource"`
+	Env      Patterns `yaml:"env"`
+
+	// Actor conditions, matched against the caller's claims; a missing claim is the empty string.
+	Issuer      Patterns `yaml:"issuer"`
+	Subject     Patterns `yaml:"subject"`
+	Repo        Patterns `yaml:"repo"`
+	Workflow    Patterns `yaml:"workflow"`
+	SHA         Patterns `yaml:"sha"`
+	Ref         Patterns `yaml:"ref"`
+	Environment Patterns `yaml:"environment"`
+	Actor       Patterns `yaml:"actor"`
+
+	// Intent predicates; all must hold for the rule to match.
+	Intent []IntentPredicate `yaml:"intent"`
 }
 
 type PolicyEffect struct {
@@ -32,4 +86,47 @@ type PolicyEffect struct {
 	AWSRoleARN      string `yaml:"aws_role_arn"`
 	Risk            string `yaml:"risk"`
 	Reason          string `yaml:"reason"`
+
+	// RequireEvidence denies the request when any listed evidence is missing.
+	RequireEvidence EvidenceNames `yaml:"require_evidence"`
+
+	// Approvals sets the approval quorum when the rule requires approval; without it
+	// one approver suffices.
+	Approvals *ApprovalQuorum `yaml:"approvals"`
+
+	// Approvers restricts who may vote on the rule's approvals; without it anyone
+	// who passes the quorum checks may.
+	Approvers *ApproverAllowlist `yaml:"approvers"`
+
+	// ApprovalTimeout overrides defaults.approval_timeout for the rule's approvals.
+	ApprovalTimeout *ApprovalTimeout `yaml:"approval_timeout"`
+
+	// ApprovalTransport posts the rule's approval requests to Slack, Teams or the
+	// webhook; empty uses whichever the gateway has configured, preferring Slack.
+	ApprovalTransport ApprovalTransport `yaml:"approval_transport"`
+
+	// ApprovalChannel posts the rule's approval requests to this Slack channel ID, or
+	// Teams channel alias, instead of the gateway's channel.
+	ApprovalChannel ApprovalChannel `yaml:"approval_channel"`
+
+	// ApprovalMentions are Slack user group IDs (S...), user IDs (U...) or "here"
+	// mentioned in the rule's approval requests.
+	ApprovalMentions []string `yaml:"approval_mentions"`
+}
+
+// EvidenceNames lists evidence a rule requires (plan_digest, diff_url).
+type EvidenceNames []string
+
+func (e *EvidenceNames) UnmarshalYAML(node *yaml.Node) error {
+	var names []string
+	if err := node.Decode(&names); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name != EvidencePlanDigest && name != EvidenceDiffURL {
+			return fmt.Errorf("line %d: unknown evidence %q", node.Line, name)
+		}
+	}
+	*e = names
+	return nil
 }
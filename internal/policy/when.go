@@ -0,0 +1,277 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Embedded zone data keeps timezone evaluation identical across hosts.
+	_ "time/tzdata"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyWhen restricts a rule or freeze to a time window. Every populated field must
+// hold; Weekly and Dates match when any of their entries does. Times are evaluated in
+// Timezone (default UTC).
+type PolicyWhen struct {
+	Timezone string        `yaml:"timezone"`
+	Days     []string      `yaml:"days"`
+	Hours    *TimeRange    `yaml:"hours"`
+	Weekly   []WeeklyRange `yaml:"weekly"`
+	Dates    []DateRange   `yaml:"dates"`
+}
+
+// TimeRange is a daily "HH:MM" window; From is inclusive, To exclusive. A range whose
+// To is earlier than From wraps past midnight.
+type TimeRange struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// WeeklyRange is a recurring window such as "fri 16:00" to "mon 08:00".
+type WeeklyRange struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// DateRange is an absolute window. Each bound is a date (YYYY-MM-DD, whole day,
+// inclusive) or an RFC3339 timestamp (From inclusive, To exclusive).
+type DateRange struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// PolicyFreeze forces a verdict while active, regardless of the matched rule.
+type PolicyFreeze struct {
+	ID     string      `yaml:"id"`
+	Match  PolicyMatch `yaml:"match"`
+	When   PolicyWhen  `yaml:"when"`
+	Effect string      `yaml:"effect"` // deny | require_approval
+	Reason string      `yaml:"reason"`
+}
+
+const (
+	FreezeDeny            = "deny"
+	FreezeRequireApproval = "require_approval"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func (w *PolicyWhen) UnmarshalYAML(node *yaml.Node) error {
+	type raw PolicyWhen
+	var r raw
+	if err := node.Decode(&r); err != nil {
+		return err
+	}
+	when := PolicyWhen(r)
+	if err := when.Validate(); err != nil {
+		return fmt.Errorf("line %d: when: %w", node.Line, err)
+	}
+	*w = when
+	return nil
+}
+
+func (f *PolicyFreeze) UnmarshalYAML(node *yaml.Node) error {
+	type raw PolicyFreeze
+	var r raw
+	if err := node.Decode(&r); err != nil {
+		return err
+	}
+	if r.ID == "" {
+		return fmt.Errorf("line %d: freeze missing id", node.Line)
+	}
+	if r.Effect != FreezeDeny && r.Effect != FreezeRequireApproval {
+		return fmt.Errorf("line %d: freeze %q: effect must be deny or require_approval", node.Line, r.ID)
+	}
+	if r.When.IsZero() {
+		return fmt.Errorf("line %d: freeze %q: missing when", node.Line, r.ID)
+	}
+	*f = PolicyFreeze(r)
+	return nil
+}
+
+// IsZero reports whether no time restriction is configured.
+func (w PolicyWhen) IsZero() bool {
+	return len(w.Days) == 0 && w.Hours == nil && len(w.Weekly) == 0 && len(w.Dates) == 0
+}
+
+// Validate reports whether every field of the window parses.
+func (w PolicyWhen) Validate() error {
+	if _, err := w.location(); err != nil {
+		return err
+	}
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("unknown day %q", day)
+		}
+	}
+	if w.Hours != nil {
+		if _, err := parseClock(w.Hours.From, false); err != nil {
+			return fmt.Errorf("hours.from: %w", err)
+		}
+		if _, err := parseClock(w.Hours.To, true); err != nil {
+			return fmt.Errorf("hours.to: %w", err)
+		}
+	}
+	for _, wr := range w.Weekly {
+		if _, err := parseWeekly(wr.From); err != nil {
+			return fmt.Errorf("weekly.from: %w", err)
+		}
+		if _, err := parseWeekly(wr.To); err != nil {
+			return fmt.Errorf("weekly.to: %w", err)
+		}
+	}
+	loc, _ := w.location()
+	for _, dr := range w.Dates {
+		from, err := parseDateBound(dr.From, loc, false)
+		if err != nil {
+			return fmt.Errorf("dates.from: %w", err)
+		}
+		to, err := parseDateBound(dr.To, loc, true)
+		if err != nil {
+			return fmt.Errorf("dates.to: %w", err)
+		}
+		if !to.After(from) {
+			return fmt.Errorf("dates: %s is not after %s", dr.To, dr.From)
+		}
+	}
+	return nil
+}
+
+// Active reports whether at falls inside the window. An empty window is always active.
+func (w PolicyWhen) Active(at time.Time) bool {
+	if w.IsZero() {
+		return true
+	}
+	loc, err := w.location()
+	if err != nil {
+		return false
+	}
+	local := at.In(loc)
+
+	if len(w.Days) > 0 {
+		found := false
+		for _, day := range w.Days {
+			if weekdays[strings.ToLower(day)] == local.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	if w.Hours != nil {
+		from, err1 := parseClock(w.Hours.From, false)
+		to, err2 := parseClock(w.Hours.To, true)
+		if err1 != nil || err2 != nil || !inCyclicRange(minute, from, to) {
+			return false
+		}
+	}
+
+	if len(w.Weekly) > 0 {
+		weekMinute := int(local.Weekday())*24*60 + minute
+		found := false
+		for _, wr := range w.Weekly {
+			from, err1 := parseWeekly(wr.From)
+			to, err2 := parseWeekly(wr.To)
+			if err1 == nil && err2 == nil && inCyclicRange(weekMinute, from, to) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(w.Dates) > 0 {
+		found := false
+		for _, dr := range w.Dates {
+			from, err1 := parseDateBound(dr.From, loc, false)
+			to, err2 := parseDateBound(dr.To, loc, true)
+			if err1 == nil && err2 == nil && !at.Before(from) && at.Before(to) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (w PolicyWhen) location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", w.Timezone)
+	}
+	return loc, nil
+}
+
+// inCyclicRange reports whether v lies in [from, to), wrapping when to <= from.
+func inCyclicRange(v, from, to int) bool {
+	if from < to {
+		return v >= from && v < to
+	}
+	return v >= from || v < to
+}
+
+// parseClock parses "HH:MM" into minutes since midnight. "24:00" is accepted as an
+// exclusive end bound.
+func parseClock(s string, end bool) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || len(hh) != 2 || len(mm) != 2 {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if err1 != nil || err2 != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && (m != 0 || !end)) {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return h*60 + m, nil
+}
+
+// parseWeekly parses "ddd HH:MM" into minutes since Sunday midnight.
+func parseWeekly(s string) (int, error) {
+	day, clock, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return 0, fmt.Errorf("invalid weekly time %q (want \"ddd HH:MM\")", s)
+	}
+	wd, ok := weekdays[strings.ToLower(day)]
+	if !ok {
+		return 0, fmt.Errorf("unknown day %q", day)
+	}
+	minute, err := parseClock(strings.TrimSpace(clock), false)
+	if err != nil {
+		return 0, err
+	}
+	return int(wd)*24*60 + minute, nil
+}
+
+// parseDateBound parses a DateRange bound. Dates are whole days in loc, so an end
+// date covers that entire day.
+func parseDateBound(s string, loc *time.Location, end bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseInLocation("2006-01-02", s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (want YYYY-MM-DD or RFC3339)", s)
+	}
+	if end {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d, nil
+}
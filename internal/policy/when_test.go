@@ -0,0 +1,178 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const whenPolicyYAML = `policy_id: p
+policy_version: "1"
+defaults:
+  ttl_seconds: 900
+rules:
+  - id: prod_weekend_block
+    match:
+      env: prod
+    when:
+      timezone: Europe/Berlin
+      weekly:
+        - from: "fri 16:00"
+          to: "mon 08:00"
+    effect:
+      deny: true
+      reason: no prod deploys over the weekend
+  - id: prod_after_hours
+    match:
+      env: prod
+    when:
+      timezone: Europe/Berlin
+      days: [mon, tue, wed, thu, fri]
+      hours:
+        from: "18:00"
+        to: "08:00"
+    effect:
+      require_approval: true
+  - id: prod
+    match:
+      env: prod
+    effect:
+      require_approval: false
+freezes:
+  - id: holidays-2026
+    match:
+      env: [prod, staging]
+    when:
+      timezone: Europe/Berlin
+      dates:
+        - from: "2026-12-24"
+          to: "2026-12-26"
+    effect: deny
+    reason: holiday change freeze
+  - id: release-week
+    when:
+      dates:
+        - from: "2026-11-02T00:00:00Z"
+          to: "2026-11-09T00:00:00Z"
+    effect: require_approval
+    reason: release week
+`
+
+func TestEvaluateWhenWindows(t *testing.T) {
+	loaded, err := LoadPolicyFromBytes([]byte(whenPolicyYAML))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		at      string
+		verdict string
+		rule    string
+	}{
+		{"weekday_business_hours", "2026-10-14T10:00:00+02:00", "allow", "prod"},
+		{"friday_before_cutoff", "2026-10-16T15:59:00+02:00", "allow", "prod"},
+		{"friday_after_cutoff", "2026-10-16T16:00:00+02:00", "deny", "prod_weekend_block"},
+		{"sunday", "2026-10-18T12:00:00+02:00", "deny", "prod_weekend_block"},
+		{"monday_early", "2026-10-19T07:59:00+02:00", "deny", "prod_weekend_block"},
+		{"monday_after_window", "2026-10-19T08:00:00+02:00", "allow", "prod"},
+		{"weekday_evening_wraps_midnight", "2026-10-14T23:30:00+02:00", "require_approval", "prod_after_hours"},
+		{"weekday_early_morning", "2026-10-15T06:00:00+02:00", "require_approval", "prod_after_hours"},
+		// 15:30 UTC on a winter Friday is 16:30 in Berlin.
+		{"timezone_applied", "2026-12-04T15:30:00Z", "deny", "prod_weekend_block"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, tc.at)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			decision := Evaluate(loaded.Policy, loaded.Hash, Input{Action: "deploy", Env: "prod", At: at})
+			if decision.Verdict != tc.verdict || decision.MatchedRuleID != tc.rule {
+				t.Fatalf("expected %s via %s, got %+v", tc.verdict, tc.rule, decision)
+			}
+			if tc.rule != "prod" && !containsCode(decision.ReasonCodes, "WHEN_MATCH:"+tc.rule) {
+				t.Fatalf("expected WHEN_MATCH code, got %v", decision.ReasonCodes)
+			}
+		})
+	}
+}
+
+func TestEvaluateFreezes(t *testing.T) {
+	loaded, err := LoadPolicyFromBytes([]byte(whenPolicyYAML))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	// Thursday 24 Dec, inside the date freeze; the end date covers the whole day.
+	for _, at := range []string{"2026-12-24T10:00:00Z", "2026-12-26T22:59:00Z"} {
+		decision := Evaluate(loaded.Policy, loaded.Hash, Input{Env: "staging", At: mustTime(t, at)})
+		if decision.Verdict != "deny" || decision.Reason != "holiday change freeze" {
+			t.Fatalf("%s: expected freeze deny, got %+v", at, decision)
+		}
+		if !containsCode(decision.ReasonCodes, "FREEZE:holidays-2026") {
+			t.Fatalf("%s: expected FREEZE code, got %v", at, decision.ReasonCodes)
+		}
+	}
+
+	// Midnight 27 Dec in Berlin is past the freeze.
+	decision := Evaluate(loaded.Policy, loaded.Hash, Input{Env: "staging", At: mustTime(t, "2026-12-26T23:00:00Z")})
+	if decision.Verdict != "allow" || len(decision.ReasonCodes) != 0 {
+		t.Fatalf("expected allow after freeze, got %+v", decision)
+	}
+
+	// Freeze match does not select dev.
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Env: "dev", At: mustTime(t, "2026-12-24T10:00:00Z")})
+	if decision.Verdict != "allow" {
+		t.Fatalf("expected allow for dev, got %+v", decision)
+	}
+
+	// require_approval freezes escalate allow but never relax deny.
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Env: "dev", At: mustTime(t, "2026-11-03T10:00:00Z")})
+	if decision.Verdict != "require_approval" || !decision.RequireApproval || decision.Reason != "release week" {
+		t.Fatalf("expected release-week approval, got %+v", decision)
+	}
+	decision = Evaluate(loaded.Policy, loaded.Hash, Input{Env: "prod", At: mustTime(t, "2026-11-07T10:00:00Z")})
+	if decision.Verdict != "deny" || decision.MatchedRuleID != "prod_weekend_block" {
+		t.Fatalf("expected weekend deny to stand, got %+v", decision)
+	}
+	if !containsCode(decision.ReasonCodes, "FREEZE:release-week") {
+		t.Fatalf("expected FREEZE code, got %v", decision.ReasonCodes)
+	}
+}
+
+func TestLoadPolicyRejectsInvalidWhen(t *testing.T) {
+	cases := map[string]string{
+		"timezone":     "rules:\n  - id: r\n    when:\n      timezone: Mars/Olympus\n      days: [mon]\n",
+		"day":          "rules:\n  - id: r\n    when:\n      days: [funday]\n",
+		"hours":        "rules:\n  - id: r\n    when:\n      hours: {from: \"25:00\", to: \"08:00\"}\n",
+		"weekly":       "rules:\n  - id: r\n    when:\n      weekly: [{from: \"16:00\", to: \"mon 08:00\"}]\n",
+		"date":         "rules:\n  - id: r\n    when:\n      dates: [{from: \"24.12.2026\", to: \"2026-12-26\"}]\n",
+		"date_order":   "rules:\n  - id: r\n    when:\n      dates: [{from: \"2026-12-26\", to: \"2026-12-24\"}]\n",
+		"freeze_id":    "freezes:\n  - effect: deny\n    when: {days: [mon]}\n",
+		"freeze_eff":   "freezes:\n  - id: f\n    effect: allow\n    when: {days: [mon]}\n",
+		"freeze_when":  "freezes:\n  - id: f\n    effect: deny\n",
+		"freeze_match": "freezes:\n  - id: f\n    effect: deny\n    when: {days: [mon]}\n    match: {env: \"[\"}\n",
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadPolicyFromBytes([]byte(body)); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+
+	_, err := LoadPolicyFromBytes([]byte(cases["timezone"]))
+	if err == nil || !strings.Contains(err.Error(), "unknown timezone") {
+		t.Fatalf("expected timezone error, got %v", err)
+	}
+}
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("parse %s: %v", s, err)
+	}
+	return at
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 )
 
@@ -15,6 +16,54 @@ type Client struct {
 }
 
 func (c *Client) PostApproval(channel string, message ApprovalMessageInput) (string, error) {
+	if channel == "" {
+		return "", fmt.Errorf("missing slack channel")
+	}
+	msgBytes, err := BuildApprovalMessage(message)
+	if err != nil {
+		return "", err
+	}
+	ts, err := c.postMessage("chat.postMessage", msgBytes, map[string]any{"channel": channel})
+	if err != nil {
+		return "", err
+	}
+	if ts == "" {
+		return "", fmt.Errorf("missing slack message ts")
+	}
+	return ts, nil
+}
+
+// UpdateMessage replaces the approval message at ts with its resolved form, removing
+// the Approve/Deny buttons. It needs the message's channel ID, as returned when it was
+// posted.
+func (c *Client) UpdateMessage(channel, ts string, message ApprovalMessageInput, resolution ApprovalResolution) error {
+	if channel == "" || ts == "" {
+		return fmt.Errorf("missing slack channel or message ts")
+	}
+	msgBytes, err := BuildResolvedMessage(message, resolution)
+	if err != nil {
+		return err
+	}
+	_, err = c.postMessage("chat.update", msgBytes, map[string]any{"channel": channel, "ts": ts})
+	return err
+}
+
+// PostThreadReply posts text as a reply in the thread of the message at threadTS.
+func (c *Client) PostThreadReply(channel, threadTS, text string) error {
+	if channel == "" || threadTS == "" {
+		return fmt.Errorf("missing slack channel or thread ts")
+	}
+	msgBytes, err := json.Marshal(map[string]any{"text": text})
+	if err != nil {
+		return err
+	}
+	_, err = c.postMessage("chat.postMessage", msgBytes, map[string]any{"channel": channel, "thread_ts": threadTS})
+	return err
+}
+
+// postMessage calls a chat.* method with the message JSON plus extra fields and returns
+// the message ts.
+func (c *Client) postMessage(method string, msgBytes []byte, extra map[string]any) (string, error) {
 	if c.HTTP == nil {
 		c.HTTP = &http.Client{Timeout: 10 * time.Second}
 	}
@@ -25,27 +74,21 @@ func (c *Client) PostApproval(channel string, message ApprovalMessageInput) (str
 	if c.Token == "" {
 		return "", fmt.Errorf("missing slack token")
 	}
-	if channel == "" {
-		return "", fmt.Errorf("missing slack channel")
-	}
-
-	msgBytes, err := BuildApprovalMessage(message)
-	if err != nil {
-		return "", err
-	}
 
 	var payload map[string]any
 	if err := json.Unmarshal(msgBytes, &payload); err != nil {
 		return "", err
 	}
-	payload["channel"] = channel
+	for key, value := range extra {
+		payload[key] = value
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return "", err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, baseURL+"/chat.postMessage", bytes.NewBuffer(body))
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/"+method, bytes.NewBuffer(body))
 	if err != nil {
 		return "", err
 	}
@@ -72,8 +115,58 @@ func (c *Client) PostApproval(channel string, message ApprovalMessageInput) (str
 		}
 		return "", fmt.Errorf("%s", resp.Error)
 	}
-	if resp.TS == "" {
-		return "", fmt.Errorf("missing slack message ts")
-	}
 	return resp.TS, nil
 }
+
+// UserGroups returns the IDs of the Slack user groups userID belongs to. It needs the
+// usergroups:read scope.
+func (c *Client) UserGroups(userID string) ([]string, error) {
+	if c.HTTP == nil {
+		c.HTTP = &http.Client{Timeout: 10 * time.Second}
+	}
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = "https://slack.com/api"
+	}
+	if c.Token == "" {
+		return nil, fmt.Errorf("missing slack token")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/usergroups.list?include_users=true", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var resp struct {
+		OK         bool   `json:"ok"`
+		Error      string `json:"error,omitempty"`
+		Usergroups []struct {
+			ID    string   `json:"id"`
+			Users []string `json:"users"`
+		} `json:"usergroups"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, err
+	}
+	if !resp.OK {
+		if resp.Error == "" {
+			resp.Error = "slack api error"
+		}
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+
+	var groups []string
+	for _, group := range resp.Usergroups {
+		if slices.Contains(group.Users, userID) {
+			groups = append(groups, group.ID)
+		}
+	}
+	return groups, nil
+}
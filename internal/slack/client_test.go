@@ -102,3 +102,72 @@ func TestClientPostApprovalDefaultHTTPTimeout(t *testing.T) {
 		t.Fatalf("expected non-zero timeout")
 	}
 }
+
+func TestClientUpdateMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.update" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"channel":"C123"`) || !strings.Contains(string(body), `"ts":"123.456"`) {
+			t.Fatalf("missing channel or ts in request: %s", string(body))
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"ts":"123.456"}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{Token: "xoxb-test", BaseURL: srv.URL, HTTP: srv.Client()}
+	input := ApprovalMessageInput{ApprovalID: "a1", Action: "x", Env: "dev", Resource: "r"}
+	if err := c.UpdateMessage("C123", "123.456", input, ApprovalResolution{Status: "expired", At: "2025-12-20T16:30:00Z"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := c.UpdateMessage("C123", "", input, ApprovalResolution{Status: "expired"}); err == nil {
+		t.Fatalf("expected missing ts error")
+	}
+}
+
+func TestClientPostThreadReply(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"thread_ts":"123.456"`) || !strings.Contains(string(body), `"text":"Credentials issued."`) {
+			t.Fatalf("missing thread_ts or text in request: %s", string(body))
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"ts":"123.789"}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{Token: "xoxb-test", BaseURL: srv.URL, HTTP: srv.Client()}
+	if err := c.PostThreadReply("C123", "123.456", "Credentials issued."); err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if err := c.PostThreadReply("C123", "", "Credentials issued."); err == nil {
+		t.Fatalf("expected missing thread ts error")
+	}
+}
+
+func TestClientUserGroups(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/usergroups.list" || r.URL.Query().Get("include_users") != "true" {
+			t.Fatalf("unexpected request: %s", r.URL)
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"usergroups":[{"id":"S1","users":["U1","U2"]},{"id":"S2","users":["U3"]},{"id":"S3","users":["U2"]}]}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{Token: "xoxb-test", BaseURL: srv.URL, HTTP: srv.Client()}
+	groups, err := c.UserGroups("U2")
+	if err != nil {
+		t.Fatalf("user groups: %v", err)
+	}
+	if strings.Join(groups, ",") != "S1,S3" {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+
+	c.Token = ""
+	if _, err := c.UserGroups("U2"); err == nil {
+		t.Fatalf("expected missing token error")
+	}
+}
@@ -1,6 +1,7 @@
 package slack
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
@@ -8,20 +9,39 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/davidahmann/relia/pkg/types"
 )
 
 type Approver interface {
-	Approve(approvalID string, status string, createdAt string) (string, error)
+	Approve(approvalID string, approver types.Approver, status string, createdAt string) (string, error)
+}
+
+// UserGroupLister resolves the Slack user groups a user belongs to; *Client implements it.
+type UserGroupLister interface {
+	UserGroups(userID string) ([]string, error)
 }
 
 type InteractionHandler struct {
 	SigningSecret string
 	Approver      Approver
 	Now           func() time.Time
+
+	// Groups, when set, fills in the clicking user's Slack user groups so policies can
+	// allow approvers by group.
+	Groups UserGroupLister
+	// HTTP posts ephemeral replies to the interaction's response_url.
+	HTTP *http.Client
+}
+
+// voteRejected is implemented by Approver errors for votes the user may not cast.
+type voteRejected interface {
+	VoteRejected() bool
 }
 
 func (h *InteractionHandler) HandleInteractions(w http.ResponseWriter, r *http.Request) {
-	if h.Approver == nil {
+	// Unsigned payloads are never accepted: the voter's user ID comes from the body.
+	if h.Approver == nil || h.SigningSecret == "" {
 		w.WriteHeader(http.StatusNotImplemented)
 		return
 	}
@@ -32,17 +52,15 @@ func (h *InteractionHandler) HandleInteractions(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	now := time.Now()
+	if h.Now != nil {
+		now = h.Now()
+	}
 	sig := r.Header.Get("X-Slack-Signature")
 	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
-	if h.SigningSecret != "" {
-		now := time.Now()
-		if h.Now != nil {
-			now = h.Now()
-		}
-		if err := VerifySignature(h.SigningSecret, sig, timestamp, body, now); err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
+	if err := VerifySignature(h.SigningSecret, sig, timestamp, body, now); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
 	}
 
 	payload, err := parsePayload(body)
@@ -62,12 +80,22 @@ func (h *InteractionHandler) HandleInteractions(w http.ResponseWriter, r *http.R
 		status = "approved"
 	}
 
-	now := time.Now()
-	if h.Now != nil {
-		now = h.Now()
+	approver := payload.approver()
+	if h.Groups != nil && approver.ID != "" {
+		// A failed lookup (e.g. a token without usergroups:read) only leaves the
+		// approver without groups, which can never grant more than it would with them.
+		if groups, err := h.Groups.UserGroups(approver.ID); err == nil {
+			approver.Groups = groups
+		}
 	}
 
-	_, err = h.Approver.Approve(approvalID, status, now.UTC().Format(time.RFC3339))
+	_, err = h.Approver.Approve(approvalID, approver, status, now.UTC().Format(time.RFC3339))
+	var rejected voteRejected
+	if errors.As(err, &rejected) && rejected.VoteRejected() {
+		h.replyEphemeral(payload.ResponseURL, "Your vote was not counted: "+err.Error())
+		w.WriteHeader(http.StatusOK)
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -81,6 +109,60 @@ type slackPayload struct {
 		ActionID string `json:"action_id"`
 		Value    string `json:"value"`
 	} `json:"actions"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+		TeamID   string `json:"team_id"`
+	} `json:"user"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	ResponseURL string `json:"response_url"`
+}
+
+// replyEphemeral shows text only to the user who clicked, leaving the approval message
+// in place. Block actions cannot carry a reply in the HTTP response, so it goes to the
+// interaction's response_url; failures are ignored since the vote outcome is already
+// recorded.
+func (h *InteractionHandler) replyEphemeral(responseURL, text string) {
+	if responseURL == "" {
+		return
+	}
+	body, err := json.Marshal(map[string]any{
+		"response_type":    "ephemeral",
+		"replace_original": false,
+		"text":             text,
+	})
+	if err != nil {
+		return
+	}
+	client := h.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	res, err := client.Post(responseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	_ = res.Body.Close()
+}
+
+// approver returns the Slack user who clicked the button.
+func (p slackPayload) approver() types.Approver {
+	approver := types.Approver{
+		Kind:    "slack",
+		ID:      p.User.ID,
+		Display: p.User.Username,
+		TeamID:  p.User.TeamID,
+	}
+	if approver.Display == "" {
+		approver.Display = p.User.Name
+	}
+	if approver.TeamID == "" {
+		approver.TeamID = p.Team.ID
+	}
+	return approver
 }
 
 func parsePayload(body []byte) (slackPayload, error) {
@@ -5,27 +5,47 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/davidahmann/relia/pkg/types"
 )
 
 type testApprover struct {
 	approvalID string
+	approver   types.Approver
 	status     string
 }
 
-func (t *testApprover) Approve(approvalID string, status string, createdAt string) (string, error) {
+func (t *testApprover) Approve(approvalID string, approver types.Approver, status string, createdAt string) (string, error) {
 	t.approvalID = approvalID
+	t.approver = approver
 	t.status = status
 	return "receipt-1", nil
 }
 
+const testSigningSecret = "secret"
+
+// signedInteraction builds an interactions request signed with testSigningSecret.
+func signedInteraction(body string) *http.Request {
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	_, _ = mac.Write([]byte("v0:" + timestamp + ":" + body))
+	req := httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString(body))
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
 func TestHandleInteractionsApprove(t *testing.T) {
-	payload := `{"actions":[{"action_id":"approve","value":"appr-1"}]}`
+	payload := `{"actions":[{"action_id":"approve","value":"appr-1"}],"user":{"id":"U123","username":"alice","name":"Alice","team_id":"T1"}}`
 	form := url.Values{}
 	form.Set("payload", payload)
 	body := []byte(form.Encode())
@@ -54,6 +74,10 @@ func TestHandleInteractionsApprove(t *testing.T) {
 	if approver.approvalID != "appr-1" || approver.status != "approved" {
 		t.Fatalf("unexpected approval %s %s", approver.approvalID, approver.status)
 	}
+	want := types.Approver{Kind: "slack", ID: "U123", Display: "alice", TeamID: "T1"}
+	if !reflect.DeepEqual(approver.approver, want) {
+		t.Fatalf("unexpected approver %+v", approver.approver)
+	}
 }
 
 func TestHandleInteractionsDenyFallbackValue(t *testing.T) {
@@ -63,10 +87,9 @@ func TestHandleInteractionsDenyFallbackValue(t *testing.T) {
 	body := []byte(form.Encode())
 
 	approver := &testApprover{}
-	h := &InteractionHandler{Approver: approver}
+	h := &InteractionHandler{SigningSecret: testSigningSecret, Approver: approver}
 
-	req := httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req := signedInteraction(string(body))
 
 	res := httptest.NewRecorder()
 	h.HandleInteractions(res, req)
@@ -81,10 +104,9 @@ func TestHandleInteractionsDenyFallbackValue(t *testing.T) {
 
 func TestHandleInteractionsMissingPayload(t *testing.T) {
 	approver := &testApprover{}
-	h := &InteractionHandler{Approver: approver}
+	h := &InteractionHandler{SigningSecret: testSigningSecret, Approver: approver}
 
-	req := httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString("payload="))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req := signedInteraction("payload=")
 
 	res := httptest.NewRecorder()
 	h.HandleInteractions(res, req)
@@ -117,7 +139,7 @@ func TestHandleInteractionsInvalidSignature(t *testing.T) {
 
 type errorApprover struct{}
 
-func (e errorApprover) Approve(string, string, string) (string, error) {
+func (e errorApprover) Approve(string, types.Approver, string, string) (string, error) {
 	return "", fmt.Errorf("boom")
 }
 
@@ -129,12 +151,23 @@ func TestHandleInteractionsNotConfigured(t *testing.T) {
 	if res.Code != http.StatusNotImplemented {
 		t.Fatalf("expected 501, got %d", res.Code)
 	}
+
+	// Without a signing secret, unsigned payloads could name any Slack user as voter.
+	approver := &testApprover{}
+	h = &InteractionHandler{Approver: approver}
+	form := url.Values{"payload": {`{"actions":[{"value":"approve:appr-1"}],"user":{"id":"U1"}}`}}
+	req = httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res = httptest.NewRecorder()
+	h.HandleInteractions(res, req)
+	if res.Code != http.StatusNotImplemented || approver.approvalID != "" {
+		t.Fatalf("expected 501 and no vote without a signing secret, got %d %+v", res.Code, approver)
+	}
 }
 
 func TestHandleInteractionsBadQuery(t *testing.T) {
-	h := &InteractionHandler{Approver: &testApprover{}}
-	req := httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString("%zz"))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h := &InteractionHandler{SigningSecret: testSigningSecret, Approver: &testApprover{}}
+	req := signedInteraction("%zz")
 	res := httptest.NewRecorder()
 	h.HandleInteractions(res, req)
 	if res.Code != http.StatusBadRequest {
@@ -144,9 +177,8 @@ func TestHandleInteractionsBadQuery(t *testing.T) {
 
 func TestHandleInteractionsInvalidPayloadJSON(t *testing.T) {
 	approver := &testApprover{}
-	h := &InteractionHandler{Approver: approver}
-	req := httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString("payload={invalid"))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h := &InteractionHandler{SigningSecret: testSigningSecret, Approver: approver}
+	req := signedInteraction("payload={invalid")
 	res := httptest.NewRecorder()
 	h.HandleInteractions(res, req)
 	if res.Code != http.StatusBadRequest {
@@ -156,12 +188,11 @@ func TestHandleInteractionsInvalidPayloadJSON(t *testing.T) {
 
 func TestHandleInteractionsMissingActions(t *testing.T) {
 	approver := &testApprover{}
-	h := &InteractionHandler{Approver: approver}
+	h := &InteractionHandler{SigningSecret: testSigningSecret, Approver: approver}
 
 	form := url.Values{}
 	form.Set("payload", `{"actions":[]}`)
-	req := httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString(form.Encode()))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req := signedInteraction(form.Encode())
 	res := httptest.NewRecorder()
 	h.HandleInteractions(res, req)
 	if res.Code != http.StatusBadRequest {
@@ -171,12 +202,11 @@ func TestHandleInteractionsMissingActions(t *testing.T) {
 
 func TestHandleInteractionsParseActionErrors(t *testing.T) {
 	approver := &testApprover{}
-	h := &InteractionHandler{Approver: approver}
+	h := &InteractionHandler{SigningSecret: testSigningSecret, Approver: approver}
 
 	form := url.Values{}
 	form.Set("payload", `{"actions":[{"value":""}]}`)
-	req := httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString(form.Encode()))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req := signedInteraction(form.Encode())
 	res := httptest.NewRecorder()
 	h.HandleInteractions(res, req)
 	if res.Code != http.StatusBadRequest {
@@ -185,8 +215,7 @@ func TestHandleInteractionsParseActionErrors(t *testing.T) {
 
 	form = url.Values{}
 	form.Set("payload", `{"actions":[{"value":"not-colon"}]}`)
-	req = httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString(form.Encode()))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = signedInteraction(form.Encode())
 	res = httptest.NewRecorder()
 	h.HandleInteractions(res, req)
 	if res.Code != http.StatusBadRequest {
@@ -199,12 +228,72 @@ func TestHandleInteractionsApproverError(t *testing.T) {
 	form := url.Values{}
 	form.Set("payload", payload)
 
-	h := &InteractionHandler{Approver: errorApprover{}}
-	req := httptest.NewRequest(http.MethodPost, "/v1/slack/interactions", bytes.NewBufferString(form.Encode()))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h := &InteractionHandler{SigningSecret: testSigningSecret, Approver: errorApprover{}}
+	req := signedInteraction(form.Encode())
 	res := httptest.NewRecorder()
 	h.HandleInteractions(res, req)
 	if res.Code != http.StatusInternalServerError {
 		t.Fatalf("expected 500, got %d", res.Code)
 	}
 }
+
+func TestSlackPayloadApproverFallbacks(t *testing.T) {
+	payload, err := parsePayload([]byte(url.Values{"payload": {`{"actions":[{"value":"approve:a1"}],"user":{"id":"U9","name":"bob"},"team":{"id":"T9"}}`}}.Encode()))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := types.Approver{Kind: "slack", ID: "U9", Display: "bob", TeamID: "T9"}
+	if got := payload.approver(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected approver %+v", got)
+	}
+}
+
+type rejectedVote struct{}
+
+func (rejectedVote) Error() string      { return "approver is not allowed to approve this request" }
+func (rejectedVote) VoteRejected() bool { return true }
+
+type rejectingApprover struct {
+	approver types.Approver
+}
+
+func (r *rejectingApprover) Approve(_ string, approver types.Approver, _, _ string) (string, error) {
+	r.approver = approver
+	return "", fmt.Errorf("vote: %w", rejectedVote{})
+}
+
+type staticGroups map[string][]string
+
+func (g staticGroups) UserGroups(userID string) ([]string, error) {
+	return g[userID], nil
+}
+
+func TestHandleInteractionsRejectedVoteRepliesEphemeral(t *testing.T) {
+	var reply map[string]any
+	responses := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&reply); err != nil {
+			t.Fatalf("decode reply: %v", err)
+		}
+	}))
+	defer responses.Close()
+
+	payload := `{"actions":[{"action_id":"approve","value":"appr-1"}],"user":{"id":"U7"},"response_url":"` + responses.URL + `/hooks"}`
+	form := url.Values{}
+	form.Set("payload", payload)
+
+	approver := &rejectingApprover{}
+	h := &InteractionHandler{SigningSecret: testSigningSecret, Approver: approver, Groups: staticGroups{"U7": {"S_DEV"}}, HTTP: responses.Client()}
+	req := signedInteraction(form.Encode())
+	res := httptest.NewRecorder()
+	h.HandleInteractions(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.Code)
+	}
+	if !reflect.DeepEqual(approver.approver.Groups, []string{"S_DEV"}) {
+		t.Fatalf("expected resolved groups, got %+v", approver.approver)
+	}
+	if reply["response_type"] != "ephemeral" || reply["replace_original"] != false || reply["text"] != "Your vote was not counted: vote: approver is not allowed to approve this request" {
+		t.Fatalf("unexpected ephemeral reply: %+v", reply)
+	}
+}
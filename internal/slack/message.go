@@ -1,23 +1,114 @@
 package slack
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
 
-type ApprovalMessageInput struct {
-	ApprovalID string
-	ReceiptID  string
-	PolicyHash string
-	ContextID  string
-	DecisionID string
-	Action     string
-	Resource   string
-	Env        string
-	Risk       string
-	DiffURL    string
-	RunURL     string
+	"github.com/davidahmann/relia/internal/notify"
+)
+
+// ApprovalMessageInput is the transport-neutral approval message.
+type ApprovalMessageInput = notify.ApprovalMessage
+
+// ApprovalResolution describes how an approval ended, for updating its Slack message.
+type ApprovalResolution struct {
+	Status string // approved | denied | expired
+	By     string // who decided; empty when the approval expired
+	At     string // RFC3339
+	// ReceiptURL links to the verify page of the receipt that recorded the outcome.
+	ReceiptURL string
 }
 
 // BuildApprovalMessage returns Slack Block Kit JSON for an approval request.
 func BuildApprovalMessage(input ApprovalMessageInput) ([]byte, error) {
+	blocks := approvalBlocks(input)
+	if mentions := mentionText(input.Mentions); mentions != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": mentions},
+		})
+	}
+	blocks = append(blocks, map[string]any{
+		"type": "actions",
+		"elements": []map[string]any{
+			{
+				"type":      "button",
+				"text":      map[string]any{"type": "plain_text", "text": "Approve"},
+				"style":     "primary",
+				"action_id": "approve",
+				"value":     input.ApprovalID,
+			},
+			{
+				"type":      "button",
+				"text":      map[string]any{"type": "plain_text", "text": "Deny"},
+				"style":     "danger",
+				"action_id": "deny",
+				"value":     input.ApprovalID,
+			},
+		},
+	})
+
+	payload := map[string]any{
+		"blocks": blocks,
+	}
+
+	return json.Marshal(payload)
+}
+
+// BuildResolvedMessage returns the approval message with its buttons replaced by how
+// the approval ended, so nobody votes on a decided approval.
+func BuildResolvedMessage(input ApprovalMessageInput, resolution ApprovalResolution) ([]byte, error) {
+	text := "Expired"
+	switch resolution.Status {
+	case "approved":
+		text = "Approved"
+	case "denied":
+		text = "Denied"
+	}
+	if resolution.By != "" {
+		text += " by " + resolution.By
+	}
+	if resolution.At != "" {
+		text += " at " + resolution.At
+	}
+	if resolution.ReceiptURL != "" {
+		text += " · <" + resolution.ReceiptURL + "|View receipt>"
+	}
+
+	blocks := append(approvalBlocks(input), map[string]any{
+		"type": "context",
+		"elements": []map[string]any{
+			{"type": "mrkdwn", "text": text},
+		},
+	})
+
+	payload := map[string]any{
+		"text":   "Relia approval " + resolution.Status,
+		"blocks": blocks,
+	}
+
+	return json.Marshal(payload)
+}
+
+// mentionText renders mentions in Slack syntax: user group IDs as <!subteam^S...>,
+// user IDs as <@U...>, and "here" or "channel" as themselves.
+func mentionText(mentions []string) string {
+	parts := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		switch {
+		case mention == "here" || mention == "channel":
+			parts = append(parts, "<!"+mention+">")
+		case strings.HasPrefix(mention, "S"):
+			parts = append(parts, "<!subteam^"+mention+">")
+		case mention != "":
+			parts = append(parts, "<@"+mention+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// approvalBlocks returns the blocks describing the request, without any buttons.
+func approvalBlocks(input ApprovalMessageInput) []map[string]any {
 	blocks := []map[string]any{
 		{
 			"type": "section",
@@ -53,30 +144,5 @@ func BuildApprovalMessage(input ApprovalMessageInput) ([]byte, error) {
 			},
 		})
 	}
-
-	blocks = append(blocks, map[string]any{
-		"type": "actions",
-		"elements": []map[string]any{
-			{
-				"type":      "button",
-				"text":      map[string]any{"type": "plain_text", "text": "Approve"},
-				"style":     "primary",
-				"action_id": "approve",
-				"value":     input.ApprovalID,
-			},
-			{
-				"type":      "button",
-				"text":      map[string]any{"type": "plain_text", "text": "Deny"},
-				"style":     "danger",
-				"action_id": "deny",
-				"value":     input.ApprovalID,
-			},
-		},
-	})
-
-	payload := map[string]any{
-		"blocks": blocks,
-	}
-
-	return json.Marshal(payload)
+	return blocks
 }
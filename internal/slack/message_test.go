@@ -1,6 +1,10 @@
 package slack
 
-import "testing"
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
 
 func TestBuildApprovalMessage(t *testing.T) {
 	payload, err := BuildApprovalMessage(ApprovalMessageInput{
@@ -33,3 +37,70 @@ func TestBuildApprovalMessageMinimal(t *testing.T) {
 		t.Fatalf("expected payload")
 	}
 }
+
+func TestBuildApprovalMessageMentions(t *testing.T) {
+	payload, err := BuildApprovalMessage(ApprovalMessageInput{
+		ApprovalID: "appr-3",
+		Action:     "db.migrate",
+		Resource:   "db/orders",
+		Env:        "prod",
+		Mentions:   []string{"S0614TZR7", "U024BE7LH", "here"},
+	})
+	if err != nil {
+		t.Fatalf("message: %v", err)
+	}
+	var decoded struct {
+		Blocks []struct {
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	found := false
+	for _, block := range decoded.Blocks {
+		found = found || block.Text.Text == "<!subteam^S0614TZR7> <@U024BE7LH> <!here>"
+	}
+	if !found {
+		t.Fatalf("expected mentions in message: %s", payload)
+	}
+}
+
+func TestBuildResolvedMessage(t *testing.T) {
+	input := ApprovalMessageInput{ApprovalID: "appr-3", Action: "deploy", Resource: "res", Env: "prod"}
+	payload, err := BuildResolvedMessage(input, ApprovalResolution{Status: "expired", At: "2025-12-20T16:30:00Z"})
+	if err != nil {
+		t.Fatalf("message: %v", err)
+	}
+	if strings.Contains(string(payload), `"actions"`) {
+		t.Fatalf("expected buttons removed: %s", payload)
+	}
+	if !strings.Contains(string(payload), "Expired at 2025-12-20T16:30:00Z") {
+		t.Fatalf("expected expiry text: %s", payload)
+	}
+}
+
+func TestBuildResolvedMessageApproved(t *testing.T) {
+	input := ApprovalMessageInput{ApprovalID: "appr-4", Action: "deploy", Resource: "res", Env: "prod"}
+	payload, err := BuildResolvedMessage(input, ApprovalResolution{Status: "approved", By: "<@U1>", At: "2025-12-20T16:30:00Z", ReceiptURL: "https://relia.example/verify/r1"})
+	if err != nil {
+		t.Fatalf("message: %v", err)
+	}
+	var msg struct {
+		Blocks []struct {
+			Type     string `json:"type"`
+			Elements []struct {
+				Text string `json:"text"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	last := msg.Blocks[len(msg.Blocks)-1]
+	if last.Type != "context" || last.Elements[0].Text != "Approved by <@U1> at 2025-12-20T16:30:00Z · <https://relia.example/verify/r1|View receipt>" {
+		t.Fatalf("expected approver and receipt link: %s", payload)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/davidahmann/relia/internal/ledger"
+	"github.com/davidahmann/relia/internal/outbox"
 )
 
 type flakyPoster struct {
@@ -23,137 +24,135 @@ func (p *flakyPoster) PostApproval(channel string, message ApprovalMessageInput)
 	return "1700000000.1234", nil
 }
 
-func TestProcessOutboxDue_RetryThenSuccess(t *testing.T) {
-	store := ledger.NewInMemoryStore()
-
-	approval := ledger.ApprovalRecord{
-		ApprovalID: "a1",
-		IdemKey:    "idem1",
-		Status:     "pending",
-		CreatedAt:  "now",
-		UpdatedAt:  "now",
+func putSlackPost(t *testing.T, store ledger.Store, payload []byte, now time.Time) {
+	t.Helper()
+	rec := ledger.OutboxRecord{
+		OutboxID:      "slack_post:a1",
+		Kind:          outbox.KindSlackPost,
+		ApprovalID:    "a1",
+		Target:        "C1",
+		PayloadJSON:   payload,
+		Status:        outbox.StatusPending,
+		NextAttemptAt: now.Format(time.RFC3339),
+		CreatedAt:     now.Format(time.RFC3339),
+		UpdatedAt:     now.Format(time.RFC3339),
+	}
+	if err := store.PutOutbox(rec); err != nil {
+		t.Fatalf("put outbox: %v", err)
 	}
-	if err := store.PutApproval(approval); err != nil {
+}
+
+func TestDeliverPost_RetryThenSuccess(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	if err := store.PutApproval(ledger.ApprovalRecord{ApprovalID: "a1", IdemKey: "idem1", Status: "pending", CreatedAt: "now", UpdatedAt: "now"}); err != nil {
 		t.Fatalf("put approval: %v", err)
 	}
-
-	input := ApprovalMessageInput{ApprovalID: "a1", Action: "x", Env: "prod", Resource: "r", Risk: "high"}
-	msgBytes, _ := json.Marshal(input)
-
+	msgBytes, _ := json.Marshal(ApprovalMessageInput{ApprovalID: "a1", Action: "x", Env: "prod", Resource: "r", Risk: "high"})
 	now := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
-	rec := ledger.SlackOutboxRecord{
-		NotificationID: "slack:a1",
-		ApprovalID:     "a1",
-		Channel:        "C1",
-		MessageJSON:    msgBytes,
-		Status:         OutboxStatusPending,
-		AttemptCount:   0,
-		NextAttemptAt:  now.Format(time.RFC3339),
-		CreatedAt:      now.Format(time.RFC3339),
-		UpdatedAt:      now.Format(time.RFC3339),
-	}
-	if err := store.PutSlackOutbox(rec); err != nil {
-		t.Fatalf("put outbox: %v", err)
-	}
+	putSlackPost(t, store, msgBytes, now)
 
 	poster := &flakyPoster{fail: 1}
-	if n, err := ProcessOutboxDue(context.Background(), store, poster, now, 10); err != nil || n != 1 {
+	w := &outbox.Worker{Store: store, Kind: outbox.KindSlackPost, Deliver: DeliverPost(store, poster)}
+	if n, err := w.ProcessDue(context.Background(), now, 10); err != nil || n != 1 {
 		t.Fatalf("process: n=%d err=%v", n, err)
 	}
-
-	afterFail, ok := store.GetSlackOutbox("slack:a1")
-	if !ok || afterFail.AttemptCount != 1 || afterFail.Status != OutboxStatusPending || afterFail.LastError == nil {
+	afterFail, ok := store.GetOutbox("slack_post:a1")
+	if !ok || afterFail.AttemptCount != 1 || afterFail.Status != outbox.StatusPending || afterFail.LastError == nil {
 		t.Fatalf("unexpected after fail: %+v ok=%v", afterFail, ok)
 	}
 
-	// Move time forward to next attempt.
-	now2 := now.Add(10 * time.Second)
-	afterFail.NextAttemptAt = now2.Add(-1 * time.Second).Format(time.RFC3339)
-	if err := store.PutSlackOutbox(afterFail); err != nil {
-		t.Fatalf("put outbox: %v", err)
-	}
-
-	if n, err := ProcessOutboxDue(context.Background(), store, poster, now2, 10); err != nil || n != 1 {
+	if n, err := w.ProcessDue(context.Background(), now.Add(10*time.Second), 10); err != nil || n != 1 {
 		t.Fatalf("process2: n=%d err=%v", n, err)
 	}
+	final, _ := store.GetOutbox("slack_post:a1")
+	if final.Status != outbox.StatusSent || final.SentAt == nil {
+		t.Fatalf("unexpected final: %+v", final)
+	}
+	approval, _ := store.GetApproval("a1")
+	if approval.SlackMsgTS == nil || *approval.SlackMsgTS != "1700000000.1234" || approval.SlackChannel == nil || *approval.SlackChannel != "C1" {
+		t.Fatalf("expected slack message on approval: %+v", approval)
+	}
+}
 
-	final, ok := store.GetSlackOutbox("slack:a1")
-	if !ok || final.Status != OutboxStatusSent || final.SentAt == nil {
-		t.Fatalf("unexpected final: %+v ok=%v", final, ok)
+// votingPoster approves the approval while its message is being posted.
+type votingPoster struct {
+	store ledger.Store
+}
+
+func (p votingPoster) PostApproval(channel string, message ApprovalMessageInput) (string, error) {
+	approval, _ := p.store.GetApproval(message.ApprovalID)
+	approval.Status = "approved"
+	approval.Votes = append(approval.Votes, ledger.ApprovalVote{ApproverKind: "oidc", ApproverID: "https://sso.example.com|alice", Status: "approved", ReceiptID: "r2"})
+	if err := p.store.PutApproval(approval); err != nil {
+		return "", err
 	}
+	return "1700000000.1234", nil
+}
 
-	updatedApproval, ok := store.GetApproval("a1")
-	if !ok || updatedApproval.SlackMsgTS == nil || *updatedApproval.SlackMsgTS == "" {
-		t.Fatalf("expected slack msg ts on approval: %+v ok=%v", updatedApproval, ok)
+func TestDeliverPost_KeepsVotesCastWhilePosting(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	if err := store.PutApproval(ledger.ApprovalRecord{ApprovalID: "a1", IdemKey: "idem1", Status: "pending", CreatedAt: "now", UpdatedAt: "now"}); err != nil {
+		t.Fatalf("put approval: %v", err)
+	}
+	msgBytes, _ := json.Marshal(ApprovalMessageInput{ApprovalID: "a1"})
+	if err := DeliverPost(store, votingPoster{store: store})(context.Background(), ledger.OutboxRecord{ApprovalID: "a1", Target: "C1", PayloadJSON: msgBytes}); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	approval, _ := store.GetApproval("a1")
+	if approval.Status != "approved" || len(approval.Votes) != 1 || approval.SlackMsgTS == nil || *approval.SlackMsgTS != "1700000000.1234" {
+		t.Fatalf("expected the vote kept and the message recorded, got %+v", approval)
 	}
 }
 
-func TestProcessOutboxDue_InvalidJSONMarksSent(t *testing.T) {
+func TestDeliverPost_InvalidJSONDeadLetters(t *testing.T) {
 	store := ledger.NewInMemoryStore()
 	_ = store.PutApproval(ledger.ApprovalRecord{ApprovalID: "a1", IdemKey: "idem1", Status: "pending", CreatedAt: "now", UpdatedAt: "now"})
-
 	now := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
-	rec := ledger.SlackOutboxRecord{
-		NotificationID: "slack:a1",
-		ApprovalID:     "a1",
-		Channel:        "C1",
-		MessageJSON:    []byte("not-json"),
-		Status:         OutboxStatusPending,
-		AttemptCount:   0,
-		NextAttemptAt:  now.Format(time.RFC3339),
-		CreatedAt:      now.Format(time.RFC3339),
-		UpdatedAt:      now.Format(time.RFC3339),
-	}
-	_ = store.PutSlackOutbox(rec)
-
-	poster := &flakyPoster{fail: 0}
-	if _, err := ProcessOutboxDue(context.Background(), store, poster, now, 10); err != nil {
+	putSlackPost(t, store, []byte("not-json"), now)
+
+	poster := &flakyPoster{}
+	w := &outbox.Worker{Store: store, Kind: outbox.KindSlackPost, Deliver: DeliverPost(store, poster)}
+	if _, err := w.ProcessDue(context.Background(), now, 10); err != nil {
 		t.Fatalf("process: %v", err)
 	}
-	got, _ := store.GetSlackOutbox("slack:a1")
-	if got.Status != OutboxStatusSent {
-		t.Fatalf("expected sent, got %s", got.Status)
+	got, _ := store.GetOutbox("slack_post:a1")
+	if got.Status != outbox.StatusDead || got.LastError == nil || poster.calls != 0 {
+		t.Fatalf("expected dead without posting, got %+v calls=%d", got, poster.calls)
 	}
 }
 
-func TestNextAttemptCapped(t *testing.T) {
-	if got := nextAttempt(0); got != 5*time.Second {
-		t.Fatalf("expected 5s, got %v", got)
+func TestDeliverPost_SkipsResolvedApproval(t *testing.T) {
+	store := ledger.NewInMemoryStore()
+	if err := store.PutApproval(ledger.ApprovalRecord{ApprovalID: "a1", IdemKey: "idem1", Status: "expired", CreatedAt: "now", UpdatedAt: "now"}); err != nil {
+		t.Fatalf("put approval: %v", err)
 	}
-	if got := nextAttempt(1); got != 10*time.Second {
-		t.Fatalf("expected 10s, got %v", got)
+	msgBytes, _ := json.Marshal(ApprovalMessageInput{ApprovalID: "a1"})
+	now := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+	putSlackPost(t, store, msgBytes, now)
+
+	poster := &flakyPoster{}
+	if err := DeliverPost(store, poster)(context.Background(), ledger.OutboxRecord{ApprovalID: "a1", PayloadJSON: msgBytes}); err != nil {
+		t.Fatalf("deliver: %v", err)
 	}
-	if got := nextAttempt(20); got != 5*time.Minute {
-		t.Fatalf("expected cap 5m, got %v", got)
+	if poster.calls != 0 {
+		t.Fatalf("expected no post for a resolved approval, got %d", poster.calls)
 	}
 }
 
-func TestRunOutboxWorker(t *testing.T) {
+func TestRunWorker(t *testing.T) {
 	store := ledger.NewInMemoryStore()
 	_ = store.PutApproval(ledger.ApprovalRecord{ApprovalID: "a1", IdemKey: "idem1", Status: "pending", CreatedAt: "now", UpdatedAt: "now"})
-	_ = store.PutSlackOutbox(ledger.SlackOutboxRecord{
-		NotificationID: "slack:a1",
-		ApprovalID:     "a1",
-		Channel:        "C1",
-		MessageJSON:    []byte(`{"approval_id":"a1","action":"x","resource":"r","env":"prod","risk":"high"}`),
-		Status:         OutboxStatusPending,
-		AttemptCount:   0,
-		NextAttemptAt:  time.Now().UTC().Add(-time.Second).Format(time.RFC3339),
-		CreatedAt:      "now",
-		UpdatedAt:      "now",
-	})
-
-	poster := &flakyPoster{fail: 0}
+	putSlackPost(t, store, []byte(`{"approval_id":"a1","action":"x","resource":"r","env":"prod","risk":"high"}`), time.Now().UTC().Add(-time.Second))
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-
-	go RunOutboxWorker(ctx, store, poster, 5*time.Millisecond)
+	w := &outbox.Worker{Store: store, Kind: outbox.KindSlackPost, Deliver: DeliverPost(store, &flakyPoster{})}
+	go w.Run(ctx, 5*time.Millisecond)
 
 	deadline := time.Now().Add(500 * time.Millisecond)
 	for time.Now().Before(deadline) {
 		approval, ok := store.GetApproval("a1")
 		if ok && approval.SlackMsgTS != nil && *approval.SlackMsgTS != "" {
-			cancel()
 			return
 		}
 		time.Sleep(5 * time.Millisecond)
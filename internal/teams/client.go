@@ -0,0 +1,61 @@
+package teams
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client posts approval cards to a Teams channel through an incoming webhook.
+type Client struct {
+	// WebhookURL is the default channel's incoming webhook (or Workflows) URL.
+	WebhookURL string
+	// Channels maps channel aliases, which policy rules name in approval_channel, to
+	// their incoming webhook URLs. The URLs stay in gateway config because anyone
+	// holding one can post to the channel.
+	Channels map[string]string
+	HTTP     *http.Client
+}
+
+// HasChannel reports whether alias names a configured channel.
+func (c *Client) HasChannel(alias string) bool {
+	_, ok := c.Channels[alias]
+	return ok
+}
+
+// PostApproval posts the approval card to the channel alias, or to WebhookURL when
+// channel is empty. Incoming webhooks return no message ID, so the returned reference
+// is always empty.
+func (c *Client) PostApproval(channel string, message ApprovalMessageInput) (string, error) {
+	url := c.WebhookURL
+	if channel != "" {
+		var ok bool
+		if url, ok = c.Channels[channel]; !ok {
+			return "", fmt.Errorf("unknown teams channel %q", channel)
+		}
+	}
+	if url == "" {
+		return "", fmt.Errorf("missing teams webhook url")
+	}
+	msgBytes, err := BuildApprovalCard(message)
+	if err != nil {
+		return "", err
+	}
+
+	if c.HTTP == nil {
+		c.HTTP = &http.Client{Timeout: 10 * time.Second}
+	}
+	res, err := c.HTTP.Post(url, "application/json", bytes.NewReader(msgBytes))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return "", fmt.Errorf("teams webhook error: status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return "", nil
+}
@@ -0,0 +1,69 @@
+package teams
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientPostApproval(t *testing.T) {
+	var got map[string]any
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("bad card"))
+			return
+		}
+		_, _ = w.Write([]byte("1"))
+	}))
+	defer server.Close()
+
+	client := &Client{WebhookURL: server.URL + "/hook", HTTP: server.Client()}
+	if _, err := client.PostApproval("", ApprovalMessageInput{ApprovalID: "a1"}); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if hits != 1 || got["type"] != "message" {
+		t.Fatalf("expected a card post, got %d %v", hits, got)
+	}
+
+	client.WebhookURL = server.URL + "/fail"
+	if _, err := client.PostApproval("", ApprovalMessageInput{ApprovalID: "a1"}); err == nil || hits != 2 {
+		t.Fatalf("expected an error for a rejected post, got hits=%d err=%v", hits, err)
+	}
+
+	if _, err := (&Client{}).PostApproval("", ApprovalMessageInput{ApprovalID: "a1"}); err == nil {
+		t.Fatalf("expected an error without a webhook url")
+	}
+}
+
+func TestClientPostApprovalChannelAlias(t *testing.T) {
+	var hits int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path != "/data" {
+			t.Errorf("expected the channel webhook, got %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{WebhookURL: server.URL + "/default", Channels: map[string]string{"data": server.URL + "/data"}, HTTP: server.Client()}
+	if !client.HasChannel("data") || client.HasChannel("ops") {
+		t.Fatalf("unexpected channel lookup")
+	}
+	if _, err := client.PostApproval("data", ApprovalMessageInput{ApprovalID: "a1"}); err != nil || hits != 1 {
+		t.Fatalf("post: hits=%d err=%v", hits, err)
+	}
+	// An unknown alias is an error rather than a post to the default channel, and a URL
+	// is never used as one.
+	for _, channel := range []string{"ops", server.URL + "/data"} {
+		if _, err := client.PostApproval(channel, ApprovalMessageInput{ApprovalID: "a1"}); err == nil || hits != 1 {
+			t.Fatalf("expected an error for %q, got hits=%d err=%v", channel, hits, err)
+		}
+	}
+}
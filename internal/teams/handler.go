@@ -0,0 +1,140 @@
+package teams
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+type Approver interface {
+	Approve(approvalID string, approver types.Approver, status string, createdAt string) (string, error)
+}
+
+// InteractionHandler serves a Teams outgoing webhook: approvers mention it with
+// "approve <approval_id>" or "deny <approval_id>" and get the outcome as the reply.
+type InteractionHandler struct {
+	// SecurityToken is the outgoing webhook's base64 security token. Requests are
+	// rejected while it is unset, since the signature is their only authentication.
+	SecurityToken string
+	Approver      Approver
+	Now           func() time.Time
+}
+
+// voteRejected is implemented by Approver errors for votes the user may not cast.
+type voteRejected interface {
+	VoteRejected() bool
+}
+
+type activity struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	From struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		AADObjectID string `json:"aadObjectId"`
+	} `json:"from"`
+	ChannelData struct {
+		Tenant struct {
+			ID string `json:"id"`
+		} `json:"tenant"`
+	} `json:"channelData"`
+}
+
+const usage = "Usage: approve <approval_id> or deny <approval_id>"
+
+func (h *InteractionHandler) HandleInteractions(w http.ResponseWriter, r *http.Request) {
+	if h.Approver == nil || h.SecurityToken == "" {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := VerifySignature(h.SecurityToken, r.Header.Get("Authorization"), body); err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var act activity
+	if err := json.Unmarshal(body, &act); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	approvalID, action, ok := parseCommand(act.Text)
+	if !ok {
+		reply(w, usage)
+		return
+	}
+
+	status := "denied"
+	if action == "approve" {
+		status = "approved"
+	}
+
+	now := time.Now()
+	if h.Now != nil {
+		now = h.Now()
+	}
+
+	_, err = h.Approver.Approve(approvalID, act.approver(), status, now.UTC().Format(time.RFC3339))
+	var rejected voteRejected
+	if errors.As(err, &rejected) && rejected.VoteRejected() {
+		reply(w, "Your vote was not counted: "+err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	reply(w, "Recorded your "+action+" vote on "+approvalID+".")
+}
+
+// approver returns the Teams user who sent the command, identified by their Entra ID
+// object ID when Teams provides it.
+func (a activity) approver() types.Approver {
+	id := a.From.AADObjectID
+	if id == "" {
+		id = a.From.ID
+	}
+	return types.Approver{
+		Kind:    "teams",
+		ID:      id,
+		Display: a.From.Name,
+		TeamID:  a.ChannelData.Tenant.ID,
+	}
+}
+
+var mentionPattern = regexp.MustCompile(`<at>.*?</at>`)
+
+// parseCommand finds "approve <id>" or "deny <id>" in the message text, ignoring the
+// mention of the webhook and any HTML Teams wraps around it.
+func parseCommand(text string) (approvalID, action string, ok bool) {
+	text = mentionPattern.ReplaceAllString(text, " ")
+	text = strings.NewReplacer("&nbsp;", " ", "<p>", " ", "</p>", " ").Replace(text)
+	fields := strings.Fields(text)
+	for i, field := range fields {
+		action := strings.ToLower(field)
+		if (action == "approve" || action == "deny") && i+1 < len(fields) {
+			return fields[i+1], action, true
+		}
+	}
+	return "", "", false
+}
+
+// reply answers the outgoing webhook; Teams posts the text in the thread.
+func reply(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]string{"type": "message", "text": text})
+}
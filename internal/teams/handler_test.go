@@ -0,0 +1,95 @@
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/pkg/types"
+)
+
+type fakeApprover struct {
+	approvalID string
+	approver   types.Approver
+	status     string
+	err        error
+}
+
+func (f *fakeApprover) Approve(approvalID string, approver types.Approver, status string, createdAt string) (string, error) {
+	f.approvalID, f.approver, f.status = approvalID, approver, status
+	return "receipt", f.err
+}
+
+type rejectedErr struct{}
+
+func (rejectedErr) Error() string      { return "not an allowed approver" }
+func (rejectedErr) VoteRejected() bool { return true }
+
+func postActivity(t *testing.T, h *InteractionHandler, text, auth string) (int, string) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{
+		"type":        "message",
+		"text":        text,
+		"from":        map[string]any{"id": "29:1", "name": "Alice", "aadObjectId": "aad-1"},
+		"channelData": map[string]any{"tenant": map[string]any{"id": "tenant-1"}},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/teams/interactions", bytes.NewReader(body))
+	if auth == "" {
+		auth = sign(testToken, body)
+	}
+	req.Header.Set("Authorization", auth)
+	res := httptest.NewRecorder()
+	h.HandleInteractions(res, req)
+	var out map[string]string
+	_ = json.Unmarshal(res.Body.Bytes(), &out)
+	return res.Code, out["text"]
+}
+
+func TestHandleInteractionsApprove(t *testing.T) {
+	approver := &fakeApprover{}
+	h := &InteractionHandler{SecurityToken: testToken, Approver: approver, Now: func() time.Time { return time.Unix(0, 0) }}
+
+	code, text := postActivity(t, h, "<at>Relia</at>&nbsp;approve appr-1", "")
+	if code != http.StatusOK || text != "Recorded your approve vote on appr-1." {
+		t.Fatalf("unexpected reply: %d %q", code, text)
+	}
+	if approver.approvalID != "appr-1" || approver.status != "approved" {
+		t.Fatalf("unexpected vote: %+v", approver)
+	}
+	if approver.approver.Kind != "teams" || approver.approver.ID != "aad-1" || approver.approver.Display != "Alice" || approver.approver.TeamID != "tenant-1" {
+		t.Fatalf("unexpected approver: %+v", approver.approver)
+	}
+
+	if _, _ = postActivity(t, h, "<at>Relia</at> DENY appr-2", ""); approver.status != "denied" || approver.approvalID != "appr-2" {
+		t.Fatalf("expected a deny vote: %+v", approver)
+	}
+}
+
+func TestHandleInteractionsRejects(t *testing.T) {
+	approver := &fakeApprover{}
+	h := &InteractionHandler{SecurityToken: testToken, Approver: approver}
+
+	if code, _ := postActivity(t, h, "approve appr-1", "HMAC bad"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a bad signature, got %d", code)
+	}
+	if code, text := postActivity(t, h, "<at>Relia</at> hello", ""); code != http.StatusOK || text != usage || approver.approvalID != "" {
+		t.Fatalf("expected usage, got %d %q", code, text)
+	}
+
+	approver.err = rejectedErr{}
+	if code, text := postActivity(t, h, "approve appr-1", ""); code != http.StatusOK || text != "Your vote was not counted: not an allowed approver" {
+		t.Fatalf("expected a rejected vote reply, got %d %q", code, text)
+	}
+	approver.err = errors.New("boom")
+	if code, _ := postActivity(t, h, "approve appr-1", ""); code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", code)
+	}
+
+	if code, _ := postActivity(t, &InteractionHandler{Approver: approver}, "approve appr-1", ""); code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 without a security token, got %d", code)
+	}
+}
@@ -0,0 +1,59 @@
+package teams
+
+import (
+	"encoding/json"
+
+	"github.com/davidahmann/relia/internal/notify"
+)
+
+// ApprovalMessageInput is the transport-neutral approval message.
+type ApprovalMessageInput = notify.ApprovalMessage
+
+// BuildApprovalCard returns a Teams message carrying an Adaptive Card for an approval
+// request. Cards posted through an incoming webhook cannot submit actions, so the card
+// tells approvers to answer the Relia outgoing webhook instead.
+func BuildApprovalCard(input ApprovalMessageInput) ([]byte, error) {
+	facts := []map[string]any{
+		{"title": "Action", "value": input.Action},
+		{"title": "Env", "value": input.Env},
+		{"title": "Resource", "value": input.Resource},
+		{"title": "Risk", "value": input.Risk},
+		{"title": "Approval", "value": input.ApprovalID},
+	}
+
+	body := []map[string]any{
+		{"type": "TextBlock", "text": "Relia approval required", "weight": "Bolder", "size": "Medium", "wrap": true},
+		{"type": "FactSet", "facts": facts},
+		{
+			"type": "TextBlock",
+			"text": "Reply `@Relia approve " + input.ApprovalID + "` or `@Relia deny " + input.ApprovalID + "` to decide.",
+			"wrap": true,
+		},
+	}
+
+	var actions []map[string]any
+	if input.DiffURL != "" {
+		actions = append(actions, map[string]any{"type": "Action.OpenUrl", "title": "Diff", "url": input.DiffURL})
+	}
+	if input.RunURL != "" {
+		actions = append(actions, map[string]any{"type": "Action.OpenUrl", "title": "Run", "url": input.RunURL})
+	}
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if len(actions) > 0 {
+		card["actions"] = actions
+	}
+
+	payload := map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	}
+	return json.Marshal(payload)
+}
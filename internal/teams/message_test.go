@@ -0,0 +1,42 @@
+package teams
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildApprovalCard(t *testing.T) {
+	data, err := BuildApprovalCard(ApprovalMessageInput{ApprovalID: "a1", Action: "terraform.apply", Env: "prod", Resource: "res", Risk: "high", DiffURL: "https://example.test/diff"})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	var msg struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type    string `json:"type"`
+				Body    []map[string]any
+				Actions []map[string]any
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if msg.Type != "message" || len(msg.Attachments) != 1 || msg.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("unexpected message: %s", data)
+	}
+	card := msg.Attachments[0].Content
+	if card.Type != "AdaptiveCard" || len(card.Body) != 3 {
+		t.Fatalf("unexpected card: %s", data)
+	}
+	if text, _ := card.Body[2]["text"].(string); !strings.Contains(text, "approve a1") || !strings.Contains(text, "deny a1") {
+		t.Fatalf("expected vote instructions, got %q", text)
+	}
+	if len(card.Actions) != 1 || card.Actions[0]["url"] != "https://example.test/diff" {
+		t.Fatalf("expected a diff link, got %v", card.Actions)
+	}
+}
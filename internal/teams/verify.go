@@ -0,0 +1,37 @@
+package teams
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing teams signature")
+	ErrInvalidSignature = errors.New("invalid teams signature")
+)
+
+// VerifySignature validates an outgoing webhook request. Teams sends
+// "Authorization: HMAC <base64>", the HMAC-SHA256 of the body keyed with the
+// base64-decoded security token shown when the webhook was created.
+func VerifySignature(securityToken, authorization string, body []byte) error {
+	sig, ok := strings.CutPrefix(authorization, "HMAC ")
+	if !ok || sig == "" {
+		return ErrMissingSignature
+	}
+	key, err := base64.StdEncoding.DecodeString(securityToken)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package teams
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+func sign(token string, body []byte) string {
+	key, _ := base64.StdEncoding.DecodeString(token)
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(body)
+	return "HMAC " + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+var testToken = base64.StdEncoding.EncodeToString([]byte("teams-security-token"))
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"type":"message"}`)
+	if err := VerifySignature(testToken, sign(testToken, body), body); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if err := VerifySignature(testToken, sign(testToken, body), []byte(`{"type":"other"}`)); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+	if err := VerifySignature("not base64!", sign(testToken, body), body); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature for a bad token, got %v", err)
+	}
+}
+
+func TestVerifySignatureMissing(t *testing.T) {
+	for _, header := range []string{"", "Bearer x", "HMAC "} {
+		if err := VerifySignature(testToken, header, []byte("x")); err != ErrMissingSignature {
+			t.Fatalf("%q: expected ErrMissingSignature, got %v", header, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/relia/internal/notify"
+)
+
+// ApprovalRequest is the JSON body POSTed to the webhook for each approval.
+type ApprovalRequest struct {
+	Type       string `json:"type"`
+	ApprovalID string `json:"approval_id"`
+	ReceiptID  string `json:"receipt_id"`
+	PolicyHash string `json:"policy_hash,omitempty"`
+	Action     string `json:"action"`
+	Resource   string `json:"resource"`
+	Env        string `json:"env"`
+	Risk       string `json:"risk,omitempty"`
+	DiffURL    string `json:"diff_url,omitempty"`
+	RunURL     string `json:"run_url,omitempty"`
+}
+
+// ApprovalRequestType identifies approval requests among future webhook payloads.
+const ApprovalRequestType = "relia.approval_request"
+
+// Client posts signed approval requests to an external system such as a change
+// management tool.
+type Client struct {
+	URL    string
+	Signer Signer
+	HTTP   *http.Client
+	Now    func() time.Time
+}
+
+// PostApproval sends the approval request to URL; channel is not used. Webhooks return
+// no message reference, so the returned reference is always empty; any non-2xx response
+// is an error and is retried by the outbox.
+func (c *Client) PostApproval(channel string, message notify.ApprovalMessage) (string, error) {
+	if c.URL == "" {
+		return "", fmt.Errorf("missing webhook url")
+	}
+	if c.Signer == nil {
+		return "", fmt.Errorf("missing webhook signer")
+	}
+
+	body, err := json.Marshal(ApprovalRequest{
+		Type:       ApprovalRequestType,
+		ApprovalID: message.ApprovalID,
+		ReceiptID:  message.ReceiptID,
+		PolicyHash: message.PolicyHash,
+		Action:     message.Action,
+		Resource:   message.Resource,
+		Env:        message.Env,
+		Risk:       message.Risk,
+		DiffURL:    message.DiffURL,
+		RunURL:     message.RunURL,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature, err := c.Signer.Sign(PurposeApprovalRequest, timestamp, body)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, signature)
+	if keyed, ok := c.Signer.(interface{ KeyID() string }); ok {
+		req.Header.Set(HeaderKeyID, keyed.KeyID())
+	}
+
+	if c.HTTP == nil {
+		c.HTTP = &http.Client{Timeout: 10 * time.Second}
+	}
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return "", fmt.Errorf("webhook error: status %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return "", nil
+}
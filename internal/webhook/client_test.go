@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/davidahmann/relia/internal/notify"
+)
+
+func TestClientPostApproval(t *testing.T) {
+	now := time.Unix(1766246054, 0)
+	secret := HMAC{Secret: []byte("secret")}
+
+	var got ApprovalRequest
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := secret.Verify(PurposeApprovalRequest, r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp), body, now); err != nil {
+			t.Errorf("verify: %v", err)
+		}
+		_ = json.Unmarshal(body, &got)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, Signer: secret, HTTP: server.Client(), Now: func() time.Time { return now }}
+	msg := notify.ApprovalMessage{ApprovalID: "a1", ReceiptID: "r1", Action: "terraform.apply", Resource: "res", Env: "prod", Risk: "high", DiffURL: "https://example.test/diff"}
+	if _, err := client.PostApproval("", msg); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if got.Type != ApprovalRequestType || got.ApprovalID != "a1" || got.ReceiptID != "r1" || got.Env != "prod" || got.DiffURL != "https://example.test/diff" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+
+	status = http.StatusServiceUnavailable
+	if _, err := client.PostApproval("", msg); err == nil {
+		t.Fatalf("expected an error for a 503")
+	}
+
+	if _, err := (&Client{Signer: secret}).PostApproval("", msg); err == nil {
+		t.Fatalf("expected an error without a url")
+	}
+	if _, err := (&Client{URL: server.URL}).PostApproval("", msg); err == nil {
+		t.Fatalf("expected an error without a signer")
+	}
+}
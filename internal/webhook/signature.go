@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidahmann/relia/internal/ledger"
+)
+
+// Headers carried by signed requests in both directions.
+const (
+	HeaderTimestamp = "X-Relia-Timestamp"
+	HeaderSignature = "X-Relia-Signature"
+	// HeaderKeyID names the gateway key behind an Ed25519 signature.
+	HeaderKeyID = "X-Relia-Key-Id"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleTimestamp   = errors.New("stale webhook timestamp")
+)
+
+// Signature purposes, one per direction. The purpose is signed with the request, so an
+// approval request the gateway sent never verifies as a callback, even when both
+// directions share an HMAC secret.
+const (
+	// PurposeApprovalRequest signs approval requests the gateway sends.
+	PurposeApprovalRequest = "relia.approval_request"
+	// PurposeApprovalCallback signs votes posted back to the gateway.
+	PurposeApprovalCallback = "relia.approval_callback"
+)
+
+// A Signer produces the X-Relia-Signature value for a request. Signatures cover
+// "<purpose>.<timestamp>.<body>", so a captured request cannot be replayed later or
+// in the other direction.
+type Signer interface {
+	Sign(purpose, timestamp string, body []byte) (string, error)
+}
+
+// A Verifier checks the X-Relia-Signature value of a request made for purpose.
+type Verifier interface {
+	Verify(purpose, signature, timestamp string, body []byte, now time.Time) error
+}
+
+// HMAC signs and verifies with a shared secret, as "v1=<hex HMAC-SHA256>".
+type HMAC struct {
+	Secret []byte
+}
+
+func (h HMAC) Sign(purpose, timestamp string, body []byte) (string, error) {
+	if len(h.Secret) == 0 {
+		return "", errors.New("missing webhook hmac secret")
+	}
+	return "v1=" + hex.EncodeToString(h.mac(purpose, timestamp, body)), nil
+}
+
+func (h HMAC) Verify(purpose, signature, timestamp string, body []byte, now time.Time) error {
+	if err := checkTimestamp(signature, timestamp, now); err != nil {
+		return err
+	}
+	sig, ok := strings.CutPrefix(signature, "v1=")
+	if !ok || len(h.Secret) == 0 {
+		return ErrInvalidSignature
+	}
+	expected := hex.EncodeToString(h.mac(purpose, timestamp, body))
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (h HMAC) mac(purpose, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, h.Secret)
+	_, _ = mac.Write(signedPayload(purpose, timestamp, body))
+	return mac.Sum(nil)
+}
+
+// Ed25519Signer signs with the gateway's receipt signing key, as "ed25519=<base64>",
+// so receivers can verify requests with the same public key as receipts.
+type Ed25519Signer struct {
+	Key ledger.Signer
+}
+
+func (s Ed25519Signer) Sign(purpose, timestamp string, body []byte) (string, error) {
+	sig, err := s.Key.SignEd25519(signedPayload(purpose, timestamp, body))
+	if err != nil {
+		return "", err
+	}
+	return "ed25519=" + base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// KeyID is sent as X-Relia-Key-Id.
+func (s Ed25519Signer) KeyID() string {
+	return s.Key.KeyID()
+}
+
+// Ed25519Verifier verifies "ed25519=<base64>" signatures made by the sender's key.
+type Ed25519Verifier struct {
+	PublicKey ed25519.PublicKey
+}
+
+func (v Ed25519Verifier) Verify(purpose, signature, timestamp string, body []byte, now time.Time) error {
+	if err := checkTimestamp(signature, timestamp, now); err != nil {
+		return err
+	}
+	encoded, ok := strings.CutPrefix(signature, "ed25519=")
+	if !ok || len(v.PublicKey) != ed25519.PublicKeySize {
+		return ErrInvalidSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || !ed25519.Verify(v.PublicKey, signedPayload(purpose, timestamp, body), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signedPayload(purpose, timestamp string, body []byte) []byte {
+	return append([]byte(purpose+"."+timestamp+"."), body...)
+}
+
+// checkTimestamp rejects missing headers and timestamps more than five minutes from now.
+func checkTimestamp(signature, timestamp string, now time.Time) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	requestTime := time.Unix(ts, 0)
+	if now.Sub(requestTime) > 5*time.Minute || requestTime.Sub(now) > 5*time.Minute {
+		return ErrStaleTimestamp
+	}
+	return nil
+}
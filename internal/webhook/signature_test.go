@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"crypto/ed25519"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testKey struct{ priv ed25519.PrivateKey }
+
+func (k testKey) KeyID() string { return "test" }
+func (k testKey) SignEd25519(message []byte) ([]byte, error) {
+	return ed25519.Sign(k.priv, message), nil
+}
+
+func TestHMACSignVerify(t *testing.T) {
+	now := time.Unix(1766246054, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"approval_id":"a1"}`)
+	h := HMAC{Secret: []byte("secret")}
+
+	sig, err := h.Sign(PurposeApprovalCallback, ts, body)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := h.Verify(PurposeApprovalCallback, sig, ts, body, now); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if err := h.Verify(PurposeApprovalCallback, sig, ts, []byte(`{"approval_id":"a2"}`), now); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature for a changed body, got %v", err)
+	}
+	if err := (HMAC{Secret: []byte("other")}).Verify(PurposeApprovalCallback, sig, ts, body, now); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature for another secret, got %v", err)
+	}
+	if err := h.Verify(PurposeApprovalCallback, sig, ts, body, now.Add(10*time.Minute)); err != ErrStaleTimestamp {
+		t.Fatalf("expected ErrStaleTimestamp, got %v", err)
+	}
+	if err := h.Verify(PurposeApprovalCallback, "", ts, body, now); err != ErrMissingSignature {
+		t.Fatalf("expected ErrMissingSignature, got %v", err)
+	}
+	// A request the gateway signed does not verify as a callback under the same secret.
+	outbound, _ := h.Sign(PurposeApprovalRequest, ts, body)
+	if err := h.Verify(PurposeApprovalCallback, outbound, ts, body, now); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature across directions, got %v", err)
+	}
+	if _, err := (HMAC{}).Sign(PurposeApprovalCallback, ts, body); err == nil {
+		t.Fatalf("expected an error without a secret")
+	}
+}
+
+func TestEd25519SignVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	now := time.Unix(1766246054, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"approval_id":"a1"}`)
+
+	signer := Ed25519Signer{Key: testKey{priv: priv}}
+	sig, err := signer.Sign(PurposeApprovalRequest, ts, body)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if signer.KeyID() != "test" {
+		t.Fatalf("unexpected key id %q", signer.KeyID())
+	}
+	if err := (Ed25519Verifier{PublicKey: pub}).Verify(PurposeApprovalRequest, sig, ts, body, now); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	if err := (Ed25519Verifier{PublicKey: otherPub}).Verify(PurposeApprovalRequest, sig, ts, body, now); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature for another key, got %v", err)
+	}
+	// A signature for one timestamp does not verify under another.
+	later := strconv.FormatInt(now.Unix()+1, 10)
+	if err := (Ed25519Verifier{PublicKey: pub}).Verify(PurposeApprovalRequest, sig, later, body, now); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature for a moved timestamp, got %v", err)
+	}
+	if err := (Ed25519Verifier{PublicKey: pub}).Verify(PurposeApprovalRequest, "v1=abc", ts, body, now); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature for an hmac signature, got %v", err)
+	}
+}
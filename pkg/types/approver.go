@@ -0,0 +1,19 @@
+package types
+
+// Approver identifies who voted on an approval, as reported by the approval transport.
+// For Slack, ID is the user ID, Display the username, and TeamID the workspace.
+type Approver struct {
+	Kind    string   `json:"kind"` // "slack" | "api" | other (transport-defined)
+	ID      string   `json:"id"`
+	Display string   `json:"display,omitempty"`
+	TeamID  string   `json:"team_id,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+}
+
+// Subject returns the approver as "kind:id", or "" when the approver is anonymous.
+func (a Approver) Subject() string {
+	if a.ID == "" {
+		return ""
+	}
+	return a.Kind + ":" + a.ID
+}
@@ -8,6 +8,7 @@ type DecisionRecord struct {
 	Policy           DecisionPolicy `json:"policy"`
 	Verdict          string         `json:"verdict"`
 	ReasonCodes      []string       `json:"reason_codes,omitempty"`
+	MatchedRules     []string       `json:"matched_rules,omitempty"`
 	RequiresApproval bool           `json:"requires_approval"`
 	Risk             string         `json:"risk,omitempty"`
 }
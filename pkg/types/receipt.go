@@ -7,6 +7,7 @@ const (
 	OutcomeApprovalPending    OutcomeStatus = "approval_pending"
 	OutcomeApprovalApproved   OutcomeStatus = "approval_approved"
 	OutcomeApprovalDenied     OutcomeStatus = "approval_denied"
+	OutcomeApprovalExpired    OutcomeStatus = "approval_expired"
 	OutcomeIssuingCredentials OutcomeStatus = "issuing_credentials"
 	OutcomeIssuedCredentials  OutcomeStatus = "issued_credentials"
 	OutcomeDenied             OutcomeStatus = "denied"
@@ -38,15 +39,30 @@ type ReceiptPolicy struct {
 }
 
 type ReceiptApproval struct {
-	Required   bool   `json:"required"`
-	ApprovalID string `json:"approval_id,omitempty"`
-	Status     string `json:"status,omitempty"`
-	ApprovedAt string `json:"approved_at,omitempty"`
-	Approver   *struct {
-		Kind    string `json:"kind"`
-		ID      string `json:"id"`
-		Display string `json:"display"`
-	} `json:"approver,omitempty"`
+	Required   bool             `json:"required"`
+	ApprovalID string           `json:"approval_id,omitempty"`
+	Status     string           `json:"status,omitempty"`
+	ApprovedAt string           `json:"approved_at,omitempty"`
+	Approver   *ReceiptApprover `json:"approver,omitempty"`
+
+	// Quorum is the number of approvers required, when more than one.
+	Quorum int `json:"quorum,omitempty"`
+	// Approvers lists every vote cast so far, oldest first.
+	Approvers []ReceiptApprover `json:"approvers,omitempty"`
+	// ExpiresAt is when a pending approval times out, when the policy sets a timeout.
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+type ReceiptApprover struct {
+	Kind    string `json:"kind"`
+	ID      string `json:"id"`
+	Display string `json:"display"`
+	TeamID  string `json:"team_id,omitempty"`
+	// Vote is approved or denied; it is set in ReceiptApproval.Approvers.
+	Vote string `json:"vote,omitempty"`
+	At   string `json:"at,omitempty"`
+	// Comment is the approver's note, for approvals made through the API.
+	Comment string `json:"comment,omitempty"`
 }
 
 type ReceiptCredentialGrant struct {